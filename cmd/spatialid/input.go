@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// 経由点の入力形式
+const (
+	inputCSV     = "csv"     // 経度,緯度[,高さ]の行を並べたCSV
+	inputGeoJSON = "geojson" // LineStringのGeoJSON
+	inputJSON    = "json"    // {"lon", "lat", "alt"}の配列のJSON
+)
+
+// readWaypoints 経由点の読込
+//
+// 引数：
+//
+//	r： 読込元
+//	format： 入力形式。空文字列の場合は内容から判定する
+//
+// 戻り値：
+//
+//	経由点のリスト
+//
+// 戻り値(エラー)：
+//
+//	読込に失敗した場合、もしくは座標の値が不正な場合、エラーインスタンスが返却される。
+func readWaypoints(r io.Reader, format string) ([]*object.Point, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "" {
+		format = sniffInputFormat(data)
+	}
+
+	switch format {
+	case inputGeoJSON:
+		return readGeoJSON(data)
+	case inputJSON:
+		return readJSON(data)
+	default:
+		return readCSV(data)
+	}
+}
+
+// sniffInputFormat 内容から入力形式を判定
+//
+// 引数：
+//
+//	data： 入力内容
+//
+// 戻り値：
+//
+//	入力形式。"["で始まる場合はJSON、"{"で始まる場合はGeoJSON、それ以外はCSV
+func sniffInputFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		return inputJSON
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return inputGeoJSON
+	}
+	return inputCSV
+}
+
+// readCSV CSVの経由点の読込
+//
+// 1行を1経由点とし、経度,緯度[,高さ]の順に記載する。高さを省略した場合は0とする。
+// 先頭行が数値でない場合はヘッダ行とみなして読み飛ばす。"#"で始まる行はコメントとする。
+//
+// 引数：
+//
+//	data： 入力内容
+//
+// 戻り値：
+//
+//	経由点のリスト
+//
+// 戻り値(エラー)：
+//
+//	CSVの形式、もしくは座標の値が不正な場合、エラーインスタンスが返却される。
+func readCSV(data []byte) ([]*object.Point, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	points := []*object.Point{}
+	for i, record := range records {
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("%d行目: 経度,緯度[,高さ]の形式ではありません", i+1)
+		}
+
+		values := []float64{0, 0, 0}
+		var parseErr error
+		for j, field := range record {
+			values[j], parseErr = strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if parseErr != nil {
+				break
+			}
+		}
+		if parseErr != nil {
+			// 先頭行はヘッダ行として読み飛ばす
+			if i == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("%d行目: %w", i+1, parseErr)
+		}
+
+		point, err := object.NewPoint(values[0], values[1], values[2])
+		if err != nil {
+			return nil, fmt.Errorf("%d行目: %w", i+1, err)
+		}
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+// geoJSONObject 経由点の読込に使用するGeoJSONの構造
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSONObject  `json:"geometry"`
+	Features    []geoJSONObject `json:"features"`
+}
+
+// readGeoJSON GeoJSONの経由点の読込
+//
+// LineStringのジオメトリ、LineStringを持つFeature、
+// もしくはFeatureCollection内の最初のLineStringを経由点として読み込む。
+// 座標の3番目の値を高さとし、省略した場合は0とする。
+//
+// 引数：
+//
+//	data： 入力内容
+//
+// 戻り値：
+//
+//	経由点のリスト
+//
+// 戻り値(エラー)：
+//
+//	GeoJSONの形式、もしくは座標の値が不正な場合、エラーインスタンスが返却される。
+func readGeoJSON(data []byte) ([]*object.Point, error) {
+	root := geoJSONObject{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	lineString := findLineString(&root)
+	if lineString == nil {
+		return nil, fmt.Errorf("LineStringが見つかりません")
+	}
+
+	coordinates := [][]float64{}
+	if err := json.Unmarshal(lineString.Coordinates, &coordinates); err != nil {
+		return nil, err
+	}
+
+	points := []*object.Point{}
+	for i, coordinate := range coordinates {
+		if len(coordinate) < 2 {
+			return nil, fmt.Errorf("%d番目の座標: 経度,緯度がありません", i+1)
+		}
+		alt := 0.0
+		if len(coordinate) > 2 {
+			alt = coordinate[2]
+		}
+		point, err := object.NewPoint(coordinate[0], coordinate[1], alt)
+		if err != nil {
+			return nil, fmt.Errorf("%d番目の座標: %w", i+1, err)
+		}
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+// findLineString GeoJSONからLineStringを探索
+//
+// 引数：
+//
+//	obj： GeoJSONオブジェクト
+//
+// 戻り値：
+//
+//	最初に見つかったLineStringのジオメトリ。見つからない場合はnil
+func findLineString(obj *geoJSONObject) *geoJSONObject {
+	switch obj.Type {
+	case "LineString":
+		return obj
+	case "Feature":
+		if obj.Geometry != nil {
+			return findLineString(obj.Geometry)
+		}
+	case "FeatureCollection":
+		for i := range obj.Features {
+			if lineString := findLineString(&obj.Features[i]); lineString != nil {
+				return lineString
+			}
+		}
+	}
+	return nil
+}
+
+// jsonWaypoint JSONの経由点の構造
+type jsonWaypoint struct {
+	Lon *float64 `json:"lon"`
+	Lat *float64 `json:"lat"`
+	Alt float64  `json:"alt"`
+}
+
+// readJSON JSONの経由点の読込
+//
+// {"lon": 経度, "lat": 緯度, "alt": 高さ}の配列を読み込む。高さを省略した場合は0とする。
+//
+// 引数：
+//
+//	data： 入力内容
+//
+// 戻り値：
+//
+//	経由点のリスト
+//
+// 戻り値(エラー)：
+//
+//	JSONの形式、もしくは座標の値が不正な場合、エラーインスタンスが返却される。
+func readJSON(data []byte) ([]*object.Point, error) {
+	waypoints := []jsonWaypoint{}
+	if err := json.Unmarshal(data, &waypoints); err != nil {
+		return nil, err
+	}
+
+	points := []*object.Point{}
+	for i, waypoint := range waypoints {
+		if waypoint.Lon == nil || waypoint.Lat == nil {
+			return nil, fmt.Errorf("%d番目の経由点: lon, latがありません", i+1)
+		}
+		point, err := object.NewPoint(*waypoint.Lon, *waypoint.Lat, waypoint.Alt)
+		if err != nil {
+			return nil, fmt.Errorf("%d番目の経由点: %w", i+1, err)
+		}
+		points = append(points, point)
+	}
+
+	return points, nil
+}
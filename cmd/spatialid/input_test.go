@@ -0,0 +1,145 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestReadWaypoints01 正常系動作確認(CSV)
+//
+// 試験詳細：
+// + 試験データ
+//   - ヘッダ行、コメント行、高さを省略した行を含むCSV
+//
+// + 確認内容
+//   - ヘッダ行、コメント行が読み飛ばされること
+//   - 高さを省略した場合は0となること
+func TestReadWaypoints01(t *testing.T) {
+	//入力パラメータ
+	input := "lon,lat,alt\n# コメント\n139.753098,35.685371,11.0\n139.754, 35.686\n"
+
+	// テスト対象呼び出し
+	resultVal, err := readWaypoints(strings.NewReader(input), inputCSV)
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	// 経由点の比較
+	if len(resultVal) != 2 {
+		t.Fatalf("経由点数 - 期待値：2, 取得値：%v", len(resultVal))
+	}
+	if resultVal[0].Lon() != 139.753098 || resultVal[0].Lat() != 35.685371 || resultVal[0].Alt() != 11.0 {
+		t.Errorf("経由点 - 期待値：(139.753098, 35.685371, 11.0), 取得値：%v", resultVal[0])
+	}
+	if resultVal[1].Alt() != 0.0 {
+		t.Errorf("高さ - 期待値：0, 取得値：%v", resultVal[1].Alt())
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestReadWaypoints02 正常系動作確認(GeoJSON)
+//
+// 試験詳細：
+// + 試験データ
+//   - Point、LineStringのFeatureを含むFeatureCollection
+//
+// + 確認内容
+//   - 形式を省略した場合にGeoJSONと判定されること
+//   - LineStringの座標が経由点として読み込まれること
+func TestReadWaypoints02(t *testing.T) {
+	//入力パラメータ
+	input := `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "geometry": {"type": "Point", "coordinates": [139.0, 35.0]}, "properties": {}},
+		{"type": "Feature", "geometry": {"type": "LineString",
+			"coordinates": [[139.753098, 35.685371, 11.0], [139.754, 35.686]]}, "properties": {}}
+	]}`
+
+	// テスト対象呼び出し
+	resultVal, err := readWaypoints(strings.NewReader(input), "")
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	// 経由点の比較
+	if len(resultVal) != 2 {
+		t.Fatalf("経由点数 - 期待値：2, 取得値：%v", len(resultVal))
+	}
+	if resultVal[0].Alt() != 11.0 || resultVal[1].Lon() != 139.754 {
+		t.Errorf("経由点 - 取得値：%v, %v", resultVal[0], resultVal[1])
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestReadWaypoints03 正常系動作確認(JSON)
+//
+// 試験詳細：
+// + 試験データ
+//   - {"lon", "lat", "alt"}の配列
+//
+// + 確認内容
+//   - 形式を省略した場合にJSONと判定されること
+//   - 経由点が読み込まれること
+func TestReadWaypoints03(t *testing.T) {
+	//入力パラメータ
+	input := `[{"lon": 139.753098, "lat": 35.685371, "alt": 11.0}, {"lon": 139.754, "lat": 35.686}]`
+
+	// テスト対象呼び出し
+	resultVal, err := readWaypoints(strings.NewReader(input), "")
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	// 経由点の比較
+	if len(resultVal) != 2 {
+		t.Fatalf("経由点数 - 期待値：2, 取得値：%v", len(resultVal))
+	}
+	if resultVal[0].Alt() != 11.0 || resultVal[1].Lat() != 35.686 {
+		t.Errorf("経由点 - 取得値：%v, %v", resultVal[0], resultVal[1])
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestReadWaypoints04 異常系動作確認(入力値不正)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：2行目以降に数値でない値を含むCSV
+//   - パターン2：LineStringを含まないGeoJSON
+//   - パターン3：latを含まないJSON
+//   - パターン4：緯度が範囲外のCSV
+//
+// + 確認内容
+//   - 戻り値としてエラー内容が返却されること
+func TestReadWaypoints04(t *testing.T) {
+	//入力パラメータ
+	inputs := []struct {
+		input  string
+		format string
+	}{
+		{"139.0,35.0\nabc,35.0\n", inputCSV},
+		{`{"type": "Point", "coordinates": [139.0, 35.0]}`, inputGeoJSON},
+		{`[{"lon": 139.0}]`, inputJSON},
+		{"139.0,95.0\n", inputCSV},
+	}
+
+	for _, input := range inputs {
+		// テスト対象呼び出し
+		_, err := readWaypoints(strings.NewReader(input.input), input.format)
+
+		// エラーが返されない場合はErrorをログに出力
+		if err == nil {
+			t.Errorf("error - 期待値：エラー, 取得値：nil, 入力値：%v", input.input)
+		}
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,253 @@
+// spatialid 経路の空間ID取得コマンド
+//
+// 経由点のファイル(CSV、GeoJSON LineString、JSON)を読み込み、
+// 経由点を結ぶ円柱(またはカプセル)の経路が通る空間IDを出力する。
+//
+// 使用例：
+//
+//	spatialid -input route.csv -radius 5 -hzoom 25 -vzoom 25
+//	spatialid -input route.geojson -radius 5 -zoom 23 -capsule -format geojson -output route_ids.geojson
+//	spatialid -input route.csv -radius 5 -dem dem.tif -agl
+//
+// 指定可能なオプションは "spatialid -h" で確認できる。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+	"github.com/trajectoryjp/spatial_id_plus_go/terrain"
+)
+
+// config コマンドの設定構造体
+type config struct {
+	input       string  // 経由点の入力ファイル("-"の場合は標準入力)
+	inputFormat string  // 経由点の入力形式
+	output      string  // 空間IDの出力ファイル("-"の場合は標準出力)
+	format      string  // 空間IDの出力形式
+	radius      float64 // 円柱の半径(単位:m)
+	hZoom       int64   // 水平方向の精度レベル
+	vZoom       int64   // 垂直方向の精度レベル
+	zoom        int64   // 空間IDの精度レベル(0以上の場合は空間IDを出力)
+	isCapsule   bool    // カプセル判定
+	isPrecision bool    // 衝突判定実施オプション
+	dem         string  // 数値標高モデルのファイル(空文字列の場合は地形を考慮しない)
+	isAGL       bool    // 経由点の高さを地上高とするか
+}
+
+// isSpatialID 空間IDを出力するかを示す
+//
+// 戻り値：
+//
+//	True: 空間ID / False: 拡張空間ID
+func (c config) isSpatialID() bool {
+	return c.zoom >= 0
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "spatialid:", err)
+		os.Exit(1)
+	}
+}
+
+// parseFlags コマンドライン引数の解析
+//
+// 引数：
+//
+//	args： コマンドライン引数(コマンド名を除く)
+//
+// 戻り値：
+//
+//	コマンドの設定
+//
+// 戻り値(エラー)：
+//
+//	引数の解析に失敗した場合、もしくは出力形式、入力形式が不正な場合、エラーインスタンスが返却される。
+func parseFlags(args []string) (*config, error) {
+	c := new(config)
+
+	flags := flag.NewFlagSet("spatialid", flag.ContinueOnError)
+	flags.StringVar(&c.input, "input", "-", "経由点の入力ファイル(\"-\"の場合は標準入力)")
+	flags.StringVar(&c.inputFormat, "input-format", "",
+		"経由点の入力形式: csv, geojson, json(省略時は拡張子または内容から判定)")
+	flags.StringVar(&c.output, "output", "-", "空間IDの出力ファイル(\"-\"の場合は標準出力)")
+	flags.StringVar(&c.format, "format", formatText,
+		"空間IDの出力形式: text, json, geojson, compressed")
+	flags.Float64Var(&c.radius, "radius", 0, "円柱の半径(単位:m)")
+	flags.Int64Var(&c.hZoom, "hzoom", 25, "水平方向の精度レベル")
+	flags.Int64Var(&c.vZoom, "vzoom", 25, "垂直方向の精度レベル")
+	flags.Int64Var(&c.zoom, "zoom", -1,
+		"空間IDの精度レベル。指定した場合は-hzoom, -vzoomの代わりに使用し、空間IDを出力する")
+	flags.BoolVar(&c.isCapsule, "capsule", false, "始点、終点を球状とする(カプセル)。省略時は円柱")
+	flags.BoolVar(&c.isPrecision, "precision", true, "衝突判定を実施する")
+	flags.StringVar(&c.dem, "dem", "",
+		"数値標高モデルのファイル(.asc, .tif)。指定した場合は地中の空間IDを除外する")
+	flags.BoolVar(&c.isAGL, "agl", false, "経由点の高さを地上高とする(-demの指定が必要)")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("不明な引数です: %s", strings.Join(flags.Args(), " "))
+	}
+	if c.isAGL && c.dem == "" {
+		return nil, fmt.Errorf("-aglを指定する場合は-demを指定してください")
+	}
+
+	switch c.format {
+	case formatText, formatJSON, formatGeoJSON, formatCompressed:
+	default:
+		return nil, fmt.Errorf("出力形式が不正です: %s", c.format)
+	}
+
+	if c.inputFormat == "" {
+		c.inputFormat = detectInputFormat(c.input)
+	}
+	switch c.inputFormat {
+	case "", inputCSV, inputGeoJSON, inputJSON:
+	default:
+		return nil, fmt.Errorf("入力形式が不正です: %s", c.inputFormat)
+	}
+
+	return c, nil
+}
+
+// detectInputFormat ファイルの拡張子から入力形式を判定
+//
+// 引数：
+//
+//	path： 入力ファイルのパス
+//
+// 戻り値：
+//
+//	入力形式。判定できない場合は空文字列
+func detectInputFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return inputCSV
+	case ".geojson":
+		return inputGeoJSON
+	case ".json":
+		return inputJSON
+	}
+	return ""
+}
+
+// run コマンドの実行
+//
+// 引数：
+//
+//	args： コマンドライン引数(コマンド名を除く)
+//	stdin： 標準入力
+//	stdout： 標準出力
+//
+// 戻り値(エラー)：
+//
+//	入出力、空間IDの取得に失敗した場合、エラーインスタンスが返却される。
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	c, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	// 経由点の読込
+	reader := stdin
+	if c.input != "-" {
+		file, err := os.Open(c.input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+	points, err := readWaypoints(reader, c.inputFormat)
+	if err != nil {
+		return fmt.Errorf("経由点の読込に失敗しました: %w", err)
+	}
+
+	// 数値標高モデルの読込
+	var dem shape.ElevationModel
+	if c.dem != "" {
+		d, err := terrain.Open(c.dem)
+		if err != nil {
+			return fmt.Errorf("数値標高モデルの読込に失敗しました: %w", err)
+		}
+		dem = d
+	}
+
+	// 空間IDの取得
+	spatialIDs, err := calcSpatialIDs(points, c, dem)
+	if err != nil {
+		return fmt.Errorf("空間IDの取得に失敗しました: %w", err)
+	}
+
+	// 空間IDの出力
+	if c.output == "-" {
+		return writeSpatialIDs(stdout, spatialIDs, c)
+	}
+	file, err := os.Create(c.output)
+	if err != nil {
+		return err
+	}
+	if err := writeSpatialIDs(file, spatialIDs, c); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// calcSpatialIDs 経路の空間ID取得
+//
+// 精度レベルが指定されている場合はGetSpatialIdsOnCylindersで空間IDを、
+// それ以外の場合はGetExtendedSpatialIdsOnCylindersで拡張空間IDを取得する。
+//
+// 引数：
+//
+//	points： 経由点
+//	c： コマンドの設定
+//	dem： 地表面の標高モデル(nilの場合は地形を考慮しない)
+//
+// 戻り値：
+//
+//	空間ID、もしくは拡張空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	空間IDの取得に失敗した場合、エラーインスタンスが返却される。
+func calcSpatialIDs(points []*object.Point, c *config, dem shape.ElevationModel) ([]string, error) {
+	// 地上高の変換に使用する標高モデル(nilの場合は変換しない)
+	var aboveGround shape.ElevationModel
+	if c.isAGL {
+		aboveGround = dem
+	}
+
+	if c.isSpatialID() {
+		return shape.GetSpatialIdsOnCylinders(
+			points,
+			c.radius,
+			c.zoom,
+			c.isCapsule,
+			shape.IsPrecision(c.isPrecision),
+			shape.TerrainClip(dem),
+			shape.AboveGroundLevel(aboveGround),
+		)
+	}
+
+	return shape.GetExtendedSpatialIdsOnCylinders(
+		points,
+		c.radius,
+		c.hZoom,
+		c.vZoom,
+		c.isCapsule,
+		shape.IsPrecision(c.isPrecision),
+		shape.TerrainClip(dem),
+		shape.AboveGroundLevel(aboveGround),
+	)
+}
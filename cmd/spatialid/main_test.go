@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+	"github.com/trajectoryjp/spatial_id_plus_go/terrain"
+)
+
+// TestParseFlags01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 引数：-input route.geojson -radius 5 -zoom 23 -capsule -precision=false
+//
+// + 確認内容
+//   - 引数が設定に反映されること
+//   - 拡張子から入力形式が判定されること
+func TestParseFlags01(t *testing.T) {
+	//入力パラメータ
+	args := []string{"-input", "route.geojson", "-radius", "5", "-zoom", "23", "-capsule", "-precision=false"}
+
+	// 期待値
+	expectVal := config{
+		input:       "route.geojson",
+		inputFormat: inputGeoJSON,
+		output:      "-",
+		format:      formatText,
+		radius:      5,
+		hZoom:       25,
+		vZoom:       25,
+		zoom:        23,
+		isCapsule:   true,
+		isPrecision: false,
+	}
+
+	// テスト対象呼び出し
+	resultVal, err := parseFlags(args)
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	if *resultVal != expectVal {
+		t.Errorf("設定 - 期待値：%+v, 取得値：%+v", expectVal, *resultVal)
+	}
+	if !resultVal.isSpatialID() {
+		t.Errorf("空間ID出力 - 期待値：true, 取得値：false")
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestParseFlags02 異常系動作確認(引数不正)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：-format xml(不正値)
+//   - パターン2：-input-format kml(不正値)
+//   - パターン3：不明な引数
+//   - パターン4：-demなしで-agl
+//
+// + 確認内容
+//   - 戻り値としてエラー内容が返却されること
+func TestParseFlags02(t *testing.T) {
+	for _, args := range [][]string{
+		{"-format", "xml"},
+		{"-input-format", "kml"},
+		{"-radius", "5", "route.csv"},
+		{"-radius", "5", "-agl"},
+	} {
+		// テスト対象呼び出し
+		_, err := parseFlags(args)
+
+		// エラーが返されない場合はErrorをログに出力
+		if err == nil {
+			t.Errorf("error - 期待値：エラー, 取得値：nil, 引数：%v", args)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestRun01 正常系動作確認(標準入力から標準出力)
+//
+// 試験詳細：
+// + 試験データ
+//   - 標準入力：経由点2点のCSV
+//   - 引数：-radius 2 -hzoom 25 -vzoom 25
+//
+// + 確認内容
+//   - GetExtendedSpatialIdsOnCylindersと同じ拡張空間IDが出力されること
+func TestRun01(t *testing.T) {
+	//入力パラメータ
+	stdin := strings.NewReader("139.753098,35.685371,11.0\n139.753198,35.685371,11.0\n")
+	stdout := new(bytes.Buffer)
+
+	// 期待値
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+	spatialIDs, _ := shape.GetExtendedSpatialIdsOnCylinders(
+		[]*object.Point{p1, p2}, 2.0, 25, 25, false, shape.IsPrecision(true),
+	)
+	expectVal := strings.Join(spatialIDs, "\n") + "\n"
+
+	// テスト対象呼び出し
+	err := run([]string{"-radius", "2", "-hzoom", "25", "-vzoom", "25"}, stdin, stdout)
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	if stdout.String() != expectVal {
+		t.Errorf("出力 - 期待値：%v, 取得値：%v", expectVal, stdout.String())
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestRun02 正常系動作確認(ファイル入出力)
+//
+// 試験詳細：
+// + 試験データ
+//   - 入力ファイル：経由点1点のJSON
+//   - 引数：-radius 2 -zoom 25 -format json -output (一時ファイル)
+//
+// + 確認内容
+//   - 出力ファイルに空間IDのJSONが出力されること
+func TestRun02(t *testing.T) {
+	//入力パラメータ
+	dir := t.TempDir()
+	input := filepath.Join(dir, "route.json")
+	output := filepath.Join(dir, "ids.json")
+	os.WriteFile(input, []byte(`[{"lon": 139.753098, "lat": 35.685371, "alt": 11.0}]`), 0o600)
+
+	// テスト対象呼び出し
+	err := run(
+		[]string{"-input", input, "-radius", "2", "-zoom", "25", "-format", "json", "-output", output},
+		strings.NewReader(""),
+		new(bytes.Buffer),
+	)
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	resultVal, _ := os.ReadFile(output)
+	if !strings.HasPrefix(string(resultVal), `["25/`) {
+		t.Errorf("出力ファイル - 取得値：%s", resultVal)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestRun03 異常系動作確認(半径不正)
+//
+// 試験詳細：
+// + 試験データ
+//   - 標準入力：経由点1点のCSV
+//   - 引数：-radius 0
+//
+// + 確認内容
+//   - 戻り値としてエラー内容が返却されること
+func TestRun03(t *testing.T) {
+	// テスト対象呼び出し
+	err := run([]string{"-radius", "0"}, strings.NewReader("139.753098,35.685371,11.0\n"), new(bytes.Buffer))
+
+	// エラーが返されない場合はErrorをログに出力
+	if err == nil || !strings.Contains(err.Error(), "InputValueError") {
+		t.Errorf("error - 期待値：InputValueError, 取得値：%v", err)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestRun04 正常系動作確認(数値標高モデル)
+//
+// 試験詳細：
+// + 試験データ
+//   - 標準入力：経由点2点のCSV(高さ1.0m)
+//   - 数値標高モデル：標高10mの平坦なASCIIグリッド
+//   - 引数：-radius 2 -dem (一時ファイル) -agl
+//
+// + 確認内容
+//   - 経由点の高さを11.0mとし、標高10mより低い空間IDを除外した拡張空間IDが出力されること
+//   - 数値標高モデルが読み込めない場合はエラーとなること
+func TestRun04(t *testing.T) {
+	//入力パラメータ
+	dem := filepath.Join(t.TempDir(), "dem.asc")
+	grid := "ncols 1\nnrows 1\nxllcorner 139.0\nyllcorner 35.0\ncellsize 1\n10\n"
+	if err := os.WriteFile(dem, []byte(grid), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stdin := "139.753098,35.685371,1.0\n139.753198,35.685371,1.0\n"
+	stdout := new(bytes.Buffer)
+
+	// 期待値
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+	allIDs, _ := shape.GetExtendedSpatialIdsOnCylinders([]*object.Point{p1, p2}, 2.0, 25, 25, false)
+	terrainDEM, _ := terrain.Open(dem)
+	spatialIDs, _, _ := shape.ClassifyExtendedSpatialIdsByTerrain(allIDs, terrainDEM)
+	expectVal := strings.Join(spatialIDs, "\n") + "\n"
+
+	// テスト対象呼び出し
+	err := run([]string{"-radius", "2", "-dem", dem, "-agl"}, strings.NewReader(stdin), stdout)
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	if stdout.String() != expectVal {
+		t.Errorf("出力 - 期待値：%v, 取得値：%v", expectVal, stdout.String())
+	}
+
+	err = run([]string{"-radius", "2", "-dem", dem + ".missing"}, strings.NewReader(stdin), new(bytes.Buffer))
+	if err == nil {
+		t.Error("error - 期待値：エラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	sidshape "github.com/trajectoryjp/spatial_id_go/shape"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+)
+
+// 空間IDの出力形式
+const (
+	formatText       = "text"       // 1行に1つの空間ID
+	formatJSON       = "json"       // 空間IDの配列のJSON
+	formatGeoJSON    = "geojson"    // ボクセルの水平面を多角形としたGeoJSONのFeatureCollection
+	formatCompressed = "compressed" // 子ボクセルを親ボクセルに統合した空間ID(1行に1つ)
+)
+
+// writeSpatialIDs 空間IDの出力
+//
+// 引数：
+//
+//	w： 出力先
+//	spatialIDs： 空間ID、もしくは拡張空間IDのリスト
+//	c： コマンドの設定
+//
+// 戻り値(エラー)：
+//
+//	出力先への書き込み、空間IDの変換に失敗した場合、エラーインスタンスが返却される。
+func writeSpatialIDs(w io.Writer, spatialIDs []string, c *config) error {
+	switch c.format {
+	case formatJSON:
+		return json.NewEncoder(w).Encode(spatialIDs)
+	case formatGeoJSON:
+		return writeGeoJSON(w, spatialIDs, c.isSpatialID())
+	case formatCompressed:
+		compressed, err := compressSpatialIDs(spatialIDs, c.isSpatialID())
+		if err != nil {
+			return err
+		}
+		return writeText(w, compressed)
+	default:
+		return writeText(w, spatialIDs)
+	}
+}
+
+// writeText 空間IDのテキスト出力
+//
+// 引数：
+//
+//	w： 出力先
+//	spatialIDs： 空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	出力先への書き込みに失敗した場合、エラーインスタンスが返却される。
+func writeText(w io.Writer, spatialIDs []string) error {
+	writer := bufio.NewWriter(w)
+	for _, spatialID := range spatialIDs {
+		fmt.Fprintln(writer, spatialID)
+	}
+	return writer.Flush()
+}
+
+// toExtendedSpatialID 空間IDを拡張空間IDに変換
+//
+// 引数：
+//
+//	spatialID： 空間ID(精度/高さ/X/Y)
+//
+// 戻り値：
+//
+//	拡張空間ID(水平精度/X/Y/垂直精度/高さ)
+//
+// 戻り値(エラー)：
+//
+//	空間IDのフォーマットが不正な場合、エラーインスタンスが返却される。
+func toExtendedSpatialID(spatialID string) (string, error) {
+	ids := strings.Split(spatialID, consts.SpatialIDDelimiter)
+	if len(ids) != 4 {
+		return "", fmt.Errorf("空間IDのフォーマットが不正です: %s", spatialID)
+	}
+	return strings.Join([]string{ids[0], ids[2], ids[3], ids[0], ids[1]}, consts.SpatialIDDelimiter), nil
+}
+
+// toExtendedSpatialIDs 空間IDのリストを拡張空間IDのリストに変換
+//
+// 引数：
+//
+//	spatialIDs： 空間ID、もしくは拡張空間IDのリスト
+//	isSpatialID： 入力が空間IDであるかを示す
+//
+// 戻り値：
+//
+//	拡張空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	空間IDのフォーマットが不正な場合、エラーインスタンスが返却される。
+func toExtendedSpatialIDs(spatialIDs []string, isSpatialID bool) ([]string, error) {
+	if !isSpatialID {
+		return spatialIDs, nil
+	}
+
+	extendedIDs := make([]string, 0, len(spatialIDs))
+	for _, spatialID := range spatialIDs {
+		extendedID, err := toExtendedSpatialID(spatialID)
+		if err != nil {
+			return nil, err
+		}
+		extendedIDs = append(extendedIDs, extendedID)
+	}
+	return extendedIDs, nil
+}
+
+// compressSpatialIDs 空間IDの統合
+//
+// 子ボクセルが全て揃っている空間IDを親ボクセルの空間IDに統合する。
+//
+// 引数：
+//
+//	spatialIDs： 空間ID、もしくは拡張空間IDのリスト
+//	isSpatialID： 入力が空間IDであるかを示す
+//
+// 戻り値：
+//
+//	統合後の空間ID、もしくは拡張空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	空間IDのフォーマットが不正な場合、エラーインスタンスが返却される。
+func compressSpatialIDs(spatialIDs []string, isSpatialID bool) ([]string, error) {
+	extendedIDs, err := toExtendedSpatialIDs(spatialIDs, isSpatialID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := shape.MergeExtendedSpatialIds(extendedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 水平方向精度と垂直方向精度は同時に統合されるため、空間IDの形式に戻せる
+	if isSpatialID {
+		return sidshape.ConvertExtendedSpatialIdsToSpatialIds(merged)
+	}
+	return merged, nil
+}
+
+// geoJSONFeatureCollection 出力するGeoJSONのFeatureCollection
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// geoJSONFeature 出力するGeoJSONのFeature
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONPolygon         `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// geoJSONPolygon 出力するGeoJSONのPolygon
+type geoJSONPolygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+// writeGeoJSON 空間IDのGeoJSON出力
+//
+// ボクセルの水平面を多角形とし、空間IDと高さの範囲をプロパティとしたFeatureを出力する。
+//
+// 引数：
+//
+//	w： 出力先
+//	spatialIDs： 空間ID、もしくは拡張空間IDのリスト
+//	isSpatialID： 入力が空間IDであるかを示す
+//
+// 戻り値(エラー)：
+//
+//	出力先への書き込み、空間IDの変換に失敗した場合、エラーインスタンスが返却される。
+func writeGeoJSON(w io.Writer, spatialIDs []string, isSpatialID bool) error {
+	extendedIDs, err := toExtendedSpatialIDs(spatialIDs, isSpatialID)
+	if err != nil {
+		return err
+	}
+
+	collection := geoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]geoJSONFeature, 0, len(spatialIDs)),
+	}
+	for i, extendedID := range extendedIDs {
+		points, err := sidshape.GetPointOnExtendedSpatialId(extendedID, enum.Vertex)
+		if err != nil {
+			return err
+		}
+
+		// ボクセルの経度、緯度、高さの範囲
+		minLon, minLat, minAlt := math.Inf(1), math.Inf(1), math.Inf(1)
+		maxLon, maxLat, maxAlt := math.Inf(-1), math.Inf(-1), math.Inf(-1)
+		for _, point := range points {
+			minLon, maxLon = math.Min(minLon, point.Lon()), math.Max(maxLon, point.Lon())
+			minLat, maxLat = math.Min(minLat, point.Lat()), math.Max(maxLat, point.Lat())
+			minAlt, maxAlt = math.Min(minAlt, point.Alt()), math.Max(maxAlt, point.Alt())
+		}
+
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type: "Feature",
+			Geometry: geoJSONPolygon{
+				Type: "Polygon",
+				// 外周は反時計回り(RFC 7946)
+				Coordinates: [][][2]float64{{
+					{minLon, minLat},
+					{maxLon, minLat},
+					{maxLon, maxLat},
+					{minLon, maxLat},
+					{minLon, minLat},
+				}},
+			},
+			Properties: map[string]interface{}{
+				"id":     spatialIDs[i],
+				"minAlt": minAlt,
+				"maxAlt": maxAlt,
+			},
+		})
+	}
+
+	return json.NewEncoder(w).Encode(collection)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestWriteSpatialIDs01 正常系動作確認(テキスト、JSON)
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：25/1/2/25/3, 25/1/2/25/4
+//
+// + 確認内容
+//   - テキスト形式では1行に1つの拡張空間IDが出力されること
+//   - JSON形式では拡張空間IDの配列が出力されること
+func TestWriteSpatialIDs01(t *testing.T) {
+	//入力パラメータ
+	spatialIDs := []string{"25/1/2/25/3", "25/1/2/25/4"}
+
+	// テスト対象呼び出し
+	text := new(bytes.Buffer)
+	textErr := writeSpatialIDs(text, spatialIDs, &config{format: formatText, zoom: -1})
+	jsonText := new(bytes.Buffer)
+	jsonErr := writeSpatialIDs(jsonText, spatialIDs, &config{format: formatJSON, zoom: -1})
+
+	// エラーが返された場合はErrorをログに出力
+	if textErr != nil || jsonErr != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v, %v", textErr, jsonErr)
+	}
+
+	// テキスト形式の比較
+	if text.String() != "25/1/2/25/3\n25/1/2/25/4\n" {
+		t.Errorf("テキスト形式 - 取得値：%q", text.String())
+	}
+
+	// JSON形式の比較
+	resultVal := []string{}
+	json.Unmarshal(jsonText.Bytes(), &resultVal)
+	if !reflect.DeepEqual(spatialIDs, resultVal) {
+		t.Errorf("JSON形式 - 期待値：%v, 取得値：%v", spatialIDs, resultVal)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestWriteSpatialIDs02 正常系動作確認(統合形式)
+//
+// 試験詳細：
+// + 試験データ
+//   - 空間ID：24/0/2/2 を構成する精度25の8個の空間ID
+//
+// + 確認内容
+//   - 精度24の空間ID1個に統合されて出力されること
+func TestWriteSpatialIDs02(t *testing.T) {
+	//入力パラメータ
+	spatialIDs := []string{}
+	for _, f := range []string{"0", "1"} {
+		for _, x := range []string{"4", "5"} {
+			for _, y := range []string{"4", "5"} {
+				spatialIDs = append(spatialIDs, strings.Join([]string{"25", f, x, y}, "/"))
+			}
+		}
+	}
+
+	// テスト対象呼び出し
+	buffer := new(bytes.Buffer)
+	err := writeSpatialIDs(buffer, spatialIDs, &config{format: formatCompressed, zoom: 25})
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	if buffer.String() != "24/0/2/2\n" {
+		t.Errorf("統合形式 - 期待値：%q, 取得値：%q", "24/0/2/2\n", buffer.String())
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestWriteSpatialIDs03 正常系動作確認(GeoJSON)
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：25/29803148/13212522/25/0
+//
+// + 確認内容
+//   - FeatureCollectionにボクセル1個分のPolygonが出力されること
+//   - プロパティに拡張空間IDと高さの範囲が出力されること
+func TestWriteSpatialIDs03(t *testing.T) {
+	//入力パラメータ
+	spatialIDs := []string{"25/29803148/13212522/25/0"}
+
+	// テスト対象呼び出し
+	buffer := new(bytes.Buffer)
+	err := writeSpatialIDs(buffer, spatialIDs, &config{format: formatGeoJSON, zoom: -1})
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	resultVal := geoJSONFeatureCollection{}
+	json.Unmarshal(buffer.Bytes(), &resultVal)
+	if resultVal.Type != "FeatureCollection" || len(resultVal.Features) != 1 {
+		t.Fatalf("FeatureCollection - 取得値：%v", resultVal)
+	}
+
+	feature := resultVal.Features[0]
+	if feature.Geometry.Type != "Polygon" || len(feature.Geometry.Coordinates[0]) != 5 {
+		t.Errorf("ジオメトリ - 取得値：%v", feature.Geometry)
+	}
+	if feature.Properties["id"] != spatialIDs[0] {
+		t.Errorf("拡張空間ID - 期待値：%v, 取得値：%v", spatialIDs[0], feature.Properties["id"])
+	}
+	if feature.Properties["minAlt"] != 0.0 || feature.Properties["maxAlt"] != 1.0 {
+		t.Errorf("高さの範囲 - 期待値：0～1, 取得値：%v～%v",
+			feature.Properties["minAlt"], feature.Properties["maxAlt"])
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestToExtendedSpatialID01 異常系動作確認(空間IDのフォーマット不正)
+//
+// 試験詳細：
+// + 試験データ
+//   - 空間ID：25/0/1/2/3
+//
+// + 確認内容
+//   - 戻り値としてエラー内容が返却されること
+func TestToExtendedSpatialID01(t *testing.T) {
+	// テスト対象呼び出し
+	_, err := toExtendedSpatialID("25/0/1/2/3")
+
+	// エラーが返されない場合はErrorをログに出力
+	if err == nil {
+		t.Errorf("error - 期待値：エラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
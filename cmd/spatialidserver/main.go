@@ -0,0 +1,66 @@
+// spatialidserver 空間ID取得HTTPサーバコマンド
+//
+// httpapiパッケージのハンドラをHTTPサーバとして起動する。
+// エンドポイントはhttpapiパッケージを参照。
+//
+// 使用例：
+//
+//	spatialidserver -addr :8080 -max-body-bytes 1048576 -max-points 1000 -max-spatial-ids 1000000
+//
+// SIGINT、SIGTERMを受信した場合は処理中のリクエストの完了を待って終了する。
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/trajectoryjp/spatial_id_plus_go/httpapi"
+)
+
+// shutdownTimeout 終了時に処理中のリクエストの完了を待つ時間
+const shutdownTimeout = 30 * time.Second
+
+func main() {
+	addr := flag.String("addr", ":8080", "待ち受けるアドレス")
+	maxBodyBytes := flag.Int64("max-body-bytes", httpapi.DefaultMaxBodyBytes, "リクエストボディの最大サイズ(単位:byte)")
+	maxPoints := flag.Int("max-points", httpapi.DefaultMaxPoints, "円柱の接続点数の上限")
+	maxSpatialIDs := flag.Int("max-spatial-ids", httpapi.DefaultMaxSpatialIDs, "見積もった空間IDの数の上限")
+	flag.Parse()
+
+	server := &http.Server{
+		Addr: *addr,
+		Handler: httpapi.NewHandler(
+			httpapi.MaxBodyBytes(*maxBodyBytes),
+			httpapi.MaxPoints(*maxPoints),
+			httpapi.MaxSpatialIDs(*maxSpatialIDs),
+		),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Println("spatialidserver:", err)
+		}
+	}()
+
+	log.Printf("spatialidserver: %s で待ち受けを開始します", *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, "spatialidserver:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,239 @@
+// Package geoid ジオイドモデルパッケージ
+//
+// 国土地理院のジオイドモデル(日本のジオイド2011等)の格子データをファイルから読み込み、
+// 任意の地点のジオイド高を補間して返却する。
+// 標高(ジオイド面からの高さ)にジオイド高を加えると楕円体高となる。
+//
+// 使用例：
+//
+//	model, err := geoid.Open("gsigeo2011_ver2_2.asc")
+//	...
+//	ids, err := shape.GetExtendedSpatialIdsOnCylinders(
+//		center, radius, hZoom, vZoom, false,
+//		shape.Altitude(shape.AltitudeOrthometric), shape.Geoid(model),
+//	)
+package geoid
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+)
+
+// MissingValue 国土地理院のジオイドモデルの欠損値
+const MissingValue = 999.0
+
+// maxGridPoints 読み込む格子点数の上限
+const maxGridPoints = 1 << 26
+
+// Model ジオイドモデル構造体
+//
+// 格子点は南西端から東方向、北方向の順に保持する。欠損値はNaNとして保持する。
+type Model struct {
+	south  float64   // 南西端の格子点の緯度
+	west   float64   // 南西端の格子点の経度
+	dLat   float64   // 緯度方向の格子間隔(単位:度)
+	dLon   float64   // 経度方向の格子間隔(単位:度)
+	rows   int       // 緯度方向の格子点数
+	cols   int       // 経度方向の格子点数
+	values []float64 // ジオイド高(単位:m)
+}
+
+// New ジオイドモデル初期化関数
+//
+// 引数：
+//
+//	south： 南西端の格子点の緯度
+//	west： 南西端の格子点の経度
+//	dLat： 緯度方向の格子間隔(単位:度)
+//	dLon： 経度方向の格子間隔(単位:度)
+//	rows： 緯度方向の格子点数
+//	cols： 経度方向の格子点数
+//	values： 南西端から東方向、北方向の順に並べたジオイド高(単位:m)。欠損値はNaN
+//
+// 戻り値：
+//
+//	初期化したModelオブジェクト
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力値不正： 格子間隔が0以下、格子点数が1以下、もしくはジオイド高の数が格子点数と一致しない場合
+func New(south, west, dLat, dLon float64, rows, cols int, values []float64) (*Model, error) {
+	if !(dLat > 0) || !(dLon > 0) || rows < 2 || cols < 2 || len(values) != rows*cols {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "ジオイドモデル: 格子の定義が不正です")
+	}
+	return &Model{
+		south:  south,
+		west:   west,
+		dLat:   dLat,
+		dLon:   dLon,
+		rows:   rows,
+		cols:   cols,
+		values: values,
+	}, nil
+}
+
+// Open ジオイドモデルファイル読込
+//
+// 引数：
+//
+//	path： 国土地理院のジオイドモデルのASCII形式のファイルのパス
+//
+// 戻り値：
+//
+//	読み込んだModelオブジェクト
+//
+// 戻り値(エラー)：
+//
+//	ファイルが読み込めない場合、もしくは形式が不正な場合、エラーインスタンスが返却される。
+func Open(path string) (*Model, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Read(file)
+}
+
+// Read 国土地理院のジオイドモデルのASCII形式の読込
+//
+// 先頭行は南西端の緯度、経度、緯度方向、経度方向の格子間隔、緯度方向、経度方向の格子点数、
+// データ種別、バージョンとし、続けて南の行から順にジオイド高を記述したものとする。
+// 格子間隔は秒単位に丸めて扱う(0.016667度は1分とする)。
+// 999.0000は欠損値とする。
+//
+// 引数：
+//
+//	r： 読込元
+//
+// 戻り値：
+//
+//	読み込んだModelオブジェクト
+//
+// 戻り値(エラー)：
+//
+//	ヘッダ、もしくはジオイド高の記述が不正な場合、エラーインスタンスが返却される。
+func Read(r io.Reader) (*Model, error) {
+	reader := bufio.NewReader(r)
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return nil, formatError("ヘッダがありません")
+	}
+	header := []float64{}
+	for _, word := range strings.Fields(line) {
+		v, err := strconv.ParseFloat(word, 64)
+		if err != nil {
+			break
+		}
+		header = append(header, v)
+	}
+	if len(header) < 6 {
+		return nil, formatError("ヘッダが不正です")
+	}
+	if !isGridCount(header[4]) || !isGridCount(header[5]) || header[4]*header[5] > maxGridPoints {
+		return nil, formatError("格子点数が不正です")
+	}
+	rows, cols := int(header[4]), int(header[5])
+
+	// ヘッダの格子点数では確保せず、読み込んだジオイド高の数に応じて拡張する
+	values := []float64{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Split(bufio.ScanWords)
+	for len(values) < rows*cols && scanner.Scan() {
+		v, err := strconv.ParseFloat(scanner.Text(), 64)
+		if err != nil {
+			return nil, formatError("ジオイド高の値が不正です: " + scanner.Text())
+		}
+		if v == MissingValue {
+			v = math.NaN()
+		}
+		values = append(values, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(values) != rows*cols {
+		return nil, formatError("ジオイド高の数が格子点数と一致しません")
+	}
+
+	return New(header[0], header[1], toArcSecond(header[2]), toArcSecond(header[3]), rows, cols, values)
+}
+
+// GeoidHeight ジオイド高取得
+//
+// 周囲4つの格子点のジオイド高を双線形補間して返却する。
+//
+// 引数：
+//
+//	lon： 経度
+//	lat： 緯度
+//
+// 戻り値：
+//
+//	ジオイド高(単位:m)
+//	ジオイド高を取得できたか。範囲外の場合、もしくは周囲の格子点に欠損値を含む場合はfalse
+func (m *Model) GeoidHeight(lon, lat float64) (float64, bool) {
+	fx := (lon - m.west) / m.dLon
+	fy := (lat - m.south) / m.dLat
+	if !(fx >= 0 && fx <= float64(m.cols-1) && fy >= 0 && fy <= float64(m.rows-1)) {
+		return 0, false
+	}
+
+	// 北端、東端の格子点上の場合は1つ内側の格子で補間する
+	x0, y0 := min(int(fx), m.cols-2), min(int(fy), m.rows-2)
+	tx, ty := fx-float64(x0), fy-float64(y0)
+
+	v00 := m.values[y0*m.cols+x0]
+	v10 := m.values[y0*m.cols+x0+1]
+	v01 := m.values[(y0+1)*m.cols+x0]
+	v11 := m.values[(y0+1)*m.cols+x0+1]
+	v := (1-tx)*(1-ty)*v00 + tx*(1-ty)*v10 + (1-tx)*ty*v01 + tx*ty*v11
+	if math.IsNaN(v) {
+		return 0, false
+	}
+	return v, true
+}
+
+// toArcSecond 格子間隔の秒単位への丸め
+//
+// ヘッダの格子間隔は小数点以下6桁で記述されるため、秒単位に十分近い値は秒単位の値とする。
+//
+// 引数：
+//
+//	v： 格子間隔(単位:度)
+//
+// 戻り値：
+//
+//	丸めた格子間隔(単位:度)
+func toArcSecond(v float64) float64 {
+	seconds := v * 3600
+	if r := math.Round(seconds); r > 0 && math.Abs(seconds-r) < 0.01 {
+		return r / 3600
+	}
+	return v
+}
+
+// isGridCount 格子点数の判定
+//
+// 引数：
+//
+//	v： ヘッダに記述された格子点数
+//
+// 戻り値：
+//
+//	2以上、maxGridPoints以下の整数の場合true。NaN、無限大、小数の場合はfalse
+func isGridCount(v float64) bool {
+	return v >= 2 && v <= maxGridPoints && v == math.Trunc(v)
+}
+
+// formatError ジオイドモデルの入力チェックエラー生成
+func formatError(detail string) error {
+	return errors.NewSpatialIdError(errors.InputValueErrorCode, "ジオイドモデル: "+detail)
+}
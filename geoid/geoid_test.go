@@ -0,0 +1,134 @@
+package geoid
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testModel テスト用ジオイドモデル(国土地理院のASCII形式)
+//
+// 南西端(35.0, 139.0)、格子間隔は緯度1分、経度1.5分、3×3格子点。北東端は欠損値。
+const testModel = `  35.00000 139.00000  0.016667  0.025000    3    3    1 ver2.2
+ 36.0000 36.1000 36.2000
+ 36.3000 36.4000 36.5000
+ 36.6000 36.7000 999.0000
+`
+
+// TestRead01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 3×3格子点、格子間隔は緯度0.016667度、経度0.025度、北東端は欠損値
+//
+// + 確認内容
+//   - 格子点上で格子点のジオイド高が取得されること
+//   - 格子点の間は双線形補間されること
+//   - 格子間隔が秒単位に丸められ、北端の格子点が取得できること
+//   - 範囲外、欠損値を含む格子では取得できないこと
+func TestRead01(t *testing.T) {
+	// テスト対象呼び出し
+	model, err := Read(strings.NewReader(testModel))
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	for _, c := range []struct {
+		lon, lat, expect float64
+		ok               bool
+	}{
+		{139.0, 35.0, 36.0, true},
+		{139.025, 35.0, 36.1, true},
+		{139.0, 35.0 + 1.0/60, 36.3, true},
+		{139.0125, 35.0 + 0.5/60, 36.2, true},
+		{139.0, 35.0 + 2.0/60, 36.6, true},
+		{139.0375, 35.0 + 0.5/60, 36.3, true},
+		{139.0375, 35.0 + 1.5/60, 0, false},
+		{138.99, 35.0, 0, false},
+		{139.0, 35.04, 0, false},
+	} {
+		result, ok := model.GeoidHeight(c.lon, c.lat)
+		if ok != c.ok || math.Abs(result-c.expect) > 1e-9 {
+			t.Errorf("ジオイド高(%v, %v) - 期待値：%v, %v, 取得値：%v, %v", c.lon, c.lat, c.expect, c.ok, result, ok)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestRead02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：空の入力
+//   - パターン2：ヘッダの項目不足
+//   - パターン3：ジオイド高の数が不足
+//   - パターン4：ジオイド高が数値でない
+//   - パターン5：格子点数が1
+//   - パターン6：格子点数がNaN
+//   - パターン7：格子点数が無限大
+//   - パターン8：格子点数が小数
+//   - パターン9：格子点数の積が上限を超える
+//
+// + 確認内容
+//   - 入力チェックエラーとなること
+func TestRead02(t *testing.T) {
+	//入力パラメータ
+	inputs := []string{
+		"",
+		"35.0 139.0 0.016667 0.025\n1 2 3 4\n",
+		"35.0 139.0 0.016667 0.025 2 2 1 ver2.2\n1 2 3\n",
+		"35.0 139.0 0.016667 0.025 2 2 1 ver2.2\n1 2 x 4\n",
+		"35.0 139.0 0.016667 0.025 1 2 1 ver2.2\n1 2\n",
+		"35.0 139.0 0.016667 0.025 NaN 2 1 ver2.2\n1 2 3 4\n",
+		"35.0 139.0 0.016667 0.025 2 +Inf 1 ver2.2\n1 2 3 4\n",
+		"35.0 139.0 0.016667 0.025 2 2.5 1 ver2.2\n1 2 3 4\n",
+		"35.0 139.0 0.016667 0.025 100000 100000 1 ver2.2\n1 2 3 4\n",
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, err := Read(strings.NewReader(input))
+
+		if err == nil || !strings.HasPrefix(err.Error(), "InputValueError") {
+			t.Errorf("パターン%d error - 期待値：入力チェックエラー, 取得値：%v", i+1, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestOpen01 正常系・異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：ジオイドモデルのファイル
+//   - パターン2：存在しないファイル
+//
+// + 確認内容
+//   - パターン1：ジオイドモデルが読み込まれること
+//   - パターン2：エラーとなること
+func TestOpen01(t *testing.T) {
+	//入力パラメータ
+	path := filepath.Join(t.TempDir(), "gsigeo.asc")
+	if err := os.WriteFile(path, []byte(testModel), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// テスト対象呼び出し
+	model, err := Open(path)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	if result, ok := model.GeoidHeight(139.025, 35.0); !ok || math.Abs(result-36.1) > 1e-9 {
+		t.Errorf("ジオイド高 - 期待値：36.1, 取得値：%v, %v", result, ok)
+	}
+
+	if _, err := Open(path + ".missing"); err == nil {
+		t.Error("error - 期待値：エラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
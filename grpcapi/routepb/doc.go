@@ -0,0 +1,6 @@
+// Package routepb 経路の空間ID取得gRPCサービスの生成コードパッケージ
+//
+// route.protoを変更した場合は、リポジトリのルートでgo generateを実行して再生成する。
+package routepb
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative grpcapi/routepb/route.proto
@@ -0,0 +1,398 @@
+// 経路の空間ID取得gRPCサービス定義
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: grpcapi/routepb/route.proto
+
+package routepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Point 座標
+type Point struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Lon float64 `protobuf:"fixed64,1,opt,name=lon,proto3" json:"lon,omitempty"` // 経度
+	Lat float64 `protobuf:"fixed64,2,opt,name=lat,proto3" json:"lat,omitempty"` // 緯度
+	Alt float64 `protobuf:"fixed64,3,opt,name=alt,proto3" json:"alt,omitempty"` // 高さ
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpcapi_routepb_route_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_routepb_route_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_grpcapi_routepb_route_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Point) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+func (x *Point) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Point) GetAlt() float64 {
+	if x != nil {
+		return x.Alt
+	}
+	return 0
+}
+
+// VoxelizeRouteRequest 経路の空間ID取得のリクエスト
+type VoxelizeRouteRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Center      []*Point `protobuf:"bytes,1,rep,name=center,proto3" json:"center,omitempty"`                                     // 円柱の中心の接続点
+	Radius      float64  `protobuf:"fixed64,2,opt,name=radius,proto3" json:"radius,omitempty"`                                   // 円柱の半径(単位:m)
+	HZoom       int64    `protobuf:"varint,3,opt,name=h_zoom,json=hZoom,proto3" json:"h_zoom,omitempty"`                         // 水平方向の精度レベル
+	VZoom       int64    `protobuf:"varint,4,opt,name=v_zoom,json=vZoom,proto3" json:"v_zoom,omitempty"`                         // 垂直方向の精度レベル
+	IsCapsule   bool     `protobuf:"varint,5,opt,name=is_capsule,json=isCapsule,proto3" json:"is_capsule,omitempty"`             // 始点、終点が球状であるかを示す。True: カプセル / False: 円柱
+	IsPrecision *bool    `protobuf:"varint,6,opt,name=is_precision,json=isPrecision,proto3,oneof" json:"is_precision,omitempty"` // 衝突判定を実施するかのフラグ(省略時はTrue)
+	IsSpatialId bool     `protobuf:"varint,7,opt,name=is_spatial_id,json=isSpatialId,proto3" json:"is_spatial_id,omitempty"`     // 空間IDで返却するかを示す。True: 空間ID(精度はh_zoom) / False: 拡張空間ID
+	ChunkSize   int32    `protobuf:"varint,8,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`             // 1回の応答に含める空間IDの数(0の場合はサーバの既定値)
+}
+
+func (x *VoxelizeRouteRequest) Reset() {
+	*x = VoxelizeRouteRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpcapi_routepb_route_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VoxelizeRouteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoxelizeRouteRequest) ProtoMessage() {}
+
+func (x *VoxelizeRouteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_routepb_route_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoxelizeRouteRequest.ProtoReflect.Descriptor instead.
+func (*VoxelizeRouteRequest) Descriptor() ([]byte, []int) {
+	return file_grpcapi_routepb_route_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *VoxelizeRouteRequest) GetCenter() []*Point {
+	if x != nil {
+		return x.Center
+	}
+	return nil
+}
+
+func (x *VoxelizeRouteRequest) GetRadius() float64 {
+	if x != nil {
+		return x.Radius
+	}
+	return 0
+}
+
+func (x *VoxelizeRouteRequest) GetHZoom() int64 {
+	if x != nil {
+		return x.HZoom
+	}
+	return 0
+}
+
+func (x *VoxelizeRouteRequest) GetVZoom() int64 {
+	if x != nil {
+		return x.VZoom
+	}
+	return 0
+}
+
+func (x *VoxelizeRouteRequest) GetIsCapsule() bool {
+	if x != nil {
+		return x.IsCapsule
+	}
+	return false
+}
+
+func (x *VoxelizeRouteRequest) GetIsPrecision() bool {
+	if x != nil && x.IsPrecision != nil {
+		return *x.IsPrecision
+	}
+	return false
+}
+
+func (x *VoxelizeRouteRequest) GetIsSpatialId() bool {
+	if x != nil {
+		return x.IsSpatialId
+	}
+	return false
+}
+
+func (x *VoxelizeRouteRequest) GetChunkSize() int32 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+// VoxelizeRouteResponse 経路の空間ID取得の応答
+type VoxelizeRouteResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SpatialIds []string `protobuf:"bytes,1,rep,name=spatial_ids,json=spatialIds,proto3" json:"spatial_ids,omitempty"` // 空間ID、もしくは拡張空間IDのリスト
+	Offset     int64    `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`                          // 全体の空間IDのリストにおける先頭の位置
+	Total      int64    `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`                            // 全体の空間IDの数(最後の応答のみ設定し、それ以外は0)
+}
+
+func (x *VoxelizeRouteResponse) Reset() {
+	*x = VoxelizeRouteResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_grpcapi_routepb_route_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VoxelizeRouteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoxelizeRouteResponse) ProtoMessage() {}
+
+func (x *VoxelizeRouteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpcapi_routepb_route_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoxelizeRouteResponse.ProtoReflect.Descriptor instead.
+func (*VoxelizeRouteResponse) Descriptor() ([]byte, []int) {
+	return file_grpcapi_routepb_route_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *VoxelizeRouteResponse) GetSpatialIds() []string {
+	if x != nil {
+		return x.SpatialIds
+	}
+	return nil
+}
+
+func (x *VoxelizeRouteResponse) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *VoxelizeRouteResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+var File_grpcapi_routepb_route_proto protoreflect.FileDescriptor
+
+var file_grpcapi_routepb_route_proto_rawDesc = []byte{
+	0x0a, 0x1b, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x70,
+	0x62, 0x2f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x12, 0x73,
+	0x70, 0x61, 0x74, 0x69, 0x61, 0x6c, 0x69, 0x64, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x2e, 0x76,
+	0x31, 0x22, 0x3d, 0x0a, 0x05, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6c, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03,
+	0x6c, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x61, 0x6c, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x61, 0x6c, 0x74,
+	0x22, 0xaa, 0x02, 0x0a, 0x14, 0x56, 0x6f, 0x78, 0x65, 0x6c, 0x69, 0x7a, 0x65, 0x52, 0x6f, 0x75,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x31, 0x0a, 0x06, 0x63, 0x65, 0x6e,
+	0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x70, 0x61, 0x74,
+	0x69, 0x61, 0x6c, 0x69, 0x64, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x6f, 0x69, 0x6e, 0x74, 0x52, 0x06, 0x63, 0x65, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06,
+	0x72, 0x61, 0x64, 0x69, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x72, 0x61,
+	0x64, 0x69, 0x75, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x68, 0x5f, 0x7a, 0x6f, 0x6f, 0x6d, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x68, 0x5a, 0x6f, 0x6f, 0x6d, 0x12, 0x15, 0x0a, 0x06, 0x76,
+	0x5f, 0x7a, 0x6f, 0x6f, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x76, 0x5a, 0x6f,
+	0x6f, 0x6d, 0x12, 0x1d, 0x0a, 0x0a, 0x69, 0x73, 0x5f, 0x63, 0x61, 0x70, 0x73, 0x75, 0x6c, 0x65,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x73, 0x43, 0x61, 0x70, 0x73, 0x75, 0x6c,
+	0x65, 0x12, 0x26, 0x0a, 0x0c, 0x69, 0x73, 0x5f, 0x70, 0x72, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x0b, 0x69, 0x73, 0x50, 0x72, 0x65,
+	0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a, 0x0d, 0x69, 0x73, 0x5f,
+	0x73, 0x70, 0x61, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0b, 0x69, 0x73, 0x53, 0x70, 0x61, 0x74, 0x69, 0x61, 0x6c, 0x49, 0x64, 0x12, 0x1d, 0x0a,
+	0x0a, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x0f, 0x0a, 0x0d,
+	0x5f, 0x69, 0x73, 0x5f, 0x70, 0x72, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x66, 0x0a,
+	0x15, 0x56, 0x6f, 0x78, 0x65, 0x6c, 0x69, 0x7a, 0x65, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x70, 0x61, 0x74, 0x69, 0x61,
+	0x6c, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x70, 0x61,
+	0x74, 0x69, 0x61, 0x6c, 0x49, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x32, 0x76, 0x0a, 0x0c, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x66, 0x0a, 0x0d, 0x56, 0x6f, 0x78, 0x65, 0x6c, 0x69, 0x7a,
+	0x65, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x28, 0x2e, 0x73, 0x70, 0x61, 0x74, 0x69, 0x61, 0x6c,
+	0x69, 0x64, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x6f, 0x78, 0x65,
+	0x6c, 0x69, 0x7a, 0x65, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x29, 0x2e, 0x73, 0x70, 0x61, 0x74, 0x69, 0x61, 0x6c, 0x69, 0x64, 0x2e, 0x72, 0x6f, 0x75,
+	0x74, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x6f, 0x78, 0x65, 0x6c, 0x69, 0x7a, 0x65, 0x52, 0x6f,
+	0x75, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x3c, 0x5a,
+	0x3a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x72, 0x61, 0x6a,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x6a, 0x70, 0x2f, 0x73, 0x70, 0x61, 0x74, 0x69, 0x61, 0x6c,
+	0x5f, 0x69, 0x64, 0x5f, 0x70, 0x6c, 0x75, 0x73, 0x5f, 0x67, 0x6f, 0x2f, 0x67, 0x72, 0x70, 0x63,
+	0x61, 0x70, 0x69, 0x2f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_grpcapi_routepb_route_proto_rawDescOnce sync.Once
+	file_grpcapi_routepb_route_proto_rawDescData = file_grpcapi_routepb_route_proto_rawDesc
+)
+
+func file_grpcapi_routepb_route_proto_rawDescGZIP() []byte {
+	file_grpcapi_routepb_route_proto_rawDescOnce.Do(func() {
+		file_grpcapi_routepb_route_proto_rawDescData = protoimpl.X.CompressGZIP(file_grpcapi_routepb_route_proto_rawDescData)
+	})
+	return file_grpcapi_routepb_route_proto_rawDescData
+}
+
+var file_grpcapi_routepb_route_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_grpcapi_routepb_route_proto_goTypes = []interface{}{
+	(*Point)(nil),                 // 0: spatialid.route.v1.Point
+	(*VoxelizeRouteRequest)(nil),  // 1: spatialid.route.v1.VoxelizeRouteRequest
+	(*VoxelizeRouteResponse)(nil), // 2: spatialid.route.v1.VoxelizeRouteResponse
+}
+var file_grpcapi_routepb_route_proto_depIdxs = []int32{
+	0, // 0: spatialid.route.v1.VoxelizeRouteRequest.center:type_name -> spatialid.route.v1.Point
+	1, // 1: spatialid.route.v1.RouteService.VoxelizeRoute:input_type -> spatialid.route.v1.VoxelizeRouteRequest
+	2, // 2: spatialid.route.v1.RouteService.VoxelizeRoute:output_type -> spatialid.route.v1.VoxelizeRouteResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_grpcapi_routepb_route_proto_init() }
+func file_grpcapi_routepb_route_proto_init() {
+	if File_grpcapi_routepb_route_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_grpcapi_routepb_route_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Point); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpcapi_routepb_route_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VoxelizeRouteRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_grpcapi_routepb_route_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VoxelizeRouteResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_grpcapi_routepb_route_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_grpcapi_routepb_route_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_grpcapi_routepb_route_proto_goTypes,
+		DependencyIndexes: file_grpcapi_routepb_route_proto_depIdxs,
+		MessageInfos:      file_grpcapi_routepb_route_proto_msgTypes,
+	}.Build()
+	File_grpcapi_routepb_route_proto = out.File
+	file_grpcapi_routepb_route_proto_rawDesc = nil
+	file_grpcapi_routepb_route_proto_goTypes = nil
+	file_grpcapi_routepb_route_proto_depIdxs = nil
+}
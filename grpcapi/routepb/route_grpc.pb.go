@@ -0,0 +1,142 @@
+// 経路の空間ID取得gRPCサービス定義
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: grpcapi/routepb/route.proto
+
+package routepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	RouteService_VoxelizeRoute_FullMethodName = "/spatialid.route.v1.RouteService/VoxelizeRoute"
+)
+
+// RouteServiceClient is the client API for RouteService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RouteServiceClient interface {
+	// VoxelizeRoute 円柱を複数つなげた経路が通る空間IDを取得する。
+	// 空間IDはchunk_size個ずつに分割してストリーミングで返却する。
+	VoxelizeRoute(ctx context.Context, in *VoxelizeRouteRequest, opts ...grpc.CallOption) (RouteService_VoxelizeRouteClient, error)
+}
+
+type routeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRouteServiceClient(cc grpc.ClientConnInterface) RouteServiceClient {
+	return &routeServiceClient{cc}
+}
+
+func (c *routeServiceClient) VoxelizeRoute(ctx context.Context, in *VoxelizeRouteRequest, opts ...grpc.CallOption) (RouteService_VoxelizeRouteClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RouteService_ServiceDesc.Streams[0], RouteService_VoxelizeRoute_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &routeServiceVoxelizeRouteClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RouteService_VoxelizeRouteClient interface {
+	Recv() (*VoxelizeRouteResponse, error)
+	grpc.ClientStream
+}
+
+type routeServiceVoxelizeRouteClient struct {
+	grpc.ClientStream
+}
+
+func (x *routeServiceVoxelizeRouteClient) Recv() (*VoxelizeRouteResponse, error) {
+	m := new(VoxelizeRouteResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RouteServiceServer is the server API for RouteService service.
+// All implementations must embed UnimplementedRouteServiceServer
+// for forward compatibility
+type RouteServiceServer interface {
+	// VoxelizeRoute 円柱を複数つなげた経路が通る空間IDを取得する。
+	// 空間IDはchunk_size個ずつに分割してストリーミングで返却する。
+	VoxelizeRoute(*VoxelizeRouteRequest, RouteService_VoxelizeRouteServer) error
+	mustEmbedUnimplementedRouteServiceServer()
+}
+
+// UnimplementedRouteServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRouteServiceServer struct {
+}
+
+func (UnimplementedRouteServiceServer) VoxelizeRoute(*VoxelizeRouteRequest, RouteService_VoxelizeRouteServer) error {
+	return status.Errorf(codes.Unimplemented, "method VoxelizeRoute not implemented")
+}
+func (UnimplementedRouteServiceServer) mustEmbedUnimplementedRouteServiceServer() {}
+
+// UnsafeRouteServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RouteServiceServer will
+// result in compilation errors.
+type UnsafeRouteServiceServer interface {
+	mustEmbedUnimplementedRouteServiceServer()
+}
+
+func RegisterRouteServiceServer(s grpc.ServiceRegistrar, srv RouteServiceServer) {
+	s.RegisterService(&RouteService_ServiceDesc, srv)
+}
+
+func _RouteService_VoxelizeRoute_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(VoxelizeRouteRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RouteServiceServer).VoxelizeRoute(m, &routeServiceVoxelizeRouteServer{stream})
+}
+
+type RouteService_VoxelizeRouteServer interface {
+	Send(*VoxelizeRouteResponse) error
+	grpc.ServerStream
+}
+
+type routeServiceVoxelizeRouteServer struct {
+	grpc.ServerStream
+}
+
+func (x *routeServiceVoxelizeRouteServer) Send(m *VoxelizeRouteResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// RouteService_ServiceDesc is the grpc.ServiceDesc for RouteService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RouteService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spatialid.route.v1.RouteService",
+	HandlerType: (*RouteServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "VoxelizeRoute",
+			Handler:       _RouteService_VoxelizeRoute_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "grpcapi/routepb/route.proto",
+}
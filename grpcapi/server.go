@@ -0,0 +1,419 @@
+// Package grpcapi 経路の空間ID取得gRPCサービスパッケージ
+//
+// routepb.RouteServiceの実装を提供する。
+// 空間IDは経路の区間ごとに取得し、一定数ごとに分割してストリーミングで返却するため、
+// 長大な経路でも1つの応答メッセージが巨大にならない。
+//
+// 使用例：
+//
+//	server := grpc.NewServer()
+//	routepb.RegisterRouteServiceServer(server, grpcapi.NewServer())
+package grpcapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	sidshape "github.com/trajectoryjp/spatial_id_go/shape"
+	"github.com/trajectoryjp/spatial_id_plus_go/grpcapi/routepb"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultChunkSize 1回の応答に含める空間IDの数のデフォルト値
+	DefaultChunkSize = 1000
+	// DefaultMaxChunkSize リクエストで指定可能な1回の応答に含める空間IDの数の上限のデフォルト値
+	DefaultMaxChunkSize = 10000
+	// DefaultMaxPoints 円柱の接続点数の上限のデフォルト値
+	DefaultMaxPoints = 1000
+	// DefaultMaxSpatialIDs 見積もった空間IDの数の上限のデフォルト値
+	DefaultMaxSpatialIDs = 1000000
+)
+
+// ServerOpts サーバのオプショナル引数構造体
+type ServerOpts struct {
+	ChunkSize     int // 1回の応答に含める空間IDの数
+	MaxChunkSize  int // リクエストで指定可能な1回の応答に含める空間IDの数の上限
+	MaxPoints     int // 円柱の接続点数の上限
+	MaxSpatialIDs int // 見積もった空間IDの数の上限
+}
+
+// サーバのオプショナル型
+type option func(*ServerOpts)
+
+// ChunkSize 1回の応答に含める空間IDの数設定関数
+//
+// リクエストでchunk_sizeが指定されなかった場合に使用する。
+//
+// 引数：
+//
+//	v: 1回の応答に含める空間IDの数
+//
+// 戻り値：
+//
+//	サーバのオプショナル型の関数
+func ChunkSize(v int) option {
+	return func(o *ServerOpts) {
+		o.ChunkSize = v
+	}
+}
+
+// MaxChunkSize リクエストで指定可能な1回の応答に含める空間IDの数の上限設定関数
+//
+// 引数：
+//
+//	v: 1回の応答に含める空間IDの数の上限
+//
+// 戻り値：
+//
+//	サーバのオプショナル型の関数
+func MaxChunkSize(v int) option {
+	return func(o *ServerOpts) {
+		o.MaxChunkSize = v
+	}
+}
+
+// MaxPoints 円柱の接続点数の上限設定関数
+//
+// 引数：
+//
+//	v: 円柱の接続点数の上限
+//
+// 戻り値：
+//
+//	サーバのオプショナル型の関数
+func MaxPoints(v int) option {
+	return func(o *ServerOpts) {
+		o.MaxPoints = v
+	}
+}
+
+// MaxSpatialIDs 見積もった空間IDの数の上限設定関数
+//
+// 半径、経路の長さと精度レベルのボクセルの大きさから見積もった空間IDの数が上限を超える場合、
+// 空間IDを取得せずにInvalidArgumentのステータスエラーを返却する。
+//
+// 引数：
+//
+//	v: 見積もった空間IDの数の上限
+//
+// 戻り値：
+//
+//	サーバのオプショナル型の関数
+func MaxSpatialIDs(v int) option {
+	return func(o *ServerOpts) {
+		o.MaxSpatialIDs = v
+	}
+}
+
+// Server 経路の空間ID取得サービスの実装構造体
+type Server struct {
+	routepb.UnimplementedRouteServiceServer
+	opts ServerOpts // オプション
+}
+
+// NewServer サーバ初期化関数
+//
+// 引数：
+//
+//	opts： オプション(ChunkSize, MaxChunkSize, MaxPoints, MaxSpatialIDs)
+//
+// 戻り値：
+//
+//	初期化したServerオブジェクト
+func NewServer(opts ...option) *Server {
+	s := &Server{
+		opts: ServerOpts{
+			ChunkSize:     DefaultChunkSize,
+			MaxChunkSize:  DefaultMaxChunkSize,
+			MaxPoints:     DefaultMaxPoints,
+			MaxSpatialIDs: DefaultMaxSpatialIDs,
+		},
+	}
+	for _, opt := range opts {
+		opt(&s.opts)
+	}
+	return s
+}
+
+// VoxelizeRoute 経路の空間ID取得
+//
+// 円柱を複数つなげた経路が通る空間IDを区間ごとに取得し、chunk_size個ずつストリーミングで返却する。
+// 区間をまたがる重複を除くため、送信済みの空間IDは保持する。
+// 全体の空間IDの数は最後の応答のみに設定する。
+// 区間の処理、応答の送信の前にストリームのキャンセルを確認し、キャンセルされた場合は処理を中断する。
+//
+// 引数：
+//
+//	req： リクエスト
+//	stream： 応答のストリーム
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、gRPCのステータスエラーが返却される。
+//	 InvalidArgument： GetExtendedSpatialIdsOnCylindersの入力チェックに当てはまる場合、
+//	                   もしくは接続点数、chunk_size、見積もった空間IDの数が上限を超過した場合。
+//	                   メッセージにはSpatialIdErrorのエラー文字列が設定される。
+//	 Canceled、DeadlineExceeded： ストリームがキャンセル、もしくはタイムアウトした場合。
+//	 その他： 空間IDの取得、応答の送信に失敗した場合。
+func (s *Server) VoxelizeRoute(req *routepb.VoxelizeRouteRequest, stream routepb.RouteService_VoxelizeRouteServer) error {
+	chunkSize, err := s.chunkSize(req)
+	if err != nil {
+		return err
+	}
+	center, err := s.validate(req)
+	if err != nil {
+		return err
+	}
+
+	isPrecision := true
+	if req.IsPrecision != nil {
+		isPrecision = req.GetIsPrecision()
+	}
+
+	sender := &chunkSender{stream: stream, size: chunkSize, sent: map[string]bool{}}
+	for _, points := range routeParts(center, req.GetIsCapsule()) {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		var spatialIDs []string
+		if req.GetIsSpatialId() {
+			spatialIDs, err = shape.GetSpatialIdsOnCylinders(
+				points, req.GetRadius(), req.GetHZoom(), req.GetIsCapsule(), shape.IsPrecision(isPrecision),
+			)
+		} else {
+			spatialIDs, err = shape.GetExtendedSpatialIdsOnCylinders(
+				points, req.GetRadius(), req.GetHZoom(), req.GetVZoom(), req.GetIsCapsule(), shape.IsPrecision(isPrecision),
+			)
+		}
+		if err != nil {
+			return toStatusError(err)
+		}
+		if err := sender.add(spatialIDs); err != nil {
+			return err
+		}
+	}
+
+	// 空間IDが0個の場合も全体の数を通知するため1回は応答する
+	return sender.flush()
+}
+
+// routeParts 経路を区間ごとの接続点に分割
+//
+// 円柱の場合は、区間の円柱とは別に、途中の接続点の球を1点の接続点とする。
+//
+// 引数：
+//
+//	center： 円柱の中心の接続点
+//	isCapsule： 始点、終点が球状であるかを示す
+//
+// 戻り値：
+//
+//	区間、もしくは接続点の球ごとの接続点のリスト(接続点が1つ以下の場合は経路全体)
+func routeParts(center []*object.Point, isCapsule bool) [][]*object.Point {
+	if len(center) <= 1 {
+		return [][]*object.Point{center}
+	}
+	parts := make([][]*object.Point, 0, 2*len(center))
+	for i := range center[:len(center)-1] {
+		parts = append(parts, []*object.Point{center[i], center[i+1]})
+		if !isCapsule && i < len(center)-2 {
+			parts = append(parts, []*object.Point{center[i+1]})
+		}
+	}
+	return parts
+}
+
+// chunkSender 空間IDをchunk_size個ずつ送信する構造体
+type chunkSender struct {
+	stream  routepb.RouteService_VoxelizeRouteServer // 応答のストリーム
+	size    int                                      // 1回の応答に含める空間IDの数
+	sent    map[string]bool                          // 送信済み、もしくは送信待ちの空間ID
+	pending []string                                 // 送信待ちの空間ID
+	offset  int64                                    // 送信待ちの空間IDの全体における先頭の位置
+}
+
+// add 空間IDの追加
+//
+// 送信済みの空間IDを除いて送信待ちに追加し、chunk_size個を超えた場合はchunk_size個を送信する。
+// 最後の応答に全体の数を設定するため、送信待ちの空間IDは1個以上残す。
+//
+// 引数：
+//
+//	spatialIDs： 空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	応答の送信に失敗した場合、エラーが返却される。
+func (c *chunkSender) add(spatialIDs []string) error {
+	for _, spatialID := range spatialIDs {
+		if c.sent[spatialID] {
+			continue
+		}
+		c.sent[spatialID] = true
+		c.pending = append(c.pending, spatialID)
+
+		if len(c.pending) > c.size {
+			if err := c.send(c.pending[:c.size], 0); err != nil {
+				return err
+			}
+			c.pending = append(make([]string, 0, c.size+1), c.pending[c.size:]...)
+		}
+	}
+	return nil
+}
+
+// flush 送信待ちの空間IDを全体の数とともに送信
+//
+// 戻り値(エラー)：
+//
+//	応答の送信に失敗した場合、エラーが返却される。
+func (c *chunkSender) flush() error {
+	return c.send(c.pending, c.offset+int64(len(c.pending)))
+}
+
+// send 応答の送信
+//
+// 引数：
+//
+//	spatialIDs： 応答に含める空間ID
+//	total： 全体の空間IDの数(最後の応答以外は0)
+//
+// 戻り値(エラー)：
+//
+//	ストリームがキャンセルされた場合、もしくは応答の送信に失敗した場合、エラーが返却される。
+func (c *chunkSender) send(spatialIDs []string, total int64) error {
+	if err := c.stream.Context().Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	if err := c.stream.Send(&routepb.VoxelizeRouteResponse{
+		SpatialIds: spatialIDs,
+		Offset:     c.offset,
+		Total:      total,
+	}); err != nil {
+		return err
+	}
+	c.offset += int64(len(spatialIDs))
+	return nil
+}
+
+// chunkSize 1回の応答に含める空間IDの数の決定
+//
+// 引数：
+//
+//	req： リクエスト
+//
+// 戻り値：
+//
+//	1回の応答に含める空間IDの数
+//
+// 戻り値(エラー)：
+//
+//	chunk_sizeが負の値、もしくは上限を超過した場合、InvalidArgumentのステータスエラーが返却される。
+func (s *Server) chunkSize(req *routepb.VoxelizeRouteRequest) (int, error) {
+	chunkSize := int(req.GetChunkSize())
+	if chunkSize == 0 {
+		return s.opts.ChunkSize, nil
+	}
+	if chunkSize < 0 || chunkSize > s.opts.MaxChunkSize {
+		return 0, invalidArgument(fmt.Sprintf("chunk_size: 1～%dの整数値を指定してください", s.opts.MaxChunkSize))
+	}
+	return chunkSize, nil
+}
+
+// validate リクエストの入力チェック
+//
+// GetExtendedSpatialIdsOnCylindersと同じ入力チェックを行い、
+// 空間IDを取得する前に見積もった空間IDの数で処理量を制限する。
+//
+// 引数：
+//
+//	req： リクエスト
+//
+// 戻り値：
+//
+//	円柱の中心の接続点
+//
+// 戻り値(エラー)：
+//
+//	入力値が不正な場合、InvalidArgumentのステータスエラーが返却される。
+func (s *Server) validate(req *routepb.VoxelizeRouteRequest) ([]*object.Point, error) {
+	if len(req.GetCenter()) > s.opts.MaxPoints {
+		return nil, invalidArgument(fmt.Sprintf("center: 接続点数が上限(%d)を超過しています", s.opts.MaxPoints))
+	}
+
+	center := make([]*object.Point, 0, len(req.GetCenter()))
+	for i, p := range req.GetCenter() {
+		if p == nil {
+			return nil, invalidArgument(fmt.Sprintf("center[%d]: 座標を指定してください", i))
+		}
+		point, err := object.NewPoint(p.GetLon(), p.GetLat(), p.GetAlt())
+		if err != nil {
+			return nil, invalidArgument(fmt.Sprintf("center[%d]: 座標の値が不正です", i))
+		}
+		center = append(center, point)
+	}
+
+	if !sidshape.CheckZoom(req.GetHZoom()) || (!req.GetIsSpatialId() && !sidshape.CheckZoom(req.GetVZoom())) {
+		return nil, invalidArgument("精度レベルは0～35の整数値を指定してください")
+	}
+	if req.GetRadius() <= consts.Minima {
+		return nil, invalidArgument("radius: 0より大きい値を指定してください")
+	}
+
+	vZoom := req.GetVZoom()
+	if req.GetIsSpatialId() {
+		vZoom = req.GetHZoom()
+	}
+	estimated, err := shape.EstimateExtendedSpatialIdsOnCylinders(center, req.GetRadius(), req.GetHZoom(), vZoom)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	if estimated > float64(s.opts.MaxSpatialIDs) {
+		return nil, invalidArgument(
+			fmt.Sprintf("空間IDの数が上限(%d)を超過します。半径を小さく、もしくは精度レベルを下げてください", s.opts.MaxSpatialIDs),
+		)
+	}
+
+	return center, nil
+}
+
+// invalidArgument 入力チェックエラーのステータスエラー生成
+//
+// 引数：
+//
+//	detail： エラーの詳細
+//
+// 戻り値：
+//
+//	InvalidArgumentのステータスエラー。メッセージは"SpatialIdErrorのエラー文字列: 詳細"
+func invalidArgument(detail string) error {
+	err := errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	return status.Error(codes.InvalidArgument, err.Error()+": "+detail)
+}
+
+// toStatusError SpatialIdErrorからステータスエラーへの変換
+//
+// 引数：
+//
+//	err： 変換するエラー
+//
+// 戻り値：
+//
+//	SpatialIdErrorのエラーコードに対応するステータスエラー
+func toStatusError(err error) error {
+	code, _, _ := strings.Cut(err.Error(), ",")
+	switch code {
+	case errors.InputValueErrorCode:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.ValueConvertErrorCode:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,318 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_plus_go/grpcapi/routepb"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestClient テスト用クライアント生成
+//
+// bufconnでプロセス内に起動したサーバに接続するクライアントを生成する。
+func newTestClient(t *testing.T, opts ...option) routepb.RouteServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	routepb.RegisterRouteServiceServer(server, NewServer(opts...))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("接続エラー: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return routepb.NewRouteServiceClient(conn)
+}
+
+// receiveAll テスト用ストリームの全応答受信
+func receiveAll(stream routepb.RouteService_VoxelizeRouteClient) ([]*routepb.VoxelizeRouteResponse, error) {
+	responses := []*routepb.VoxelizeRouteResponse{}
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			return responses, nil
+		}
+		if err != nil {
+			return responses, err
+		}
+		responses = append(responses, response)
+	}
+}
+
+// TestVoxelizeRoute01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.753098, 35.685371, 11.0), (139.753198, 35.685371, 11.0)
+//   - 半径：2.0、水平精度：25、垂直精度：25、chunk_size：7
+//
+// + 確認内容
+//   - 応答の空間IDを連結するとGetExtendedSpatialIdsOnCylindersの結果と一致すること
+//   - 各応答の空間IDの数がchunk_size以下であること
+//   - offsetが正しく設定され、totalが最後の応答のみ全体の数、それ以外は0であること
+func TestVoxelizeRoute01(t *testing.T) {
+	//入力パラメータ
+	req := &routepb.VoxelizeRouteRequest{
+		Center: []*routepb.Point{
+			{Lon: 139.753098, Lat: 35.685371, Alt: 11.0},
+			{Lon: 139.753198, Lat: 35.685371, Alt: 11.0},
+		},
+		Radius:    2.0,
+		HZoom:     25,
+		VZoom:     25,
+		ChunkSize: 7,
+	}
+
+	// 期待値
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+	expectVal, _ := shape.GetExtendedSpatialIdsOnCylinders([]*object.Point{p1, p2}, 2.0, 25, 25, false)
+
+	// テスト対象呼び出し
+	stream, err := newTestClient(t).VoxelizeRoute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	responses, err := receiveAll(stream)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	resultVal := []string{}
+	for i, response := range responses {
+		if len(response.SpatialIds) > 7 {
+			t.Errorf("空間IDの数 - 期待値：7以下, 取得値：%v", len(response.SpatialIds))
+		}
+		total := int64(0)
+		if i == len(responses)-1 {
+			total = int64(len(expectVal))
+		}
+		if response.Offset != int64(len(resultVal)) || response.Total != total {
+			t.Errorf("offset, total - 期待値：%v, %v, 取得値：%v, %v",
+				len(resultVal), total, response.Offset, response.Total)
+		}
+		resultVal = append(resultVal, response.SpatialIds...)
+	}
+	if len(responses) < 2 || !reflect.DeepEqual(resultVal, expectVal) {
+		t.Errorf("空間ID - 期待値：%v, 取得値：%v(応答数：%v)", expectVal, resultVal, len(responses))
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestVoxelizeRoute02 正常系動作確認(空間ID、接続点なし)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：接続点(139.753098, 35.685371, 11.0)、半径：1.0、精度：25、空間ID
+//   - パターン2：接続点なし、半径：1.0、精度：25
+//
+// + 確認内容
+//   - パターン1：GetSpatialIdsOnCylindersの結果と一致すること
+//   - パターン2：空間IDが0個の応答が1回返却されること
+func TestVoxelizeRoute02(t *testing.T) {
+	client := newTestClient(t)
+
+	// 期待値
+	p, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	expectVal, _ := shape.GetSpatialIdsOnCylinders([]*object.Point{p}, 1.0, 25, false)
+
+	// テスト対象呼び出し
+	stream, _ := client.VoxelizeRoute(context.Background(), &routepb.VoxelizeRouteRequest{
+		Center:      []*routepb.Point{{Lon: 139.753098, Lat: 35.685371, Alt: 11.0}},
+		Radius:      1.0,
+		HZoom:       25,
+		IsSpatialId: true,
+		IsPrecision: proto.Bool(true),
+	})
+	responses, err := receiveAll(stream)
+	if err != nil || len(responses) != 1 || !reflect.DeepEqual(responses[0].SpatialIds, expectVal) {
+		t.Errorf("空間ID - 期待値：%v, 取得値：%v, %v", expectVal, responses, err)
+	}
+
+	stream, _ = client.VoxelizeRoute(context.Background(), &routepb.VoxelizeRouteRequest{
+		Radius: 1.0,
+		HZoom:  25,
+		VZoom:  25,
+	})
+	responses, err = receiveAll(stream)
+	if err != nil || len(responses) != 1 || len(responses[0].SpatialIds) != 0 || responses[0].Total != 0 {
+		t.Errorf("空間ID - 期待値：0個の応答1回, 取得値：%v, %v", responses, err)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestVoxelizeRoute03 異常系動作確認(入力チェック)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：半径が0
+//   - パターン2：水平精度が36
+//   - パターン3：緯度が範囲外
+//   - パターン4：接続点数が上限(2)超過
+//   - パターン5：chunk_sizeが上限(100)超過
+//   - パターン6：半径1000m、精度35(見積もった空間IDの数が上限超過)
+//
+// + 確認内容
+//   - InvalidArgumentのステータスエラーが返却されること
+//   - メッセージにSpatialIdErrorのエラーコードが含まれること
+func TestVoxelizeRoute03(t *testing.T) {
+	//入力パラメータ
+	point := &routepb.Point{Lon: 139.0, Lat: 35.0}
+	requests := []*routepb.VoxelizeRouteRequest{
+		{Center: []*routepb.Point{point}, Radius: 0, HZoom: 25, VZoom: 25},
+		{Center: []*routepb.Point{point}, Radius: 1, HZoom: 36, VZoom: 25},
+		{Center: []*routepb.Point{{Lon: 139.0, Lat: 95.0}}, Radius: 1, HZoom: 25, VZoom: 25},
+		{Center: []*routepb.Point{point, point, point}, Radius: 1, HZoom: 25, VZoom: 25},
+		{Center: []*routepb.Point{point}, Radius: 1, HZoom: 25, VZoom: 25, ChunkSize: 101},
+		{Center: []*routepb.Point{point}, Radius: 1000, HZoom: 35, VZoom: 35},
+	}
+
+	client := newTestClient(t, MaxPoints(2), MaxChunkSize(100))
+	for _, req := range requests {
+		// テスト対象呼び出し
+		stream, err := client.VoxelizeRoute(context.Background(), req)
+		if err == nil {
+			_, err = receiveAll(stream)
+		}
+
+		if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), "InputValueError") {
+			t.Errorf("error - 期待値：InvalidArgument(InputValueError), 取得値：%v, 入力値：%v", err, req)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestVoxelizeRoute04 正常系動作確認(複数の区間)
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0), (139.7505, 35.68, 100.0), (139.7505, 35.6805, 100.0)
+//   - 半径：5.0、水平精度：23、垂直精度：23、chunk_size：10
+//   - 円柱、カプセル
+//
+// + 確認内容
+//   - 応答の空間IDに重複が無く、GetExtendedSpatialIdsOnCylindersの結果と集合として一致すること
+func TestVoxelizeRoute04(t *testing.T) {
+	//入力パラメータ
+	coordinates := [][3]float64{{139.75, 35.68, 100.0}, {139.7505, 35.68, 100.0}, {139.7505, 35.6805, 100.0}}
+	center := []*routepb.Point{}
+	points := []*object.Point{}
+	for _, c := range coordinates {
+		center = append(center, &routepb.Point{Lon: c[0], Lat: c[1], Alt: c[2]})
+		point, _ := object.NewPoint(c[0], c[1], c[2])
+		points = append(points, point)
+	}
+	client := newTestClient(t)
+
+	for _, isCapsule := range []bool{false, true} {
+		expectVal, _ := shape.GetExtendedSpatialIdsOnCylinders(points, 5.0, 23, 23, isCapsule)
+
+		// テスト対象呼び出し
+		stream, err := client.VoxelizeRoute(context.Background(), &routepb.VoxelizeRouteRequest{
+			Center:    center,
+			Radius:    5.0,
+			HZoom:     23,
+			VZoom:     23,
+			IsCapsule: isCapsule,
+			ChunkSize: 10,
+		})
+		if err != nil {
+			t.Fatalf("カプセル%v error - 期待値：nil, 取得値：%v", isCapsule, err)
+		}
+		responses, err := receiveAll(stream)
+		if err != nil {
+			t.Fatalf("カプセル%v error - 期待値：nil, 取得値：%v", isCapsule, err)
+		}
+
+		resultSet := map[string]bool{}
+		count := 0
+		for _, response := range responses {
+			for _, spatialID := range response.SpatialIds {
+				resultSet[spatialID] = true
+				count++
+			}
+		}
+		expectSet := map[string]bool{}
+		for _, spatialID := range expectVal {
+			expectSet[spatialID] = true
+		}
+		if count != len(resultSet) || !reflect.DeepEqual(resultSet, expectSet) {
+			t.Errorf("カプセル%v - 期待値：%v個, 取得値：%v個(重複を除き%v個)", isCapsule, len(expectSet), count, len(resultSet))
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// cancelledStream テスト用のキャンセル済みのストリーム
+type cancelledStream struct {
+	routepb.RouteService_VoxelizeRouteServer
+	ctx  context.Context
+	sent int
+}
+
+func (c *cancelledStream) Context() context.Context {
+	return c.ctx
+}
+
+func (c *cancelledStream) Send(*routepb.VoxelizeRouteResponse) error {
+	c.sent++
+	return nil
+}
+
+// TestVoxelizeRoute05 異常系動作確認(キャンセル)
+//
+// 試験詳細：
+// + 試験データ
+//   - キャンセル済みのコンテキストのストリーム
+//   - 接続点：(139.753098, 35.685371, 11.0), (139.753198, 35.685371, 11.0)、半径：2.0、精度：25
+//
+// + 確認内容
+//   - Canceledのステータスエラーが返却され、応答が送信されないこと
+func TestVoxelizeRoute05(t *testing.T) {
+	//入力パラメータ
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stream := &cancelledStream{ctx: ctx}
+
+	// テスト対象呼び出し
+	err := NewServer().VoxelizeRoute(&routepb.VoxelizeRouteRequest{
+		Center: []*routepb.Point{
+			{Lon: 139.753098, Lat: 35.685371, Alt: 11.0},
+			{Lon: 139.753198, Lat: 35.685371, Alt: 11.0},
+		},
+		Radius: 2.0,
+		HZoom:  25,
+		VZoom:  25,
+	}, stream)
+
+	if status.Code(err) != codes.Canceled || stream.sent != 0 {
+		t.Errorf("error - 期待値：Canceled(送信0回), 取得値：%v(送信%v回)", err, stream.sent)
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,111 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+)
+
+// ErrorResponse エラー応答のJSON構造体
+type ErrorResponse struct {
+	Code    string `json:"code"`             // SpatialIdErrorのエラーコード
+	Message string `json:"message"`          // SpatialIdErrorのエラーメッセージ
+	Detail  string `json:"detail,omitempty"` // エラーの詳細
+}
+
+// requestError リクエスト不正を示すエラー構造体
+//
+// SpatialIdErrorと同じエラーコードで応答するため、エラーコードと詳細を保持する。
+type requestError struct {
+	status int    // HTTPステータスコード
+	code   string // SpatialIdErrorのエラーコード
+	detail string // エラーの詳細
+}
+
+// Error エラー文字列取得
+//
+// 戻り値：
+//
+//	"エラーコード,詳細"の形式の文字列
+func (e requestError) Error() string {
+	return e.code + "," + e.detail
+}
+
+// newInputValueError 入力チェックエラーの生成
+//
+// 引数：
+//
+//	detail： エラーの詳細
+//
+// 戻り値：
+//
+//	入力チェックエラー(HTTPステータスコード400)
+func newInputValueError(detail string) error {
+	return requestError{
+		status: http.StatusBadRequest,
+		code:   errors.InputValueErrorCode,
+		detail: detail,
+	}
+}
+
+// toErrorResponse エラーからエラー応答への変換
+//
+// SpatialIdErrorのエラー文字列は"エラーコード,メッセージ"の形式であるため、
+// 最初のカンマで分割してエラーコードとメッセージを取得する。
+//
+// 引数：
+//
+//	err： 変換するエラー
+//
+// 戻り値：
+//
+//	(HTTPステータスコード, エラー応答)
+func toErrorResponse(err error) (int, ErrorResponse) {
+	if reqErr, ok := err.(requestError); ok {
+		// メッセージはSpatialIdErrorと揃える
+		code, message, _ := strings.Cut(errors.NewSpatialIdError(reqErr.code, "").Error(), ",")
+		return reqErr.status, ErrorResponse{Code: code, Message: message, Detail: reqErr.detail}
+	}
+
+	code, message, found := strings.Cut(err.Error(), ",")
+	if !found {
+		return http.StatusInternalServerError, ErrorResponse{
+			Code:    errors.OtherErrorCode,
+			Message: err.Error(),
+		}
+	}
+
+	switch code {
+	case errors.InputValueErrorCode:
+		return http.StatusBadRequest, ErrorResponse{Code: code, Message: message}
+	case errors.ValueConvertErrorCode:
+		return http.StatusUnprocessableEntity, ErrorResponse{Code: code, Message: message}
+	}
+	return http.StatusInternalServerError, ErrorResponse{Code: code, Message: message}
+}
+
+// writeError エラー応答の出力
+//
+// 引数：
+//
+//	w： 応答の出力先
+//	err： 応答するエラー
+func writeError(w http.ResponseWriter, err error) {
+	status, body := toErrorResponse(err)
+	writeJSON(w, status, body)
+}
+
+// writeJSON JSON応答の出力
+//
+// 引数：
+//
+//	w： 応答の出力先
+//	status： HTTPステータスコード
+//	body： JSONに変換する応答内容
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
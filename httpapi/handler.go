@@ -0,0 +1,419 @@
+// Package httpapi 空間ID取得HTTP APIパッケージ
+//
+// shapeパッケージの空間ID取得機能をJSONのHTTP APIとして提供する。
+// NewHandlerで生成したハンドラは任意のhttp.Serverやhttp.ServeMuxに組み込める。
+//
+// エンドポイント(全てPOST、リクエスト、応答ともにJSON)：
+//
+//	/cylinders                 : 円柱を複数つなげた経路が通る空間IDを取得する
+//	/spheres                   : 球が通る空間IDを取得する
+//	/axis-ids/to-spatial-id    : 軸IDから拡張空間IDを取得する
+//	/axis-ids/from-spatial-id  : 拡張空間IDから軸IDを取得する
+//
+// エラー時はErrorResponseの形式でSpatialIdErrorのエラーコードを応答する。
+package httpapi
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+)
+
+const (
+	// DefaultMaxBodyBytes リクエストボディの最大サイズのデフォルト値(単位:byte)
+	DefaultMaxBodyBytes = 1 << 20
+	// DefaultMaxPoints 円柱の接続点数の上限のデフォルト値
+	DefaultMaxPoints = 1000
+	// DefaultMaxSpatialIDs 見積もった空間IDの数の上限のデフォルト値
+	DefaultMaxSpatialIDs = 1000000
+)
+
+// HandlerOpts ハンドラのオプショナル引数構造体
+type HandlerOpts struct {
+	MaxBodyBytes  int64 // リクエストボディの最大サイズ(単位:byte)
+	MaxPoints     int   // 円柱の接続点数の上限
+	MaxSpatialIDs int   // 見積もった空間IDの数の上限
+}
+
+// ハンドラのオプショナル型
+type option func(*HandlerOpts)
+
+// MaxBodyBytes リクエストボディの最大サイズ設定関数
+//
+// 引数：
+//
+//	v: リクエストボディの最大サイズ(単位:byte)
+//
+// 戻り値：
+//
+//	ハンドラのオプショナル型の関数
+func MaxBodyBytes(v int64) option {
+	return func(o *HandlerOpts) {
+		o.MaxBodyBytes = v
+	}
+}
+
+// MaxPoints 円柱の接続点数の上限設定関数
+//
+// 引数：
+//
+//	v: 円柱の接続点数の上限
+//
+// 戻り値：
+//
+//	ハンドラのオプショナル型の関数
+func MaxPoints(v int) option {
+	return func(o *HandlerOpts) {
+		o.MaxPoints = v
+	}
+}
+
+// MaxSpatialIDs 見積もった空間IDの数の上限設定関数
+//
+// 半径、経路の長さと精度レベルのボクセルの大きさから見積もった空間IDの数が上限を超える場合、
+// 空間IDを取得せずに入力チェックエラーを応答する。
+//
+// 引数：
+//
+//	v: 見積もった空間IDの数の上限
+//
+// 戻り値：
+//
+//	ハンドラのオプショナル型の関数
+func MaxSpatialIDs(v int) option {
+	return func(o *HandlerOpts) {
+		o.MaxSpatialIDs = v
+	}
+}
+
+// Handler 空間ID取得HTTP APIのハンドラ構造体
+type Handler struct {
+	opts HandlerOpts    // オプション
+	mux  *http.ServeMux // エンドポイントの振り分け
+}
+
+// NewHandler ハンドラ初期化関数
+//
+// 引数：
+//
+//	opts： オプション(MaxBodyBytes, MaxPoints, MaxSpatialIDs)
+//
+// 戻り値：
+//
+//	初期化したHandlerオブジェクト
+func NewHandler(opts ...option) *Handler {
+	h := &Handler{
+		opts: HandlerOpts{
+			MaxBodyBytes:  DefaultMaxBodyBytes,
+			MaxPoints:     DefaultMaxPoints,
+			MaxSpatialIDs: DefaultMaxSpatialIDs,
+		},
+		mux: http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+
+	h.mux.HandleFunc("/cylinders", h.post(h.cylinders))
+	h.mux.HandleFunc("/spheres", h.post(h.spheres))
+	h.mux.HandleFunc("/axis-ids/to-spatial-id", h.post(h.toSpatialID))
+	h.mux.HandleFunc("/axis-ids/from-spatial-id", h.post(h.fromSpatialID))
+
+	return h
+}
+
+// ServeHTTP リクエストの処理
+//
+// 引数：
+//
+//	w： 応答の出力先
+//	r： リクエスト
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// post POSTリクエスト処理関数の生成
+//
+// メソッドの確認とリクエストボディの最大サイズの制限を行い、処理結果をJSONで応答する。
+//
+// 引数：
+//
+//	handle： リクエストボディを受け取り、応答内容を返却する処理関数
+//
+// 戻り値：
+//
+//	HTTPハンドラ関数
+func (h *Handler) post(handle func(decoder *json.Decoder) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, requestError{
+				status: http.StatusMethodNotAllowed,
+				code:   errors.InputValueErrorCode,
+				detail: "POSTメソッドを使用してください",
+			})
+			return
+		}
+
+		body := http.MaxBytesReader(w, r.Body, h.opts.MaxBodyBytes)
+		decoder := json.NewDecoder(body)
+		decoder.DisallowUnknownFields()
+
+		response, err := handle(decoder)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// decode リクエストボディのデコード
+//
+// 引数：
+//
+//	decoder： リクエストボディのデコーダ
+//	v： デコード先
+//
+// 戻り値(エラー)：
+//
+//	リクエストボディがJSONとして不正な場合、もしくは最大サイズを超過した場合、エラーが返却される。
+func decode(decoder *json.Decoder, v interface{}) error {
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if stderrors.As(err, &maxBytesErr) {
+		return requestError{
+			status: http.StatusRequestEntityTooLarge,
+			code:   errors.InputValueErrorCode,
+			detail: "リクエストボディが最大サイズを超過しています",
+		}
+	}
+	return newInputValueError(fmt.Sprintf("リクエストボディが不正です: %v", err))
+}
+
+// cylinders 空間ID(円柱)取得
+//
+// 引数：
+//
+//	decoder： リクエストボディ(CylindersRequest)のデコーダ
+//
+// 戻り値：
+//
+//	SpatialIDsResponse
+//
+// 戻り値(エラー)：
+//
+//	GetExtendedSpatialIdsOnCylindersと同じ入力チェックに当てはまる場合、
+//	接続点数が上限を超過した場合、もしくは見積もった空間IDの数が上限を超過した場合、
+//	入力チェックエラーが返却される。
+func (h *Handler) cylinders(decoder *json.Decoder) (interface{}, error) {
+	req := CylindersRequest{}
+	if err := decode(decoder, &req); err != nil {
+		return nil, err
+	}
+
+	if len(req.Center) > h.opts.MaxPoints {
+		return nil, newInputValueError(fmt.Sprintf("center: 接続点数が上限(%d)を超過しています", h.opts.MaxPoints))
+	}
+	center := make([]*object.Point, 0, len(req.Center))
+	for i, p := range req.Center {
+		point, err := toObjectPoint(p, fmt.Sprintf("center[%d]", i))
+		if err != nil {
+			return nil, err
+		}
+		center = append(center, point)
+	}
+
+	hZoom, vZoom, err := resolveZooms(req.HZoom, req.VZoom, req.Zoom)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRadius(req.Radius); err != nil {
+		return nil, err
+	}
+
+	return h.calcSpatialIDs(center, req.Radius, hZoom, vZoom, req.Zoom != nil, req.IsCapsule, req.IsPrecision)
+}
+
+// spheres 空間ID(球)取得
+//
+// 引数：
+//
+//	decoder： リクエストボディ(SphereRequest)のデコーダ
+//
+// 戻り値：
+//
+//	SpatialIDsResponse
+//
+// 戻り値(エラー)：
+//
+//	中心、精度、半径が不正な場合、もしくは見積もった空間IDの数が上限を超過した場合、
+//	入力チェックエラーが返却される。
+func (h *Handler) spheres(decoder *json.Decoder) (interface{}, error) {
+	req := SphereRequest{}
+	if err := decode(decoder, &req); err != nil {
+		return nil, err
+	}
+
+	center, err := toObjectPoint(req.Center, "center")
+	if err != nil {
+		return nil, err
+	}
+	hZoom, vZoom, err := resolveZooms(req.HZoom, req.VZoom, req.Zoom)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRadius(req.Radius); err != nil {
+		return nil, err
+	}
+
+	// 接続点が1つの場合は球となる
+	return h.calcSpatialIDs([]*object.Point{center}, req.Radius, hZoom, vZoom, req.Zoom != nil, false, req.IsPrecision)
+}
+
+// calcSpatialIDs 空間IDの取得
+//
+// 引数：
+//
+//	center： 円柱の中心の接続点
+//	radius： 円柱の半径(単位:m)
+//	hZoom： 水平方向の精度レベル
+//	vZoom： 垂直方向の精度レベル
+//	isSpatialID： 空間IDを返却するかを示す。True: 空間ID / False: 拡張空間ID
+//	isCapsule： 始点、終点が球状であるかを示す
+//	isPrecision： 衝突判定実施オプション(nilの場合はTrue)
+//
+// 戻り値：
+//
+//	SpatialIDsResponse
+//
+// 戻り値(エラー)：
+//
+//	見積もった空間IDの数が上限を超過した場合、入力チェックエラーが返却される。
+//	空間IDの取得に失敗した場合、SpatialIdErrorが返却される。
+func (h *Handler) calcSpatialIDs(
+	center []*object.Point,
+	radius float64,
+	hZoom int64,
+	vZoom int64,
+	isSpatialID bool,
+	isCapsule bool,
+	isPrecision *bool,
+) (interface{}, error) {
+	precision := true
+	if isPrecision != nil {
+		precision = *isPrecision
+	}
+
+	// 空間IDを取得する前に処理量を制限する
+	estimated, err := shape.EstimateExtendedSpatialIdsOnCylinders(center, radius, hZoom, vZoom)
+	if err != nil {
+		return nil, err
+	}
+	if estimated > float64(h.opts.MaxSpatialIDs) {
+		return nil, newInputValueError(
+			fmt.Sprintf("空間IDの数が上限(%d)を超過します。半径を小さく、もしくは精度レベルを下げてください", h.opts.MaxSpatialIDs),
+		)
+	}
+
+	var spatialIDs []string
+	if isSpatialID {
+		spatialIDs, err = shape.GetSpatialIdsOnCylinders(
+			center, radius, hZoom, isCapsule, shape.IsPrecision(precision),
+		)
+	} else {
+		spatialIDs, err = shape.GetExtendedSpatialIdsOnCylinders(
+			center, radius, hZoom, vZoom, isCapsule, shape.IsPrecision(precision),
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return SpatialIDsResponse{SpatialIDs: spatialIDs}, nil
+}
+
+// toSpatialID 軸IDから拡張空間ID取得
+//
+// 引数：
+//
+//	decoder： リクエストボディ(AxisIDs)のデコーダ
+//
+// 戻り値：
+//
+//	SpatialIDResponse
+//
+// 戻り値(エラー)：
+//
+//	軸ID、精度が不正な場合、入力チェックエラーが返却される。
+func (h *Handler) toSpatialID(decoder *json.Decoder) (interface{}, error) {
+	req := AxisIDs{}
+	if err := decode(decoder, &req); err != nil {
+		return nil, err
+	}
+	if err := validateAxisIDs(&req); err != nil {
+		return nil, err
+	}
+
+	return SpatialIDResponse{
+		SpatialID: shape.GetSpatialIDOnAxisIDs(*req.XID, *req.YID, *req.ZID, *req.HZoom, *req.VZoom),
+	}, nil
+}
+
+// fromSpatialID 拡張空間IDから軸ID取得
+//
+// 引数：
+//
+//	decoder： リクエストボディ(SpatialIDRequest)のデコーダ
+//
+// 戻り値：
+//
+//	AxisIDs
+//
+// 戻り値(エラー)：
+//
+//	拡張空間IDのフォーマット、もしくは値が不正な場合、入力チェックエラーが返却される。
+func (h *Handler) fromSpatialID(decoder *json.Decoder) (interface{}, error) {
+	req := SpatialIDRequest{}
+	if err := decode(decoder, &req); err != nil {
+		return nil, err
+	}
+
+	ids := strings.Split(req.SpatialID, consts.SpatialIDDelimiter)
+	if len(ids) != 5 {
+		return nil, newInputValueError("spatialId: 拡張空間IDのフォーマットが不正です")
+	}
+	values := make([]int64, len(ids))
+	for i, id := range ids {
+		value, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, newInputValueError("spatialId: 拡張空間IDのフォーマットが不正です")
+		}
+		values[i] = value
+	}
+
+	// 拡張空間IDは"水平精度/X/Y/垂直精度/高さ"の順
+	axisIDs := AxisIDs{
+		HZoom: &values[0],
+		XID:   &values[1],
+		YID:   &values[2],
+		VZoom: &values[3],
+		ZID:   &values[4],
+	}
+	if err := validateAxisIDs(&axisIDs); err != nil {
+		return nil, err
+	}
+	return axisIDs, nil
+}
@@ -0,0 +1,261 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+)
+
+// post テスト用POSTリクエストの送信
+func post(h http.Handler, path string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return recorder
+}
+
+// TestCylinders01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.753098, 35.685371, 11.0), (139.753198, 35.685371, 11.0)
+//   - 半径：2.0、水平精度：25、垂直精度：25
+//
+// + 確認内容
+//   - GetExtendedSpatialIdsOnCylindersと同じ拡張空間IDが応答されること
+func TestCylinders01(t *testing.T) {
+	//入力パラメータ
+	body := `{"center": [{"lon": 139.753098, "lat": 35.685371, "alt": 11.0},
+		{"lon": 139.753198, "lat": 35.685371, "alt": 11.0}], "radius": 2.0, "hZoom": 25, "vZoom": 25}`
+
+	// 期待値
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+	expectVal, _ := shape.GetExtendedSpatialIdsOnCylinders([]*object.Point{p1, p2}, 2.0, 25, 25, false)
+
+	// テスト対象呼び出し
+	recorder := post(NewHandler(), "/cylinders", body)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("ステータスコード - 期待値：200, 取得値：%v, 応答：%s", recorder.Code, recorder.Body)
+	}
+	resultVal := SpatialIDsResponse{}
+	json.Unmarshal(recorder.Body.Bytes(), &resultVal)
+	if !reflect.DeepEqual(resultVal.SpatialIDs, expectVal) {
+		t.Errorf("空間ID - 期待値：%v, 取得値：%v", expectVal, resultVal.SpatialIDs)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestCylinders02 異常系動作確認(入力チェック)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：半径が0
+//   - パターン2：水平精度が36
+//   - パターン3：精度が未指定
+//   - パターン4：緯度が未指定
+//   - パターン5：接続点数が上限超過
+//   - パターン6：不明な項目
+//   - パターン7：JSONとして不正
+//   - パターン8：半径1000m、精度35(見積もった空間IDの数が上限超過)
+//
+// + 確認内容
+//   - ステータスコード400、エラーコードInputValueErrorが応答されること
+func TestCylinders02(t *testing.T) {
+	//入力パラメータ
+	bodies := []string{
+		`{"center": [{"lon": 139.0, "lat": 35.0}], "radius": 0, "hZoom": 25, "vZoom": 25}`,
+		`{"center": [{"lon": 139.0, "lat": 35.0}], "radius": 1, "hZoom": 36, "vZoom": 25}`,
+		`{"center": [{"lon": 139.0, "lat": 35.0}], "radius": 1}`,
+		`{"center": [{"lon": 139.0}], "radius": 1, "zoom": 25}`,
+		`{"center": [{"lon": 139.0, "lat": 35.0}, {"lon": 139.1, "lat": 35.0}, {"lon": 139.2, "lat": 35.0}],
+			"radius": 1, "zoom": 25}`,
+		`{"center": [{"lon": 139.0, "lat": 35.0}], "radius": 1, "zoom": 25, "height": 1}`,
+		`{"center": [`,
+		`{"center": [{"lon": 139.0, "lat": 35.0}], "radius": 1000, "zoom": 35}`,
+	}
+
+	h := NewHandler(MaxPoints(2))
+	for _, body := range bodies {
+		// テスト対象呼び出し
+		recorder := post(h, "/cylinders", body)
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("ステータスコード - 期待値：400, 取得値：%v, 入力値：%s", recorder.Code, body)
+		}
+		resultVal := ErrorResponse{}
+		json.Unmarshal(recorder.Body.Bytes(), &resultVal)
+		if resultVal.Code != "InputValueError" || resultVal.Message == "" {
+			t.Errorf("エラー応答 - 期待値：InputValueError, 取得値：%+v, 入力値：%s", resultVal, body)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestCylinders03 異常系動作確認(リクエストサイズ、メソッド)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：リクエストボディの最大サイズ(16byte)を超過
+//   - パターン2：GETメソッド
+//
+// + 確認内容
+//   - パターン1：ステータスコード413が応答されること
+//   - パターン2：ステータスコード405が応答されること
+func TestCylinders03(t *testing.T) {
+	h := NewHandler(MaxBodyBytes(16))
+
+	// テスト対象呼び出し
+	recorder := post(h, "/cylinders", `{"center": [{"lon": 139.0, "lat": 35.0}], "radius": 1, "zoom": 25}`)
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("ステータスコード - 期待値：413, 取得値：%v", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/cylinders", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("ステータスコード - 期待値：405, 取得値：%v", recorder.Code)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestSpheres01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 中心：(139.753098, 35.685371, 11.0)
+//   - 半径：1.0、精度：25
+//
+// + 確認内容
+//   - 接続点1つのGetSpatialIdsOnCylindersと同じ空間IDが応答されること
+func TestSpheres01(t *testing.T) {
+	//入力パラメータ
+	body := `{"center": {"lon": 139.753098, "lat": 35.685371, "alt": 11.0}, "radius": 1.0, "zoom": 25}`
+
+	// 期待値
+	p, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	expectVal, _ := shape.GetSpatialIdsOnCylinders([]*object.Point{p}, 1.0, 25, false)
+
+	// テスト対象呼び出し
+	recorder := post(NewHandler(), "/spheres", body)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("ステータスコード - 期待値：200, 取得値：%v, 応答：%s", recorder.Code, recorder.Body)
+	}
+	resultVal := SpatialIDsResponse{}
+	json.Unmarshal(recorder.Body.Bytes(), &resultVal)
+	if len(resultVal.SpatialIDs) == 0 || !reflect.DeepEqual(resultVal.SpatialIDs, expectVal) {
+		t.Errorf("空間ID - 期待値：%v, 取得値：%v", expectVal, resultVal.SpatialIDs)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestAxisIDs01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 軸ID：(1, 2, -3)、水平精度：4、垂直精度：5
+//
+// + 確認内容
+//   - 軸IDから拡張空間ID"4/1/2/5/-3"が応答されること
+//   - 拡張空間IDから元の軸IDが応答されること
+func TestAxisIDs01(t *testing.T) {
+	h := NewHandler()
+
+	// テスト対象呼び出し
+	recorder := post(h, "/axis-ids/to-spatial-id", `{"xId": 1, "yId": 2, "zId": -3, "hZoom": 4, "vZoom": 5}`)
+	spatialID := SpatialIDResponse{}
+	json.Unmarshal(recorder.Body.Bytes(), &spatialID)
+	if recorder.Code != http.StatusOK || spatialID.SpatialID != "4/1/2/5/-3" {
+		t.Errorf("拡張空間ID - 期待値：4/1/2/5/-3, 取得値：%v, %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = post(h, "/axis-ids/from-spatial-id", `{"spatialId": "4/1/2/5/-3"}`)
+	axisIDs := AxisIDs{}
+	json.Unmarshal(recorder.Body.Bytes(), &axisIDs)
+	if recorder.Code != http.StatusOK || *axisIDs.XID != 1 || *axisIDs.YID != 2 || *axisIDs.ZID != -3 ||
+		*axisIDs.HZoom != 4 || *axisIDs.VZoom != 5 {
+		t.Errorf("軸ID - 期待値：(1, 2, -3, 4, 5), 取得値：%v, %s", recorder.Code, recorder.Body)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestAxisIDs02 異常系動作確認(入力チェック)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：X軸IDが水平精度の範囲外
+//   - パターン2：Z軸IDが未指定
+//   - パターン3：拡張空間IDの区切り数不正
+//   - パターン4：拡張空間IDに数値以外を含む
+//
+// + 確認内容
+//   - ステータスコード400、エラーコードInputValueErrorが応答されること
+func TestAxisIDs02(t *testing.T) {
+	//入力パラメータ
+	requests := []struct {
+		path string
+		body string
+	}{
+		{"/axis-ids/to-spatial-id", `{"xId": 16, "yId": 2, "zId": 0, "hZoom": 4, "vZoom": 5}`},
+		{"/axis-ids/to-spatial-id", `{"xId": 1, "yId": 2, "hZoom": 4, "vZoom": 5}`},
+		{"/axis-ids/from-spatial-id", `{"spatialId": "4/1/2/5"}`},
+		{"/axis-ids/from-spatial-id", `{"spatialId": "4/1/a/5/0"}`},
+	}
+
+	h := NewHandler()
+	for _, request := range requests {
+		// テスト対象呼び出し
+		recorder := post(h, request.path, request.body)
+
+		resultVal := ErrorResponse{}
+		json.Unmarshal(recorder.Body.Bytes(), &resultVal)
+		if recorder.Code != http.StatusBadRequest || resultVal.Code != "InputValueError" {
+			t.Errorf("エラー応答 - 期待値：400 InputValueError, 取得値：%v %+v, 入力値：%s",
+				recorder.Code, resultVal, request.body)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestToErrorResponse01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：エラー文字列"ValueConvertError,値変換エラー"
+//   - パターン2：カンマを含まないエラー文字列
+//
+// + 確認内容
+//   - パターン1：ステータスコード422、エラーコードValueConvertErrorとなること
+//   - パターン2：ステータスコード500、エラーコードOtherErrorとなること
+func TestToErrorResponse01(t *testing.T) {
+	// テスト対象呼び出し
+	status, resultVal := toErrorResponse(testError("ValueConvertError,値変換エラー"))
+	if status != http.StatusUnprocessableEntity || resultVal.Code != "ValueConvertError" || resultVal.Message != "値変換エラー" {
+		t.Errorf("エラー応答 - 取得値：%v %+v", status, resultVal)
+	}
+
+	status, resultVal = toErrorResponse(testError("unexpected"))
+	if status != http.StatusInternalServerError || resultVal.Code != "OtherError" {
+		t.Errorf("エラー応答 - 取得値：%v %+v", status, resultVal)
+	}
+
+	t.Log("テスト終了")
+}
+
+// testError テスト用エラー型
+type testError string
+
+func (e testError) Error() string { return string(e) }
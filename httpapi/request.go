@@ -0,0 +1,161 @@
+package httpapi
+
+import (
+	"fmt"
+
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// Point 座標のJSON構造体
+type Point struct {
+	Lon *float64 `json:"lon"` // 経度
+	Lat *float64 `json:"lat"` // 緯度
+	Alt float64  `json:"alt"` // 高さ(省略時は0)
+}
+
+// CylindersRequest 空間ID(円柱)取得のリクエスト構造体
+type CylindersRequest struct {
+	Center      []*Point `json:"center"`      // 円柱の中心の接続点
+	Radius      float64  `json:"radius"`      // 円柱の半径(単位:m)
+	HZoom       *int64   `json:"hZoom"`       // 水平方向の精度レベル
+	VZoom       *int64   `json:"vZoom"`       // 垂直方向の精度レベル
+	Zoom        *int64   `json:"zoom"`        // 空間IDの精度レベル(指定時は空間IDを返却)
+	IsCapsule   bool     `json:"isCapsule"`   // 始点、終点が球状であるかを示す
+	IsPrecision *bool    `json:"isPrecision"` // 衝突判定実施オプション(省略時はTrue)
+}
+
+// SphereRequest 空間ID(球)取得のリクエスト構造体
+type SphereRequest struct {
+	Center      *Point  `json:"center"`      // 球の中心
+	Radius      float64 `json:"radius"`      // 球の半径(単位:m)
+	HZoom       *int64  `json:"hZoom"`       // 水平方向の精度レベル
+	VZoom       *int64  `json:"vZoom"`       // 垂直方向の精度レベル
+	Zoom        *int64  `json:"zoom"`        // 空間IDの精度レベル(指定時は空間IDを返却)
+	IsPrecision *bool   `json:"isPrecision"` // 衝突判定実施オプション(省略時はTrue)
+}
+
+// AxisIDs 軸IDと精度のJSON構造体
+type AxisIDs struct {
+	XID   *int64 `json:"xId"`   // X軸ボクセルID
+	YID   *int64 `json:"yId"`   // Y軸ボクセルID
+	ZID   *int64 `json:"zId"`   // Z軸ボクセルID
+	HZoom *int64 `json:"hZoom"` // 水平方向精度
+	VZoom *int64 `json:"vZoom"` // 垂直方向精度
+}
+
+// SpatialIDRequest 拡張空間IDのリクエスト構造体
+type SpatialIDRequest struct {
+	SpatialID string `json:"spatialId"` // 拡張空間ID
+}
+
+// SpatialIDResponse 拡張空間IDの応答構造体
+type SpatialIDResponse struct {
+	SpatialID string `json:"spatialId"` // 拡張空間ID
+}
+
+// SpatialIDsResponse 空間IDリストの応答構造体
+type SpatialIDsResponse struct {
+	SpatialIDs []string `json:"spatialIds"` // 空間ID、もしくは拡張空間IDのリスト
+}
+
+// toObjectPoint 座標のJSON構造体からPointオブジェクトへの変換
+//
+// 引数：
+//
+//	p： 座標のJSON構造体
+//	name： エラーの詳細に使用する項目名
+//
+// 戻り値：
+//
+//	Pointオブジェクト
+//
+// 戻り値(エラー)：
+//
+//	座標が未指定、もしくは値が不正な場合、入力チェックエラーが返却される。
+func toObjectPoint(p *Point, name string) (*object.Point, error) {
+	if p == nil || p.Lon == nil || p.Lat == nil {
+		return nil, newInputValueError(fmt.Sprintf("%s: lon, latを指定してください", name))
+	}
+
+	point, err := object.NewPoint(*p.Lon, *p.Lat, p.Alt)
+	if err != nil {
+		return nil, newInputValueError(fmt.Sprintf("%s: 座標の値が不正です", name))
+	}
+	return point, nil
+}
+
+// resolveZooms 精度レベルの決定
+//
+// zoomが指定されている場合は水平方向、垂直方向ともにzoomを使用する。
+//
+// 引数：
+//
+//	hZoom： 水平方向の精度レベル
+//	vZoom： 垂直方向の精度レベル
+//	zoom： 空間IDの精度レベル
+//
+// 戻り値：
+//
+//	(水平方向の精度レベル, 垂直方向の精度レベル)
+//
+// 戻り値(エラー)：
+//
+//	精度レベルが未指定、もしくは 0 ～ 35 の整数値以外の場合、入力チェックエラーが返却される。
+func resolveZooms(hZoom, vZoom, zoom *int64) (int64, int64, error) {
+	if zoom != nil {
+		hZoom, vZoom = zoom, zoom
+	}
+	if hZoom == nil || vZoom == nil {
+		return 0, 0, newInputValueError("hZoom, vZoom、もしくはzoomを指定してください")
+	}
+	if !shape.CheckZoom(*hZoom) || !shape.CheckZoom(*vZoom) {
+		return 0, 0, newInputValueError("精度レベルは0～35の整数値を指定してください")
+	}
+	return *hZoom, *vZoom, nil
+}
+
+// validateRadius 半径の入力チェック
+//
+// 引数：
+//
+//	radius： 半径(単位:m)
+//
+// 戻り値(エラー)：
+//
+//	半径が0以下の場合、入力チェックエラーが返却される。
+func validateRadius(radius float64) error {
+	if radius <= consts.Minima {
+		return newInputValueError("radius: 0より大きい値を指定してください")
+	}
+	return nil
+}
+
+// validateAxisIDs 軸IDの入力チェック
+//
+// 引数：
+//
+//	a： 軸IDと精度
+//
+// 戻り値(エラー)：
+//
+//	軸ID、精度が未指定、もしくは精度の範囲外の場合、入力チェックエラーが返却される。
+func validateAxisIDs(a *AxisIDs) error {
+	if a.XID == nil || a.YID == nil || a.ZID == nil || a.HZoom == nil || a.VZoom == nil {
+		return newInputValueError("xId, yId, zId, hZoom, vZoomを指定してください")
+	}
+	if !shape.CheckZoom(*a.HZoom) || !shape.CheckZoom(*a.VZoom) {
+		return newInputValueError("精度レベルは0～35の整数値を指定してください")
+	}
+
+	hMax := int64(1) << *a.HZoom
+	vMax := int64(1) << *a.VZoom
+	if *a.XID < 0 || *a.XID >= hMax || *a.YID < 0 || *a.YID >= hMax {
+		return newInputValueError("xId, yId: 水平方向精度の範囲外です")
+	}
+	if *a.ZID < -vMax || *a.ZID >= vMax {
+		return newInputValueError("zId: 垂直方向精度の範囲外です")
+	}
+	return nil
+}
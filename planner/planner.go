@@ -0,0 +1,476 @@
+// Package planner 経路探索パッケージ
+//
+// 障害物とする拡張空間IDを避けて、2地点間を結ぶ経路を拡張空間IDの隣接関係の上で探索する。
+// 探索はTheta*(見通しのある祖先の空間IDへ直接接続するA*)により行い、
+// 経由点の折れ線を円柱の経路とした空間IDが障害物の空間IDと重ならない経路を返却する。
+package planner
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	sidshape "github.com/trajectoryjp/spatial_id_go/shape"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+)
+
+// DefaultMaxExpansions 探索する空間IDの数の上限のデフォルト値
+const DefaultMaxExpansions = 100000
+
+// lineOfSightIterate 線分とボクセルの最短距離の探索の反復回数
+const lineOfSightIterate = 60
+
+// Opts 経路探索オプショナル引数構造体
+type Opts struct {
+	MaxExpansions int // 探索する空間IDの数の上限(0以下の場合はDefaultMaxExpansions)
+}
+
+// 経路探索オプショナル型
+type option func(*Opts)
+
+// MaxExpansions 探索する空間IDの数の上限設定関数
+//
+// 障害物に囲まれて到達できない場合等に探索を打ち切る空間IDの数を設定する。
+//
+// 引数：
+//
+//	n: 探索する空間IDの数の上限
+//
+// 戻り値：
+//
+//	経路探索オプショナル型の関数
+func MaxExpansions(n int) option {
+	return func(o *Opts) {
+		o.MaxExpansions = n
+	}
+}
+
+// voxelKey 探索する空間IDの軸ID(X成分は経度180度で折り返さない)
+type voxelKey struct {
+	x, y, z int64
+}
+
+// grid 探索する空間IDの格子
+type grid struct {
+	hZoom   int64                       // 水平方向の精度レベル
+	vZoom   int64                       // 垂直方向の精度レベル
+	origin  voxelKey                    // 始点の空間IDの軸ID
+	center  spatial.Point3              // 【直交座標空間】始点の空間IDの中心
+	unit    spatial.Vector3             // 【直交座標空間】ボクセルの対角線ベクトル
+	radius  float64                     // 【直交座標空間】経路の円柱の半径
+	blocked map[voxelKey]bool           // 障害物の空間IDの軸ID
+	keys    []voxelKey                  // 障害物の空間IDの軸IDのリスト
+	points  map[voxelKey]spatial.Point3 // 【直交座標空間】始点、終点の空間IDの位置
+}
+
+// PlanRoute 障害物を避ける経路の探索
+//
+// 始点から終点まで、障害物の拡張空間IDを避けて拡張空間IDの隣接関係(26近傍)の上で経路を探索する。
+// 返却する経由点をGetExtendedSpatialIdsOnCylinders(isCapsule: false)で半径radiusの円柱の経路とした
+// 拡張空間IDは、障害物の拡張空間IDと重ならないことを確認済みとする。
+// 経由点は始点、終点と、経路が曲がる位置の空間IDの中心とする。
+//
+// 引数：
+//
+//	start  : 始点
+//	goal   : 終点
+//	radius : 経路の円柱の半径(単位:m)
+//	hZoom  : 水平方向の精度レベル
+//	vZoom  : 垂直方向の精度レベル
+//	blocked: 障害物の拡張空間ID。精度レベルはhZoom、vZoomと同じとする
+//	opts   : 経路探索オプション。MaxExpansionsを使用する
+//
+// 戻り値：
+//
+//	始点から終点までの経由点のリスト
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 始点、終点がnilの場合、半径が0以下の場合、精度レベルが範囲外の場合、
+//	               障害物の拡張空間IDのフォーマット、精度レベルが不正な場合、
+//	               もしくは始点、終点の円柱の半径の範囲に障害物がある場合
+//	 その他例外： 探索する空間IDの数の上限までに経路が見つからない場合、
+//	             もしくは経路の空間IDが障害物の空間IDと重なる場合
+func PlanRoute(
+	start *object.Point,
+	goal *object.Point,
+	radius float64,
+	hZoom int64,
+	vZoom int64,
+	blocked []string,
+	opts ...option,
+) ([]*object.Point, error) {
+	o := &Opts{MaxExpansions: DefaultMaxExpansions}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.MaxExpansions <= 0 {
+		o.MaxExpansions = DefaultMaxExpansions
+	}
+
+	// 入力値チェック
+	if start == nil || goal == nil || radius <= consts.Minima ||
+		!sidshape.CheckZoom(hZoom) || !sidshape.CheckZoom(vZoom) {
+		return []*object.Point{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+
+	g, startKey, goalKey, err := newGrid(start, goal, radius, hZoom, vZoom, blocked)
+	if err != nil {
+		return []*object.Point{}, err
+	}
+	if !g.lineOfSight(startKey, startKey) || !g.lineOfSight(goalKey, goalKey) {
+		return []*object.Point{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "始点、終点の近くに障害物があります")
+	}
+
+	path, err := g.search(startKey, goalKey, o.MaxExpansions)
+	if err != nil {
+		return []*object.Point{}, err
+	}
+
+	// 経由点の座標
+	route := make([]*object.Point, 0, len(path))
+	for i, key := range path {
+		switch {
+		case i == 0:
+			route = append(route, start)
+		case i == len(path)-1:
+			route = append(route, goal)
+		default:
+			points, _ := sidshape.GetPointOnExtendedSpatialId(g.spatialID(key), enum.Center)
+			route = append(route, points[0])
+		}
+	}
+
+	// 経路の空間IDが障害物の空間IDと重ならないことを確認
+	routeIDs, err := shape.GetExtendedSpatialIdsOnCylinders(route, radius, hZoom, vZoom, false)
+	if err != nil {
+		return []*object.Point{}, err
+	}
+	if len(common.Intersect(routeIDs, blocked)) > 0 {
+		return []*object.Point{}, errors.NewSpatialIdError(errors.OtherErrorCode, "経路の空間IDが障害物と重なります")
+	}
+
+	return route, nil
+}
+
+// newGrid 探索する空間IDの格子の作成
+//
+// 引数：
+//
+//	start： 始点
+//	goal： 終点
+//	radius： 経路の円柱の半径(単位:m)
+//	hZoom： 水平方向の精度レベル
+//	vZoom： 垂直方向の精度レベル
+//	blocked： 障害物の拡張空間ID
+//
+// 戻り値：
+//
+//	探索する空間IDの格子、始点、終点の空間IDの軸ID
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 始点、終点の座標が不正な場合、障害物の拡張空間IDのフォーマット、精度レベルが不正な場合
+func newGrid(
+	start, goal *object.Point,
+	radius float64,
+	hZoom, vZoom int64,
+	blocked []string,
+) (*grid, voxelKey, voxelKey, error) {
+	ids, err := sidshape.GetExtendedSpatialIdsOnPoints([]*object.Point{start, goal}, hZoom, vZoom)
+	if err != nil || len(ids) != 2 {
+		return nil, voxelKey{}, voxelKey{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+
+	// メルカトル距離補正
+	factor := 1 / math.Cos(common.DegreeToRadian(start.Lat()))
+
+	// 【直交座標空間】始点の空間IDの頂点から中心、ボクセルの対角線ベクトルを算出
+	vertexes, _ := sidshape.GetPointOnExtendedSpatialId(ids[0], enum.Vertex)
+	orthVertexes, _ := sidshape.ConvertPointListToProjectedPointList(vertexes, consts.OrthCrs)
+	low := spatial.Point3{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)}
+	high := spatial.Point3{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)}
+	for _, v := range orthVertexes {
+		low = spatial.Point3{X: math.Min(low.X, v.X), Y: math.Min(low.Y, v.Y), Z: math.Min(low.Z, v.Alt*factor)}
+		high = spatial.Point3{X: math.Max(high.X, v.X), Y: math.Max(high.Y, v.Y), Z: math.Max(high.Z, v.Alt*factor)}
+	}
+
+	g := &grid{
+		hZoom:   hZoom,
+		vZoom:   vZoom,
+		origin:  parseKey(shape.GetVoxelIDToSpatialID(ids[0])),
+		center:  spatial.Point3{X: (low.X + high.X) / 2, Y: (low.Y + high.Y) / 2, Z: (low.Z + high.Z) / 2},
+		unit:    spatial.Vector3{X: high.X - low.X, Y: high.Y - low.Y, Z: high.Z - low.Z},
+		radius:  radius * factor,
+		blocked: make(map[voxelKey]bool, len(blocked)),
+		points:  map[voxelKey]spatial.Point3{},
+	}
+
+	for _, spatialID := range blocked {
+		id, err := shape.ParseExtendedSpatialID(spatialID)
+		if err != nil || id.HZoom() != hZoom || id.VZoom() != vZoom {
+			return nil, voxelKey{}, voxelKey{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "障害物の空間IDが不正です")
+		}
+		key := g.unwrap(voxelKey{x: id.X(), y: id.Y(), z: id.F()})
+		if !g.blocked[key] {
+			g.blocked[key] = true
+			g.keys = append(g.keys, key)
+		}
+	}
+
+	// 【直交座標空間】始点、終点の空間IDの位置は始点、終点の座標とする
+	startKey := g.origin
+	rawGoalKey := parseKey(shape.GetVoxelIDToSpatialID(ids[1]))
+	goalKey := g.unwrap(rawGoalKey)
+	orthPoints, err := sidshape.ConvertPointListToProjectedPointList([]*object.Point{start, goal}, consts.OrthCrs)
+	if err != nil {
+		return nil, voxelKey{}, voxelKey{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	goalOrth := spatial.Point3{X: orthPoints[1].X, Y: orthPoints[1].Y, Z: orthPoints[1].Alt * factor}
+	// 経度180度をまたがる場合は始点側へ折り返した座標とする
+	goalOrth.X += float64(goalKey.x-rawGoalKey.x) * g.unit.X
+	g.points[startKey] = spatial.Point3{X: orthPoints[0].X, Y: orthPoints[0].Y, Z: orthPoints[0].Alt * factor}
+	if startKey != goalKey {
+		g.points[goalKey] = goalOrth
+	}
+
+	return g, startKey, goalKey, nil
+}
+
+// parseKey 軸IDから空間IDの軸IDへの変換
+func parseKey(ids []int64) voxelKey {
+	return voxelKey{x: ids[0], y: ids[1], z: ids[2]}
+}
+
+// unwrap 始点に近い側へ経度180度で折り返したX成分の軸ID
+func (g *grid) unwrap(key voxelKey) voxelKey {
+	size := int64(1) << g.hZoom
+	dx := ((key.x-g.origin.x)%size + size) % size
+	if dx > size/2 {
+		dx -= size
+	}
+	key.x = g.origin.x + dx
+	return key
+}
+
+// spatialID 軸IDから拡張空間IDへの変換(X成分は経度180度で折り返す)
+func (g *grid) spatialID(key voxelKey) string {
+	size := int64(1) << g.hZoom
+	return shape.GetSpatialIDOnAxisIDs((key.x%size+size)%size, key.y, key.z, g.hZoom, g.vZoom)
+}
+
+// voxelCenter 【直交座標空間】空間IDの中心
+func (g *grid) voxelCenter(key voxelKey) spatial.Point3 {
+	return spatial.Point3{
+		X: g.center.X + float64(key.x-g.origin.x)*g.unit.X,
+		Y: g.center.Y - float64(key.y-g.origin.y)*g.unit.Y,
+		Z: g.center.Z + float64(key.z-g.origin.z)*g.unit.Z,
+	}
+}
+
+// position 【直交座標空間】経由点の位置(始点、終点の空間IDは始点、終点の座標)
+func (g *grid) position(key voxelKey) spatial.Point3 {
+	if point, ok := g.points[key]; ok {
+		return point
+	}
+	return g.voxelCenter(key)
+}
+
+// distance 【直交座標空間】経由点間の距離
+func (g *grid) distance(a, b voxelKey) float64 {
+	return spatial.NewVectorFromPoints(g.position(a), g.position(b)).Norm()
+}
+
+// lineOfSight 経由点間の見通し判定
+//
+// 経由点間の線分から円柱の半径以内に障害物のボクセルが無い場合に見通しがあると判定する。
+//
+// 引数：
+//
+//	a： 経由点の空間IDの軸ID
+//	b： 経由点の空間IDの軸ID
+//
+// 戻り値：
+//
+//	見通しがある場合true
+func (g *grid) lineOfSight(a, b voxelKey) bool {
+	p, q := g.position(a), g.position(b)
+
+	// 線分の範囲に円柱の半径を加えた範囲の軸ID
+	margins := [3]int64{
+		int64(math.Ceil(g.radius/g.unit.X)) + 1,
+		int64(math.Ceil(g.radius/g.unit.Y)) + 1,
+		int64(math.Ceil(g.radius/g.unit.Z)) + 1,
+	}
+	lowKey := voxelKey{x: min(a.x, b.x) - margins[0], y: min(a.y, b.y) - margins[1], z: min(a.z, b.z) - margins[2]}
+	highKey := voxelKey{x: max(a.x, b.x) + margins[0], y: max(a.y, b.y) + margins[1], z: max(a.z, b.z) + margins[2]}
+
+	collide := func(key voxelKey) bool {
+		return g.segmentDistance(p, q, g.voxelCenter(key)) <= g.radius
+	}
+
+	// 範囲の空間IDの数が障害物の数より少ない場合は範囲の空間IDを確認する
+	volume := float64(highKey.x-lowKey.x+1) * float64(highKey.y-lowKey.y+1) * float64(highKey.z-lowKey.z+1)
+	if volume < float64(len(g.keys)) {
+		for x := lowKey.x; x <= highKey.x; x++ {
+			for y := lowKey.y; y <= highKey.y; y++ {
+				for z := lowKey.z; z <= highKey.z; z++ {
+					key := voxelKey{x: x, y: y, z: z}
+					if g.blocked[key] && collide(key) {
+						return false
+					}
+				}
+			}
+		}
+		return true
+	}
+
+	for _, key := range g.keys {
+		if key.x < lowKey.x || key.x > highKey.x || key.y < lowKey.y || key.y > highKey.y ||
+			key.z < lowKey.z || key.z > highKey.z {
+			continue
+		}
+		if collide(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// segmentDistance 【直交座標空間】線分とボクセルの最短距離
+//
+// ボクセルとの距離は線分上の位置に対して凸関数のため三分探索により求める。
+//
+// 引数：
+//
+//	p： 線分の始点
+//	q： 線分の終点
+//	center： ボクセルの中心
+//
+// 戻り値：
+//
+//	最短距離(線分がボクセルを通る場合は0)
+func (g *grid) segmentDistance(p, q, center spatial.Point3) float64 {
+	distance := func(t float64) float64 {
+		dx := math.Max(math.Abs(p.X+(q.X-p.X)*t-center.X)-g.unit.X/2, 0)
+		dy := math.Max(math.Abs(p.Y+(q.Y-p.Y)*t-center.Y)-g.unit.Y/2, 0)
+		dz := math.Max(math.Abs(p.Z+(q.Z-p.Z)*t-center.Z)-g.unit.Z/2, 0)
+		return math.Sqrt(dx*dx + dy*dy + dz*dz)
+	}
+
+	low, high := 0.0, 1.0
+	for i := 0; i < lineOfSightIterate; i++ {
+		m1, m2 := low+(high-low)/3, high-(high-low)/3
+		if distance(m1) < distance(m2) {
+			high = m2
+		} else {
+			low = m1
+		}
+	}
+	return math.Min(distance((low+high)/2), math.Min(distance(0), distance(1)))
+}
+
+// search Theta*による経路探索
+//
+// 引数：
+//
+//	start： 始点の空間IDの軸ID
+//	goal： 終点の空間IDの軸ID
+//	maxExpansions： 探索する空間IDの数の上限
+//
+// 戻り値：
+//
+//	始点から終点までの経由点の空間IDの軸ID
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 その他例外： 探索する空間IDの数の上限までに経路が見つからない場合
+func (g *grid) search(start, goal voxelKey, maxExpansions int) ([]voxelKey, error) {
+	size, vSize := int64(1)<<g.hZoom, int64(1)<<g.vZoom
+	cost := map[voxelKey]float64{start: 0}
+	parent := map[voxelKey]voxelKey{start: start}
+	closed := map[voxelKey]bool{}
+	open := &nodeQueue{}
+	heap.Push(open, &node{key: start, priority: g.distance(start, goal)})
+
+	for expansions := 0; open.Len() > 0; {
+		current := heap.Pop(open).(*node)
+		if closed[current.key] {
+			continue
+		}
+		if current.key == goal {
+			// 終点から始点へ親をたどる
+			path := []voxelKey{goal}
+			for key := goal; key != start; {
+				key = parent[key]
+				path = append(path, key)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path, nil
+		}
+		closed[current.key] = true
+		if expansions++; expansions > maxExpansions {
+			break
+		}
+
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for dz := int64(-1); dz <= 1; dz++ {
+					next := voxelKey{x: current.key.x + dx, y: current.key.y + dy, z: current.key.z + dz}
+					if next == current.key || closed[next] || next.y < 0 || next.y >= size ||
+						next.z < -vSize || next.z >= vSize {
+						continue
+					}
+
+					// 親の経由点から見通しがある場合は親の経由点に直接接続する
+					from := parent[current.key]
+					if !g.lineOfSight(from, next) {
+						from = current.key
+						if !g.lineOfSight(from, next) {
+							continue
+						}
+					}
+					nextCost := cost[from] + g.distance(from, next)
+					if c, ok := cost[next]; ok && c <= nextCost {
+						continue
+					}
+					cost[next] = nextCost
+					parent[next] = from
+					heap.Push(open, &node{key: next, priority: nextCost + g.distance(next, goal)})
+				}
+			}
+		}
+	}
+
+	return nil, errors.NewSpatialIdError(errors.OtherErrorCode, "経路が見つかりません")
+}
+
+// node 探索中の空間ID
+type node struct {
+	key      voxelKey // 空間IDの軸ID
+	priority float64  // 始点からのコストと終点までの推定コストの和
+}
+
+// nodeQueue 探索中の空間IDの優先度付きキュー
+type nodeQueue []*node
+
+func (q nodeQueue) Len() int            { return len(q) }
+func (q nodeQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q nodeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x interface{}) { *q = append(*q, x.(*node)) }
+func (q *nodeQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
@@ -0,0 +1,152 @@
+package planner
+
+import (
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	sidshape "github.com/trajectoryjp/spatial_id_go/shape"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+)
+
+// voxelIDs テスト用の地点の空間IDの軸ID
+func voxelIDs(point *object.Point, zoom int64) []int64 {
+	ids, _ := sidshape.GetExtendedSpatialIdsOnPoints([]*object.Point{point}, zoom, zoom)
+	return shape.GetVoxelIDToSpatialID(ids[0])
+}
+
+// TestPlanRoute01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点：(139.75, 35.68, 50.0)、終点：(139.7566, 35.68, 50.0)(東向き約600m)
+//   - 半径：5.0、水平精度：20、垂直精度：20
+//   - パターン1：障害物なし
+//   - パターン2：始点と終点の中間に南北11個、高さ7個の壁状の障害物
+//
+// + 確認内容
+//   - 経由点の始点、終点が入力の始点、終点であること
+//   - パターン1：経由点が始点、終点の2点であること
+//   - パターン2：経由点が3点以上であること
+//   - 経由点を円柱の経路とした空間IDが障害物の空間IDと重ならないこと
+func TestPlanRoute01(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 50.0)
+	goal, _ := object.NewPoint(139.7566, 35.68, 50.0)
+	startIDs, goalIDs := voxelIDs(start, 20), voxelIDs(goal, 20)
+	wall := []string{}
+	midX := (startIDs[0] + goalIDs[0]) / 2
+	for y := startIDs[1] - 5; y <= startIDs[1]+5; y++ {
+		for z := startIDs[2] - 3; z <= startIDs[2]+3; z++ {
+			wall = append(wall, shape.GetSpatialIDOnAxisIDs(midX, y, z, 20, 20))
+		}
+	}
+
+	for i, blocked := range [][]string{{}, wall} {
+		// テスト対象呼び出し
+		resultVal, err := PlanRoute(start, goal, 5.0, 20, 20, blocked)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		if resultVal[0] != start || resultVal[len(resultVal)-1] != goal {
+			t.Errorf("パターン%d 始点、終点 - 期待値：%v, %v, 取得値：%v", i+1, start, goal, resultVal)
+		}
+		if i == 0 && len(resultVal) != 2 {
+			t.Errorf("パターン%d 経由点の数 - 期待値：2, 取得値：%v", i+1, len(resultVal))
+		}
+		if i == 1 && len(resultVal) < 3 {
+			t.Errorf("パターン%d 経由点の数 - 期待値：3以上, 取得値：%v", i+1, len(resultVal))
+		}
+		routeIDs, _ := shape.GetExtendedSpatialIdsOnCylinders(resultVal, 5.0, 20, 20, false)
+		if overlap := common.Intersect(routeIDs, blocked); len(overlap) > 0 {
+			t.Errorf("パターン%d 障害物 - 期待値：重ならない, 取得値：%v", i+1, overlap)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestPlanRoute02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点：(139.75, 35.68, 50.0)、終点：(139.7566, 35.68, 50.0)、半径：5.0、水平精度：20、垂直精度：20
+//   - パターン1：終点の空間IDを囲む障害物(探索する空間IDの数の上限：2000)
+//   - パターン2：始点の空間IDが障害物
+//   - パターン3：障害物の空間IDの精度レベルが異なる
+//   - パターン4：半径が0
+//   - パターン5：終点がnil
+//   - パターン6：始点、終点の高さ33554420.0(高さ成分が最大値)で、終点の空間IDの周囲と下を囲む障害物
+//     (探索する空間IDの数の上限：2000)
+//   - パターン7：障害物の空間IDのX成分が範囲外
+//   - パターン8：障害物の空間IDのX成分が数値でない
+//
+// + 確認内容
+//   - パターン1：経路が見つからないエラーとなること
+//   - パターン2～5：入力チェックエラーとなること
+//   - パターン6：高さ方向の範囲外を経由せず、経路が見つからないエラーとなること
+//   - パターン7、8：入力チェックエラーとなること
+func TestPlanRoute02(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 50.0)
+	goal, _ := object.NewPoint(139.7566, 35.68, 50.0)
+	startIDs, goalIDs := voxelIDs(start, 20), voxelIDs(goal, 20)
+	shell := []string{}
+	for x := int64(-2); x <= 2; x++ {
+		for y := int64(-2); y <= 2; y++ {
+			for z := int64(-2); z <= 2; z++ {
+				if max(abs(x), abs(y), abs(z)) == 2 {
+					shell = append(shell, shape.GetSpatialIDOnAxisIDs(goalIDs[0]+x, goalIDs[1]+y, goalIDs[2]+z, 20, 20))
+				}
+			}
+		}
+	}
+	// 高さ方向の範囲の上端で、上方向のみ開いた障害物
+	topStart, _ := object.NewPoint(139.75, 35.68, 33554420.0)
+	topGoal, _ := object.NewPoint(139.7566, 35.68, 33554420.0)
+	topGoalIDs := voxelIDs(topGoal, 20)
+	cup := []string{}
+	for x := int64(-2); x <= 2; x++ {
+		for y := int64(-2); y <= 2; y++ {
+			for z := int64(-2); z <= 0; z++ {
+				if max(abs(x), abs(y), abs(z)) == 2 {
+					cup = append(cup, shape.GetSpatialIDOnAxisIDs(topGoalIDs[0]+x, topGoalIDs[1]+y, topGoalIDs[2]+z, 20, 20))
+				}
+			}
+		}
+	}
+	calls := []func() ([]*object.Point, error){
+		func() ([]*object.Point, error) { return PlanRoute(start, goal, 5.0, 20, 20, shell, MaxExpansions(2000)) },
+		func() ([]*object.Point, error) {
+			return PlanRoute(start, goal, 5.0, 20, 20, []string{shape.GetSpatialIDOnAxisIDs(startIDs[0], startIDs[1], startIDs[2], 20, 20)})
+		},
+		func() ([]*object.Point, error) { return PlanRoute(start, goal, 5.0, 20, 20, []string{"19/1/1/20/1"}) },
+		func() ([]*object.Point, error) { return PlanRoute(start, goal, 0, 20, 20, nil) },
+		func() ([]*object.Point, error) { return PlanRoute(start, nil, 5.0, 20, 20, nil) },
+		func() ([]*object.Point, error) {
+			return PlanRoute(topStart, topGoal, 5.0, 20, 20, cup, MaxExpansions(2000))
+		},
+		func() ([]*object.Point, error) { return PlanRoute(start, goal, 5.0, 20, 20, []string{"20/-5/0/20/0"}) },
+		func() ([]*object.Point, error) { return PlanRoute(start, goal, 5.0, 20, 20, []string{"20/abc/0/20/0"}) },
+	}
+
+	for i, call := range calls {
+		// テスト対象呼び出し
+		resultVal, err := call()
+
+		if err == nil || len(resultVal) != 0 {
+			t.Errorf("パターン%d - 期待値：エラー, 取得値：%v, %v", i+1, resultVal, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// abs テスト用の整数の絶対値
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
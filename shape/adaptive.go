@@ -0,0 +1,345 @@
+package shape
+
+import (
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// isIncludeVoxel ボクセルの内部判定
+//
+// カプセル、円柱、球は凸形状のため、ボクセルの8頂点が全てオブジェクトの内部にある場合に内部と判定する。
+//
+// 引数：
+//
+//	center： 【直交座標空間】ボクセル中心
+//	lens： 【直交座標空間】ボクセルの対角線ベクトル
+//
+// 戻り値：
+//
+//	ボクセル全体がオブジェクトの内部にある場合true
+func (c Capsule) isIncludeVoxel(center spatial.Point3, lens spatial.Vector3) bool {
+	radius := c.radius * c.factor
+	axis := spatial.NewVectorFromPoints(c.start, c.end)
+	length2 := axis.X*axis.X + axis.Y*axis.Y + axis.Z*axis.Z
+
+	for _, sx := range []float64{-0.5, 0.5} {
+		for _, sy := range []float64{-0.5, 0.5} {
+			for _, sz := range []float64{-0.5, 0.5} {
+				vertex := spatial.Point3{X: center.X + lens.X*sx, Y: center.Y + lens.Y*sy, Z: center.Z + lens.Z*sz}
+				toVertex := spatial.NewVectorFromPoints(c.start, vertex)
+
+				// 軸上の最近点の位置(始点を0、終点を1とする)
+				t := 0.0
+				if !c.isSphere {
+					t = (toVertex.X*axis.X + toVertex.Y*axis.Y + toVertex.Z*axis.Z) / length2
+				}
+				if !c.isSphere && !c.isCapsule && (t < 0 || t > 1) {
+					return false
+				}
+				t = math.Min(math.Max(t, 0), 1)
+
+				if spatial.NewVectorFromPoints(c.start.Translate(axis.Scale(t)), vertex).Norm() > radius {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// calcAdaptiveSpatialIDs 粗い精度から段階的に有効な空間IDを取得
+//
+// 粗い精度の全空間IDから開始し、オブジェクトの内部にあるボクセルはその精度で採用、
+// 衝突しないボクセルは除外し、境界のボクセルのみ子ボクセルに分割して目標の精度まで繰り返す。
+//
+// 引数：
+//
+//	hZoom： 目標の水平精度
+//	vZoom： 目標の垂直精度
+//
+// 戻り値：
+//
+//	(水平精度, 垂直精度)ごとの有効な空間ID、衝突判定の実施回数
+func (c *Capsule) calcAdaptiveSpatialIDs(hZoom, vZoom int64) (map[[2]int64][]string, int) {
+	lineSpatialIDs, _, _ := c.calcLineSpatialIDs()
+	c.calcAllSpatialIDs(lineSpatialIDs, c.calcBaseUnitVoxel())
+
+	accepted := map[[2]int64][]string{}
+	tests := 0
+	candidates := c.allSpatialIDs
+	for h, v := c.hZoom, c.vZoom; len(candidates) > 0; h, v = h+1, v+1 {
+		// 同一緯度のボクセルの対角線のベクトル
+		rect := Rectangular{hZoom: h, vZoom: v, factor: c.factor}
+		latDict := make(map[int64]spatial.Vector3)
+
+		next := []string{}
+		for _, spatialID := range candidates {
+			lat := GetVoxelIDToSpatialID(spatialID)[1]
+			lens, ok := latDict[lat]
+			if !ok {
+				lens, _ = rect.calcUnitVoxelVector(spatialID)
+				latDict[lat] = lens
+			}
+			centers, _ := shape.GetPointOnExtendedSpatialId(spatialID, enum.Center)
+			orthCenters, _ := shape.ConvertPointListToProjectedPointList(centers, consts.OrthCrs)
+			orthCenter := spatial.Point3{X: orthCenters[0].X, Y: orthCenters[0].Y, Z: orthCenters[0].Alt * c.factor}
+
+			// 内部のボクセルは衝突判定せずに採用
+			if c.isIncludeVoxel(orthCenter, lens) {
+				accepted[[2]int64{h, v}] = append(accepted[[2]int64{h, v}], spatialID)
+				continue
+			}
+			// 衝突判定実施オプションがfalseの場合は衝突判定をスキップし、境界のボクセルを全て分割する
+			if c.isPrecision {
+				tests++
+				if !c.object.IsCollideVoxel(orthCenter, lens) {
+					continue
+				}
+			}
+			if h == hZoom {
+				accepted[[2]int64{h, v}] = append(accepted[[2]int64{h, v}], spatialID)
+				continue
+			}
+			index, _ := parseVoxelIndex(spatialID)
+			for _, child := range index.children() {
+				next = append(next, child.String())
+			}
+		}
+		debugLog(
+			c.logger,
+			"段階的な衝突判定",
+			slog.Int64("hZoom", h),
+			slog.Int64("vZoom", v),
+			slog.Int("candidateIDs", len(candidates)),
+			slog.Int("acceptedIDs", len(accepted[[2]int64{h, v}])),
+		)
+		candidates = next
+	}
+	return accepted, tests
+}
+
+// adaptiveStartLevel 段階的な取得を開始する精度の差を算出
+//
+// 目標の精度のボクセルを半径以下の大きさに収まる範囲で粗くする。
+//
+// 引数：
+//
+//	radius： 半径
+//	hZoom： 目標の水平精度
+//	vZoom： 目標の垂直精度
+//	factor： Webメルカトル換算係数
+//
+// 戻り値：
+//
+//	目標の精度から下げる精度の数
+func adaptiveStartLevel(radius float64, hZoom, vZoom int64, factor float64) int64 {
+	unitVoxel := NewRectangular(spatial.Point3{}, spatial.Point3{}, radius, hZoom, vZoom, factor).calcBaseUnitVoxel()
+	level := int64(math.Floor(math.Log2(radius * factor / math.Max(unitVoxel.X, unitVoxel.Z))))
+	return min(max(level, 0), hZoom, vZoom)
+}
+
+// GetAdaptiveExtendedSpatialIdsOnCylinders 拡張空間ID(円柱)の段階的な取得
+//
+// GetExtendedSpatialIdsOnCylindersと同じ円柱、またはカプセルの経路の拡張空間IDを、
+// 粗い精度のボクセルから段階的に取得する。
+// オブジェクトの内部にあるボクセルは粗い精度のまま採用し、衝突しないボクセルは除外し、
+// 境界のボクセルのみ子ボクセルに分割して目標の精度まで衝突判定を繰り返す。
+// 半径が大きい場合に衝突判定の回数と拡張空間IDの数を削減できる。
+// 戻り値の拡張空間IDは精度が混在し、ConvertExtendedSpatialIdsZoomで目標の精度に変換できる。
+// TerrainClipを指定した場合は、粗い精度のボクセルも上端が地表面より低い場合のみ除外する。
+//
+// 引数：
+//
+//	center： 円柱の中心の接続点
+//	radius： 円柱の半径
+//	hZoom： 目標の水平方向の精度レベル
+//	vZoom： 目標の垂直方向の精度レベル
+//	isCapsule： カプセルの場合true、円柱の場合false
+//	isPrecision： GetExtendedSpatialIdsOnCylindersと同じオプション。IsPrecision、LogHandler、Observer、
+//	              TerrainClip、AboveGroundLevel、Altitude、Geoid、Geodesicを使用する。
+//	              IsPrecisionにfalseを指定した場合は、衝突判定を実施せずに境界のボクセルを目標の精度まで分割する。
+//	              TurnRadius、PositionUncertainty、UncertaintyGrowthは未対応のため、指定した場合はエラーとする
+//
+// 戻り値：
+//
+//	拡張空間IDのリスト(昇順、統合できる子ボクセルは親ボクセルに統合する)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 接続点にnilがある場合、もしくは半径が0以下の場合
+//	               緯度がWebメルカトル投影の範囲外(±85.0511287798度を超える)の場合もエラー
+//	               TurnRadius、PositionUncertainty、UncertaintyGrowthを指定した場合もエラー
+//	 高さ変換不可： 高さの変換に必要なモデルが未指定の場合、
+//	               もしくは接続点の位置のジオイド高、標高を取得できない場合。
+//	 測地線分割不可： Geodesicに負の間隔を指定した場合、もしくは対蹠点に近い区間がある場合。
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func GetAdaptiveExtendedSpatialIdsOnCylinders(
+	center []*object.Point,
+	radius float64,
+	hZoom int64,
+	vZoom int64,
+	isCapsule bool,
+	isPrecision ...option,
+) ([]string, error) {
+	p := &IsPrecisionOpts{
+		IsPrecision: true,
+	}
+	for _, opt := range isPrecision {
+		opt(p)
+	}
+
+	var log *slog.Logger
+	var begin time.Time
+	if p.LogHandler != nil {
+		log = slog.New(p.LogHandler)
+		begin = time.Now()
+	}
+
+	spatialIDs := []string{}
+	endRoute := startStage(p.Observer, StageRoute, RouteSegment)
+	defer func() { endRoute(StageResult{Count: len(spatialIDs)}) }()
+
+	// 入力値チェック
+	if common.Include(center, nil) || !shape.CheckZoom(hZoom) || !shape.CheckZoom(vZoom) ||
+		radius <= consts.Minima {
+		return spatialIDs, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	} else if p.TurnRadius != 0 || p.Uncertainties != nil || p.UncertaintyGrowth != (Uncertainty{}) {
+		return spatialIDs, errors.NewSpatialIdError(errors.InputValueErrorCode, "旋回半径、位置の不確かさは未対応です")
+	} else if len(center) == 0 {
+		return spatialIDs, nil
+	}
+
+	// 接続点の高さを楕円体高に変換
+	if p.Altitude != AltitudeEllipsoidal {
+		terrain := p.AboveGround
+		if terrain == nil {
+			terrain = p.Terrain
+		}
+		converted, err := ConvertAltitudePoints(center, p.Altitude, p.Geoid, terrain)
+		if err != nil {
+			debugLog(log, "接続点の高さを変換できません", slog.Int("altitude", int(p.Altitude)))
+			return spatialIDs, err
+		}
+		center = converted
+	}
+
+	// 接続点間を測地線に沿って分割
+	if p.GeodesicInterval != 0 {
+		densified, err := DensifyGeodesic(center, p.GeodesicInterval)
+		if err != nil {
+			debugLog(log, "接続点間を測地線に沿って分割できません", slog.Float64("interval", p.GeodesicInterval))
+			return spatialIDs, err
+		}
+		debugLog(log, "測地線に沿った分割", slog.Int("points", len(densified)))
+		center = densified
+	}
+
+	if err := checkMercatorLatitude(center); err != nil {
+		return spatialIDs, err
+	}
+
+	// メルカトル距離補正
+	factor := 1 / math.Cos(common.DegreeToRadian(center[0].Lat()))
+	level := adaptiveStartLevel(radius, hZoom, vZoom, factor)
+	debugLog(log, "段階的な取得の開始精度", slog.Int64("hZoom", hZoom-level), slog.Int64("vZoom", vZoom-level))
+
+	// 区間のカプセル、円柱と、円柱の場合は接続点の球
+	type segment struct {
+		points []*object.Point
+		index  int
+	}
+	segments := []segment{}
+	for i := range center[:len(center)-1] {
+		if center[i] == center[i+1] {
+			continue
+		}
+		segments = append(segments, segment{[]*object.Point{center[i], center[i+1]}, i})
+		if !isCapsule && i < len(center)-2 {
+			segments = append(segments, segment{[]*object.Point{center[i+1], center[i+1]}, i})
+		}
+	}
+	if len(segments) == 0 {
+		segments = append(segments, segment{[]*object.Point{center[0], center[0]}, 0})
+	}
+
+	voxels := map[voxelIndex]bool{}
+	for _, s := range segments {
+		// 経度180度をまたがる区間は経度を180度ずらした座標系で空間IDを取得する
+		points := s.points
+		shifted := isAntimeridianFrame(points...)
+		if shifted {
+			shiftedPoints, err := shiftLongitudes(points...)
+			if err != nil {
+				return []string{}, err
+			}
+			points = shiftedPoints
+		}
+		crsPoints, _ := shape.ConvertPointListToProjectedPointList(points, consts.OrthCrs)
+		startOrth := spatial.Point3{X: crsPoints[0].X, Y: crsPoints[0].Y, Z: crsPoints[0].Alt * factor}
+		endOrth := spatial.Point3{X: crsPoints[1].X, Y: crsPoints[1].Y, Z: crsPoints[1].Alt * factor}
+
+		endSegment := startStage(p.Observer, StageSegment, s.index)
+		capsule := NewCapsule(startOrth, endOrth, radius, hZoom-level, vZoom-level, isCapsule, p.IsPrecision, factor)
+		capsule.logger = log
+		endStage := startStage(p.Observer, StageCollision, s.index)
+		accepted, tests := capsule.calcAdaptiveSpatialIDs(hZoom, vZoom)
+		count := 0
+		for zoom, ids := range accepted {
+			for _, spatialID := range wrapExtendedSpatialIDs(ids, zoom[0], zoom[1], shifted) {
+				index, _ := parseVoxelIndex(spatialID)
+				voxels[index] = true
+			}
+			count += len(ids)
+		}
+		endStage(StageResult{Count: count, Tests: tests})
+		endSegment(StageResult{Count: count})
+		debugLog(log, "接続点間の空間ID", slog.Int("segment", s.index), slog.Int("acceptedIDs", count), slog.Int("tests", tests))
+	}
+
+	// 他の区間の粗い精度の空間IDに含まれる空間IDを除外し、子ボクセルを統合
+	set := &voxelSet{voxels: voxels, zooms: [][2]int64{}}
+	for index := range voxels {
+		if !containsZooms(set.zooms, index.hZoom, index.vZoom) {
+			set.zooms = append(set.zooms, [2]int64{index.hZoom, index.vZoom})
+		}
+	}
+	outermost := map[voxelIndex]bool{}
+	for _, index := range set.outermost() {
+		outermost[index] = true
+	}
+	spatialIDs = voxelSetToSpatialIDs(outermost)
+
+	// 地中の空間IDを除外
+	if p.Terrain != nil {
+		aboveIDs, undergroundIDs, err := ClassifyExtendedSpatialIdsByTerrain(
+			spatialIDs, ellipsoidalTerrainModel(p.Terrain, p.Geoid),
+		)
+		if err != nil {
+			debugLog(log, "地形による空間IDの分類ができません")
+			return []string{}, err
+		}
+		debugLog(log, "地中の空間IDを除外", slog.Int("undergroundIDs", len(undergroundIDs)))
+		spatialIDs = aboveIDs
+	}
+	spatialIDs, _ = MergeExtendedSpatialIds(spatialIDs)
+
+	if log != nil {
+		debugLog(
+			log,
+			"拡張空間ID(円柱)の段階的な取得",
+			slog.Int("acceptedIDs", len(spatialIDs)),
+			slog.Duration("elapsed", time.Since(begin)),
+		)
+	}
+	return spatialIDs, nil
+}
@@ -0,0 +1,265 @@
+package shape
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// TestCapsuleIsIncludeVoxel01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点(0, 0, 0)、終点(10, 0, 0)、半径2.0のカプセル、円柱、始点と終点が同じ球
+//   - パターン1：中心(5, 0, 0)、大きさ1のボクセル
+//   - パターン2：中心(11, 0, 0)、大きさ1のボクセル(終点の外側)
+//   - パターン3：中心(5, 1.5, 0)、大きさ1のボクセル(一部が半径の外側)
+//
+// + 確認内容
+//   - パターン1：カプセル、円柱で内部と判定され、球で内部と判定されないこと
+//   - パターン2：カプセルのみ内部と判定されること
+//   - パターン3：内部と判定されないこと
+func TestCapsuleIsIncludeVoxel01(t *testing.T) {
+	//入力パラメータ
+	start := spatial.Point3{X: 0, Y: 0, Z: 0}
+	end := spatial.Point3{X: 10, Y: 0, Z: 0}
+	lens := spatial.Vector3{X: 1, Y: 1, Z: 1}
+	capsule := NewCapsule(start, end, 2.0, 20, 20, true, true, 1)
+	cylinder := NewCapsule(start, end, 2.0, 20, 20, false, true, 1)
+	sphere := NewCapsule(start, start, 2.0, 20, 20, false, true, 1)
+	inputs := []struct {
+		center   spatial.Point3
+		capsule  bool
+		cylinder bool
+		sphere   bool
+	}{
+		{spatial.Point3{X: 5, Y: 0, Z: 0}, true, true, false},
+		{spatial.Point3{X: 11, Y: 0, Z: 0}, true, false, false},
+		{spatial.Point3{X: 5, Y: 1.5, Z: 0}, false, false, false},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultCapsule := capsule.isIncludeVoxel(input.center, lens)
+		resultCylinder := cylinder.isIncludeVoxel(input.center, lens)
+		resultSphere := sphere.isIncludeVoxel(input.center, lens)
+
+		if resultCapsule != input.capsule || resultCylinder != input.cylinder || resultSphere != input.sphere {
+			t.Errorf("パターン%d - 期待値：%v, %v, %v, 取得値：%v, %v, %v", i+1,
+				input.capsule, input.cylinder, input.sphere, resultCapsule, resultCylinder, resultSphere)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestGetAdaptiveExtendedSpatialIdsOnCylinders01 正常系動作確認(円柱、カプセル)
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0), (139.76, 35.68, 100.0), (139.76, 35.69, 100.0)
+//   - 半径：200.0、精度：20
+//   - 円柱、カプセル
+//
+// + 確認内容
+//   - 精度20に変換した拡張空間IDが、GetExtendedSpatialIdsOnCylindersの拡張空間IDを全て含むこと
+//   - 精度20に変換した拡張空間IDの数が、GetExtendedSpatialIdsOnCylindersの拡張空間IDの数の1.1倍以下であること
+//   - 拡張空間IDの数がGetExtendedSpatialIdsOnCylindersより少ないこと
+func TestGetAdaptiveExtendedSpatialIdsOnCylinders01(t *testing.T) {
+	//入力パラメータ
+	radius := 200.0
+	var hZoom, vZoom int64 = 20, 20
+
+	for _, isCapsule := range []bool{false, true} {
+		expectVal, _ := GetExtendedSpatialIdsOnCylinders(turnRoute(), radius, hZoom, vZoom, isCapsule)
+
+		// テスト対象呼び出し
+		resultVal, err := GetAdaptiveExtendedSpatialIdsOnCylinders(turnRoute(), radius, hZoom, vZoom, isCapsule)
+		if err != nil {
+			t.Fatalf("カプセル%v error - 期待値：nil, 取得値：%v", isCapsule, err)
+		}
+
+		fine, _ := ConvertExtendedSpatialIdsZoom(resultVal, hZoom, vZoom, DownsampleAnyTouch)
+		if missing := common.Difference(expectVal, fine); len(missing) != 0 {
+			t.Errorf("カプセル%v - 含まれない拡張空間ID：%v個", isCapsule, len(missing))
+		}
+		if float64(len(fine)) > float64(len(expectVal))*1.1 {
+			t.Errorf("カプセル%v - 期待値：%v個以下, 取得値：%v個", isCapsule, float64(len(expectVal))*1.1, len(fine))
+		}
+		if len(resultVal) >= len(expectVal) {
+			t.Errorf("カプセル%v - 期待値：%v個未満, 取得値：%v個", isCapsule, len(expectVal), len(resultVal))
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestGetAdaptiveExtendedSpatialIdsOnCylinders02 正常系動作確認(接続点が1点、半径が小さい場合)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：接続点(139.75, 35.68, 100.0)、半径50.0、精度20
+//   - パターン2：turnRouteの接続点、半径5.0、精度20
+//
+// + 確認内容
+//   - 精度20に変換した拡張空間IDが、GetExtendedSpatialIdsOnCylindersの拡張空間IDを全て含むこと
+func TestGetAdaptiveExtendedSpatialIdsOnCylinders02(t *testing.T) {
+	//入力パラメータ
+	point, _ := object.NewPoint(139.75, 35.68, 100.0)
+	inputs := []struct {
+		center []*object.Point
+		radius float64
+	}{
+		{[]*object.Point{point}, 50.0},
+		{turnRoute(), 5.0},
+	}
+
+	for i, input := range inputs {
+		expectVal, _ := GetExtendedSpatialIdsOnCylinders(input.center, input.radius, 20, 20, false)
+
+		// テスト対象呼び出し
+		resultVal, err := GetAdaptiveExtendedSpatialIdsOnCylinders(input.center, input.radius, 20, 20, false)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		fine, _ := ConvertExtendedSpatialIdsZoom(resultVal, 20, 20, DownsampleAnyTouch)
+		if missing := common.Difference(expectVal, fine); len(missing) != 0 || len(fine) == 0 {
+			t.Errorf("パターン%d - 含まれない拡張空間ID：%v", i+1, missing)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestGetAdaptiveExtendedSpatialIdsOnCylinders03 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：半径が0
+//   - パターン2：水平精度が36
+//   - パターン3：接続点にnilを含む
+//   - パターン4：緯度がWebメルカトル投影の範囲外
+//   - パターン5～7：未対応のTurnRadius、PositionUncertainty、UncertaintyGrowthを指定
+//   - パターン8：AltitudeAboveGroundで標高モデルが未指定
+//
+// + 確認内容
+//   - エラーが返却されること
+func TestGetAdaptiveExtendedSpatialIdsOnCylinders03(t *testing.T) {
+	//入力パラメータ
+	polar, _ := object.NewPoint(139.75, 86.0, 100.0)
+	inputs := []struct {
+		center []*object.Point
+		radius float64
+		hZoom  int64
+		opts   []option
+	}{
+		{turnRoute(), 0, 20, nil},
+		{turnRoute(), 10.0, 36, nil},
+		{append(turnRoute(), nil), 10.0, 20, nil},
+		{[]*object.Point{polar}, 10.0, 20, nil},
+		{turnRoute(), 10.0, 20, []option{TurnRadius(50)}},
+		{turnRoute(), 10.0, 20, []option{PositionUncertainty([]Uncertainty{{5, 5}, {5, 5}, {5, 5}})}},
+		{turnRoute(), 10.0, 20, []option{UncertaintyGrowth(Uncertainty{Horizontal: 0.01})}},
+		{turnRoute(), 10.0, 20, []option{Altitude(AltitudeAboveGround)}},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, err := GetAdaptiveExtendedSpatialIdsOnCylinders(input.center, input.radius, input.hZoom, 20, false, input.opts...)
+		if err == nil {
+			t.Errorf("パターン%d error - 期待値：エラー, 取得値：nil", i+1)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestGetAdaptiveExtendedSpatialIdsOnCylinders04 正常系動作確認(オプション)
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0), (139.76, 35.68, 100.0), (139.76, 35.69, 100.0)
+//   - 半径：200.0、精度：20、円柱
+//   - パターン1：IsPrecision(false)
+//   - パターン2：Geodesic(100)
+//   - パターン3：標高50mの地表面からの地上高(AltitudeAboveGround、AboveGroundLevel)
+//   - パターン4：標高100mの地表面で地中の空間IDを除外(TerrainClip)
+//
+// + 確認内容
+//   - パターン1：精度20に変換した拡張空間IDが、オプション未指定の場合の拡張空間IDを全て含み、数が多いこと
+//   - パターン2：精度20に変換した拡張空間IDが、Geodesicを指定したGetExtendedSpatialIdsOnCylindersの拡張空間IDを全て含むこと
+//   - パターン3：接続点の高さを150mとした場合の拡張空間IDと一致すること
+//   - パターン4：上端が100mより低い拡張空間IDを含まず、オプション未指定の場合の拡張空間IDに含まれること
+func TestGetAdaptiveExtendedSpatialIdsOnCylinders04(t *testing.T) {
+	//入力パラメータ
+	radius := 200.0
+	defaultVal, _ := GetAdaptiveExtendedSpatialIdsOnCylinders(turnRoute(), radius, 20, 20, false)
+	defaultFine, _ := ConvertExtendedSpatialIdsZoom(defaultVal, 20, 20, DownsampleAnyTouch)
+
+	// テスト対象呼び出し
+	resultVal, err := GetAdaptiveExtendedSpatialIdsOnCylinders(turnRoute(), radius, 20, 20, false, IsPrecision(false))
+	if err != nil {
+		t.Fatalf("パターン1 error - 期待値：nil, 取得値：%v", err)
+	}
+	fine, _ := ConvertExtendedSpatialIdsZoom(resultVal, 20, 20, DownsampleAnyTouch)
+	if missing := common.Difference(defaultFine, fine); len(missing) != 0 || len(fine) <= len(defaultFine) {
+		t.Errorf("パターン1 - 含まれない拡張空間ID：%v個, 取得値：%v個", len(missing), len(fine))
+	}
+
+	expectVal, _ := GetExtendedSpatialIdsOnCylinders(turnRoute(), radius, 20, 20, false, Geodesic(100))
+	resultVal, err = GetAdaptiveExtendedSpatialIdsOnCylinders(turnRoute(), radius, 20, 20, false, Geodesic(100))
+	if err != nil {
+		t.Fatalf("パターン2 error - 期待値：nil, 取得値：%v", err)
+	}
+	fine, _ = ConvertExtendedSpatialIdsZoom(resultVal, 20, 20, DownsampleAnyTouch)
+	if missing := common.Difference(expectVal, fine); len(missing) != 0 {
+		t.Errorf("パターン2 - 含まれない拡張空間ID：%v個", len(missing))
+	}
+
+	raised := []*object.Point{}
+	for _, point := range turnRoute() {
+		p, _ := object.NewPoint(point.Lon(), point.Lat(), point.Alt()+50)
+		raised = append(raised, p)
+	}
+	expectVal, _ = GetAdaptiveExtendedSpatialIdsOnCylinders(raised, radius, 20, 20, false)
+	resultVal, err = GetAdaptiveExtendedSpatialIdsOnCylinders(
+		turnRoute(), radius, 20, 20, false,
+		Altitude(AltitudeAboveGround), AboveGroundLevel(flatTerrain{elevation: 50, lonMax: 180}),
+	)
+	if err != nil {
+		t.Fatalf("パターン3 error - 期待値：nil, 取得値：%v", err)
+	}
+	if !reflect.DeepEqual(expectVal, resultVal) {
+		t.Errorf("パターン3 - 期待値：%v個, 取得値：%v個", len(expectVal), len(resultVal))
+	}
+
+	resultVal, err = GetAdaptiveExtendedSpatialIdsOnCylinders(
+		turnRoute(), radius, 20, 20, false, TerrainClip(flatTerrain{elevation: 100, lonMax: 180}),
+	)
+	if err != nil {
+		t.Fatalf("パターン4 error - 期待値：nil, 取得値：%v", err)
+	}
+	fine, _ = ConvertExtendedSpatialIdsZoom(resultVal, 20, 20, DownsampleAnyTouch)
+	if extra := common.Difference(fine, defaultFine); len(extra) != 0 || len(resultVal) == 0 {
+		t.Errorf("パターン4 - 含まれる拡張空間ID：%v個, 取得値：%v個", len(extra), len(resultVal))
+	}
+	for _, spatialID := range resultVal {
+		vertexes, _ := shape.GetPointOnExtendedSpatialId(spatialID, enum.Vertex)
+		ceiling := math.Inf(-1)
+		for _, vertex := range vertexes {
+			ceiling = math.Max(ceiling, vertex.Alt())
+		}
+		if ceiling < 100 {
+			t.Errorf("パターン4 - 地中の拡張空間ID：%v", spatialID)
+		}
+	}
+
+	t.Log("テスト終了")
+}
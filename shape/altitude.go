@@ -0,0 +1,207 @@
+package shape
+
+import (
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// AltitudeReference 接続点の高さの基準
+type AltitudeReference int
+
+const (
+	AltitudeEllipsoidal AltitudeReference = iota // 楕円体高(空間IDの高さの基準。変換しない)
+	AltitudeOrthometric                          // 標高(ジオイド面からの高さ)。ジオイド高を加えて変換する
+	AltitudeAboveGround                          // 地上高。地表面の標高を加えて変換する
+)
+
+// GeoidModel ジオイドモデルインターフェース
+//
+// geoid.Modelが実装する。
+type GeoidModel interface {
+	// GeoidHeight ジオイド高取得
+	//
+	// 引数：
+	//
+	//	lon： 経度
+	//	lat： 緯度
+	//
+	// 戻り値：
+	//
+	//	ジオイド高(楕円体面からジオイド面までの高さ。単位:m)
+	//	ジオイド高を取得できたか。範囲外、欠損値の場合はfalse
+	GeoidHeight(lon, lat float64) (float64, bool)
+}
+
+// Altitude 接続点の高さの基準設定関数
+//
+// 以下の関数の接続点の高さの基準を設定する。
+// 接続点の高さは空間IDの垂直方向の位置を求める前に楕円体高に変換する。
+// 未指定の場合は楕円体高とする。
+//   - GetSpatialIdsOnCylinders
+//   - GetExtendedSpatialIdsOnCylinders
+//
+// AltitudeOrthometricの場合はGeoidの指定が必要となる。
+// AltitudeAboveGroundの場合はAboveGroundLevel、もしくはTerrainClipで指定した標高モデルを使用する。
+//
+// 引数：
+//
+//	ref: 接続点の高さの基準
+//
+// 戻り値：
+//
+//	衝突判定実施オプショナル型の関数
+func Altitude(ref AltitudeReference) option {
+	return func(p *IsPrecisionOpts) {
+		p.Altitude = ref
+	}
+}
+
+// Geoid ジオイドモデル設定関数
+//
+// 以下の関数で標高を楕円体高に変換する際のジオイドモデルを設定する。
+// 指定した場合は、AboveGroundLevel、TerrainClipで指定した標高モデルの値も標高として扱い、
+// ジオイド高を加えて楕円体高に変換する。
+//   - GetSpatialIdsOnCylinders
+//   - GetExtendedSpatialIdsOnCylinders
+//
+// 引数：
+//
+//	g: ジオイドモデル。nilの場合は変換しない
+//
+// 戻り値：
+//
+//	衝突判定実施オプショナル型の関数
+func Geoid(g GeoidModel) option {
+	return func(p *IsPrecisionOpts) {
+		p.Geoid = g
+	}
+}
+
+// ellipsoidalTerrain 楕円体高の標高モデル
+//
+// 標高の標高モデルの値にジオイド高を加えて楕円体高とする。
+type ellipsoidalTerrain struct {
+	terrain ElevationModel // 標高の標高モデル
+	geoid   GeoidModel     // ジオイドモデル
+}
+
+// Elevation 楕円体高の地表面の高さ取得
+func (e ellipsoidalTerrain) Elevation(lon, lat float64) (float64, bool) {
+	h, ok := e.terrain.Elevation(lon, lat)
+	if !ok {
+		return 0, false
+	}
+	n, ok := e.geoid.GeoidHeight(lon, lat)
+	return h + n, ok
+}
+
+// ellipsoidalTerrainModel 楕円体高の標高モデル取得
+//
+// 引数：
+//
+//	m： 標高モデル
+//	g： ジオイドモデル
+//
+// 戻り値：
+//
+//	ジオイドモデルがnilの場合はm、それ以外の場合はジオイド高を加える標高モデル
+func ellipsoidalTerrainModel(m ElevationModel, g GeoidModel) ElevationModel {
+	if m == nil || g == nil {
+		return m
+	}
+	return ellipsoidalTerrain{terrain: m, geoid: g}
+}
+
+// ConvertAltitudePoints 接続点の高さの楕円体高への変換
+//
+// 同じPointを複数回指定した場合は、変換後も同じPointとなる。
+//
+// 引数：
+//
+//	points： 接続点
+//	ref： 接続点の高さの基準
+//	g： ジオイドモデル。AltitudeOrthometricの場合は必須。
+//	    AltitudeAboveGroundの場合に指定すると標高モデルの値を標高として扱う
+//	m： 地表面の標高モデル。AltitudeAboveGroundの場合は必須
+//
+// 戻り値：
+//
+//	高さを楕円体高とした接続点。AltitudeEllipsoidalの場合はpointsをそのまま返却する
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力値不正： 接続点にnilがある場合、必須のモデルがnilの場合、
+//	             高さの基準が不正な場合、もしくは接続点の位置のジオイド高、標高を取得できない場合
+func ConvertAltitudePoints(
+	points []*object.Point,
+	ref AltitudeReference,
+	g GeoidModel,
+	m ElevationModel,
+) ([]*object.Point, error) {
+	switch ref {
+	case AltitudeEllipsoidal:
+		return points, nil
+
+	case AltitudeOrthometric:
+		if g == nil {
+			return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "ジオイドモデルが指定されていません")
+		}
+		return offsetPoints(points, g.GeoidHeight, "接続点の位置のジオイド高を取得できません")
+
+	case AltitudeAboveGround:
+		if m == nil {
+			return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "標高モデルが指定されていません")
+		}
+		return offsetPoints(points, ellipsoidalTerrainModel(m, g).Elevation, "接続点の位置の標高を取得できません")
+	}
+
+	return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "高さの基準が不正です")
+}
+
+// offsetPoints 接続点の高さの加算
+//
+// 同じPointを複数回指定した場合は、変換後も同じPointとなる。
+//
+// 引数：
+//
+//	points： 接続点
+//	offset： 接続点の位置で高さに加える値を返却する関数
+//	msg： 加える値を取得できない場合のエラーメッセージ
+//
+// 戻り値：
+//
+//	高さを加算した接続点
+//
+// 戻り値(エラー)：
+//
+//	接続点にnilがある場合、もしくは加える値を取得できない場合、エラーインスタンスが返却される。
+func offsetPoints(
+	points []*object.Point,
+	offset func(lon, lat float64) (float64, bool),
+	msg string,
+) ([]*object.Point, error) {
+	converted := make([]*object.Point, len(points))
+	cache := map[*object.Point]*object.Point{}
+	for i, point := range points {
+		if point == nil {
+			return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+		}
+		if c, ok := cache[point]; ok {
+			converted[i] = c
+			continue
+		}
+
+		v, ok := offset(point.Lon(), point.Lat())
+		if !ok {
+			return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, msg)
+		}
+		c, err := object.NewPoint(point.Lon(), point.Lat(), point.Alt()+v)
+		if err != nil {
+			return nil, err
+		}
+		cache[point] = c
+		converted[i] = c
+	}
+	return converted, nil
+}
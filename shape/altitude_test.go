@@ -0,0 +1,126 @@
+package shape
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// flatGeoid テスト用の一定のジオイド高
+type flatGeoid float64
+
+func (g flatGeoid) GeoidHeight(lon, lat float64) (float64, bool) {
+	return float64(g), true
+}
+
+// TestAltitude01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.753098, 35.685371, h), (139.753198, 35.685371, h)
+//   - 半径：2.0、水平精度：25、垂直精度：25、ジオイド高：36.0m
+//   - パターン1：AltitudeOrthometric、h=-25.0
+//   - パターン2：AltitudeAboveGround(TerrainClipの標高-40.0mの標高モデルを使用)、h=51.0
+//   - パターン3：AboveGroundLevel(標高4.0m)、ジオイドモデルあり、h=-29.0
+//   - パターン4：AltitudeEllipsoidal、ジオイドモデルあり、h=11.0
+//
+// + 確認内容
+//   - 全パターンで楕円体高11.0mの接続点と同じ空間IDが取得されること
+func TestAltitude01(t *testing.T) {
+	//入力パラメータ
+	terrain := flatTerrain{elevation: 4, lonMax: 180}
+	inputs := []struct {
+		alt  float64
+		opts []option
+	}{
+		{-25.0, []option{Altitude(AltitudeOrthometric), Geoid(flatGeoid(36))}},
+		{51.0, []option{Altitude(AltitudeAboveGround), TerrainClip(flatTerrain{elevation: -40, lonMax: 180})}},
+		{-29.0, []option{AboveGroundLevel(terrain), Geoid(flatGeoid(36))}},
+		{11.0, []option{Altitude(AltitudeEllipsoidal), Geoid(flatGeoid(36))}},
+	}
+
+	// 期待値
+	e1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	e2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+	expectVal, _ := GetExtendedSpatialIdsOnCylinders([]*object.Point{e1, e2}, 2.0, 25, 25, false)
+
+	for i, input := range inputs {
+		p1, _ := object.NewPoint(139.753098, 35.685371, input.alt)
+		p2, _ := object.NewPoint(139.753198, 35.685371, input.alt)
+
+		// テスト対象呼び出し
+		resultVal, err := GetExtendedSpatialIdsOnCylinders([]*object.Point{p1, p2}, 2.0, 25, 25, false, input.opts...)
+
+		if err != nil || !reflect.DeepEqual(resultVal, expectVal) {
+			t.Errorf("パターン%d 空間ID - 期待値：%v, 取得値：%v, %v", i+1, expectVal, resultVal, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestAltitude02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：AltitudeOrthometric、ジオイドモデルなし
+//   - パターン2：AltitudeAboveGround、標高モデルなし
+//   - パターン3：不正な高さの基準
+//
+// + 確認内容
+//   - 入力チェックエラーとなること
+func TestAltitude02(t *testing.T) {
+	//入力パラメータ
+	p, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	inputs := [][]option{
+		{Altitude(AltitudeOrthometric)},
+		{Altitude(AltitudeAboveGround), Geoid(flatGeoid(36))},
+		{Altitude(AltitudeReference(99))},
+	}
+
+	for i, opts := range inputs {
+		// テスト対象呼び出し
+		_, err := GetExtendedSpatialIdsOnCylinders([]*object.Point{p}, 2.0, 25, 25, false, opts...)
+
+		if err == nil {
+			t.Errorf("パターン%d error - 期待値：入力チェックエラー, 取得値：nil", i+1)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestTerrainClip02 正常系動作確認(ジオイドモデルあり)
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.753098, 35.685371, 11.0), (139.753198, 35.685371, 11.0)
+//   - 半径：2.0、水平精度：25、垂直精度：25
+//   - 地表面：標高-25.0m、ジオイド高：36.0m
+//
+// + 確認内容
+//   - 楕円体高11.0mの地表面で除外した場合と同じ空間IDが取得されること
+func TestTerrainClip02(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+	center := []*object.Point{p1, p2}
+
+	// 期待値
+	expectVal, _ := GetExtendedSpatialIdsOnCylinders(
+		center, 2.0, 25, 25, false, TerrainClip(flatTerrain{elevation: 11, lonMax: 180}),
+	)
+
+	// テスト対象呼び出し
+	resultVal, err := GetExtendedSpatialIdsOnCylinders(
+		center, 2.0, 25, 25, false,
+		TerrainClip(flatTerrain{elevation: -25, lonMax: 180}), Geoid(flatGeoid(36)),
+	)
+
+	if err != nil || !reflect.DeepEqual(resultVal, expectVal) {
+		t.Errorf("空間ID - 期待値：%v, 取得値：%v, %v", expectVal, resultVal, err)
+	}
+
+	t.Log("テスト終了")
+}
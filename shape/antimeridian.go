@@ -0,0 +1,123 @@
+package shape
+
+import (
+	"math"
+
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// MercatorMaxLatitude Webメルカトル投影で扱える緯度の上限(単位:度)
+//
+// 空間IDのY成分の範囲は緯度±85.0511287798度までとなる。
+const MercatorMaxLatitude = 85.0511287798066
+
+// isAntimeridianFrame 経度を180度ずらした座標系の要否判定
+//
+// 経度をそのまま扱うよりも、経度を180度ずらした方が経度180度から離れる場合にtrueを返却する。
+// 経度を180度ずらした座標系では経度180度をまたがる区間が経度0度をまたがる区間となり、
+// 区間の始点、終点間の経度の差が180度以下となる。
+//
+// 引数：
+//
+//	points： 判定する接続点
+//
+// 戻り値：
+//
+//	true：経度を180度ずらした座標系で扱う false：経度をそのまま扱う
+func isAntimeridianFrame(points ...*object.Point) bool {
+	maxLon, maxShiftedLon := 0.0, 0.0
+	for _, point := range points {
+		maxLon = math.Max(maxLon, math.Abs(point.Lon()))
+		maxShiftedLon = math.Max(maxShiftedLon, 180-math.Abs(point.Lon()))
+	}
+	return maxShiftedLon < maxLon
+}
+
+// shiftLongitudes 経度を180度ずらした接続点の取得
+//
+// 引数：
+//
+//	points： 接続点
+//
+// 戻り値：
+//
+//	経度を180度ずらした接続点(経度は-180～180度)
+//
+// 戻り値(エラー)：
+//
+//	接続点の座標が不正な場合、エラーインスタンスが返却される。
+func shiftLongitudes(points ...*object.Point) ([]*object.Point, error) {
+	shifted := make([]*object.Point, 0, len(points))
+	for _, point := range points {
+		lon := point.Lon() - 180
+		if point.Lon() < 0 {
+			lon = point.Lon() + 180
+		}
+		p, err := object.NewPoint(lon, point.Lat(), point.Alt())
+		if err != nil {
+			return nil, err
+		}
+		shifted = append(shifted, p)
+	}
+	return shifted, nil
+}
+
+// checkMercatorLatitude 接続点の緯度チェック
+//
+// 引数：
+//
+//	points： 接続点
+//
+// 戻り値(エラー)：
+//
+//	緯度がWebメルカトル投影で扱える範囲外の接続点がある場合、エラーインスタンスが返却される。
+func checkMercatorLatitude(points []*object.Point) error {
+	for _, point := range points {
+		if !(math.Abs(point.Lat()) <= MercatorMaxLatitude) {
+			return errors.NewSpatialIdError(
+				errors.InputValueErrorCode, "緯度がWebメルカトル投影の範囲外です",
+			)
+		}
+	}
+	return nil
+}
+
+// wrapExtendedSpatialIDs 拡張空間IDのX成分、Y成分の範囲補正
+//
+// 経度を180度ずらした座標系で取得した拡張空間IDのX成分を元の座標系に戻し、
+// 経度180度を越えたX成分を反対側に折り返す。
+// 緯度がWebメルカトル投影の範囲外となるY成分の拡張空間IDは除外する。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間ID
+//	hZoom： 水平精度
+//	vZoom： 垂直精度
+//	shifted： 経度を180度ずらした座標系で取得した拡張空間IDであるか
+//
+// 戻り値：
+//
+//	補正した拡張空間ID
+func wrapExtendedSpatialIDs(spatialIDs []string, hZoom, vZoom int64, shifted bool) []string {
+	size := int64(1) << hZoom
+	offset := int64(0)
+	if shifted {
+		offset = size / 2
+	}
+
+	wrapped := make([]string, 0, len(spatialIDs))
+	for _, spatialID := range spatialIDs {
+		ids := GetVoxelIDToSpatialID(spatialID)
+		if ids[1] < 0 || ids[1] >= size {
+			continue
+		}
+		x := ((ids[0]+offset)%size + size) % size
+		if x == ids[0] {
+			wrapped = append(wrapped, spatialID)
+			continue
+		}
+		wrapped = append(wrapped, GetSpatialIDOnAxisIDs(x, ids[1], ids[2], hZoom, vZoom))
+	}
+	return wrapped
+}
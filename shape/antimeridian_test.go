@@ -0,0 +1,201 @@
+package shape
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// shiftSpatialIDsX テスト用拡張空間IDのX成分を経度180度分ずらす
+func shiftSpatialIDsX(spatialIDs []string, hZoom, vZoom int64) []string {
+	size := int64(1) << hZoom
+	shifted := []string{}
+	for _, spatialID := range spatialIDs {
+		ids := GetVoxelIDToSpatialID(spatialID)
+		shifted = append(shifted, GetSpatialIDOnAxisIDs((ids[0]+size/2)%size, ids[1], ids[2], hZoom, vZoom))
+	}
+	sort.Strings(shifted)
+	return shifted
+}
+
+// TestIsAntimeridianFrame01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 経度180度をまたがる区間、経度0度をまたがる区間、経度180度に近い点、経度0度に近い点
+//
+// + 確認内容
+//   - 経度180度をまたがる区間、経度180度に近い点の場合のみtrueとなること
+func TestIsAntimeridianFrame01(t *testing.T) {
+	//入力パラメータ
+	inputs := []struct {
+		lons   []float64
+		expect bool
+	}{
+		{[]float64{179.9, -179.9}, true},
+		{[]float64{100.0, -100.0}, true},
+		{[]float64{80.0, -80.0}, false},
+		{[]float64{-0.1, 0.1}, false},
+		{[]float64{179.99}, true},
+		{[]float64{10.0}, false},
+	}
+
+	for i, input := range inputs {
+		points := []*object.Point{}
+		for _, lon := range input.lons {
+			p, _ := object.NewPoint(lon, -17.0, 100.0)
+			points = append(points, p)
+		}
+
+		// テスト対象呼び出し
+		resultVal := isAntimeridianFrame(points...)
+
+		if resultVal != input.expect {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+1, input.expect, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestAntimeridian01 正常系動作確認(経度180度をまたがる経路)
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(179.9995, -17.0, 100.0), (-179.9995, -17.0, 100.0)(フィジー付近の日付変更線)
+//   - 半径：20.0、水平精度：20、垂直精度：20、衝突判定あり、なし
+//
+// + 確認内容
+//   - 経度0度をまたがる同じ長さの経路(-0.0005度～0.0005度)の空間IDを経度180度分ずらした空間IDと一致すること
+//   - X成分が0～2^20-1の範囲内であり、経度180度付近の空間IDのみであること
+func TestAntimeridian01(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(179.9995, -17.0, 100.0)
+	p2, _ := object.NewPoint(-179.9995, -17.0, 100.0)
+
+	// 期待値
+	g1, _ := object.NewPoint(-0.0005, -17.0, 100.0)
+	g2, _ := object.NewPoint(0.0005, -17.0, 100.0)
+
+	for _, isPrecision := range []bool{true, false} {
+		expectVal, _ := GetExtendedSpatialIdsOnCylinders(
+			[]*object.Point{g1, g2}, 20.0, 20, 20, false, IsPrecision(isPrecision),
+		)
+		expectVal = shiftSpatialIDsX(expectVal, 20, 20)
+
+		// テスト対象呼び出し
+		resultVal, err := GetExtendedSpatialIdsOnCylinders(
+			[]*object.Point{p1, p2}, 20.0, 20, 20, false, IsPrecision(isPrecision),
+		)
+		if err != nil {
+			t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+		}
+		sort.Strings(resultVal)
+
+		if !reflect.DeepEqual(resultVal, expectVal) {
+			t.Errorf("衝突判定%v 空間ID - 期待値：%v, 取得値：%v", isPrecision, expectVal, resultVal)
+		}
+		for _, spatialID := range resultVal {
+			if x := GetVoxelIDToSpatialID(spatialID)[0]; x < 0 || (x >= 10 && x < 1<<20-10) || x >= 1<<20 {
+				t.Errorf("衝突判定%v X成分 - 期待値：経度180度付近, 取得値：%v", isPrecision, spatialID)
+			}
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestAntimeridian02 正常系動作確認(経度180度に近い接続点、測地線分割)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：接続点(179.99999, -17.0, 100.0)のみ、半径：5.0、水平精度：20、垂直精度：20
+//   - パターン2：接続点(179.99, -17.0, 100.0), (-179.99, -17.0, 100.0)、
+//     半径：20.0、水平精度：20、垂直精度：20、測地線に沿って500m間隔で分割
+//
+// + 確認内容
+//   - パターン1：接続点(-0.00001, -17.0, 100.0)の空間IDを経度180度分ずらした空間IDと一致すること
+//   - パターン2：X成分が0～2^20-1の範囲内であり、経度180度付近の空間IDのみであること
+func TestAntimeridian02(t *testing.T) {
+	//入力パラメータ
+	p, _ := object.NewPoint(179.99999, -17.0, 100.0)
+	g, _ := object.NewPoint(-0.00001, -17.0, 100.0)
+
+	// 期待値
+	expectVal, _ := GetExtendedSpatialIdsOnCylinders([]*object.Point{g}, 5.0, 20, 20, false)
+	expectVal = shiftSpatialIDsX(expectVal, 20, 20)
+
+	// テスト対象呼び出し
+	resultVal, err := GetExtendedSpatialIdsOnCylinders([]*object.Point{p}, 5.0, 20, 20, false)
+	sort.Strings(resultVal)
+
+	if err != nil || !reflect.DeepEqual(resultVal, expectVal) {
+		t.Errorf("パターン1 空間ID - 期待値：%v, 取得値：%v, %v", expectVal, resultVal, err)
+	}
+
+	//入力パラメータ
+	p1, _ := object.NewPoint(179.99, -17.0, 100.0)
+	p2, _ := object.NewPoint(-179.99, -17.0, 100.0)
+
+	// テスト対象呼び出し
+	resultVal, err = GetExtendedSpatialIdsOnCylinders(
+		[]*object.Point{p1, p2}, 20.0, 20, 20, false, IsPrecision(false), Geodesic(500),
+	)
+	if err != nil || len(resultVal) == 0 {
+		t.Fatalf("パターン2 error - 期待値：nil, 取得値：%v, %v", resultVal, err)
+	}
+	for _, spatialID := range resultVal {
+		if x := GetVoxelIDToSpatialID(spatialID)[0]; x < 0 || (x >= 100 && x < 1<<20-100) || x >= 1<<20 {
+			t.Errorf("パターン2 X成分 - 期待値：経度180度付近, 取得値：%v", spatialID)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestMercatorLatitude01 正常系・異常系動作確認(高緯度の経路)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：接続点(10.0, 85.05, 100.0)、半径：2000.0、水平精度：10、垂直精度：10、衝突判定なし
+//   - パターン2：接続点(10.0, 85.055, 100.0)(Webメルカトル投影の範囲外)
+//
+// + 確認内容
+//   - パターン1：Y成分が0以上の空間IDのみであり、Y成分が0の空間IDを含むこと
+//   - パターン2：入力チェックエラーとなること
+func TestMercatorLatitude01(t *testing.T) {
+	//入力パラメータ
+	p, _ := object.NewPoint(10.0, 85.05, 100.0)
+
+	// テスト対象呼び出し
+	resultVal, err := GetExtendedSpatialIdsOnCylinders([]*object.Point{p}, 2000.0, 10, 10, false, IsPrecision(false))
+	if err != nil {
+		t.Fatalf("パターン1 error - 期待値：nil, 取得値：%v", err)
+	}
+
+	includeEdge := false
+	for _, spatialID := range resultVal {
+		y := GetVoxelIDToSpatialID(spatialID)[1]
+		if y < 0 {
+			t.Errorf("パターン1 Y成分 - 期待値：0以上, 取得値：%v", spatialID)
+		}
+		includeEdge = includeEdge || y == 0
+	}
+	if !includeEdge {
+		t.Errorf("パターン1 Y成分 - 期待値：0を含む, 取得値：%v", resultVal)
+	}
+
+	//入力パラメータ(Pointの生成時に範囲外となる場合はその時点で入力チェックエラー)
+	outside, err := object.NewPoint(10.0, 85.055, 100.0)
+	if err == nil {
+		// テスト対象呼び出し
+		_, err = GetExtendedSpatialIdsOnCylinders([]*object.Point{outside}, 20.0, 10, 10, false)
+	}
+	if err == nil {
+		t.Error("パターン2 error - 期待値：入力チェックエラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,321 @@
+package shape
+
+import (
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape/physics"
+)
+
+// Obstruction 経路と重なる障害物の構造体
+type Obstruction struct {
+	Segment   int    // 障害物と重なる区間の番号(接続点の球は接続点を終点とする区間の番号。Geodesicで分割した場合も分割前の区間の番号)
+	SpatialID string // 障害物の拡張空間ID
+}
+
+// blockedVoxel 【直交座標空間】障害物のボクセル
+type blockedVoxel struct {
+	spatialID string          // 障害物の拡張空間ID
+	center    spatial.Point3  // ボクセルの中心
+	lens      spatial.Vector3 // ボクセルの対角線ベクトル
+}
+
+// CheckRouteClearance 経路と障害物の重なりの確認
+//
+// 円柱を複数つなげた経路が障害物の拡張空間IDのボクセルと重なるかを、
+// 経路の空間IDを取得せずに区間の順に衝突判定して確認する。
+// 重なる場合は最初に重なる区間と、区間の中で始点に最も近い障害物の拡張空間IDを返却する。
+// 障害物の拡張空間IDの精度レベルは混在してもよい。
+//
+// 引数：
+//
+//	center     : 円柱の中心の接続点。Pointを複数指定するリスト
+//	radius     : 円柱の半径(単位:m)
+//	isCapsule  : 始点、終点が球状であるかを示す。True: カプセル / False: 円柱
+//	blocked    : 障害物の拡張空間ID
+//	isPrecision: GetExtendedSpatialIdsOnCylindersと同じオプション。LogHandler、
+//	             AboveGroundLevel、Altitude、Geoid、Geodesicを使用する。
+//	             TurnRadius、PositionUncertainty、UncertaintyGrowthは未対応のため、指定した場合はエラーとする
+//
+// 戻り値：
+//
+//	最初に重なる障害物(重ならない場合はnil)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 座標の値が不正の場合、円柱の半径が0以下の場合、もしくは障害物の拡張空間IDが不正な場合
+//	               緯度がWebメルカトル投影の範囲外(±85.0511287798度を超える)の接続点がある場合もエラー
+//	               TurnRadius、PositionUncertainty、UncertaintyGrowthを指定した場合もエラー
+//	 高さ変換不可： 高さの変換に必要なモデルが未指定の場合、
+//	               もしくは接続点の位置のジオイド高、標高を取得できない場合。
+//	 測地線分割不可： Geodesicに負の間隔を指定した場合、もしくは対蹠点に近い区間がある場合。
+func CheckRouteClearance(
+	center []*object.Point,
+	radius float64,
+	isCapsule bool,
+	blocked []string,
+	isPrecision ...option,
+) (*Obstruction, error) {
+	p := &IsPrecisionOpts{IsPrecision: true}
+	for _, opt := range isPrecision {
+		opt(p)
+	}
+
+	var log *slog.Logger
+	var begin time.Time
+	if p.LogHandler != nil {
+		log = slog.New(p.LogHandler)
+		begin = time.Now()
+	}
+
+	// 入力値チェック
+	if common.Include(center, nil) || radius <= consts.Minima {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	} else if p.TurnRadius != 0 || p.Uncertainties != nil || p.UncertaintyGrowth != (Uncertainty{}) {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "旋回半径、位置の不確かさは未対応です")
+	} else if len(center) == 0 || len(blocked) == 0 {
+		return nil, nil
+	}
+	blockedIDs := make([]*ExtendedSpatialID, 0, len(blocked))
+	for _, spatialID := range blocked {
+		id, err := ParseExtendedSpatialID(spatialID)
+		if err != nil {
+			return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "障害物の空間IDが不正です")
+		}
+		blockedIDs = append(blockedIDs, id)
+	}
+
+	// 接続点の高さを楕円体高に変換
+	if p.Altitude != AltitudeEllipsoidal {
+		terrain := p.AboveGround
+		if terrain == nil {
+			terrain = p.Terrain
+		}
+		converted, err := ConvertAltitudePoints(center, p.Altitude, p.Geoid, terrain)
+		if err != nil {
+			debugLog(log, "接続点の高さを変換できません", slog.Int("altitude", int(p.Altitude)))
+			return nil, err
+		}
+		center = converted
+	}
+
+	// 接続点間を測地線に沿って分割し、分割後の区間ごとに分割前の区間の番号を保持する
+	legs := make([]int, len(center))
+	for i := range legs {
+		legs[i] = i
+	}
+	if p.GeodesicInterval != 0 {
+		densified, densifiedLegs, err := densifyGeodesicLegs(center, p.GeodesicInterval)
+		if err != nil {
+			return nil, err
+		}
+		center, legs = densified, densifiedLegs
+	}
+
+	if err := checkMercatorLatitude(center); err != nil {
+		return nil, err
+	}
+
+	// メルカトル距離補正
+	factor := 1 / math.Cos(common.DegreeToRadian(center[0].Lat()))
+	rect := Rectangular{factor: factor}
+
+	// 【直交座標空間】障害物のボクセル(経度を180度ずらした座標系のボクセルは必要な場合に算出する)
+	voxels := map[bool][]blockedVoxel{}
+	frameVoxels := func(shifted bool) ([]blockedVoxel, error) {
+		if v, ok := voxels[shifted]; ok {
+			return v, nil
+		}
+		v := make([]blockedVoxel, 0, len(blocked))
+		for i, spatialID := range blocked {
+			frameID := blockedIDs[i].String()
+			if shifted {
+				frameID = shiftSpatialIDLongitude(blockedIDs[i])
+			}
+			lens, err := rect.calcUnitVoxelVector(frameID)
+			if err != nil {
+				return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "障害物の空間IDが不正です")
+			}
+			centers, _ := shape.GetPointOnExtendedSpatialId(frameID, enum.Center)
+			orthCenters, _ := shape.ConvertPointListToProjectedPointList(centers, consts.OrthCrs)
+			v = append(v, blockedVoxel{
+				spatialID: spatialID,
+				center:    spatial.Point3{X: orthCenters[0].X, Y: orthCenters[0].Y, Z: orthCenters[0].Alt * factor},
+				lens:      lens,
+			})
+		}
+		voxels[shifted] = v
+		return v, nil
+	}
+
+	// 【直交座標空間】区間の始点、終点
+	orthSegment := func(points ...*object.Point) ([]spatial.Point3, bool, error) {
+		shifted := isAntimeridianFrame(points...)
+		if shifted {
+			shiftedPoints, err := shiftLongitudes(points...)
+			if err != nil {
+				return nil, false, err
+			}
+			points = shiftedPoints
+		}
+		crsPoints, _ := shape.ConvertPointListToProjectedPointList(points, consts.OrthCrs)
+		orth := make([]spatial.Point3, 0, len(crsPoints))
+		for _, crsPoint := range crsPoints {
+			orth = append(orth, spatial.Point3{X: crsPoint.X, Y: crsPoint.Y, Z: crsPoint.Alt * factor})
+		}
+		return orth, shifted, nil
+	}
+
+	r := radius * factor
+	tests := 0
+	result := func(obstruction *Obstruction) (*Obstruction, error) {
+		if log != nil {
+			attrs := []slog.Attr{slog.Int("collisionTests", tests), slog.Duration("elapsed", time.Since(begin))}
+			if obstruction != nil {
+				attrs = append(attrs, slog.Int("segment", obstruction.Segment), slog.String("spatialID", obstruction.SpatialID))
+			}
+			debugLog(log, "経路と障害物の重なりの確認", attrs...)
+		}
+		return obstruction, nil
+	}
+
+	// 接続点数
+	connectPointNum := 1
+	for i := range center[:len(center)-1] {
+		if center[i] == center[i+1] {
+			continue
+		}
+		connectPointNum++
+		orth, shifted, err := orthSegment(center[i], center[i+1])
+		if err != nil {
+			return nil, err
+		}
+		v, err := frameVoxels(shifted)
+		if err != nil {
+			return nil, err
+		}
+
+		// 区間の円柱(カプセル)の衝突判定
+		var object physics.Physics
+		if orth[0].IsClose(orth[1], consts.Minima) {
+			object = physics.NewSpherePhysics(r, orth[0])
+		} else if isCapsule {
+			object = physics.NewCapsulePhysics(r, orth[0], orth[1])
+		} else {
+			object = physics.NewCylinderPhysics(r, orth[0], orth[1])
+		}
+		if voxel := firstCollideVoxel(object, orth[0], orth[1], r, v, &tests); voxel != nil {
+			return result(&Obstruction{Segment: legs[i], SpatialID: voxel.spatialID})
+		}
+
+		// 接続点の球の衝突判定(終点もしくはカプセルの場合は球は無い)
+		if i == len(center)-2 || isCapsule {
+			continue
+		}
+		sphere := physics.NewSpherePhysics(r, orth[1])
+		if voxel := firstCollideVoxel(sphere, orth[1], orth[1], r, v, &tests); voxel != nil {
+			return result(&Obstruction{Segment: legs[i], SpatialID: voxel.spatialID})
+		}
+	}
+
+	// 接続点数が1の場合は球とする
+	if connectPointNum == 1 {
+		orth, shifted, err := orthSegment(center[0])
+		if err != nil {
+			return nil, err
+		}
+		v, err := frameVoxels(shifted)
+		if err != nil {
+			return nil, err
+		}
+		if voxel := firstCollideVoxel(physics.NewSpherePhysics(r, orth[0]), orth[0], orth[0], r, v, &tests); voxel != nil {
+			return result(&Obstruction{Segment: 0, SpatialID: voxel.spatialID})
+		}
+	}
+
+	return result(nil)
+}
+
+// firstCollideVoxel 始点に最も近い衝突するボクセルの取得
+//
+// 始点、終点を半径だけ広げた範囲と重なるボクセルを物理オブジェクトと衝突判定し、
+// 衝突するボクセルのうち、中心を区間に投影した位置が始点に最も近いものを返却する。
+//
+// 引数：
+//
+//	object： 物理オブジェクト
+//	start： 【直交座標空間】区間の始点
+//	end： 【直交座標空間】区間の終点
+//	r： 【直交座標空間】半径
+//	voxels： 障害物のボクセル
+//	tests： 衝突判定の回数(加算する)
+//
+// 戻り値：
+//
+//	始点に最も近い衝突するボクセル(衝突しない場合はnil)
+func firstCollideVoxel(
+	object physics.Physics,
+	start, end spatial.Point3,
+	r float64,
+	voxels []blockedVoxel,
+	tests *int,
+) *blockedVoxel {
+	axis := spatial.NewVectorFromPoints(start, end)
+	length2 := axis.X*axis.X + axis.Y*axis.Y + axis.Z*axis.Z
+
+	var first *blockedVoxel
+	firstT := math.Inf(1)
+	for i := range voxels {
+		voxel := &voxels[i]
+
+		// 外接する直方体と重ならないボクセルは衝突判定しない
+		if voxel.center.X+voxel.lens.X/2 < math.Min(start.X, end.X)-r ||
+			voxel.center.X-voxel.lens.X/2 > math.Max(start.X, end.X)+r ||
+			voxel.center.Y+voxel.lens.Y/2 < math.Min(start.Y, end.Y)-r ||
+			voxel.center.Y-voxel.lens.Y/2 > math.Max(start.Y, end.Y)+r ||
+			voxel.center.Z+voxel.lens.Z/2 < math.Min(start.Z, end.Z)-r ||
+			voxel.center.Z-voxel.lens.Z/2 > math.Max(start.Z, end.Z)+r {
+			continue
+		}
+
+		t := 0.0
+		if length2 > 0 {
+			toVoxel := spatial.NewVectorFromPoints(start, voxel.center)
+			t = (toVoxel.X*axis.X + toVoxel.Y*axis.Y + toVoxel.Z*axis.Z) / length2
+		}
+		if t >= firstT {
+			continue
+		}
+
+		*tests++
+		if object.IsCollideVoxel(voxel.center, voxel.lens) {
+			first, firstT = voxel, t
+		}
+	}
+	return first
+}
+
+// shiftSpatialIDLongitude 経度を180度ずらした座標系の拡張空間ID
+//
+// X成分を2^(水平精度-1)ずらした拡張空間IDを返却する。ずらす前後の変換は同じとなる。
+//
+// 引数：
+//
+//	id： 拡張空間ID
+//
+// 戻り値：
+//
+//	経度を180度ずらした座標系の拡張空間ID
+func shiftSpatialIDLongitude(id *ExtendedSpatialID) string {
+	size := int64(1) << id.HZoom()
+	return GetSpatialIDOnAxisIDs((id.X()+size/2)%size, id.Y(), id.F(), id.HZoom(), id.VZoom())
+}
@@ -0,0 +1,155 @@
+package shape
+
+import (
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// TestCheckRouteClearance01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0), (139.76, 35.68, 100.0), (139.76, 35.69, 100.0)
+//   - 半径：10.0、円柱、障害物の精度：20
+//   - パターン1：経路から200m離れた障害物
+//   - パターン2：区間1の中点、区間0の始点から600m、300mの地点の障害物
+//   - パターン3：区間1の中点を含む精度18の障害物と、経路から200m離れた精度20の障害物
+//   - パターン4、5：パターン2、3で、接続点間を測地線に沿って100mごとに分割
+//
+// + 確認内容
+//   - パターン1：重なる障害物が無いこと
+//   - パターン2：区間0、始点から300mの地点の障害物が返却されること
+//   - パターン3：区間1、精度18の障害物が返却されること
+//   - パターン4、5：分割前の区間の番号(0、1)が返却されること
+func TestCheckRouteClearance01(t *testing.T) {
+	//入力パラメータ
+	center := turnRoute()
+	farID := idAtDistance(139.755, 35.68, 100.0, 180, 200, 20)
+	nearID := idAtDistance(139.75, 35.68, 100.0, 90, 300, 20)
+	middleID := idAtDistanceZoom(139.76, 35.685, 100.0, 0, 0, 18, 18)
+	inputs := []struct {
+		blocked []string
+		expect  *Obstruction
+	}{
+		{[]string{farID}, nil},
+		{
+			[]string{
+				idAtDistance(139.76, 35.685, 100.0, 0, 0, 20),
+				idAtDistance(139.75, 35.68, 100.0, 90, 600, 20),
+				nearID,
+			},
+			&Obstruction{Segment: 0, SpatialID: nearID},
+		},
+		{[]string{farID, middleID}, &Obstruction{Segment: 1, SpatialID: middleID}},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := CheckRouteClearance(center, 10.0, false, input.blocked)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		if (resultVal == nil) != (input.expect == nil) || resultVal != nil && *resultVal != *input.expect {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+1, input.expect, resultVal)
+		}
+	}
+
+	for i, input := range inputs[1:] {
+		resultVal, err := CheckRouteClearance(center, 10.0, false, input.blocked, Geodesic(100))
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+4, err)
+		}
+
+		if resultVal == nil || *resultVal != *input.expect {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+4, input.expect, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestCheckRouteClearance02 正常系動作確認(経路の空間IDとの整合)
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0), (139.76, 35.68, 100.0), (139.76, 35.69, 100.0)
+//   - 半径：10.0、精度：20、円柱、カプセル
+//   - 障害物：区間0の中点、角の接続点から北、東、南に0m～60mの地点の空間ID(1個ずつ確認)
+//
+// + 確認内容
+//   - 重なる障害物の有無が、GetExtendedSpatialIdsOnCylindersの空間IDに障害物が含まれるかと一致すること
+func TestCheckRouteClearance02(t *testing.T) {
+	//入力パラメータ
+	center := turnRoute()
+
+	for _, isCapsule := range []bool{false, true} {
+		routeIDs, _ := GetExtendedSpatialIdsOnCylinders(center, 10.0, 20, 20, isCapsule)
+		for _, base := range [][2]float64{{139.755, 35.68}, {139.76, 35.68}} {
+			for _, azimuth := range []float64{0, 90, 180} {
+				for distance := 0.0; distance <= 60; distance += 15 {
+					id := idAtDistance(base[0], base[1], 100.0, azimuth, distance, 20)
+
+					// テスト対象呼び出し
+					resultVal, err := CheckRouteClearance(center, 10.0, isCapsule, []string{id})
+					if err != nil {
+						t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+					}
+
+					if expect := common.Include(routeIDs, id); (resultVal != nil) != expect {
+						t.Errorf("カプセル%v 空間ID%v - 期待値：%v, 取得値：%v", isCapsule, id, expect, resultVal)
+					}
+				}
+			}
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestCheckRouteClearance03 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：半径が0
+//   - パターン2：接続点にnilを含む
+//   - パターン3：障害物の空間IDのフォーマットが不正
+//   - パターン4～6：未対応のTurnRadius、PositionUncertainty、UncertaintyGrowthを指定
+//   - パターン7～9：障害物の空間IDの水平精度が-1、64、X成分が数値でない
+//
+// + 確認内容
+//   - 入力チェックエラーとなること
+func TestCheckRouteClearance03(t *testing.T) {
+	//入力パラメータ
+	center := turnRoute()
+	blocked := []string{idAtDistance(139.755, 35.68, 100.0, 0, 0, 20)}
+	inputs := []struct {
+		center  []*object.Point
+		radius  float64
+		blocked []string
+		opts    []option
+	}{
+		{center, 0, blocked, nil},
+		{[]*object.Point{center[0], nil}, 10.0, blocked, nil},
+		{center, 10.0, []string{"20/1/1"}, nil},
+		{center, 10.0, blocked, []option{TurnRadius(50)}},
+		{center, 10.0, blocked, []option{PositionUncertainty([]Uncertainty{{5, 5}, {5, 5}, {5, 5}})}},
+		{center, 10.0, blocked, []option{UncertaintyGrowth(Uncertainty{Horizontal: 0.01})}},
+		{center, 10.0, []string{"-1/0/0/20/0"}, nil},
+		{center, 10.0, []string{"64/0/0/20/0"}, nil},
+		{center, 10.0, []string{"20/abc/0/20/0"}, nil},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := CheckRouteClearance(input.center, input.radius, false, input.blocked, input.opts...)
+
+		if err == nil || resultVal != nil {
+			t.Errorf("パターン%d - 期待値：入力チェックエラー, 取得値：%v, %v", i+1, resultVal, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
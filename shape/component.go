@@ -0,0 +1,373 @@
+package shape
+
+import (
+	"math"
+	"sort"
+
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// BoundingBox 外接する範囲構造体
+//
+// 経度180度をまたがる場合はWestがEastより大きくなる。
+type BoundingBox struct {
+	West   float64 // 西端の経度(単位:度)
+	South  float64 // 南端の緯度(単位:度)
+	East   float64 // 東端の経度(単位:度)
+	North  float64 // 北端の緯度(単位:度)
+	Bottom float64 // 下端の高さ(単位:m)
+	Top    float64 // 上端の高さ(単位:m)
+}
+
+// Component 連結成分構造体
+type Component struct {
+	SpatialIDs  []string    // 連結成分の拡張空間ID(昇順)
+	Count       int         // 拡張空間IDの数
+	BoundingBox BoundingBox // 連結成分に外接する範囲
+}
+
+// voxelSet 拡張空間IDの集合構造体
+type voxelSet struct {
+	voxels map[voxelIndex]bool // 成分インデックスの集合
+	zooms  [][2]int64          // 集合に含まれる(水平方向精度, 垂直方向精度)の組
+}
+
+// newVoxelSet 拡張空間IDの集合構造体コンストラクタ
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//
+// 戻り値：
+//
+//	拡張空間IDの集合構造体
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDのフォーマットが不正な場合、精度が 0 ～ 35 の整数値以外の場合、
+//	               もしくは成分インデックスが精度に対して範囲外の場合
+func newVoxelSet(spatialIDs []string) (*voxelSet, error) {
+	voxels, err := parseVoxelSet(spatialIDs)
+	if err != nil {
+		return nil, err
+	}
+	s := &voxelSet{voxels: voxels, zooms: [][2]int64{}}
+	for index := range voxels {
+		if !containsZooms(s.zooms, index.hZoom, index.vZoom) {
+			s.zooms = append(s.zooms, [2]int64{index.hZoom, index.vZoom})
+		}
+	}
+	return s, nil
+}
+
+// aroundOffsets 自身と周囲の27個のボクセルの成分インデックスの差分
+var aroundOffsets = func() [][3]int64 {
+	offsets := make([][3]int64, 0, 27)
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for df := int64(-1); df <= 1; df++ {
+				offsets = append(offsets, [3]int64{dx, dy, df})
+			}
+		}
+	}
+	return offsets
+}()
+
+// ancestor 粗い精度の祖先ボクセルの成分インデックスを取得
+//
+// 引数：
+//
+//	hZoom： 祖先の水平方向精度(自身の水平方向精度以下)
+//	vZoom： 祖先の垂直方向精度(自身の垂直方向精度以下)
+//
+// 戻り値：
+//
+//	祖先ボクセルの成分インデックス
+func (v voxelIndex) ancestor(hZoom, vZoom int64) voxelIndex {
+	h, f := v.hZoom-hZoom, v.vZoom-vZoom
+	return voxelIndex{hZoom: hZoom, x: v.x >> h, y: v.y >> h, vZoom: vZoom, f: v.f >> f}
+}
+
+// voxelRange 最も細かい精度に換算したボクセルの成分インデックスの範囲
+type voxelRange struct {
+	min [3]int64 // (X, Y, 高さ)成分インデックスの最小値
+	max [3]int64 // (X, Y, 高さ)成分インデックスの最大値
+}
+
+// newVoxelRange 最も細かい精度に換算したボクセルの成分インデックスの範囲を取得
+//
+// 引数：
+//
+//	index： ボクセルの成分インデックス
+//	hZoom： 最も細かい水平方向精度
+//	vZoom： 最も細かい垂直方向精度
+//
+// 戻り値：
+//
+//	成分インデックスの範囲
+func newVoxelRange(index voxelIndex, hZoom, vZoom int64) voxelRange {
+	h, v := hZoom-index.hZoom, vZoom-index.vZoom
+	return voxelRange{
+		min: [3]int64{index.x << h, index.y << h, index.f << v},
+		max: [3]int64{(index.x+1)<<h - 1, (index.y+1)<<h - 1, (index.f+1)<<v - 1},
+	}
+}
+
+// isAdjacent 成分インデックスの範囲の隣接判定
+//
+// 全ての軸で範囲が重なる、または接し、接する軸の数が隣接の種類の上限以下の場合に隣接と判定する。
+// X成分は経度180度をまたがる場合を考慮する。
+//
+// 引数：
+//
+//	other： 判定する成分インデックスの範囲
+//	limit： 接する軸の数の上限(6近傍：1、18近傍：2、26近傍：3)
+//	size： 最も細かい水平方向精度のX成分インデックスの数
+//
+// 戻り値：
+//
+//	隣接する場合true
+func (r voxelRange) isAdjacent(other voxelRange, limit int, size int64) bool {
+	touches := r.touches(other, size)
+	return touches >= 0 && touches <= limit
+}
+
+// touches 成分インデックスの範囲が接する軸の数を取得
+//
+// X成分は経度180度をまたがる場合を考慮する。
+//
+// 引数：
+//
+//	other： 判定する成分インデックスの範囲
+//	size： 最も細かい水平方向精度のX成分インデックスの数
+//
+// 戻り値：
+//
+//	接する軸の数(全ての軸で重なる場合0)、いずれかの軸で離れる場合-1
+func (r voxelRange) touches(other voxelRange, size int64) int {
+	touches := 0
+	for i := range r.min {
+		// 軸の関係(0：重なる、1：接する、2：離れる)
+		relation := axisRelation(r.min[i], r.max[i], other.min[i], other.max[i])
+		if i == 0 {
+			for _, shift := range []int64{-size, size} {
+				relation = min(relation, axisRelation(r.min[i], r.max[i], other.min[i]+shift, other.max[i]+shift))
+			}
+		}
+		if relation == 2 {
+			return -1
+		}
+		touches += relation
+	}
+	return touches
+}
+
+// axisRelation 軸の範囲の関係を取得
+//
+// 戻り値：
+//
+//	範囲が重なる場合0、接する場合1、離れる場合2
+func axisRelation(aMin, aMax, bMin, bMax int64) int {
+	switch {
+	case aMin <= bMax && bMin <= aMax:
+		return 0
+	case aMax+1 == bMin || bMax+1 == aMin:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// SplitExtendedSpatialIdsByConnectivity 拡張空間IDの連結成分への分割
+//
+// 拡張空間IDの集合を、隣接する拡張空間IDをたどって到達できる連結成分に分割する。
+// 経路と飛行禁止空域の重なり等から、離れた領域がいくつあるかを確認する際に使用する。
+// 精度の異なる拡張空間IDは、最も細かい精度に換算したボクセルの範囲が重なる、
+// または隣接の種類に応じて面、辺、頂点で接する場合に隣接とする。
+// 経度180度をまたがる拡張空間IDも隣接とする。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//	connectivity： ボクセルの隣接の種類(6、18、26)
+//
+// 戻り値：
+//
+//	連結成分のリスト(連結成分の先頭の拡張空間IDの昇順)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDのフォーマットが不正な場合、精度が 0 ～ 35 の整数値以外の場合、
+//	               成分インデックスが精度に対して範囲外の場合、もしくは隣接の種類が不正な場合
+func SplitExtendedSpatialIdsByConnectivity(spatialIDs []string, connectivity Connectivity) ([]Component, error) {
+	limit, err := connectivityLimit(connectivity)
+	if err != nil {
+		return []Component{}, err
+	}
+	set, err := newVoxelSet(spatialIDs)
+	if err != nil {
+		return []Component{}, err
+	}
+
+	// 最も細かい精度
+	voxels := make([]voxelIndex, 0, len(set.voxels))
+	hZoom, vZoom := int64(0), int64(0)
+	for index := range set.voxels {
+		voxels = append(voxels, index)
+		hZoom, vZoom = max(hZoom, index.hZoom), max(vZoom, index.vZoom)
+	}
+	sort.Slice(voxels, func(i, j int) bool { return voxels[i].String() < voxels[j].String() })
+	numbers := make(map[voxelIndex]int, len(voxels))
+	ranges := make([]voxelRange, len(voxels))
+	for i, index := range voxels {
+		numbers[index] = i
+		ranges[i] = newVoxelRange(index, hZoom, vZoom)
+	}
+
+	// Union-Find
+	parents := make([]int, len(voxels))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+
+	// 精度の組ごとに、2つの精度の共通の粗い精度の祖先で拡張空間IDを分類する
+	type bucketKey struct {
+		zoom     [2]int64   // 拡張空間IDの(水平方向精度, 垂直方向精度)
+		ancestor voxelIndex // 共通の粗い精度の祖先
+	}
+	byZoom := map[[2]int64][]int{}
+	for i, index := range voxels {
+		zoom := [2]int64{index.hZoom, index.vZoom}
+		byZoom[zoom] = append(byZoom[zoom], i)
+	}
+	buckets := map[bucketKey][]int{}
+	classified := map[[2][2]int64]bool{}
+	classify := func(zoom, common [2]int64) {
+		if classified[[2][2]int64{zoom, common}] {
+			return
+		}
+		classified[[2][2]int64{zoom, common}] = true
+		for _, j := range byZoom[zoom] {
+			key := bucketKey{zoom: zoom, ancestor: voxels[j].ancestor(common[0], common[1])}
+			buckets[key] = append(buckets[key], j)
+		}
+	}
+
+	// 隣接する拡張空間IDは、共通の粗い精度の祖先が同じか隣接する
+	size := int64(1) << hZoom
+	wrapped := make([]bool, len(voxels))
+	for i, index := range voxels {
+		for _, zoom := range set.zooms {
+			// 両軸とも細かい精度の拡張空間IDからの探索で見つかるため省略する
+			if zoom[0] >= index.hZoom && zoom[1] >= index.vZoom && zoom != [2]int64{index.hZoom, index.vZoom} {
+				continue
+			}
+
+			common := [2]int64{min(index.hZoom, zoom[0]), min(index.vZoom, zoom[1])}
+			classify(zoom, common)
+			ancestor := index.ancestor(common[0], common[1])
+			commonSize := int64(1) << common[0]
+			for _, offset := range aroundOffsets {
+				y := ancestor.y + offset[1]
+				if y < 0 || y >= commonSize {
+					continue
+				}
+				neighbour := voxelIndex{
+					hZoom: common[0],
+					x:     ((ancestor.x+offset[0])%commonSize + commonSize) % commonSize,
+					y:     y,
+					vZoom: common[1],
+					f:     ancestor.f + offset[2],
+				}
+				for _, j := range buckets[bucketKey{zoom: zoom, ancestor: neighbour}] {
+					if j == i || !ranges[i].isAdjacent(ranges[j], limit, size) {
+						continue
+					}
+					// X成分が経度180度をまたがる場合のみ接する
+					if axisRelation(ranges[i].min[0], ranges[i].max[0], ranges[j].min[0], ranges[j].max[0]) == 2 {
+						wrapped[i], wrapped[j] = true, true
+					}
+					parents[find(j)] = find(i)
+				}
+			}
+		}
+	}
+
+	// 連結成分ごとに集計
+	groups := map[int][]int{}
+	for i := range voxels {
+		root := find(i)
+		groups[root] = append(groups[root], i)
+	}
+	components := make([]Component, 0, len(groups))
+	for _, members := range groups {
+		component := Component{
+			SpatialIDs: make([]string, 0, len(members)),
+			Count:      len(members),
+		}
+		crossing := false
+		for _, i := range members {
+			component.SpatialIDs = append(component.SpatialIDs, voxels[i].String())
+			crossing = crossing || wrapped[i]
+		}
+		component.BoundingBox = calcBoundingBox(component.SpatialIDs, crossing)
+		components = append(components, component)
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].SpatialIDs[0] < components[j].SpatialIDs[0] })
+
+	return components, nil
+}
+
+// calcBoundingBox 拡張空間IDに外接する範囲を算出
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//	crossing： 経度180度をまたがる場合true
+//
+// 戻り値：
+//
+//	外接する範囲
+func calcBoundingBox(spatialIDs []string, crossing bool) BoundingBox {
+	box := BoundingBox{
+		West: math.Inf(1), South: math.Inf(1), Bottom: math.Inf(1),
+		East: math.Inf(-1), North: math.Inf(-1), Top: math.Inf(-1),
+	}
+	for _, spatialID := range spatialIDs {
+		vertexes, _ := shape.GetPointOnExtendedSpatialId(spatialID, enum.Vertex)
+		west := math.Inf(1)
+		for _, vertex := range vertexes {
+			west = math.Min(west, vertex.Lon())
+		}
+		// 経度180度をまたがる場合は西半球の経度を360度ずらす
+		shift := 0.0
+		if crossing && west < 0 {
+			shift = 360
+		}
+		for _, vertex := range vertexes {
+			box.West = math.Min(box.West, vertex.Lon()+shift)
+			box.East = math.Max(box.East, vertex.Lon()+shift)
+			box.South = math.Min(box.South, vertex.Lat())
+			box.North = math.Max(box.North, vertex.Lat())
+			box.Bottom = math.Min(box.Bottom, vertex.Alt())
+			box.Top = math.Max(box.Top, vertex.Alt())
+		}
+	}
+	if box.West > 180 {
+		box.West -= 360
+	}
+	if box.East > 180 {
+		box.East -= 360
+	}
+	return box
+}
@@ -0,0 +1,224 @@
+package shape
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// TestSplitExtendedSpatialIdsByConnectivity01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：面で接する2個、その1個と頂点で接する1個、離れた1個(精度20)
+//   - パターン1：隣接の種類6
+//   - パターン2：隣接の種類26
+//
+// + 確認内容
+//   - パターン1：連結成分が3個(2個、1個、1個)であること
+//   - パターン2：連結成分が2個(3個、1個)であること
+//   - 連結成分に外接する範囲が拡張空間IDの頂点の範囲と一致すること
+func TestSplitExtendedSpatialIdsByConnectivity01(t *testing.T) {
+	//入力パラメータ
+	spatialIDs := []string{
+		"20/100/200/20/3",
+		"20/101/200/20/3",
+		"20/102/201/20/4",
+		"20/110/200/20/3",
+	}
+	inputs := []struct {
+		connectivity Connectivity
+		expect       [][]string
+	}{
+		{
+			Connectivity6,
+			[][]string{
+				{"20/100/200/20/3", "20/101/200/20/3"},
+				{"20/102/201/20/4"},
+				{"20/110/200/20/3"},
+			},
+		},
+		{
+			Connectivity26,
+			[][]string{
+				{"20/100/200/20/3", "20/101/200/20/3", "20/102/201/20/4"},
+				{"20/110/200/20/3"},
+			},
+		},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := SplitExtendedSpatialIdsByConnectivity(spatialIDs, input.connectivity)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		if len(resultVal) != len(input.expect) {
+			t.Fatalf("パターン%d 連結成分の数 - 期待値：%v, 取得値：%v", i+1, len(input.expect), len(resultVal))
+		}
+		for j, component := range resultVal {
+			if !reflect.DeepEqual(input.expect[j], component.SpatialIDs) || component.Count != len(input.expect[j]) {
+				t.Errorf("パターン%d 連結成分%d - 期待値：%v, 取得値：%v", i+1, j, input.expect[j], component)
+			}
+			expectBox := calcBoundingBox(input.expect[j], false)
+			if component.BoundingBox != expectBox {
+				t.Errorf("パターン%d 外接する範囲%d - 期待値：%v, 取得値：%v", i+1, j, expectBox, component.BoundingBox)
+			}
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestSplitExtendedSpatialIdsByConnectivity02 正常系動作確認(精度の混在)
+//
+// 試験詳細：
+// + 試験データ
+//   - 精度18の拡張空間ID：18/25/50/18/0(精度20換算でX：100～103、Y：200～203、高さ：0～3)
+//   - 精度20の拡張空間ID：東の面で接するもの、東に1個離れたもの、内部に含まれるもの
+//   - 水平精度20、垂直精度18の拡張空間ID：上の面で接するもの
+//   - 隣接の種類6
+//
+// + 確認内容
+//   - 東に1個離れた拡張空間ID以外が1つの連結成分となること
+//   - 連結成分に外接する範囲の上端が、上の面で接する拡張空間IDの上端であること
+func TestSplitExtendedSpatialIdsByConnectivity02(t *testing.T) {
+	//入力パラメータ
+	spatialIDs := []string{
+		"18/25/50/18/0",
+		"20/104/201/20/2",
+		"20/106/201/20/2",
+		"20/102/202/20/1",
+		"20/101/203/18/1",
+	}
+
+	// 期待値
+	expectVal := [][]string{
+		{"18/25/50/18/0", "20/101/203/18/1", "20/102/202/20/1", "20/104/201/20/2"},
+		{"20/106/201/20/2"},
+	}
+
+	// テスト対象呼び出し
+	resultVal, err := SplitExtendedSpatialIdsByConnectivity(spatialIDs, Connectivity6)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	if len(resultVal) != len(expectVal) {
+		t.Fatalf("連結成分の数 - 期待値：%v, 取得値：%v", len(expectVal), len(resultVal))
+	}
+	for i, component := range resultVal {
+		if !reflect.DeepEqual(expectVal[i], component.SpatialIDs) {
+			t.Errorf("連結成分%d - 期待値：%v, 取得値：%v", i, expectVal[i], component.SpatialIDs)
+		}
+	}
+	top := calcBoundingBox([]string{"20/101/203/18/1"}, false).Top
+	if resultVal[0].BoundingBox.Top != top {
+		t.Errorf("上端 - 期待値：%v, 取得値：%v", top, resultVal[0].BoundingBox.Top)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestSplitExtendedSpatialIdsByConnectivity03 正常系動作確認(経度180度)
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：3/7/2/3/0、3/0/2/3/0、隣接の種類6
+//
+// + 確認内容
+//   - 1つの連結成分となり、外接する範囲の西端が135度、東端が-135度であること
+func TestSplitExtendedSpatialIdsByConnectivity03(t *testing.T) {
+	// テスト対象呼び出し
+	resultVal, err := SplitExtendedSpatialIdsByConnectivity([]string{"3/7/2/3/0", "3/0/2/3/0"}, Connectivity6)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	if len(resultVal) != 1 {
+		t.Fatalf("連結成分の数 - 期待値：1, 取得値：%v", len(resultVal))
+	}
+	box := resultVal[0].BoundingBox
+	if math.Abs(box.West-135) > 1e-9 || math.Abs(box.East+135) > 1e-9 {
+		t.Errorf("外接する範囲 - 期待値：135～-135, 取得値：%v～%v", box.West, box.East)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestSplitExtendedSpatialIdsByConnectivity04 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：隣接の種類が8
+//   - パターン2：拡張空間IDのフォーマットが不正
+//   - パターン3：X成分が負(20/-5/0/20/0)
+//   - パターン4：Y成分が2^精度以上(3/0/8/3/0)
+//   - パターン5：高さ成分が-2^精度未満(3/0/0/3/-9)
+//
+// + 確認内容
+//   - エラーが返却されること
+func TestSplitExtendedSpatialIdsByConnectivity04(t *testing.T) {
+	//入力パラメータ
+	inputs := []struct {
+		spatialIDs   []string
+		connectivity Connectivity
+	}{
+		{[]string{"20/100/200/20/3"}, Connectivity(8)},
+		{[]string{"20/100/200/20"}, Connectivity6},
+		{[]string{"20/-5/0/20/0"}, Connectivity6},
+		{[]string{"3/0/8/3/0"}, Connectivity6},
+		{[]string{"3/0/0/3/-9"}, Connectivity6},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, err := SplitExtendedSpatialIdsByConnectivity(input.spatialIDs, input.connectivity)
+		if err == nil {
+			t.Errorf("パターン%d error - 期待値：エラー, 取得値：nil", i+1)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestSplitExtendedSpatialIdsByConnectivity05 正常系動作確認(精度10と精度25の混在)
+//
+// 試験詳細：
+// + 試験データ
+//   - A：10/100/100/10/0
+//   - B：Aの東の面に接する精度25の拡張空間ID
+//   - C：Aから精度25のボクセル2^16個分離れた精度25の拡張空間ID
+//   - D：水平方向精度25、垂直方向精度10で、Aと離れ、Eの南の面に接する拡張空間ID
+//   - E：水平方向精度10、垂直方向精度25で、Aの南の面に接する拡張空間ID
+//   - 隣接の種類：6
+//
+// + 確認内容
+//   - A、B、D、EとCの2つの連結成分に分割されること
+//   - 精度の差によらず短時間で終了すること
+func TestSplitExtendedSpatialIdsByConnectivity05(t *testing.T) {
+	//入力パラメータ
+	a := "10/100/100/10/0"
+	b := GetSpatialIDOnAxisIDs(101<<15, 100<<15, 0, 25, 25)
+	c := GetSpatialIDOnAxisIDs(103<<15, 100<<15, 0, 25, 25)
+	d := GetSpatialIDOnAxisIDs(100<<15, 102<<15, 0, 25, 10)
+	e := "10/100/101/25/0"
+	expectVal := [][]string{{a, e, d, b}, {c}}
+
+	// テスト対象呼び出し
+	resultVal, err := SplitExtendedSpatialIdsByConnectivity([]string{a, b, c, d, e}, Connectivity6)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	components := [][]string{}
+	for _, component := range resultVal {
+		components = append(components, component.SpatialIDs)
+	}
+	if !reflect.DeepEqual(expectVal, components) {
+		t.Errorf("連結成分 - 期待値：%v, 取得値：%v", expectVal, components)
+	}
+
+	t.Log("テスト終了")
+}
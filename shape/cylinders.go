@@ -2,16 +2,16 @@ package shape
 
 import (
 	// "fmt"
+	"log/slog"
 	"math"
-	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/trajectoryjp/spatial_id_go/common"
 	"github.com/trajectoryjp/spatial_id_go/common/consts"
 	"github.com/trajectoryjp/spatial_id_go/common/enum"
 	"github.com/trajectoryjp/spatial_id_go/common/errors"
-	"github.com/trajectoryjp/spatial_id_go/common/logger"
 	"github.com/trajectoryjp/spatial_id_go/common/object"
 	"github.com/trajectoryjp/spatial_id_go/common/spatial"
 	"github.com/trajectoryjp/spatial_id_go/operated"
@@ -57,6 +57,170 @@ func GetSpatialIDOnAxisIDs(xID, yID, zID, hZoom, vZoom int64) string {
 	return strings.Join(spatialIDs, consts.SpatialIDDelimiter)
 }
 
+// GetNeighbourExtendedSpatialIds 隣接する拡張空間IDの取得
+//
+// 拡張空間IDと同じ精度で、隣接の種類に応じて面、辺、頂点で接する拡張空間IDを取得する。
+// X成分は経度180度をまたがる場合に反対側に折り返し、Y成分、高さ成分が範囲外となる拡張空間IDは除く。
+//
+// 引数：
+//
+//	spatialID： 拡張空間ID
+//	connectivity： ボクセルの隣接の種類(6、18、26)
+//
+// 戻り値：
+//
+//	隣接する拡張空間IDのリスト(昇順)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDがParseExtendedSpatialIDで解析できない場合、もしくは隣接の種類が不正な場合
+func GetNeighbourExtendedSpatialIds(spatialID string, connectivity Connectivity) ([]string, error) {
+	id, err := ParseExtendedSpatialID(spatialID)
+	if err != nil {
+		return []string{}, err
+	}
+	return GetNeighbourExtendedSpatialIdsOnZoom(spatialID, connectivity, id.HZoom(), id.VZoom())
+}
+
+// GetNeighbourExtendedSpatialIdsOnZoom 指定の精度の隣接する拡張空間IDの取得
+//
+// 指定の精度の拡張空間IDのうち、拡張空間IDと重ならず、隣接の種類に応じて面、辺、頂点で接する拡張空間IDを取得する。
+// 細かい精度を指定した場合は、拡張空間IDの外側に接する細かいボクセルを取得する。
+// 取得する拡張空間IDの数は精度の差に対して指数的に増えるため、
+// 拡張空間IDよりmaxNeighbourZoomDifferenceを超えて細かい精度は指定できない。
+// 粗い精度を指定した場合は、拡張空間IDを含む粗いボクセル以外で、拡張空間IDに接する粗いボクセルを取得する。
+// X成分は経度180度をまたがる場合に反対側に折り返し、Y成分、高さ成分が範囲外となる拡張空間IDは除く。
+//
+// 引数：
+//
+//	spatialID： 拡張空間ID
+//	connectivity： ボクセルの隣接の種類(6、18、26)
+//	hZoom： 取得する拡張空間IDの水平方向精度
+//	vZoom： 取得する拡張空間IDの垂直方向精度
+//
+// 戻り値：
+//
+//	隣接する拡張空間IDのリスト(昇順)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDがParseExtendedSpatialIDで解析できない場合、もしくは隣接の種類が不正な場合
+//	               拡張空間IDよりmaxNeighbourZoomDifferenceを超えて細かい精度を指定した場合もエラー
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func GetNeighbourExtendedSpatialIdsOnZoom(
+	spatialID string,
+	connectivity Connectivity,
+	hZoom int64,
+	vZoom int64,
+) ([]string, error) {
+	limit, err := connectivityLimit(connectivity)
+	if err != nil {
+		return []string{}, err
+	}
+	id, err := ParseExtendedSpatialID(spatialID)
+	if err != nil {
+		return []string{}, err
+	}
+	if !shape.CheckZoom(hZoom) || !shape.CheckZoom(vZoom) {
+		return []string{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	} else if hZoom-id.HZoom() > maxNeighbourZoomDifference || vZoom-id.VZoom() > maxNeighbourZoomDifference {
+		return []string{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "精度の差が大きすぎます")
+	}
+
+	// 最も細かい精度に換算して判定する
+	maxH, maxV := max(hZoom, id.HZoom()), max(vZoom, id.VZoom())
+	target := newVoxelRange(id.index, maxH, maxV)
+
+	// 指定の精度で拡張空間IDを含む範囲の外側の1ボクセル分を候補とする
+	bounds := [3][2]int64{}
+	bounds[0][0], bounds[0][1], _ = zoomRange(id.X(), id.HZoom(), hZoom)
+	bounds[1][0], bounds[1][1], _ = zoomRange(id.Y(), id.HZoom(), hZoom)
+	bounds[2][0], bounds[2][1], _ = zoomRange(id.F(), id.VZoom(), vZoom)
+	size := int64(1) << hZoom
+	neighbours := map[voxelIndex]bool{}
+	for _, offset := range aroundOffsets {
+		// 軸ごとに範囲の手前、範囲内、範囲の先のいずれかとし、範囲外の軸の数が接する次元を超える組み合わせは除く
+		var low, high [3]int64
+		outside := 0
+		for axis, d := range offset {
+			switch d {
+			case -1:
+				low[axis], high[axis] = bounds[axis][0]-1, bounds[axis][0]-1
+			case 0:
+				low[axis], high[axis] = bounds[axis][0], bounds[axis][1]
+			case 1:
+				low[axis], high[axis] = bounds[axis][1]+1, bounds[axis][1]+1
+			}
+			if d != 0 {
+				outside++
+			}
+		}
+		if outside == 0 || outside > limit {
+			continue
+		}
+
+		for x := low[0]; x <= high[0]; x++ {
+			for y := low[1]; y <= high[1]; y++ {
+				for f := low[2]; f <= high[2]; f++ {
+					candidate := voxelIndex{hZoom: hZoom, x: (x%size + size) % size, y: y, vZoom: vZoom, f: f}
+					if !candidate.isValid() || neighbours[candidate] {
+						continue
+					}
+					touches := target.touches(newVoxelRange(candidate, maxH, maxV), int64(1)<<maxH)
+					if touches >= 1 && touches <= limit {
+						neighbours[candidate] = true
+					}
+				}
+			}
+		}
+	}
+	return voxelSetToSpatialIDs(neighbours), nil
+}
+
+// maxNeighbourZoomDifference 隣接する拡張空間IDの取得で許容する、拡張空間IDより細かい精度の差の最大値
+const maxNeighbourZoomDifference = 10
+
+// IsAdjacentExtendedSpatialIds 拡張空間IDの隣接判定
+//
+// 2つの拡張空間IDが重ならず、隣接の種類に応じて面、辺、頂点で接する場合に隣接と判定する。
+// 精度の異なる拡張空間IDは、細かい精度に換算したボクセルの範囲で判定する。
+// 経度180度をまたがって接する場合も隣接とする。
+//
+// 引数：
+//
+//	spatialID1： 拡張空間ID
+//	spatialID2： 拡張空間ID
+//	connectivity： ボクセルの隣接の種類(6、18、26)
+//
+// 戻り値：
+//
+//	隣接する場合true、同じ拡張空間ID、一方が他方を含む場合、もしくは離れている場合false
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDがParseExtendedSpatialIDで解析できない場合、もしくは隣接の種類が不正な場合
+func IsAdjacentExtendedSpatialIds(spatialID1, spatialID2 string, connectivity Connectivity) (bool, error) {
+	limit, err := connectivityLimit(connectivity)
+	if err != nil {
+		return false, err
+	}
+	id1, err := ParseExtendedSpatialID(spatialID1)
+	if err != nil {
+		return false, err
+	}
+	id2, err := ParseExtendedSpatialID(spatialID2)
+	if err != nil {
+		return false, err
+	}
+
+	maxH, maxV := max(id1.HZoom(), id2.HZoom()), max(id1.VZoom(), id2.VZoom())
+	touches := newVoxelRange(id1.index, maxH, maxV).touches(newVoxelRange(id2.index, maxH, maxV), int64(1)<<maxH)
+	return touches >= 1 && touches <= limit, nil
+}
+
 // GetVoxelIDToSpatialID ボクセル成分ID取得
 //
 // 拡張空間IDからボクセル成分ID取得
@@ -178,11 +342,6 @@ func (r Rectangular) calcUnitVoxelVector(spatialID string) (spatial.Vector3, err
 	maxZ, _ := common.Max(zApexes)
 	minZ, _ := common.Min(zApexes)
 
-	logger.Debug("単位ボクセルベクトル(X: %v, Y: %v, Z: %v)",
-		math.Floor((maxX-minX)*math.Pow10(6))/math.Pow10(6),
-		math.Floor((maxY-minY)*math.Pow10(6))/math.Pow10(6),
-		math.Floor((maxZ-minZ)*math.Pow10(6))*r.factor/math.Pow10(6))
-
 	return spatial.Vector3{
 		X: math.Floor((maxX-minX)*math.Pow10(6)) / math.Pow10(6),
 		Y: math.Floor((maxY-minY)*math.Pow10(6)) / math.Pow10(6),
@@ -190,6 +349,27 @@ func (r Rectangular) calcUnitVoxelVector(spatialID string) (spatial.Vector3, err
 	}, nil
 }
 
+// calcBaseUnitVoxel 赤道の単位ボクセルベクトルを算出
+//
+// 戻り値：
+//
+//	赤道上のボクセルの単位ボクセルベクトル
+func (r Rectangular) calcBaseUnitVoxel() spatial.Vector3 {
+	baseLat := int64(0)
+	if r.hZoom != 0 {
+		baseLat = int64(math.Pow(2, float64(r.hZoom-1)))
+	}
+	baseSpatialID := GetSpatialIDOnAxisIDs(
+		0,
+		baseLat,
+		0,
+		r.hZoom,
+		r.vZoom,
+	)
+	unitVoxel, _ := r.calcUnitVoxelVector(baseSpatialID)
+	return unitVoxel
+}
+
 // Capsule カプセル構造体
 type Capsule struct {
 	*Rectangular                      // 直方体構造体の埋め込み
@@ -198,6 +378,9 @@ type Capsule struct {
 	isPrecision       bool            // 衝突判定実施オプション
 	object            physics.Physics // 衝突判定オブジェクト
 	includeSpatialIDs []string        // 内部判定空間ID
+	logger            *slog.Logger    // ログ出力先(nilの場合は出力しない)
+	observer          StageObserver   // 処理段階の観測(nilの場合は観測しない)
+	segment           int             // 処理段階の観測に通知する区間番号
 }
 
 // NewCapsule カプセル構造体コンストラクタ
@@ -319,7 +502,7 @@ func (c Capsule) calcLineSpatialIDs() ([]string, []string, error) {
 		}
 		// 内部空間用始点終点間の空間IDを更新
 		insideLineSpatialIDs = lineSpatialIDs
-		logger.Debug("球の場合、接続点の空間ID取得: %s", reflect.ValueOf(lineSpatialIDs))
+		debugLog(c.logger, "球の場合、接続点の空間ID取得", slog.Int("lineIDs", len(lineSpatialIDs)))
 
 		// カプセル・円柱の場合
 	} else {
@@ -338,7 +521,7 @@ func (c Capsule) calcLineSpatialIDs() ([]string, []string, error) {
 			return []string{}, []string{}, err
 		}
 
-		logger.Debug("カプセルの場合、始点終点間の空間ID取得: %s", reflect.ValueOf(lineSpatialIDs))
+		debugLog(c.logger, "カプセルの場合、始点終点間の空間ID取得", slog.Int("lineIDs", len(lineSpatialIDs)))
 
 		// カプセルの場合
 		if c.isCapsule {
@@ -369,7 +552,11 @@ func (c Capsule) calcLineSpatialIDs() ([]string, []string, error) {
 			// 内部空間用始点終点間の空間IDを更新
 			insideLineSpatialIDs, _ =
 				shape.GetExtendedSpatialIdsOnLine(wgs84Points[0], wgs84Points[1], c.hZoom, c.vZoom)
-			logger.Debug("直径より長い円柱の場合、内部用始点終点間の空間ID取得: %s", reflect.ValueOf(lineSpatialIDs))
+			debugLog(
+				c.logger,
+				"直径より長い円柱の場合、内部用始点終点間の空間ID取得",
+				slog.Int("insideLineIDs", len(insideLineSpatialIDs)),
+			)
 		}
 	}
 
@@ -390,7 +577,13 @@ func (c *Capsule) calcAllSpatialIDs(lineSpatialIDs []string, unitVoxel spatial.V
 	yApprNum := math.Ceil(c.radius * c.factor / unitVoxel.Y)
 	zApprNum := math.Ceil(c.radius * c.factor / unitVoxel.Z)
 
-	logger.Debug("X軸シフト数: %f, Y軸シフト数: %f, Z軸シフト数: %f", xApprNum, yApprNum, zApprNum)
+	debugLog(
+		c.logger,
+		"全空間IDのシフト数",
+		slog.Float64("x", xApprNum),
+		slog.Float64("y", yApprNum),
+		slog.Float64("z", zApprNum),
+	)
 
 	// 【直交空間】オブジェクトの全空間ID簡易取得
 	for _, lineSpatialID := range lineSpatialIDs {
@@ -411,6 +604,7 @@ func (c *Capsule) calcAllSpatialIDs(lineSpatialIDs []string, unitVoxel spatial.V
 	}
 
 	c.allSpatialIDs = common.Unique(c.allSpatialIDs)
+	debugLog(c.logger, "全空間ID取得", slog.Int("candidateIDs", len(c.allSpatialIDs)))
 }
 
 // calcIncludeSpatialIDs 内部空間IDを取得
@@ -440,18 +634,21 @@ func (c *Capsule) calcIncludeSpatialIDs(insideLineSpatialIDs []string, unitVoxel
 		}
 	}
 	c.includeSpatialIDs = common.Unique(c.includeSpatialIDs)
+	debugLog(c.logger, "内部空間ID取得", slog.Int("includeIDs", len(c.includeSpatialIDs)))
 }
 
 // calcCollideSpatialIDs 衝突する空間IDを取得
 //
 // 全空間IDから内部空間IDを除いた空間IDとオブジェクトで衝突判定を実施し、衝突した空間IDを結果に追加
-func (c *Capsule) calcCollideSpatialIDs() {
+//
+// 戻り値：
+//
+//	衝突判定の実施回数
+func (c *Capsule) calcCollideSpatialIDs() int {
 
 	// Azul3Dと衝突判定を行う衝突空間ID(全空間IDから内部空間IDを除いた空間ID)
 	excludeSpatialIDs := common.Difference(c.allSpatialIDs, c.includeSpatialIDs)
 
-	logger.Debug("Azul3Dと衝突判定を行う空間ID: %s", reflect.ValueOf(excludeSpatialIDs))
-
 	latDict := make(map[int64]spatial.Vector3)
 	for _, excludeSpatialID := range excludeSpatialIDs {
 
@@ -472,7 +669,6 @@ func (c *Capsule) calcCollideSpatialIDs() {
 		centers, _ := shape.GetPointOnExtendedSpatialId(excludeSpatialID, enum.Center)
 		orthCenters, _ := shape.ConvertPointListToProjectedPointList(centers, consts.OrthCrs)
 		orthCenter := spatial.Point3{X: orthCenters[0].X, Y: orthCenters[0].Y, Z: orthCenters[0].Alt * c.factor}
-		logger.Debug("ボクセルの中心座標: %f, %f, %f", orthCenter.X, orthCenter.Y, orthCenter.Z)
 
 		if c.object.IsCollideVoxel(orthCenter, lens) {
 			c.includeSpatialIDs = append(c.includeSpatialIDs, excludeSpatialID)
@@ -481,45 +677,62 @@ func (c *Capsule) calcCollideSpatialIDs() {
 
 	// 円柱の場合は衝突判定の内部を埋める
 	if !c.isCapsule && !c.isSphere {
-		// 経度緯度をキーとした辞書初期化
-		lotLatMap := map[string][]string{}
+		c.includeSpatialIDs = fillVerticalSpatialIDs(c.includeSpatialIDs, c.hZoom, c.vZoom)
+	}
 
-		// 空間IDで経度緯度が同一のものを集約
-		for _, spatialID := range c.includeSpatialIDs {
-			ids := strings.Split(spatialID, consts.SpatialIDDelimiter)
-			lotLatMap[ids[1]+ids[2]] = append(lotLatMap[ids[1]+ids[2]], spatialID)
-		}
+	// 空間IDの重複を削除
+	c.includeSpatialIDs = common.Unique(c.includeSpatialIDs)
+	debugLog(
+		c.logger,
+		"衝突判定",
+		slog.Int("collisionTests", len(excludeSpatialIDs)),
+		slog.Int("acceptedIDs", len(c.includeSpatialIDs)),
+	)
 
-		// 経度緯度が同一の空間ID内の高さ間の空間IDを取得
-		for _, spatialIDs := range lotLatMap {
-			zIndexes := []int64{}
-			baseIndexes := GetVoxelIDToSpatialID(spatialIDs[0])
+	return len(excludeSpatialIDs)
+}
 
-			// 経度緯度が同一の空間ID内の高さを集約
-			for _, checkSpatialID := range spatialIDs {
-				ids := GetVoxelIDToSpatialID(checkSpatialID)
-				zIndexes = append(zIndexes, ids[2])
-			}
+// fillVerticalSpatialIDs 高さ方向の空間IDの補完
+//
+// 経度緯度が同一の空間IDごとに、高さの最小値・最大値間の空間IDを追加する。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間ID
+//	hZoom： 水平精度
+//	vZoom： 垂直精度
+//
+// 戻り値：
+//
+//	高さの最小値・最大値間の空間IDを追加した拡張空間ID
+func fillVerticalSpatialIDs(spatialIDs []string, hZoom, vZoom int64) []string {
+	// 経度緯度をキーとした高さの最小値・最大値
+	type column struct{ x, y, minZ, maxZ int64 }
+	columns := map[[2]int64]*column{}
+	keys := [][2]int64{}
+
+	// 空間IDで経度緯度が同一のものを集約
+	for _, spatialID := range spatialIDs {
+		ids := GetVoxelIDToSpatialID(spatialID)
+		key := [2]int64{ids[0], ids[1]}
+		if col, ok := columns[key]; ok {
+			col.minZ = min(col.minZ, ids[2])
+			col.maxZ = max(col.maxZ, ids[2])
+			continue
+		}
+		columns[key] = &column{x: ids[0], y: ids[1], minZ: ids[2], maxZ: ids[2]}
+		keys = append(keys, key)
+	}
 
-			// 高さの最大値・最小値を取得
-			minZ, _ := common.Min(zIndexes)
-			maxZ, _ := common.Max(zIndexes)
-			// 高さの最大値・最小値間の空間IDを結果に追加
-			for zIndex := minZ; zIndex <= maxZ; zIndex++ {
-				spatialID := GetSpatialIDOnAxisIDs(
-					baseIndexes[0],
-					baseIndexes[1],
-					zIndex,
-					c.hZoom,
-					c.vZoom,
-				)
-				c.includeSpatialIDs = append(c.includeSpatialIDs, spatialID)
-			}
+	// 高さの最大値・最小値間の空間IDを結果に追加
+	for _, key := range keys {
+		col := columns[key]
+		for zIndex := col.minZ; zIndex <= col.maxZ; zIndex++ {
+			spatialIDs = append(spatialIDs, GetSpatialIDOnAxisIDs(col.x, col.y, zIndex, hZoom, vZoom))
 		}
 	}
 
-	// 空間IDの重複を削除
-	c.includeSpatialIDs = common.Unique(c.includeSpatialIDs)
+	return spatialIDs
 }
 
 // CalcValidSpatialIDs 有効な空間ID取得
@@ -535,47 +748,65 @@ func (c *Capsule) calcCollideSpatialIDs() {
 //	以下の条件に当てはまる場合、エラーインスタンスが返却される。
 //	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
 func (c *Capsule) CalcValidSpatialIDs() ([]string, error) {
+	endSegment := startStage(c.observer, StageSegment, c.segment)
 
 	// 始点・終点間の軸の空間IDを取得
+	endStage := startStage(c.observer, StageLine, c.segment)
 	lineSpatialIDs, insideLineSpatialIDs, err := c.calcLineSpatialIDs()
+	endStage(StageResult{Count: len(lineSpatialIDs)})
 	if err != nil {
+		endSegment(StageResult{})
 		return []string{}, err
 	}
 
 	// 【直交座標空間】単位ボクセル
-	baseLat := int64(0)
-	if c.hZoom != 0 {
-		baseLat = int64(math.Pow(2, float64(c.hZoom-1)))
-	}
-	baseSpatialID := GetSpatialIDOnAxisIDs(
-		0,
-		baseLat,
-		0,
-		c.hZoom,
-		c.vZoom,
+	unitVoxel := c.calcBaseUnitVoxel()
+	debugLog(
+		c.logger,
+		"単位ボクセルベクトル",
+		slog.Float64("x", unitVoxel.X),
+		slog.Float64("y", unitVoxel.Y),
+		slog.Float64("z", unitVoxel.Z),
 	)
-	unitVoxel, _ := c.calcUnitVoxelVector(baseSpatialID)
 
 	// オブジェクトに外接する直方体の空間IDを全空間IDとして取得
+	endStage = startStage(c.observer, StageCandidate, c.segment)
 	c.calcAllSpatialIDs(lineSpatialIDs, unitVoxel)
+	endStage(StageResult{Count: len(c.allSpatialIDs)})
 
 	// 衝突判定実施オプションがfalseの場合は衝突判定をスキップ
 	if !c.isPrecision {
+		endSegment(StageResult{Count: len(c.allSpatialIDs)})
 		return c.allSpatialIDs, nil
 	}
 
 	// オブジェクトに内接する直方体の空間IDを内部空間IDとして取得
+	endStage = startStage(c.observer, StageInclude, c.segment)
 	c.calcIncludeSpatialIDs(insideLineSpatialIDs, unitVoxel)
+	endStage(StageResult{Count: len(c.includeSpatialIDs)})
 
 	// 全空間IDから内部空間IDを除いた空間IDとオブジェクトで衝突判定
-	c.calcCollideSpatialIDs()
+	endStage = startStage(c.observer, StageCollision, c.segment)
+	tests := c.calcCollideSpatialIDs()
+	endStage(StageResult{Count: len(c.includeSpatialIDs), Tests: tests})
 
+	endSegment(StageResult{Count: len(c.includeSpatialIDs)})
 	return c.includeSpatialIDs, nil
 }
 
 // IsPrecisionOpts 衝突判定実施オプショナル引数構造体
 type IsPrecisionOpts struct {
-	IsPrecision bool // 衝突判定実施オプション
+	IsPrecision       bool              // 衝突判定実施オプション
+	LogHandler        slog.Handler      // ログ出力先(nilの場合は出力しない)
+	Observer          StageObserver     // 処理段階の観測(nilの場合は観測しない)
+	Terrain           ElevationModel    // 地中の空間IDを除外する地表面の標高モデル(nilの場合は除外しない)
+	AboveGround       ElevationModel    // 接続点の高さを地上高とする地表面の標高モデル(nilの場合はTerrainを使用する)
+	Altitude          AltitudeReference // 接続点の高さの基準
+	Geoid             GeoidModel        // 標高を楕円体高に変換するジオイドモデル(nilの場合は変換しない)
+	GeodesicInterval  float64           // 測地線に沿って接続点間を分割する間隔(単位:m。0の場合は分割しない)
+	TurnRadius        float64           // 接続点の角を置き換える円弧の旋回半径(単位:m。0の場合は置き換えない)
+	Uncertainties     []Uncertainty     // 接続点ごとの位置の不確かさ(nilの場合は加算しない)
+	UncertaintyGrowth Uncertainty       // 経路に沿った位置の不確かさの増加率(0の場合は増加しない)
 }
 
 // 衝突判定実施オプショナル型
@@ -600,11 +831,80 @@ func IsPrecision(v bool) option {
 	}
 }
 
+// EstimateExtendedSpatialIdsOnCylinders 拡張空間ID(円柱)の数の見積もり
+//
+// GetExtendedSpatialIdsOnCylindersで取得する拡張空間IDの数を、空間IDを取得せずに見積もる。
+// 区間ごとに半径と長さをボクセルの大きさだけ広げた円柱の体積を、ボクセルの体積で割った値の合計とする。
+// 接続点の球、旋回の円弧等のオプションは考慮しない。
+// APIの応答等で、取得前に処理量を制限する際に使用する。
+//
+// 引数：
+//
+//	center： 円柱の中心の接続点
+//	radius： 円柱の半径(単位:m)
+//	hZoom： 水平方向の精度レベル
+//	vZoom： 垂直方向の精度レベル
+//
+// 戻り値：
+//
+//	拡張空間IDの数の見積もり
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 接続点にnilがある場合、円柱の半径が0以下の場合、
+//	               もしくは緯度がWebメルカトル投影の範囲外(±85.0511287798度を超える)の接続点がある場合
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func EstimateExtendedSpatialIdsOnCylinders(
+	center []*object.Point,
+	radius float64,
+	hZoom int64,
+	vZoom int64,
+) (float64, error) {
+	if common.Include(center, nil) || !shape.CheckZoom(hZoom) || !shape.CheckZoom(vZoom) ||
+		radius <= consts.Minima {
+		return 0, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	} else if len(center) == 0 {
+		return 0, nil
+	}
+	if err := checkMercatorLatitude(center); err != nil {
+		return 0, err
+	}
+
+	// 【直交座標空間】ボクセルの大きさと半径
+	factor := 1 / math.Cos(common.DegreeToRadian(center[0].Lat()))
+	unitVoxel := Rectangular{hZoom: hZoom, vZoom: vZoom, factor: factor}.calcBaseUnitVoxel()
+	margin := radius*factor + math.Max(unitVoxel.X, unitVoxel.Z)
+
+	// 接続点が1つの場合は球とする
+	count := 0.0
+	for i := 0; i == 0 || i < len(center)-1; i++ {
+		points := []*object.Point{center[i], center[min(i+1, len(center)-1)]}
+		// 経度180度をまたがる区間は経度を180度ずらした座標系で長さを算出する
+		if isAntimeridianFrame(points...) {
+			shifted, err := shiftLongitudes(points...)
+			if err != nil {
+				return 0, err
+			}
+			points = shifted
+		}
+		orthPoints, _ := shape.ConvertPointListToProjectedPointList(points, consts.OrthCrs)
+		length := spatial.NewVectorFromPoints(
+			spatial.Point3{X: orthPoints[0].X, Y: orthPoints[0].Y, Z: orthPoints[0].Alt * factor},
+			spatial.Point3{X: orthPoints[1].X, Y: orthPoints[1].Y, Z: orthPoints[1].Alt * factor},
+		).Norm()
+		count += math.Pi * margin * margin * (length + 2*margin) / (unitVoxel.X * unitVoxel.Y * unitVoxel.Z)
+	}
+	return count, nil
+}
+
 // GetSpatialIdsOnCylinders 空間ID(円柱)取得
 //
 // 円柱を複数つなげた経路が通る空間IDを取得する。
 // 円柱間の接続面は球状とする。
 // ドローンの経路や地中埋設配管が通る経路を空間IDで表現する際に使用する。
+// 経度180度をまたがる区間は経度180度を越える短い方の経路とし、
+// 緯度がWebメルカトル投影の範囲外となる空間IDは除外する。
 //
 // 引数：
 //
@@ -614,6 +914,14 @@ func IsPrecision(v bool) option {
 //	zoom       : 精度レベル
 //	isCapsule  : 始点、終点が球状であるかを示す。True: カプセル / False: 円柱
 //	isPrecision: 衝突判定を実施するかのフラグ。True: 実施 / False: 未実施(デフォルトはTrue)
+//	             LogHandlerを指定した場合は処理過程を構造化ログとして出力する。
+//	             TerrainClipを指定した場合は地中の空間IDを除外し、
+//	             AboveGroundLevelを指定した場合は接続点の高さを地上高として扱う。
+//	             Altitude、Geoidを指定した場合は接続点の高さを楕円体高に変換する。
+//	             Geodesicを指定した場合は接続点間を測地線に沿った経路とする。
+//	             TurnRadiusを指定した場合は接続点の角を旋回半径の円弧とする。
+//	             PositionUncertainty、UncertaintyGrowthを指定した場合は
+//	             接続点ごとに半径が変化する経路とする。
 //
 // 戻り値：
 //
@@ -622,9 +930,14 @@ func IsPrecision(v bool) option {
 // 戻り値(エラー)：
 //
 //	以下の条件に当てはまる場合、エラーインスタンスが返却される。
-//	 入力数値不正： 座標の値が不正の場合、もしくは円柱の半径が0以下の場合、エラー
+//	 入力数値不正： 座標の値が不正の場合、円柱の半径が0以下の場合、もしくは旋回半径が負の場合、エラー
+//	               緯度がWebメルカトル投影の範囲外(±85.0511287798度を超える)の接続点がある場合もエラー
+//	               位置の不確かさの数が接続点の数と一致しない場合、不確かさ、増加率が負の場合、
+//	               もしくは位置の不確かさとTurnRadiusを併用した場合もエラー
+//	 高さ変換不可： 高さの変換に必要なモデルが未指定の場合、
+//	               もしくは接続点の位置のジオイド高、標高を取得できない場合。
+//	 測地線分割不可： Geodesicに負の間隔を指定した場合、もしくは対蹠点に近い区間がある場合。
 //	 精度閾値超過： 精度に 0 ～ 35 の整数値以外が入力されていた場合。
-//	 注意: 経度180度をまたがる点をまたがる円柱は空間ID化できない。
 func GetSpatialIdsOnCylinders(
 	center []*object.Point,
 	radius float64,
@@ -652,6 +965,8 @@ func GetSpatialIdsOnCylinders(
 // 円柱を複数つなげた経路が通る拡張空間IDを取得する。
 // 円柱間の接続面は球状とする。
 // ドローンの経路や地中埋設配管が通る経路を拡張空間IDで表現する際に使用する。
+// 経度180度をまたがる区間は経度180度を越える短い方の経路とし、
+// 緯度がWebメルカトル投影の範囲外となる空間IDは除外する。
 //
 // 引数：
 //
@@ -662,6 +977,14 @@ func GetSpatialIdsOnCylinders(
 //	vZoom      : 垂直方向の精度レベル
 //	isCapsule  : 始点、終点が球状であるかを示す。True: カプセル / False: 円柱
 //	isPrecision: 衝突判定を実施するかのフラグ。True: 実施 / False: 未実施(デフォルトはTrue)
+//	             LogHandlerを指定した場合は処理過程を構造化ログとして出力する。
+//	             TerrainClipを指定した場合は地中の空間IDを除外し、
+//	             AboveGroundLevelを指定した場合は接続点の高さを地上高として扱う。
+//	             Altitude、Geoidを指定した場合は接続点の高さを楕円体高に変換する。
+//	             Geodesicを指定した場合は接続点間を測地線に沿った経路とする。
+//	             TurnRadiusを指定した場合は接続点の角を旋回半径の円弧とする。
+//	             PositionUncertainty、UncertaintyGrowthを指定した場合は
+//	             接続点ごとに半径が変化する経路とする。
 //
 // 戻り値：
 //
@@ -670,9 +993,14 @@ func GetSpatialIdsOnCylinders(
 // 戻り値(エラー)：
 //
 //	以下の条件に当てはまる場合、エラーインスタンスが返却される。
-//	 入力数値不正： 座標の値が不正の場合、もしくは円柱の半径が0以下の場合、エラー
+//	 入力数値不正： 座標の値が不正の場合、円柱の半径が0以下の場合、もしくは旋回半径が負の場合、エラー
+//	               緯度がWebメルカトル投影の範囲外(±85.0511287798度を超える)の接続点がある場合もエラー
+//	               位置の不確かさの数が接続点の数と一致しない場合、不確かさ、増加率が負の場合、
+//	               もしくは位置の不確かさとTurnRadiusを併用した場合もエラー
+//	 高さ変換不可： 高さの変換に必要なモデルが未指定の場合、
+//	               もしくは接続点の位置のジオイド高、標高を取得できない場合。
+//	 測地線分割不可： Geodesicに負の間隔を指定した場合、もしくは対蹠点に近い区間がある場合。
 //	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
-//	 注意: 経度180度をまたがる点をまたがる円柱は拡張空間ID化できない。
 func GetExtendedSpatialIdsOnCylinders(
 	center []*object.Point,
 	radius float64,
@@ -692,9 +1020,21 @@ func GetExtendedSpatialIdsOnCylinders(
 		opt(p)
 	}
 
+	// ログ出力先(未指定の場合はnilとし、ログ出力を行わない)
+	var log *slog.Logger
+	var begin time.Time
+	if p.LogHandler != nil {
+		log = slog.New(p.LogHandler)
+		begin = time.Now()
+	}
+
 	// 空間IDを格納するスライス
 	spatialIDs := []string{}
 
+	// 経路全体の処理段階の観測開始
+	endRoute := startStage(p.Observer, StageRoute, RouteSegment)
+	defer func() { endRoute(StageResult{Count: len(spatialIDs)}) }()
+
 	// 入力値チェック
 	// 引数のポインタにnilがある場合
 	if common.Include(center, nil) {
@@ -710,47 +1050,151 @@ func GetExtendedSpatialIdsOnCylinders(
 
 		// 半径が0以下の場合は例外を投げる
 	} else if radius <= consts.Minima {
-		logger.Debug("半径が0以下")
+		debugLog(log, "半径が0以下", slog.Float64("radius", radius))
+		return spatialIDs, errors.NewSpatialIdError(
+			errors.InputValueErrorCode, "",
+		)
+
+		// 旋回半径が負の場合は例外を投げる
+	} else if p.TurnRadius < 0 {
+		debugLog(log, "旋回半径が負の値", slog.Float64("turnRadius", p.TurnRadius))
+		return spatialIDs, errors.NewSpatialIdError(
+			errors.InputValueErrorCode, "",
+		)
+
+		// 位置の不確かさと旋回の円弧は併用できない
+	} else if p.TurnRadius > 0 && (p.Uncertainties != nil || p.UncertaintyGrowth != (Uncertainty{})) {
+		debugLog(log, "位置の不確かさと旋回半径は併用できない")
 		return spatialIDs, errors.NewSpatialIdError(
 			errors.InputValueErrorCode, "",
 		)
 
 		// 接続点数が0の場合は空配列を返却
 	} else if len(center) == 0 {
-		logger.Debug("接続点数が0個")
+		debugLog(log, "接続点数が0個")
 		return spatialIDs, nil
 	}
 
+	// 接続点の高さを楕円体高に変換
+	if p.Altitude != AltitudeEllipsoidal {
+		terrain := p.AboveGround
+		if terrain == nil {
+			terrain = p.Terrain
+		}
+		converted, err := ConvertAltitudePoints(center, p.Altitude, p.Geoid, terrain)
+		if err != nil {
+			debugLog(log, "接続点の高さを変換できません", slog.Int("altitude", int(p.Altitude)))
+			return spatialIDs, err
+		}
+		center = converted
+	}
+
+	// 接続点ごとの位置の不確かさ(nilの場合は一定の半径とする)
+	var uncertainties []Uncertainty
+	if p.Uncertainties != nil || p.UncertaintyGrowth != (Uncertainty{}) {
+		calculated, err := calcUncertainties(center, p.Uncertainties, p.UncertaintyGrowth)
+		if err != nil {
+			debugLog(log, "位置の不確かさが不正")
+			return spatialIDs, err
+		}
+		uncertainties = calculated
+	}
+
+	// 接続点間を測地線に沿って分割
+	if p.GeodesicInterval != 0 {
+		densified, err := DensifyGeodesic(center, p.GeodesicInterval)
+		if err != nil {
+			debugLog(log, "接続点間を測地線に沿って分割できません", slog.Float64("interval", p.GeodesicInterval))
+			return spatialIDs, err
+		}
+		debugLog(log, "測地線に沿った分割", slog.Int("points", len(densified)))
+		if uncertainties != nil {
+			uncertainties = interpolateUncertainties(center, uncertainties, densified)
+		}
+		center = densified
+	}
+
+	// 緯度がWebメルカトル投影の範囲外の場合は空間IDを取得できない
+	if err := checkMercatorLatitude(center); err != nil {
+		debugLog(log, "緯度がWebメルカトル投影の範囲外")
+		return spatialIDs, err
+	}
+
 	// メルカトル距離補正
 	radian := common.DegreeToRadian(center[0].Lat())
 	factor := 1 / math.Cos(radian)
 
-	logger.Debug("メルカトル係数: %v", factor)
+	debugLog(log, "メルカトル係数", slog.Float64("factor", factor))
+
+	// 接続点の角を旋回半径の円弧に置き換え
+	arcs := map[int]*turnArc{}
+	if p.TurnRadius > 0 {
+		smoothed, smoothedArcs, err := smoothCorners(center, p.TurnRadius, factor)
+		if err != nil {
+			return spatialIDs, err
+		}
+		debugLog(log, "旋回の円弧に置き換え", slog.Int("arcs", len(smoothedArcs)), slog.Int("points", len(smoothed)))
+		center, arcs = smoothed, smoothedArcs
+	}
 
 	// 接続点の球
 	sphere := new(Capsule)
+	// 接続点の球が経度を180度ずらした座標系であるか
+	sphereShifted := false
 	// 接続点数
 	connectPointNum := 1
 	// 入力の接続点数-1の数だけループ
 	for i := range center[:len(center)-1] {
 		start := center[i]
 		end := center[i+1]
-		logger.Debug("接続点: %d～%d", connectPointNum, connectPointNum+1)
-		logger.Debug("始点(地理座標系): %f, %f, %f", start.Lon(), start.Lat(), start.Alt())
-		logger.Debug("終点(地理座標系): %f, %f, %f", end.Lon(), end.Lat(), end.Alt())
+		debugLog(
+			log,
+			"接続点",
+			slog.Int("segment", i),
+			slog.Float64("startLon", start.Lon()),
+			slog.Float64("startLat", start.Lat()),
+			slog.Float64("startAlt", start.Alt()),
+			slog.Float64("endLon", end.Lon()),
+			slog.Float64("endLat", end.Lat()),
+			slog.Float64("endAlt", end.Alt()),
+		)
 
 		// 同じ座標が連続する場合は次の座標へスキップ
 		if start == end {
-			logger.Debug("同一座標の接続点が連続しているためスキップ")
+			debugLog(log, "同一座標の接続点が連続しているためスキップ", slog.Int("segment", i))
 			continue
 		}
 
 		// 接続点数をインクリメント
 		connectPointNum++
 
+		// 旋回の円弧の区間は円弧に沿った管の空間IDを取得する
+		if turn, ok := arcs[i]; ok {
+			arc := NewArc(turn.center, turn.start, turn.end, radius, hZoom, vZoom, p.IsPrecision, factor)
+			arc.logger = log
+			arc.SetObserver(p.Observer, i)
+			arcSpatialIDs, _ := arc.CalcValidSpatialIDs()
+			arcSpatialIDs = wrapExtendedSpatialIDs(arcSpatialIDs, hZoom, vZoom, turn.shifted)
+			debugLog(log, "旋回の円弧の空間ID", slog.Int("segment", i), slog.Int("acceptedIDs", len(arcSpatialIDs)))
+			spatialIDs = common.Union(arcSpatialIDs, spatialIDs)
+			continue
+		}
+
+		// 経度180度をまたがる区間は経度を180度ずらした座標系で空間IDを取得する
+		segmentPoints := []*object.Point{start, end}
+		shifted := isAntimeridianFrame(start, end)
+		if shifted {
+			shiftedPoints, err := shiftLongitudes(start, end)
+			if err != nil {
+				return []string{}, err
+			}
+			segmentPoints = shiftedPoints
+			debugLog(log, "経度を180度ずらした座標系", slog.Int("segment", i))
+		}
+
 		// 【直交座標空間】始点終点の座標
 		crsPoints, _ := shape.ConvertPointListToProjectedPointList(
-			[]*object.Point{start, end},
+			segmentPoints,
 			consts.OrthCrs,
 		)
 
@@ -764,15 +1208,43 @@ func GetExtendedSpatialIdsOnCylinders(
 			Y: crsPoints[1].Y,
 			Z: crsPoints[1].Alt * factor,
 		}
-		logger.Debug("始点(投影座標系): %f, %f, %f", startOrth.X, startOrth.Y, startOrth.Z)
-		logger.Debug("終点(投影座標系): %f, %f, %f", endOrth.X, endOrth.Y, endOrth.Z)
+
+		// 区間の処理時間計測開始
+		var segmentBegin time.Time
+		if log != nil {
+			segmentBegin = time.Now()
+		}
+
+		// 位置の不確かさを指定した場合は半径が変化する管の空間IDを取得する
+		if uncertainties != nil {
+			envelope := NewEnvelope(
+				startOrth,
+				endOrth,
+				inflateRadius(radius, uncertainties[i]),
+				inflateRadius(radius, uncertainties[i+1]),
+				hZoom,
+				vZoom,
+				p.IsPrecision,
+				factor,
+			)
+			envelope.logger = log
+			envelope.SetObserver(p.Observer, i)
+			envelopeSpatialIDs, _ := envelope.CalcValidSpatialIDs()
+			envelopeSpatialIDs = wrapExtendedSpatialIDs(envelopeSpatialIDs, hZoom, vZoom, shifted)
+			debugLog(log, "半径が変化する管の空間ID", slog.Int("segment", i), slog.Int("acceptedIDs", len(envelopeSpatialIDs)))
+			spatialIDs = common.Union(envelopeSpatialIDs, spatialIDs)
+			continue
+		}
 
 		if !sphere.IsEmpty() {
 
 			shaveSphereSpatialIDs, _ := sphere.CalcValidSpatialIDs()
-			logger.Debug(
-				"接続点の空間ID: %s",
-				reflect.ValueOf(shaveSphereSpatialIDs),
+			shaveSphereSpatialIDs = wrapExtendedSpatialIDs(shaveSphereSpatialIDs, hZoom, vZoom, sphereShifted)
+			debugLog(
+				log,
+				"接続点の空間ID",
+				slog.Int("segment", i),
+				slog.Int("acceptedIDs", len(shaveSphereSpatialIDs)),
 			)
 			spatialIDs = common.Union(shaveSphereSpatialIDs, spatialIDs)
 		}
@@ -788,18 +1260,32 @@ func GetExtendedSpatialIdsOnCylinders(
 			p.IsPrecision,
 			factor,
 		)
+		capsule.logger = log
+		capsule.SetObserver(p.Observer, i)
 		capsuleSpatialIDs, _ := capsule.CalcValidSpatialIDs()
-		logger.Debug(
-			"接続点間の空間ID: %s",
-			reflect.ValueOf(capsuleSpatialIDs),
-		)
+		capsuleSpatialIDs = wrapExtendedSpatialIDs(capsuleSpatialIDs, hZoom, vZoom, shifted)
 		// マージ処理
 		spatialIDs = common.Union(capsuleSpatialIDs, spatialIDs)
+		if log != nil {
+			debugLog(
+				log,
+				"接続点間の空間ID",
+				slog.Int("segment", i),
+				slog.Int("acceptedIDs", len(capsuleSpatialIDs)),
+				slog.Int("totalIDs", len(spatialIDs)),
+				slog.Duration("elapsed", time.Since(segmentBegin)),
+			)
+		}
 
 		// 終点もしくはカプセルの場合は接続点の空間IDは取得しない
 		if i == len(center[:len(center)-1])-1 || isCapsule {
 			continue
 		}
+		// 次の区間が旋回の円弧の場合は滑らかにつながるため接続点の空間IDは取得しない
+		if _, ok := arcs[i+1]; ok {
+			sphere = new(Capsule)
+			continue
+		}
 
 		// 【直交座標空間】接続点の球の空間ID取得
 		sphere = NewCapsule(
@@ -812,48 +1298,102 @@ func GetExtendedSpatialIdsOnCylinders(
 			p.IsPrecision,
 			factor,
 		)
+		sphere.logger = log
+		sphere.SetObserver(p.Observer, i)
+		sphereShifted = shifted
 	}
 
 	// 接続点数が1の場合
 	if connectPointNum == 1 {
-		logger.Debug("接続点数が1個")
+		debugLog(log, "接続点数が1個")
+
+		// 経度180度に近い場合は経度を180度ずらした座標系で空間IDを取得する
+		centerPoints := []*object.Point{center[0]}
+		shifted := isAntimeridianFrame(center[0])
+		if shifted {
+			shiftedPoints, err := shiftLongitudes(center[0])
+			if err != nil {
+				return []string{}, err
+			}
+			centerPoints = shiftedPoints
+		}
 
 		// 【直交座標空間】中心の座標
 		orthCenters, _ := shape.ConvertPointListToProjectedPointList(
-			[]*object.Point{center[0]}, consts.OrthCrs,
+			centerPoints, consts.OrthCrs,
 		)
 		orthCenter := spatial.Point3{
 			X: orthCenters[0].X,
 			Y: orthCenters[0].Y,
 			Z: orthCenters[0].Alt * factor,
 		}
-		logger.Debug(
-			"中心座標(地理座標系): %f, %f, %f",
-			center[0].Lon(), center[0].Lat(), center[0].Alt(),
-		)
-		logger.Debug(
-			"中心座標(投影座標系): %f, %f, %f",
-			orthCenter.X, orthCenter.Y, orthCenter.Z,
+		debugLog(
+			log,
+			"中心座標",
+			slog.Float64("lon", center[0].Lon()),
+			slog.Float64("lat", center[0].Lat()),
+			slog.Float64("alt", center[0].Alt()),
 		)
 
 		// 【直交座標空間】接続点の球の空間ID取得
-		sphere = NewCapsule(
-			orthCenter,
-			orthCenter,
-			radius,
-			hZoom,
-			vZoom,
-			isCapsule,
-			p.IsPrecision,
-			factor,
+		if uncertainties != nil {
+			envelope := NewEnvelope(
+				orthCenter,
+				orthCenter,
+				inflateRadius(radius, uncertainties[0]),
+				inflateRadius(radius, uncertainties[0]),
+				hZoom,
+				vZoom,
+				p.IsPrecision,
+				factor,
+			)
+			envelope.logger = log
+			envelope.SetObserver(p.Observer, 0)
+			spatialIDs, _ = envelope.CalcValidSpatialIDs()
+		} else {
+			sphere = NewCapsule(
+				orthCenter,
+				orthCenter,
+				radius,
+				hZoom,
+				vZoom,
+				isCapsule,
+				p.IsPrecision,
+				factor,
+			)
+			sphere.logger = log
+			sphere.SetObserver(p.Observer, 0)
+			spatialIDs, _ = sphere.CalcValidSpatialIDs()
+		}
+		spatialIDs = wrapExtendedSpatialIDs(spatialIDs, hZoom, vZoom, shifted)
+		debugLog(log, "球の空間ID", slog.Int("acceptedIDs", len(spatialIDs)))
+	}
+
+	spatialIDs = common.Unique(spatialIDs)
+
+	// 地中の空間IDを除外
+	if p.Terrain != nil {
+		aboveIDs, undergroundIDs, err := ClassifyExtendedSpatialIdsByTerrain(
+			spatialIDs, ellipsoidalTerrainModel(p.Terrain, p.Geoid),
 		)
-		spatialIDs, _ = sphere.CalcValidSpatialIDs()
-		logger.Debug(
-			"球の空間ID: %s",
-			reflect.ValueOf(spatialIDs),
+		if err != nil {
+			debugLog(log, "地形による空間IDの分類ができません")
+			return []string{}, err
+		}
+		debugLog(log, "地中の空間IDを除外", slog.Int("undergroundIDs", len(undergroundIDs)))
+		spatialIDs = aboveIDs
+	}
+
+	if log != nil {
+		debugLog(
+			log,
+			"拡張空間ID(円柱)取得",
+			slog.Int("segments", connectPointNum-1),
+			slog.Int("acceptedIDs", len(spatialIDs)),
+			slog.Duration("elapsed", time.Since(begin)),
 		)
 	}
 
-	return common.Unique(spatialIDs), nil
+	return spatialIDs, nil
 
 }
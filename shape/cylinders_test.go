@@ -105,6 +105,291 @@ func TestGetSpatialIDOnAxisIDs01(t *testing.T) {
 	t.Log("テスト終了")
 }
 
+// TestGetNeighbourExtendedSpatialIds01 正常系動作確認(グリッド内部)
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：20/931339/412910/20/3
+//   - 隣接の種類：6、18、26
+//
+// + 確認内容
+//   - 6近傍の場合、面で接する6個の拡張空間IDが取得できること
+//   - 18近傍、26近傍の場合、18個、26個の拡張空間IDが取得できること
+func TestGetNeighbourExtendedSpatialIds01(t *testing.T) {
+	//入力パラメータ
+	spatialID := "20/931339/412910/20/3"
+	expectVal := []string{
+		"20/931338/412910/20/3",
+		"20/931339/412909/20/3",
+		"20/931339/412910/20/2",
+		"20/931339/412910/20/4",
+		"20/931339/412911/20/3",
+		"20/931340/412910/20/3",
+	}
+
+	// テスト対象呼び出し
+	resultVal, err := GetNeighbourExtendedSpatialIds(spatialID, Connectivity6)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	if !reflect.DeepEqual(expectVal, resultVal) {
+		t.Errorf("6近傍 - 期待値：%v, 取得値：%v", expectVal, resultVal)
+	}
+
+	for _, connectivity := range []Connectivity{Connectivity18, Connectivity26} {
+		resultVal, err := GetNeighbourExtendedSpatialIds(spatialID, connectivity)
+		if err != nil {
+			t.Fatalf("%d近傍 error - 期待値：nil, 取得値：%v", connectivity, err)
+		}
+		if len(resultVal) != int(connectivity) {
+			t.Errorf("%d近傍 - 期待値：%v個, 取得値：%v個", connectivity, connectivity, len(resultVal))
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestGetNeighbourExtendedSpatialIds02 正常系動作確認(グリッドの端)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：精度2の全ての拡張空間ID(X：0～3、Y：0～3、高さ：-4～3)
+//   - パターン2：0/0/0/0/0(精度0)
+//   - パターン3：1/0/0/1/0(精度1)
+//   - 隣接の種類：6、18、26
+//
+// + 確認内容
+//   - パターン1：成分インデックスの差分から求めた隣接する拡張空間IDと一致すること
+//     (X成分は折り返し、Y成分、高さ成分が範囲外となる拡張空間IDと自身は除く)
+//   - パターン2：6近傍の場合、高さ成分の範囲(-1～0)内の下側の1個のみとなること
+//   - パターン3：6近傍の場合、東西の折り返しが1個に重複せず、4個となること
+func TestGetNeighbourExtendedSpatialIds02(t *testing.T) {
+	//入力パラメータ
+	var zoom int64 = 2
+	size := int64(1) << zoom
+
+	for _, connectivity := range []Connectivity{Connectivity6, Connectivity18, Connectivity26} {
+		limit, _ := connectivityLimit(connectivity)
+		for x := int64(0); x < size; x++ {
+			for y := int64(0); y < size; y++ {
+				for f := -size; f < size; f++ {
+					expectSet := map[string]bool{}
+					for dx := int64(-1); dx <= 1; dx++ {
+						for dy := int64(-1); dy <= 1; dy++ {
+							for df := int64(-1); df <= 1; df++ {
+								num := 0
+								for _, d := range []int64{dx, dy, df} {
+									if d != 0 {
+										num++
+									}
+								}
+								nx, ny, nf := ((x+dx)%size+size)%size, y+dy, f+df
+								if num > limit || ny < 0 || ny >= size || nf < -size || nf >= size ||
+									(nx == x && ny == y && nf == f) {
+									continue
+								}
+								expectSet[GetSpatialIDOnAxisIDs(nx, ny, nf, zoom, zoom)] = true
+							}
+						}
+					}
+					expectVal := []string{}
+					for spatialID := range expectSet {
+						expectVal = append(expectVal, spatialID)
+					}
+					sort.Strings(expectVal)
+					spatialID := GetSpatialIDOnAxisIDs(x, y, f, zoom, zoom)
+
+					// テスト対象呼び出し
+					resultVal, err := GetNeighbourExtendedSpatialIds(spatialID, connectivity)
+					if err != nil {
+						t.Fatalf("%v(%d近傍) error - 期待値：nil, 取得値：%v", spatialID, connectivity, err)
+					}
+					if !reflect.DeepEqual(expectVal, resultVal) {
+						t.Errorf("%v(%d近傍) - 期待値：%v, 取得値：%v", spatialID, connectivity, expectVal, resultVal)
+					}
+				}
+			}
+		}
+	}
+
+	inputs := []struct {
+		spatialID string
+		expect    []string
+	}{
+		{"0/0/0/0/0", []string{"0/0/0/0/-1"}},
+		{"1/0/0/1/0", []string{"1/0/0/1/-1", "1/0/0/1/1", "1/0/1/1/0", "1/1/0/1/0"}},
+	}
+	for i, input := range inputs {
+		resultVal, err := GetNeighbourExtendedSpatialIds(input.spatialID, Connectivity6)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+2, err)
+		}
+		if !reflect.DeepEqual(input.expect, resultVal) {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+2, input.expect, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestGetNeighbourExtendedSpatialIdsOnZoom01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：20/931339/412910/20/3(親ボクセルの東、Y成分の小さい側、上側の子ボクセル)
+//   - パターン1：精度21
+//   - パターン2：精度19
+//   - パターン3：精度20
+//   - パターン4：1/0/0/1/0を精度2
+//   - パターン5：精度26(6段階細かい精度)
+//
+// + 確認内容
+//   - パターン1：6、18、26近傍の場合、24個、48個、56個の拡張空間IDが取得できること
+//   - パターン2：6、18、26近傍の場合、3個、6個、7個の拡張空間IDが取得でき、親ボクセルを含まないこと
+//   - パターン3：GetNeighbourExtendedSpatialIdsと一致すること
+//   - パターン4：6近傍の場合、経度180度をまたがる西側の4個を含むこと
+//   - パターン5：6、18、26近傍の場合、6面の64×64個、12辺の64個、8頂点の1個の合計が取得できること
+func TestGetNeighbourExtendedSpatialIdsOnZoom01(t *testing.T) {
+	//入力パラメータ
+	spatialID := "20/931339/412910/20/3"
+	inputs := []struct {
+		pattern int
+		zoom    int64
+		counts  []int
+	}{
+		{1, 21, []int{24, 48, 56}},
+		{2, 19, []int{3, 6, 7}},
+		{5, 26, []int{6 * 64 * 64, 6*64*64 + 12*64, 6*64*64 + 12*64 + 8}},
+	}
+
+	for _, input := range inputs {
+		for j, connectivity := range []Connectivity{Connectivity6, Connectivity18, Connectivity26} {
+			// テスト対象呼び出し
+			resultVal, err := GetNeighbourExtendedSpatialIdsOnZoom(spatialID, connectivity, input.zoom, input.zoom)
+			if err != nil {
+				t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", input.pattern, err)
+			}
+			if len(resultVal) != input.counts[j] || common.Include(resultVal, "19/465669/206455/19/1") {
+				t.Errorf("パターン%d(%d近傍) - 期待値：%v個, 取得値：%v個", input.pattern, connectivity, input.counts[j], len(resultVal))
+			}
+		}
+	}
+
+	for _, connectivity := range []Connectivity{Connectivity6, Connectivity18, Connectivity26} {
+		expectVal, _ := GetNeighbourExtendedSpatialIds(spatialID, connectivity)
+		resultVal, err := GetNeighbourExtendedSpatialIdsOnZoom(spatialID, connectivity, 20, 20)
+		if err != nil {
+			t.Fatalf("パターン3 error - 期待値：nil, 取得値：%v", err)
+		}
+		if !reflect.DeepEqual(expectVal, resultVal) {
+			t.Errorf("パターン3(%d近傍) - 期待値：%v, 取得値：%v", connectivity, expectVal, resultVal)
+		}
+	}
+
+	resultVal, err := GetNeighbourExtendedSpatialIdsOnZoom("1/0/0/1/0", Connectivity6, 2, 2)
+	if err != nil {
+		t.Fatalf("パターン4 error - 期待値：nil, 取得値：%v", err)
+	}
+	west := []string{"2/3/0/2/0", "2/3/0/2/1", "2/3/1/2/0", "2/3/1/2/1"}
+	if missing := common.Difference(west, resultVal); len(missing) != 0 {
+		t.Errorf("パターン4 - 含まれない拡張空間ID：%v", missing)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestIsAdjacentExtendedSpatialIds01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 面、辺、頂点で接する拡張空間ID、同じ拡張空間ID、親ボクセル、離れた拡張空間ID
+//   - 精度の異なる拡張空間ID、経度180度をまたがる拡張空間ID
+//   - Y成分、高さ成分の両端の拡張空間ID
+//
+// + 確認内容
+//   - 隣接の種類に応じて判定されること
+//   - 同じ拡張空間ID、親ボクセルは隣接と判定されないこと
+//   - X成分は折り返して隣接と判定され、Y成分、高さ成分は折り返さないこと
+func TestIsAdjacentExtendedSpatialIds01(t *testing.T) {
+	//入力パラメータ
+	spatialID := "20/931339/412910/20/3"
+	inputs := []struct {
+		spatialID1 string
+		spatialID2 string
+		expect     [3]bool // 6、18、26近傍の判定結果
+	}{
+		{spatialID, "20/931340/412910/20/3", [3]bool{true, true, true}},
+		{spatialID, "20/931340/412911/20/3", [3]bool{false, true, true}},
+		{spatialID, "20/931340/412911/20/4", [3]bool{false, false, true}},
+		{spatialID, spatialID, [3]bool{false, false, false}},
+		{spatialID, "19/465669/206455/19/1", [3]bool{false, false, false}},
+		{spatialID, "20/931341/412910/20/3", [3]bool{false, false, false}},
+		{spatialID, "21/1862680/825820/21/6", [3]bool{true, true, true}},
+		{"3/0/2/3/0", "3/7/2/3/0", [3]bool{true, true, true}},
+		{"2/0/0/2/0", "2/0/3/2/0", [3]bool{false, false, false}},
+		{"2/0/0/2/-4", "2/0/0/2/3", [3]bool{false, false, false}},
+	}
+
+	for i, input := range inputs {
+		for j, connectivity := range []Connectivity{Connectivity6, Connectivity18, Connectivity26} {
+			// テスト対象呼び出し
+			resultVal, err := IsAdjacentExtendedSpatialIds(input.spatialID1, input.spatialID2, connectivity)
+			if err != nil {
+				t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+			}
+			reverse, _ := IsAdjacentExtendedSpatialIds(input.spatialID2, input.spatialID1, connectivity)
+			if resultVal != input.expect[j] || reverse != resultVal {
+				t.Errorf("パターン%d(%d近傍) - 期待値：%v, 取得値：%v, %v", i+1, connectivity, input.expect[j], resultVal, reverse)
+			}
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestGetNeighbourExtendedSpatialIds03 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：隣接の種類が7
+//   - パターン2：拡張空間IDのフォーマットが不正
+//   - パターン3：X成分インデックスが範囲外
+//   - パターン4：取得する精度が36(GetNeighbourExtendedSpatialIdsOnZoomのみ)
+//   - パターン5：0/0/0/0/0を精度35(maxNeighbourZoomDifferenceを超えて細かい精度、GetNeighbourExtendedSpatialIdsOnZoomのみ)
+//
+// + 確認内容
+//   - エラーが返却されること
+func TestGetNeighbourExtendedSpatialIds03(t *testing.T) {
+	//入力パラメータ
+	inputs := []struct {
+		spatialID    string
+		connectivity Connectivity
+	}{
+		{"20/931339/412910/20/3", Connectivity(7)},
+		{"20/931339/412910/20", Connectivity6},
+		{"20/1048576/412910/20/3", Connectivity6},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, err := GetNeighbourExtendedSpatialIds(input.spatialID, input.connectivity)
+		_, zoomErr := GetNeighbourExtendedSpatialIdsOnZoom(input.spatialID, input.connectivity, 20, 20)
+		_, adjacentErr := IsAdjacentExtendedSpatialIds(input.spatialID, "20/931339/412910/20/3", input.connectivity)
+		if err == nil || zoomErr == nil || adjacentErr == nil {
+			t.Errorf("パターン%d error - 期待値：エラー, 取得値：%v, %v, %v", i+1, err, zoomErr, adjacentErr)
+		}
+	}
+
+	if _, err := GetNeighbourExtendedSpatialIdsOnZoom("20/931339/412910/20/3", Connectivity6, 36, 20); err == nil {
+		t.Errorf("パターン4 error - 期待値：エラー, 取得値：nil")
+	}
+	if _, err := GetNeighbourExtendedSpatialIdsOnZoom("0/0/0/0/0", Connectivity26, 35, 35); err == nil {
+		t.Errorf("パターン5 error - 期待値：エラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
+
 // TestGetVoxelIDToSpatialID01 正常系動作確認
 //
 // 試験詳細：
@@ -2834,7 +3119,7 @@ func TestCalcValidSpatialIDs03(t *testing.T) {
 //     IsPrecisionOpts構造体の衝突判定実施オプションが更新されること
 func TestIsPrecision01(t *testing.T) {
 	// 衝突判定フラグ(shape.IsPrecisionOpts)をfalseで初期化
-	val := IsPrecisionOpts{false}
+	val := IsPrecisionOpts{IsPrecision: false}
 
 	// テスト対象呼出し
 	resultVal := IsPrecision(true)
@@ -2873,7 +3158,7 @@ func TestIsPrecision01(t *testing.T) {
 func TestIsPrecision02(t *testing.T) {
 
 	// 衝突判定フラグ(shape.IsPrecisionOpts)をtrueで初期化
-	val := IsPrecisionOpts{true}
+	val := IsPrecisionOpts{IsPrecision: true}
 
 	// テスト対象呼出し
 	resultVal := IsPrecision(false)
@@ -4363,3 +4648,76 @@ func TestGetExtendedSpatialIdsOnCylinders21(t *testing.T) {
 
 	t.Log("テスト終了")
 }
+
+// TestEstimateExtendedSpatialIdsOnCylinders01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：turnRouteの接続点、turnRouteの始点のみ
+//   - 半径、精度：(2.0, 25)、(10.0, 20)、(200.0, 20)
+//
+// + 確認内容
+//   - 見積もりがGetExtendedSpatialIdsOnCylindersの拡張空間IDの数以上、10倍以下であること
+func TestEstimateExtendedSpatialIdsOnCylinders01(t *testing.T) {
+	//入力パラメータ
+	inputs := []struct {
+		radius float64
+		zoom   int64
+	}{
+		{2.0, 25},
+		{10.0, 20},
+		{200.0, 20},
+	}
+
+	for _, center := range [][]*object.Point{turnRoute(), turnRoute()[:1]} {
+		for _, input := range inputs {
+			expectVal, _ := GetExtendedSpatialIdsOnCylinders(center, input.radius, input.zoom, input.zoom, false)
+
+			// テスト対象呼び出し
+			resultVal, err := EstimateExtendedSpatialIdsOnCylinders(center, input.radius, input.zoom, input.zoom)
+			if err != nil {
+				t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+			}
+
+			if resultVal < float64(len(expectVal)) || resultVal > float64(len(expectVal))*10 {
+				t.Errorf("接続点%d個 半径%v - 期待値：%v～%v, 取得値：%v",
+					len(center), input.radius, len(expectVal), len(expectVal)*10, resultVal)
+			}
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestEstimateExtendedSpatialIdsOnCylinders02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：半径が0
+//   - パターン2：精度が36
+//   - パターン3：接続点にnilを含む
+//
+// + 確認内容
+//   - エラーが返却されること
+func TestEstimateExtendedSpatialIdsOnCylinders02(t *testing.T) {
+	//入力パラメータ
+	inputs := []struct {
+		center []*object.Point
+		radius float64
+		zoom   int64
+	}{
+		{turnRoute(), 0, 20},
+		{turnRoute(), 10.0, 36},
+		{append(turnRoute(), nil), 10.0, 20},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, err := EstimateExtendedSpatialIdsOnCylinders(input.center, input.radius, input.zoom, input.zoom)
+		if err == nil {
+			t.Errorf("パターン%d error - 期待値：エラー, 取得値：nil", i+1)
+		}
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,246 @@
+package shape
+
+import (
+	"math"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// WGS84楕円体のパラメータ
+const (
+	wgs84A             = 6378137.0             // 長半径(単位:m)
+	wgs84F             = 1 / 298.257223563     // 扁平率
+	wgs84B             = wgs84A * (1 - wgs84F) // 短半径(単位:m)
+	vincentyEpsilon    = 1e-12                 // Vincenty法の収束判定の閾値
+	vincentyMaxIterate = 200                   // Vincenty法の反復回数の上限
+)
+
+// maxGeodesicLegPoints 測地線に沿った分割で許容する、1区間あたりの分割数の最大値
+const maxGeodesicLegPoints = 1 << 16
+
+// Geodesic 測地線に沿った経路設定関数
+//
+// 以下の関数の接続点間を、WGS84楕円体上の測地線に沿って指定間隔以下に分割してから空間IDを取得する。
+// 未指定の場合は接続点間をWebメルカトル投影上の直線として扱うため、長い区間では測地線から外れる。
+//   - GetSpatialIdsOnCylinders
+//   - GetExtendedSpatialIdsOnCylinders
+//
+// 引数：
+//
+//	interval: 分割する間隔(単位:m)。0の場合は分割しない
+//
+// 戻り値：
+//
+//	衝突判定実施オプショナル型の関数
+func Geodesic(interval float64) option {
+	return func(p *IsPrecisionOpts) {
+		p.GeodesicInterval = interval
+	}
+}
+
+// DensifyGeodesic 測地線に沿った接続点の分割
+//
+// 接続点間をWGS84楕円体上の測地線に沿って等間隔に分割し、分割点を追加する。
+// 分割点の高さは区間の始点、終点の高さを線形補間した値とする。
+// 元の接続点は同じPointのまま返却する。
+//
+// 引数：
+//
+//	points： 接続点
+//	interval： 分割する間隔の上限(単位:m)
+//
+// 戻り値：
+//
+//	分割点を追加した接続点
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力値不正： 接続点にnilがある場合、分割する間隔が0以下、NaN、+Infの場合、
+//	             もしくは区間の分割数がmaxGeodesicLegPointsを超える場合
+//	 値変換エラー： 対蹠点に近い区間で測地線を求められない場合
+func DensifyGeodesic(points []*object.Point, interval float64) ([]*object.Point, error) {
+	densified, _, err := densifyGeodesicLegs(points, interval)
+	return densified, err
+}
+
+// densifyGeodesicLegs 測地線に沿った接続点の分割と、分割前の区間の番号の取得
+//
+// 引数：
+//
+//	points： 接続点
+//	interval： 分割する間隔の上限(単位:m)
+//
+// 戻り値：
+//
+//	分割点を追加した接続点
+//	分割後の区間ごとの、分割前の区間の番号
+//
+// 戻り値(エラー)：
+//
+//	DensifyGeodesicと同じ
+func densifyGeodesicLegs(points []*object.Point, interval float64) ([]*object.Point, []int, error) {
+	if common.Include(points, nil) || !(interval > 0 && !math.IsInf(interval, 1)) {
+		return nil, nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	if len(points) == 0 {
+		return []*object.Point{}, []int{}, nil
+	}
+
+	densified := []*object.Point{points[0]}
+	legs := []int{}
+	for i := range points[:len(points)-1] {
+		start, end := points[i], points[i+1]
+		distance, azimuth, err := geodesicInverse(start.Lon(), start.Lat(), end.Lon(), end.Lat())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		count := math.Ceil(distance / interval)
+		if count > maxGeodesicLegPoints {
+			return nil, nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "区間の分割数が多すぎます")
+		}
+		n := int(count)
+		for k := 1; k < n; k++ {
+			ratio := float64(k) / float64(n)
+			lon, lat := geodesicDirect(start.Lon(), start.Lat(), azimuth, distance*ratio)
+			point, err := object.NewPoint(lon, lat, start.Alt()+(end.Alt()-start.Alt())*ratio)
+			if err != nil {
+				return nil, nil, err
+			}
+			densified = append(densified, point)
+			legs = append(legs, i)
+		}
+		densified = append(densified, end)
+		legs = append(legs, i)
+	}
+
+	return densified, legs, nil
+}
+
+// geodesicInverse 測地線の逆問題
+//
+// Vincenty法により2点間の測地線の長さと始点での方位角を求める。
+//
+// 引数：
+//
+//	lon1, lat1： 始点の経度、緯度
+//	lon2, lat2： 終点の経度、緯度
+//
+// 戻り値：
+//
+//	測地線の長さ(単位:m)
+//	始点での方位角(北から時計回り。単位:rad)
+//
+// 戻り値(エラー)：
+//
+//	対蹠点に近く反復が収束しない場合、エラーインスタンスが返却される。
+func geodesicInverse(lon1, lat1, lon2, lat2 float64) (distance, azimuth float64, err error) {
+	u1 := math.Atan((1 - wgs84F) * math.Tan(common.DegreeToRadian(lat1)))
+	u2 := math.Atan((1 - wgs84F) * math.Tan(common.DegreeToRadian(lat2)))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+	l := common.DegreeToRadian(lon2 - lon1)
+
+	lambda := l
+	var sinSigma, cosSigma, sigma, cos2Alpha, cos2SigmaM, sinLambda, cosLambda float64
+	for i := 0; ; i++ {
+		if i == vincentyMaxIterate {
+			return 0, 0, errors.NewSpatialIdError(errors.ValueConvertErrorCode, "測地線を求められません")
+		}
+
+		sinLambda, cosLambda = math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			// 同一地点
+			return 0, 0, nil
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0
+		if cos2Alpha != 0 {
+			// 赤道上の測地線以外
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		c := wgs84F / 16 * cos2Alpha * (4 + wgs84F*(4-3*cos2Alpha))
+		previous := lambda
+		lambda = l + (1-c)*wgs84F*sinAlpha*
+			(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-previous) < vincentyEpsilon {
+			break
+		}
+	}
+
+	a, b := vincentyCoefficients(cos2Alpha)
+	deltaSigma := vincentyDeltaSigma(b, sinSigma, cosSigma, cos2SigmaM)
+	distance = wgs84B * a * (sigma - deltaSigma)
+	azimuth = math.Atan2(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+	return distance, azimuth, nil
+}
+
+// geodesicDirect 測地線の順問題
+//
+// Vincenty法により始点から方位角、距離を指定した地点を求める。
+//
+// 引数：
+//
+//	lon1, lat1： 始点の経度、緯度
+//	azimuth： 始点での方位角(北から時計回り。単位:rad)
+//	distance： 距離(単位:m)
+//
+// 戻り値：
+//
+//	地点の経度、緯度。経度は-180～180度に正規化する
+func geodesicDirect(lon1, lat1, azimuth, distance float64) (lon2, lat2 float64) {
+	sinAlpha1, cosAlpha1 := math.Sincos(azimuth)
+	tanU1 := (1 - wgs84F) * math.Tan(common.DegreeToRadian(lat1))
+	cosU1 := 1 / math.Sqrt(1+tanU1*tanU1)
+	sinU1 := tanU1 * cosU1
+	sigma1 := math.Atan2(tanU1, cosAlpha1)
+	sinAlpha := cosU1 * sinAlpha1
+	cos2Alpha := 1 - sinAlpha*sinAlpha
+
+	a, b := vincentyCoefficients(cos2Alpha)
+	sigma := distance / (wgs84B * a)
+	var sinSigma, cosSigma, cos2SigmaM float64
+	for i := 0; i < vincentyMaxIterate; i++ {
+		cos2SigmaM = math.Cos(2*sigma1 + sigma)
+		sinSigma, cosSigma = math.Sincos(sigma)
+		previous := sigma
+		sigma = distance/(wgs84B*a) + vincentyDeltaSigma(b, sinSigma, cosSigma, cos2SigmaM)
+		if math.Abs(sigma-previous) < vincentyEpsilon {
+			break
+		}
+	}
+	cos2SigmaM = math.Cos(2*sigma1 + sigma)
+	sinSigma, cosSigma = math.Sincos(sigma)
+
+	tmp := sinU1*sinSigma - cosU1*cosSigma*cosAlpha1
+	phi2 := math.Atan2(sinU1*cosSigma+cosU1*sinSigma*cosAlpha1, (1-wgs84F)*math.Hypot(sinAlpha, tmp))
+	lambda := math.Atan2(sinSigma*sinAlpha1, cosU1*cosSigma-sinU1*sinSigma*cosAlpha1)
+	c := wgs84F / 16 * cos2Alpha * (4 + wgs84F*(4-3*cos2Alpha))
+	l := lambda - (1-c)*wgs84F*sinAlpha*
+		(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+
+	lon2 = math.Mod(lon1+l*180/math.Pi+540, 360) - 180
+	lat2 = phi2 * 180 / math.Pi
+	return lon2, lat2
+}
+
+// vincentyCoefficients Vincenty法の係数A, B
+func vincentyCoefficients(cos2Alpha float64) (a, b float64) {
+	u2 := cos2Alpha * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+	a = 1 + u2/16384*(4096+u2*(-768+u2*(320-175*u2)))
+	b = u2 / 1024 * (256 + u2*(-128+u2*(74-47*u2)))
+	return a, b
+}
+
+// vincentyDeltaSigma Vincenty法の補正量Δσ
+func vincentyDeltaSigma(b, sinSigma, cosSigma, cos2SigmaM float64) float64 {
+	return b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+}
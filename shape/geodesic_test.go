@@ -0,0 +1,201 @@
+package shape
+
+import (
+	"math"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// mercatorMidpoint テスト用Webメルカトル投影上の中点
+//
+// 2点をWebメルカトル投影上の直線で結んだ場合の中点の経緯度を返却する。
+func mercatorMidpoint(lon1, lat1, lon2, lat2 float64) (lon, lat float64) {
+	y := func(lat float64) float64 { return math.Log(math.Tan(math.Pi/4 + common.DegreeToRadian(lat)/2)) }
+	mid := (y(lat1) + y(lat2)) / 2
+	return (lon1 + lon2) / 2, common.RadianToDegree(2*math.Atan(math.Exp(mid)) - math.Pi/2)
+}
+
+// geodesicMidpoint テスト用測地線上の中点
+func geodesicMidpoint(lon1, lat1, lon2, lat2 float64) (lon, lat float64) {
+	distance, azimuth, _ := geodesicInverse(lon1, lat1, lon2, lat2)
+	return geodesicDirect(lon1, lat1, azimuth, distance/2)
+}
+
+// TestGeodesicInverse01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - Vincentyの論文の例(Flinders Peak - Buninyong)をWGS84で計算した値
+//
+// + 確認内容
+//   - 逆問題の距離が54972.271m、方位角が306°52′05.37″となること
+//   - 順問題で終点の経緯度に戻ること
+func TestGeodesicInverse01(t *testing.T) {
+	//入力パラメータ
+	lon1, lat1 := 144.42486788888889, -37.95103341666667
+	lon2, lat2 := 143.92649552777777, -37.65282113888889
+
+	// テスト対象呼び出し
+	distance, azimuth, err := geodesicInverse(lon1, lat1, lon2, lat2)
+
+	if err != nil || math.Abs(distance-54972.271) > 1e-3 {
+		t.Errorf("距離 - 期待値：54972.271, 取得値：%v, %v", distance, err)
+	}
+	expectAzimuth := 306 + 52.0/60 + 5.37/3600
+	if resultAzimuth := math.Mod(common.RadianToDegree(azimuth)+360, 360); math.Abs(resultAzimuth-expectAzimuth) > 1e-5 {
+		t.Errorf("方位角 - 期待値：%v, 取得値：%v", expectAzimuth, resultAzimuth)
+	}
+
+	lon, lat := geodesicDirect(lon1, lat1, azimuth, distance)
+	if math.Abs(lon-lon2) > 1e-9 || math.Abs(lat-lat2) > 1e-9 {
+		t.Errorf("順問題 - 期待値：%v, %v, 取得値：%v, %v", lon2, lat2, lon, lat)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestDensifyGeodesic01 正常系動作確認(100km以上の区間)
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(135.0, 45.0, 100.0), (145.0, 45.0, 300.0)(約786km)
+//   - 分割する間隔：10000m
+//
+// + 確認内容
+//   - 分割点の間隔が10000m以下の等間隔となること
+//   - 分割点が測地線上にあること(始点、終点までの距離の和が区間の距離と一致すること)
+//   - 分割点間をWebメルカトル投影上の直線とした場合の測地線からの横ずれが5m未満であること(区間の長さの2乗に比例するため約2m)
+//   - 区間全体をWebメルカトル投影上の直線とした場合の横ずれは10km以上であること
+//   - 高さが線形補間され、始点、終点は元のPointであること
+func TestDensifyGeodesic01(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(135.0, 45.0, 100.0)
+	end, _ := object.NewPoint(145.0, 45.0, 300.0)
+
+	// テスト対象呼び出し
+	resultVal, err := DensifyGeodesic([]*object.Point{start, end}, 10000)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	total, _, _ := geodesicInverse(start.Lon(), start.Lat(), end.Lon(), end.Lat())
+	expectNum := int(math.Ceil(total/10000)) + 1
+	if len(resultVal) != expectNum || resultVal[0] != start || resultVal[len(resultVal)-1] != end {
+		t.Fatalf("分割点 - 期待値：%v個(始点、終点は元のPoint), 取得値：%v個", expectNum, len(resultVal))
+	}
+
+	for i, point := range resultVal[1:] {
+		previous := resultVal[i]
+		step, _, _ := geodesicInverse(previous.Lon(), previous.Lat(), point.Lon(), point.Lat())
+		if step > 10000 || math.Abs(step-total/float64(expectNum-1)) > 1e-3 {
+			t.Errorf("分割点%dの間隔 - 期待値：%v, 取得値：%v", i+1, total/float64(expectNum-1), step)
+		}
+
+		toStart, _, _ := geodesicInverse(start.Lon(), start.Lat(), point.Lon(), point.Lat())
+		toEnd, _, _ := geodesicInverse(point.Lon(), point.Lat(), end.Lon(), end.Lat())
+		if math.Abs(toStart+toEnd-total) > 1e-3 {
+			t.Errorf("分割点%dの測地線からのずれ - 期待値：0, 取得値：%v", i+1, toStart+toEnd-total)
+		}
+
+		lon, lat := mercatorMidpoint(previous.Lon(), previous.Lat(), point.Lon(), point.Lat())
+		geoLon, geoLat := geodesicMidpoint(previous.Lon(), previous.Lat(), point.Lon(), point.Lat())
+		if deviation, _, _ := geodesicInverse(lon, lat, geoLon, geoLat); deviation >= 5 {
+			t.Errorf("分割点%dまでの横ずれ - 期待値：5m未満, 取得値：%v", i+1, deviation)
+		}
+
+		expectAlt := 100.0 + 200.0*float64(i+1)/float64(expectNum-1)
+		if math.Abs(point.Alt()-expectAlt) > 1e-9 {
+			t.Errorf("分割点%dの高さ - 期待値：%v, 取得値：%v", i+1, expectAlt, point.Alt())
+		}
+	}
+
+	lon, lat := mercatorMidpoint(start.Lon(), start.Lat(), end.Lon(), end.Lat())
+	geoLon, geoLat := geodesicMidpoint(start.Lon(), start.Lat(), end.Lon(), end.Lat())
+	if deviation, _, _ := geodesicInverse(lon, lat, geoLon, geoLat); deviation < 10000 {
+		t.Errorf("区間全体の横ずれ - 期待値：10km以上, 取得値：%v", deviation)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestDensifyGeodesic02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：分割する間隔が0
+//   - パターン2：接続点にnilを含む
+//   - パターン3：分割する間隔がNaN
+//   - パターン4：分割する間隔が+Inf
+//   - パターン5：約786kmの区間を1e-6mの間隔で分割(区間の分割数がmaxGeodesicLegPointsを超える)
+//
+// + 確認内容
+//   - 入力チェックエラーとなること
+func TestDensifyGeodesic02(t *testing.T) {
+	//入力パラメータ
+	p, _ := object.NewPoint(135.0, 45.0, 100.0)
+
+	// テスト対象呼び出し
+	if _, err := DensifyGeodesic([]*object.Point{p, p}, 0); err == nil {
+		t.Error("パターン1 error - 期待値：入力チェックエラー, 取得値：nil")
+	}
+	if _, err := DensifyGeodesic([]*object.Point{p, nil}, 100); err == nil {
+		t.Error("パターン2 error - 期待値：入力チェックエラー, 取得値：nil")
+	}
+	for i, interval := range []float64{math.NaN(), math.Inf(1)} {
+		if _, err := DensifyGeodesic([]*object.Point{p, p}, interval); err == nil {
+			t.Errorf("パターン%d error - 期待値：入力チェックエラー, 取得値：nil", i+3)
+		}
+	}
+	q, _ := object.NewPoint(145.0, 45.0, 100.0)
+	if _, err := DensifyGeodesic([]*object.Point{p, q}, 1e-6); err == nil {
+		t.Error("パターン5 error - 期待値：入力チェックエラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestGeodesic01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(135.0, 45.0, 100.0), (145.0, 45.0, 100.0)(約786km)
+//   - 半径：500.0、水平精度：14、垂直精度：14、分割する間隔：10000m
+//
+// + 確認内容
+//   - 測地線の中点の空間IDが含まれること
+//   - Geodesic未指定の場合は測地線の中点の空間IDが含まれないこと
+//   - Geodesicに負の間隔を指定した場合は入力チェックエラーとなること
+func TestGeodesic01(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(135.0, 45.0, 100.0)
+	end, _ := object.NewPoint(145.0, 45.0, 100.0)
+	center := []*object.Point{start, end}
+
+	// 期待値
+	lon, lat := geodesicMidpoint(start.Lon(), start.Lat(), end.Lon(), end.Lat())
+	mid, _ := object.NewPoint(lon, lat, 100.0)
+	midIDs, _ := shape.GetExtendedSpatialIdsOnPoints([]*object.Point{mid}, 14, 14)
+
+	// テスト対象呼び出し
+	resultVal, err := GetExtendedSpatialIdsOnCylinders(center, 500.0, 14, 14, false, Geodesic(10000))
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	if !common.Include(resultVal, midIDs[0]) {
+		t.Errorf("測地線の中点 - 期待値：%v を含む, 取得値：%v", midIDs[0], resultVal)
+	}
+
+	straightVal, _ := GetExtendedSpatialIdsOnCylinders(center, 500.0, 14, 14, false)
+	if common.Include(straightVal, midIDs[0]) {
+		t.Errorf("直線の経路 - 期待値：%v を含まない, 取得値：%v", midIDs[0], straightVal)
+	}
+
+	if _, err := GetExtendedSpatialIdsOnCylinders(center, 500.0, 14, 14, false, Geodesic(-1)); err == nil {
+		t.Error("error - 期待値：入力チェックエラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,58 @@
+package shape
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogHandler ログ出力先設定関数
+//
+// 以下の関数の処理過程をデバッグレベルの構造化ログとして出力する。
+// 未指定の場合はログを出力せず、ログ出力のための処理も行わない。
+//   - GetSpatialIdsOnCylinders
+//   - GetExtendedSpatialIdsOnCylinders
+//
+// 引数：
+//
+//	h: ログ出力先のハンドラ。zap等のロガーはslog.Handlerに変換して指定する
+//
+// 戻り値：
+//
+//	衝突判定実施オプショナル型の関数
+func LogHandler(h slog.Handler) option {
+	return func(p *IsPrecisionOpts) {
+		p.LogHandler = h
+	}
+}
+
+// SetLogHandler ログ出力先設定
+//
+// CalcValidSpatialIDsの処理過程をデバッグレベルの構造化ログとして出力する。
+//
+// 引数：
+//
+//	h: ログ出力先のハンドラ。nilの場合はログを出力しない
+func (c *Capsule) SetLogHandler(h slog.Handler) {
+	if h == nil {
+		c.logger = nil
+		return
+	}
+	c.logger = slog.New(h)
+}
+
+// debugLog デバッグログ出力
+//
+// ロガーがnilの場合は何もしない。
+// 属性はslog.Attrで受け取るため、ログを出力しない場合にメモリ確保は発生しない。
+//
+// 引数：
+//
+//	logger： ロガー
+//	msg： メッセージ
+//	attrs： 属性
+func debugLog(logger *slog.Logger, msg string, attrs ...slog.Attr) {
+	if logger == nil {
+		return
+	}
+	logger.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+}
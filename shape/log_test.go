@@ -0,0 +1,138 @@
+package shape
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+)
+
+// TestLogHandler01 正常系動作確認(ログ出力)
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.753098, 35.685371, 11.0), (139.753198, 35.685371, 11.0), (139.753198, 35.685471, 11.0)
+//   - 半径：2.0、水平精度：25、垂直精度：25
+//   - ログ出力先：デバッグレベルのJSONハンドラ
+//
+// + 確認内容
+//   - 区間番号、空間IDの数、処理時間が構造化ログとして出力されること
+//   - 空間IDのリストがログに出力されないこと
+//   - ログ出力の有無で取得する空間IDが変わらないこと
+func TestLogHandler01(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+	p3, _ := object.NewPoint(139.753198, 35.685471, 11.0)
+	buffer := new(bytes.Buffer)
+	handler := slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug})
+
+	// テスト対象呼び出し
+	resultVal, err := GetExtendedSpatialIdsOnCylinders(
+		[]*object.Point{p1, p2, p3}, 2.0, 25, 25, false, LogHandler(handler),
+	)
+	expectVal, _ := GetExtendedSpatialIdsOnCylinders([]*object.Point{p1, p2, p3}, 2.0, 25, 25, false)
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	if strings.Join(resultVal, ",") != strings.Join(expectVal, ",") {
+		t.Errorf("空間ID - ログ出力の有無で結果が異なる")
+	}
+
+	// 出力されたログの確認
+	segments := map[float64]bool{}
+	summary := map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		record := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("ログの形式 - 取得値：%s", line)
+		}
+		for _, value := range record {
+			if _, ok := value.([]interface{}); ok {
+				t.Errorf("ログの属性 - 期待値：リストを含まない, 取得値：%s", line)
+			}
+		}
+		if record["msg"] == "接続点間の空間ID" {
+			segments[record["segment"].(float64)] = true
+			if _, ok := record["elapsed"]; !ok {
+				t.Errorf("処理時間 - 期待値：elapsed, 取得値：%s", line)
+			}
+		}
+		if record["msg"] == "拡張空間ID(円柱)取得" {
+			summary = record
+		}
+	}
+
+	if !segments[0] || !segments[1] {
+		t.Errorf("区間番号 - 期待値：0, 1, 取得値：%v", segments)
+	}
+	if summary["acceptedIDs"] != float64(len(resultVal)) || summary["segments"] != 2.0 {
+		t.Errorf("取得結果のログ - 期待値：acceptedIDs=%v, segments=2, 取得値：%v", len(resultVal), summary)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestLogHandler02 正常系動作確認(ログ出力なし)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：ロガーなし
+//   - パターン2：情報レベルのハンドラ(デバッグレベルは無効)
+//
+// + 確認内容
+//   - ログ出力でメモリ確保が発生しないこと
+func TestLogHandler02(t *testing.T) {
+	disabled := slog.New(slog.NewTextHandler(new(bytes.Buffer), &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	for _, logger := range []*slog.Logger{nil, disabled} {
+		// テスト対象呼び出し
+		allocs := testing.AllocsPerRun(100, func() {
+			debugLog(logger, "接続点間の空間ID", slog.Int("segment", 1), slog.Int("acceptedIDs", 100))
+		})
+
+		if allocs != 0 {
+			t.Errorf("メモリ確保回数 - 期待値：0, 取得値：%v", allocs)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestSetLogHandler01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点、終点：(0, 0, 0)の球、半径：1.0、精度：25
+//   - パターン1：デバッグレベルのJSONハンドラ
+//   - パターン2：nil
+//
+// + 確認内容
+//   - パターン1：CalcValidSpatialIDsの処理過程がログに出力されること
+//   - パターン2：ロガーが解除されること
+func TestSetLogHandler01(t *testing.T) {
+	//入力パラメータ
+	buffer := new(bytes.Buffer)
+	capsule := NewCapsule(spatial.Point3{}, spatial.Point3{}, 1.0, 25, 25, false, true, 1.0)
+
+	// テスト対象呼び出し
+	capsule.SetLogHandler(slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	capsule.CalcValidSpatialIDs()
+
+	if !strings.Contains(buffer.String(), `"collisionTests"`) {
+		t.Errorf("ログ - 期待値：collisionTestsを含む, 取得値：%s", buffer.String())
+	}
+
+	capsule.SetLogHandler(nil)
+	if capsule.logger != nil {
+		t.Errorf("ロガー - 期待値：nil, 取得値：%v", capsule.logger)
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,171 @@
+package shape
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+)
+
+// voxelIndex 拡張空間IDの成分インデックス構造体
+type voxelIndex struct {
+	hZoom int64 // 水平方向精度
+	x     int64 // X成分インデックス
+	y     int64 // Y成分インデックス
+	vZoom int64 // 垂直方向精度
+	f     int64 // 高さ成分インデックス
+}
+
+// parseVoxelIndex 拡張空間IDから成分インデックスを取得
+//
+// 引数：
+//
+//	spatialID： 拡張空間ID
+//
+// 戻り値：
+//
+//	拡張空間IDの成分インデックス
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 空間IDフォーマット不正：空間IDのフォーマットに違反する値が"拡張空間ID"に入力されていた場合。
+func parseVoxelIndex(spatialID string) (voxelIndex, error) {
+	ids := strings.Split(spatialID, consts.SpatialIDDelimiter)
+	if len(ids) != 5 {
+		return voxelIndex{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+
+	values := make([]int64, 0, 5)
+	for _, id := range ids {
+		value, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return voxelIndex{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+		}
+		values = append(values, value)
+	}
+
+	return voxelIndex{
+		hZoom: values[0],
+		x:     values[1],
+		y:     values[2],
+		vZoom: values[3],
+		f:     values[4],
+	}, nil
+}
+
+// String 拡張空間IDの文字列を取得
+//
+// 戻り値：
+//
+//	拡張空間ID
+func (v voxelIndex) String() string {
+	return GetSpatialIDOnAxisIDs(v.x, v.y, v.f, v.hZoom, v.vZoom)
+}
+
+// parent 親ボクセルの成分インデックスを取得
+//
+// 水平方向精度、垂直方向精度をそれぞれ1つ下げたボクセルを親とする。
+//
+// 戻り値：
+//
+//	親ボクセルの成分インデックス
+func (v voxelIndex) parent() voxelIndex {
+	return voxelIndex{
+		hZoom: v.hZoom - 1,
+		x:     v.x >> 1,
+		y:     v.y >> 1,
+		vZoom: v.vZoom - 1,
+		// 負の高さ成分インデックスも切り捨てとなるよう算術シフトを用いる
+		f: v.f >> 1,
+	}
+}
+
+// children 子ボクセルの成分インデックスを取得
+//
+// 戻り値：
+//
+//	水平方向精度、垂直方向精度をそれぞれ1つ上げた8個の子ボクセルの成分インデックス
+func (v voxelIndex) children() []voxelIndex {
+	children := make([]voxelIndex, 0, 8)
+	for dx := int64(0); dx <= 1; dx++ {
+		for dy := int64(0); dy <= 1; dy++ {
+			for df := int64(0); df <= 1; df++ {
+				children = append(children, voxelIndex{
+					hZoom: v.hZoom + 1,
+					x:     v.x*2 + dx,
+					y:     v.y*2 + dy,
+					vZoom: v.vZoom + 1,
+					f:     v.f*2 + df,
+				})
+			}
+		}
+	}
+	return children
+}
+
+// MergeExtendedSpatialIds 拡張空間IDの統合
+//
+// 親ボクセルを構成する8個の子ボクセルが全て含まれる場合、子ボクセルを親ボクセルに置き換える。
+// 置き換えは統合できるボクセルが無くなるまで繰り返す。
+// 経路の拡張空間IDを少ない個数で表現する際に使用する。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//
+// 戻り値：
+//
+//	統合後の拡張空間IDのリスト(昇順)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 空間IDフォーマット不正：空間IDのフォーマットに違反する値が"拡張空間ID"に入力されていた場合。
+func MergeExtendedSpatialIds(spatialIDs []string) ([]string, error) {
+	voxels := map[voxelIndex]bool{}
+	for _, spatialID := range spatialIDs {
+		index, err := parseVoxelIndex(spatialID)
+		if err != nil {
+			return []string{}, err
+		}
+		voxels[index] = true
+	}
+
+	for {
+		// 親ボクセルごとに子ボクセル数を集計
+		children := map[voxelIndex]int{}
+		for index := range voxels {
+			if index.hZoom == 0 || index.vZoom == 0 {
+				continue
+			}
+			children[index.parent()]++
+		}
+
+		merged := false
+		for parent, num := range children {
+			if num != 8 {
+				continue
+			}
+			for _, child := range parent.children() {
+				delete(voxels, child)
+			}
+			voxels[parent] = true
+			merged = true
+		}
+
+		if !merged {
+			break
+		}
+	}
+
+	results := make([]string, 0, len(voxels))
+	for index := range voxels {
+		results = append(results, index.String())
+	}
+	sort.Strings(results)
+
+	return results, nil
+}
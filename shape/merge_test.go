@@ -0,0 +1,171 @@
+package shape
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestParseVoxelIndex01 正常系動作確認
+//
+// 試験詳細：
+//   - 試験データ
+//     拡張空間ID： 25/200/29803148/24/-3
+//
+// + 確認内容
+//   - 拡張空間IDに対応する成分インデックスを取得すること
+//   - 成分インデックスから元の拡張空間IDに戻せること
+func TestParseVoxelIndex01(t *testing.T) {
+	//入力パラメータ
+	spatialID := "25/200/29803148/24/-3"
+
+	// 期待値
+	expectVal := voxelIndex{hZoom: 25, x: 200, y: 29803148, vZoom: 24, f: -3}
+
+	// テスト対象呼び出し
+	resultVal, err := parseVoxelIndex(spatialID)
+
+	// 戻り値の成分インデックスと期待値の比較
+	if !reflect.DeepEqual(expectVal, resultVal) {
+		t.Errorf("成分インデックス - 期待値：%v, 取得値：%v", expectVal, resultVal)
+	}
+	// 拡張空間IDへの変換結果の比較
+	if resultVal.String() != spatialID {
+		t.Errorf("拡張空間ID - 期待値：%v, 取得値：%v", spatialID, resultVal.String())
+	}
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Errorf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestParseVoxelIndex02 異常系動作確認(フォーマット不正)
+//
+// 試験詳細：
+//   - 試験データ
+//     拡張空間ID： 25/200/29803148/24, 25/200/a/24/0
+//
+// + 確認内容
+//   - 戻り値としてエラー内容が返却されること
+func TestParseVoxelIndex02(t *testing.T) {
+	// 期待値
+	expectErr := "InputValueError,入力チェックエラー"
+
+	for _, spatialID := range []string{"25/200/29803148/24", "25/200/a/24/0"} {
+		// テスト対象呼び出し
+		_, resultErr := parseVoxelIndex(spatialID)
+
+		if resultErr == nil || resultErr.Error() != expectErr {
+			// 戻り値のエラーインスタンスが期待値と異なる場合Errorをログに出力
+			t.Errorf("error - 期待値：%s, 取得値：%v\n", expectErr, resultErr)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestMergeExtendedSpatialIds01 正常系動作確認(2段階の統合)
+//
+// 試験詳細：
+//   - 試験データ
+//     拡張空間ID： 24/1/1/24/0 を構成する精度26の64個の拡張空間ID
+//     拡張空間ID： 26/100/100/26/5(統合されない)
+//
+// + 確認内容
+//   - 精度26の64個の拡張空間IDが精度24の1個に統合されること
+//   - 統合対象でない拡張空間IDはそのまま返却されること
+func TestMergeExtendedSpatialIds01(t *testing.T) {
+	//入力パラメータ
+	spatialIDs := []string{"26/100/100/26/5"}
+	for _, child := range (voxelIndex{hZoom: 24, x: 1, y: 1, vZoom: 24, f: 0}).children() {
+		for _, grandchild := range child.children() {
+			spatialIDs = append(spatialIDs, grandchild.String())
+		}
+	}
+
+	// 期待値
+	expectVal := []string{"24/1/1/24/0", "26/100/100/26/5"}
+
+	// テスト対象呼び出し
+	resultVal, err := MergeExtendedSpatialIds(spatialIDs)
+
+	// 戻り値の拡張空間IDと期待値の比較
+	if !reflect.DeepEqual(expectVal, resultVal) {
+		t.Errorf("拡張空間ID - 期待値：%v, 取得値：%v", expectVal, resultVal)
+	}
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Errorf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestMergeExtendedSpatialIds02 正常系動作確認(子ボクセルが不足)
+//
+// 試験詳細：
+//   - 試験データ
+//     拡張空間ID： 25/2/2/25/-2 を除く 24/1/1/24/-1 の子ボクセル7個
+//
+// + 確認内容
+//   - 統合されずに昇順で返却されること
+//   - 負の高さ成分インデックスの親が切り捨てで判定されること
+func TestMergeExtendedSpatialIds02(t *testing.T) {
+	//入力パラメータ
+	spatialIDs := []string{}
+	for _, child := range (voxelIndex{hZoom: 24, x: 1, y: 1, vZoom: 24, f: -1}).children() {
+		if child.String() == "25/2/2/25/-2" {
+			continue
+		}
+		spatialIDs = append(spatialIDs, child.String())
+	}
+
+	// 期待値
+	expectVal := append([]string{}, spatialIDs...)
+	sort.Strings(expectVal)
+
+	// テスト対象呼び出し
+	resultVal, _ := MergeExtendedSpatialIds(spatialIDs)
+
+	// 戻り値の拡張空間IDと期待値の比較
+	if !reflect.DeepEqual(expectVal, resultVal) {
+		t.Errorf("拡張空間ID - 期待値：%v, 取得値：%v", expectVal, resultVal)
+	}
+
+	// 不足分を追加すると統合されること
+	resultVal, _ = MergeExtendedSpatialIds(append(spatialIDs, "25/2/2/25/-2"))
+	if !reflect.DeepEqual([]string{"24/1/1/24/-1"}, resultVal) {
+		t.Errorf("拡張空間ID - 期待値：%v, 取得値：%v", []string{"24/1/1/24/-1"}, resultVal)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestMergeExtendedSpatialIds03 異常系動作確認(フォーマット不正)
+//
+// 試験詳細：
+//   - 試験データ
+//     拡張空間ID： 25/2/2/25
+//
+// + 確認内容
+//   - 戻り値の拡張空間IDが空であること
+//   - 戻り値としてエラー内容が返却されること
+func TestMergeExtendedSpatialIds03(t *testing.T) {
+	// 期待値
+	expectErr := "InputValueError,入力チェックエラー"
+
+	// テスト対象呼び出し
+	resultVal, resultErr := MergeExtendedSpatialIds([]string{"25/2/2/25"})
+
+	if len(resultVal) != 0 {
+		t.Errorf("拡張空間IDの個数 - 期待値：0, 取得値：%v", len(resultVal))
+	}
+	if resultErr == nil || resultErr.Error() != expectErr {
+		// 戻り値のエラーインスタンスが期待値と異なる場合Errorをログに出力
+		t.Errorf("error - 期待値：%s, 取得値：%v\n", expectErr, resultErr)
+	}
+
+	t.Log("テスト終了")
+}
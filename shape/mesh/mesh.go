@@ -0,0 +1,523 @@
+// Package mesh 空間IDメッシュ出力パッケージ
+package mesh
+
+import (
+	"math"
+
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	sidshape "github.com/trajectoryjp/spatial_id_go/shape"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+)
+
+const (
+	wgs84A = 6378137.0         // WGS84楕円体の長半径(単位:m)
+	wgs84F = 1 / 298.257223563 // WGS84楕円体の扁平率
+
+	sphereStacks = 12 // 球メッシュの緯度方向分割数
+	tubeSlices   = 24 // 円柱・球メッシュの円周方向分割数
+)
+
+// Mesh 三角形メッシュ構造体
+//
+// 頂点座標はLocalFrameの局所直交座標系(東・北・上, 単位:m)で保持する。
+type Mesh struct {
+	Name      string           // メッシュ名
+	Vertices  []spatial.Point3 // 頂点座標
+	Triangles [][3]int         // 三角形を構成する頂点インデックス
+	Color     [4]float64       // 表示色(RGBA, 0～1)
+}
+
+// addTriangle 三角形追加
+//
+// 引数：
+//
+//	a, b, c： 反時計回りに並べた頂点インデックス
+func (m *Mesh) addTriangle(a, b, c int) {
+	m.Triangles = append(m.Triangles, [3]int{a, b, c})
+}
+
+// addVertex 頂点追加
+//
+// 引数：
+//
+//	point： 頂点座標
+//
+// 戻り値：
+//
+//	追加した頂点のインデックス
+func (m *Mesh) addVertex(point spatial.Point3) int {
+	m.Vertices = append(m.Vertices, point)
+	return len(m.Vertices) - 1
+}
+
+// LocalFrame 局所直交座標系構造体
+//
+// 原点を接点とする東・北・上(ENU)の直交座標系。
+type LocalFrame struct {
+	origin spatial.Point3 // 原点の地心直交座標
+	lon    float64        // 原点の経度(単位:ラジアン)
+	lat    float64        // 原点の緯度(単位:ラジアン)
+}
+
+// NewLocalFrame 局所直交座標系構造体コンストラクタ
+//
+// 引数：
+//
+//	origin： 原点とする地理座標
+//
+// 戻り値：
+//
+//	局所直交座標系構造体ポインタ
+func NewLocalFrame(origin *object.Point) *LocalFrame {
+	frame := new(LocalFrame)
+	frame.lon = origin.Lon() * math.Pi / 180
+	frame.lat = origin.Lat() * math.Pi / 180
+	frame.origin = toECEF(origin.Lon(), origin.Lat(), origin.Alt())
+
+	return frame
+}
+
+// toECEF 地理座標から地心直交座標への変換
+//
+// 引数：
+//
+//	lon： 経度(単位:度)
+//	lat： 緯度(単位:度)
+//	alt： 楕円体高(単位:m)
+//
+// 戻り値：
+//
+//	地心直交座標
+func toECEF(lon, lat, alt float64) spatial.Point3 {
+	lonRad := lon * math.Pi / 180
+	latRad := lat * math.Pi / 180
+	e2 := wgs84F * (2 - wgs84F)
+	// 卯酉線曲率半径
+	n := wgs84A / math.Sqrt(1-e2*math.Sin(latRad)*math.Sin(latRad))
+
+	return spatial.Point3{
+		X: (n + alt) * math.Cos(latRad) * math.Cos(lonRad),
+		Y: (n + alt) * math.Cos(latRad) * math.Sin(lonRad),
+		Z: (n*(1-e2) + alt) * math.Sin(latRad),
+	}
+}
+
+// ToLocal 地理座標から局所直交座標への変換
+//
+// 引数：
+//
+//	lon： 経度(単位:度)
+//	lat： 緯度(単位:度)
+//	alt： 高さ(単位:m)
+//
+// 戻り値：
+//
+//	局所直交座標(X:東, Y:北, Z:上)
+func (l LocalFrame) ToLocal(lon, lat, alt float64) spatial.Point3 {
+	ecef := toECEF(lon, lat, alt)
+	d := spatial.NewVectorFromPoints(l.origin, ecef)
+
+	sinLon, cosLon := math.Sin(l.lon), math.Cos(l.lon)
+	sinLat, cosLat := math.Sin(l.lat), math.Cos(l.lat)
+
+	return spatial.Point3{
+		X: -sinLon*d.X + cosLon*d.Y,
+		Y: -sinLat*cosLon*d.X - sinLat*sinLon*d.Y + cosLat*d.Z,
+		Z: cosLat*cosLon*d.X + cosLat*sinLon*d.Y + sinLat*d.Z,
+	}
+}
+
+// voxelKey ボクセル格子点のキー
+type voxelKey struct {
+	hZoom int64 // 水平方向精度
+	vZoom int64 // 垂直方向精度
+	x     int64 // X成分インデックス
+	y     int64 // Y成分インデックス
+	f     int64 // 高さ成分インデックス
+}
+
+// parseVoxelKey 拡張空間IDからボクセル格子点のキーを取得
+//
+// 拡張空間IDの解析はshape.ParseExtendedSpatialIDに委ねる。
+//
+// 引数：
+//
+//	spatialID： 拡張空間ID
+//
+// 戻り値：
+//
+//	ボクセル格子点のキー
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 空間IDフォーマット不正：空間IDのフォーマットに違反する値が"拡張空間ID"に入力されていた場合。
+//	 入力数値不正：精度、成分インデックスが範囲外の場合。
+func parseVoxelKey(spatialID string) (voxelKey, error) {
+	id, err := shape.ParseExtendedSpatialID(spatialID)
+	if err != nil {
+		return voxelKey{}, err
+	}
+
+	return voxelKey{
+		hZoom: id.HZoom(),
+		x:     id.X(),
+		y:     id.Y(),
+		vZoom: id.VZoom(),
+		f:     id.F(),
+	}, nil
+}
+
+// shift 隣接ボクセルのキーを取得
+//
+// 引数：
+//
+//	dx： X成分のシフト量
+//	dy： Y成分のシフト量
+//	df： 高さ成分のシフト量
+//
+// 戻り値：
+//
+//	シフト後のキー
+func (k voxelKey) shift(dx, dy, df int64) voxelKey {
+	return voxelKey{hZoom: k.hZoom, vZoom: k.vZoom, x: k.x + dx, y: k.y + dy, f: k.f + df}
+}
+
+// voxelFaces ボクセルの面定義
+//
+// 面の隣接方向と、面を構成する頂点(dx, dy, df)を周回順に並べたもの
+var voxelFaces = []struct {
+	neighbor [3]int64
+	corners  [4][3]int64
+}{
+	// 西面
+	{[3]int64{-1, 0, 0}, [4][3]int64{{0, 0, 0}, {0, 1, 0}, {0, 1, 1}, {0, 0, 1}}},
+	// 東面
+	{[3]int64{1, 0, 0}, [4][3]int64{{1, 0, 0}, {1, 1, 0}, {1, 1, 1}, {1, 0, 1}}},
+	// 北面(Y成分インデックスは南方向に増加)
+	{[3]int64{0, -1, 0}, [4][3]int64{{0, 0, 0}, {1, 0, 0}, {1, 0, 1}, {0, 0, 1}}},
+	// 南面
+	{[3]int64{0, 1, 0}, [4][3]int64{{0, 1, 0}, {1, 1, 0}, {1, 1, 1}, {0, 1, 1}}},
+	// 底面
+	{[3]int64{0, 0, -1}, [4][3]int64{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}},
+	// 上面
+	{[3]int64{0, 0, 1}, [4][3]int64{{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 1, 1}}},
+}
+
+// NewVoxelMesh ボクセルメッシュ作成
+//
+// 拡張空間IDのボクセルを直方体として三角形メッシュ化する。
+// 同一精度で隣接するボクセル間の共有面は出力しない。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//	frame： 出力する局所直交座標系
+//
+// 戻り値：
+//
+//	ボクセルメッシュ
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 空間IDフォーマット不正：空間IDのフォーマットに違反する値が"拡張空間ID"に入力されていた場合。
+func NewVoxelMesh(spatialIDs []string, frame *LocalFrame) (*Mesh, error) {
+	mesh := &Mesh{Name: "voxels", Color: [4]float64{0.2, 0.5, 1.0, 1.0}}
+
+	// 入力ボクセルの集合
+	voxels := map[voxelKey]bool{}
+	keys := make([]voxelKey, 0, len(spatialIDs))
+	for _, spatialID := range spatialIDs {
+		key, err := parseVoxelKey(spatialID)
+		if err != nil {
+			return nil, err
+		}
+		if voxels[key] {
+			continue
+		}
+		voxels[key] = true
+		keys = append(keys, key)
+	}
+
+	// 格子点と頂点インデックスの対応(共有頂点を1つにまとめる)
+	cornerIndexes := map[voxelKey]int{}
+
+	for _, key := range keys {
+		// ボクセルの頂点を格子点ごとに分類
+		corners, err := voxelCorners(key, frame)
+		if err != nil {
+			return nil, err
+		}
+
+		// ボクセルの中心
+		center := spatial.Point3{}
+		for _, corner := range corners {
+			center.X += corner.X / 8
+			center.Y += corner.Y / 8
+			center.Z += corner.Z / 8
+		}
+
+		for _, face := range voxelFaces {
+			// 隣接ボクセルが存在する場合は共有面として出力しない
+			if voxels[key.shift(face.neighbor[0], face.neighbor[1], face.neighbor[2])] {
+				continue
+			}
+
+			indexes := [4]int{}
+			faceCenter := spatial.Point3{}
+			for j, c := range face.corners {
+				cornerKey := key.shift(c[0], c[1], c[2])
+				index, ok := cornerIndexes[cornerKey]
+				if !ok {
+					index = mesh.addVertex(corners[c[0]*4+c[1]*2+c[2]])
+					cornerIndexes[cornerKey] = index
+				}
+				indexes[j] = index
+				faceCenter.X += mesh.Vertices[index].X / 4
+				faceCenter.Y += mesh.Vertices[index].Y / 4
+				faceCenter.Z += mesh.Vertices[index].Z / 4
+			}
+
+			// 法線が外向きとなるよう周回順を調整
+			outward := spatial.NewVectorFromPoints(center, faceCenter)
+			normal := cross(
+				spatial.NewVectorFromPoints(mesh.Vertices[indexes[0]], mesh.Vertices[indexes[1]]),
+				spatial.NewVectorFromPoints(mesh.Vertices[indexes[0]], mesh.Vertices[indexes[2]]),
+			)
+			if dot(normal, outward) < 0 {
+				indexes[1], indexes[3] = indexes[3], indexes[1]
+			}
+			mesh.addTriangle(indexes[0], indexes[1], indexes[2])
+			mesh.addTriangle(indexes[0], indexes[2], indexes[3])
+		}
+	}
+
+	return mesh, nil
+}
+
+// voxelCorners ボクセルの頂点取得
+//
+// 引数：
+//
+//	key： ボクセル格子点のキー
+//	frame： 出力する局所直交座標系
+//
+// 戻り値：
+//
+//	ボクセルの頂点。(dx, dy, df)の頂点をdx*4+dy*2+dfの位置に格納する。
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 空間IDフォーマット不正：空間IDのフォーマットに違反する値が"拡張空間ID"に入力されていた場合。
+func voxelCorners(key voxelKey, frame *LocalFrame) ([8]spatial.Point3, error) {
+	corners := [8]spatial.Point3{}
+
+	spatialID := shape.GetSpatialIDOnAxisIDs(key.x, key.y, key.f, key.hZoom, key.vZoom)
+	points, err := sidshape.GetPointOnExtendedSpatialId(spatialID, enum.Vertex)
+	if err != nil {
+		return corners, err
+	}
+	if len(points) != 8 {
+		return corners, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+
+	// 頂点の並び順に依存しないよう、座標の大小から格子点の位置を判定
+	minLon, minLat, minAlt := points[0].Lon(), points[0].Lat(), points[0].Alt()
+	for _, point := range points {
+		minLon = math.Min(minLon, point.Lon())
+		minLat = math.Min(minLat, point.Lat())
+		minAlt = math.Min(minAlt, point.Alt())
+	}
+	for _, point := range points {
+		dx, dy, df := 0, 0, 0
+		if point.Lon() > minLon {
+			dx = 1
+		}
+		// Y成分インデックスは南方向に増加するため、緯度が小さい側を1とする
+		if point.Lat() <= minLat {
+			dy = 1
+		}
+		if point.Alt() > minAlt {
+			df = 1
+		}
+		corners[dx*4+dy*2+df] = frame.ToLocal(point.Lon(), point.Lat(), point.Alt())
+	}
+
+	return corners, nil
+}
+
+// NewCylindersMesh 円柱経路メッシュ作成
+//
+// GetExtendedSpatialIdsOnCylindersの入力となる円柱を複数つなげた経路を三角形メッシュ化する。
+// カプセルの場合は各円柱の始点・終点に、円柱の場合は接続点に球を配置する。
+//
+// 引数：
+//
+//	center： 円柱の中心の接続点
+//	radius： 円柱の半径(単位:m)
+//	isCapsule： 始点、終点が球状であるかを示す。True: カプセル / False: 円柱
+//	frame： 出力する局所直交座標系
+//
+// 戻り値：
+//
+//	円柱経路メッシュ
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 接続点にnilが含まれる場合、もしくは円柱の半径が0以下の場合
+func NewCylindersMesh(
+	center []*object.Point,
+	radius float64,
+	isCapsule bool,
+	frame *LocalFrame,
+) (*Mesh, error) {
+	mesh := &Mesh{Name: "cylinders", Color: [4]float64{1.0, 0.4, 0.1, 0.4}}
+
+	if radius <= consts.Minima {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+
+	// 【局所直交座標空間】接続点の座標(同一座標の連続は除外)
+	points := []spatial.Point3{}
+	for _, point := range center {
+		if point == nil {
+			return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+		}
+		localPoint := frame.ToLocal(point.Lon(), point.Lat(), point.Alt())
+		if len(points) > 0 && points[len(points)-1].IsClose(localPoint, consts.Minima) {
+			continue
+		}
+		points = append(points, localPoint)
+	}
+
+	// 接続点数が1の場合は球
+	if len(points) == 1 {
+		mesh.addSphere(points[0], radius)
+		return mesh, nil
+	}
+
+	for i := 0; i < len(points)-1; i++ {
+		mesh.addTube(points[i], points[i+1], radius, !isCapsule)
+
+		// カプセルの場合は始点・終点に球を配置
+		if isCapsule {
+			mesh.addSphere(points[i], radius)
+			mesh.addSphere(points[i+1], radius)
+
+			// 円柱の場合は接続点に球を配置
+		} else if i > 0 {
+			mesh.addSphere(points[i], radius)
+		}
+	}
+
+	return mesh, nil
+}
+
+// addSphere 球メッシュ追加
+//
+// 引数：
+//
+//	center： 球の中心
+//	radius： 球の半径
+func (m *Mesh) addSphere(center spatial.Point3, radius float64) {
+	top := m.addVertex(center.Translate(spatial.Vector3{Z: radius}))
+	bottom := m.addVertex(center.Translate(spatial.Vector3{Z: -radius}))
+
+	// 極を除く緯度方向の輪
+	rings := make([][]int, 0, sphereStacks-1)
+	for i := 1; i < sphereStacks; i++ {
+		theta := math.Pi * float64(i) / sphereStacks
+		ring := make([]int, 0, tubeSlices)
+		for j := 0; j < tubeSlices; j++ {
+			phi := 2 * math.Pi * float64(j) / tubeSlices
+			ring = append(ring, m.addVertex(center.Translate(spatial.Vector3{
+				X: radius * math.Sin(theta) * math.Cos(phi),
+				Y: radius * math.Sin(theta) * math.Sin(phi),
+				Z: radius * math.Cos(theta),
+			})))
+		}
+		rings = append(rings, ring)
+	}
+
+	for j := 0; j < tubeSlices; j++ {
+		next := (j + 1) % tubeSlices
+		m.addTriangle(top, rings[0][j], rings[0][next])
+		for i := 0; i < len(rings)-1; i++ {
+			m.addTriangle(rings[i][j], rings[i+1][j], rings[i+1][next])
+			m.addTriangle(rings[i][j], rings[i+1][next], rings[i][next])
+		}
+		m.addTriangle(bottom, rings[len(rings)-1][next], rings[len(rings)-1][j])
+	}
+}
+
+// addTube 円筒メッシュ追加
+//
+// 引数：
+//
+//	start： 中心軸の始点
+//	end： 中心軸の終点
+//	radius： 円筒の半径
+//	withCaps： 両端の円形の蓋を出力するかを示す
+func (m *Mesh) addTube(start, end spatial.Point3, radius float64, withCaps bool) {
+	axis := spatial.NewVectorFromPoints(start, end).Unit()
+
+	// 軸に直交する単位ベクトル
+	base := spatial.Vector3{Z: 1}
+	if math.Abs(axis.Z) > 0.9 {
+		base = spatial.Vector3{X: 1}
+	}
+	u := cross(axis, base).Unit()
+	v := cross(axis, u)
+
+	startRing := make([]int, 0, tubeSlices)
+	endRing := make([]int, 0, tubeSlices)
+	for j := 0; j < tubeSlices; j++ {
+		phi := 2 * math.Pi * float64(j) / tubeSlices
+		offset := spatial.Vector3{
+			X: radius * (math.Cos(phi)*u.X + math.Sin(phi)*v.X),
+			Y: radius * (math.Cos(phi)*u.Y + math.Sin(phi)*v.Y),
+			Z: radius * (math.Cos(phi)*u.Z + math.Sin(phi)*v.Z),
+		}
+		startRing = append(startRing, m.addVertex(start.Translate(offset)))
+		endRing = append(endRing, m.addVertex(end.Translate(offset)))
+	}
+
+	for j := 0; j < tubeSlices; j++ {
+		next := (j + 1) % tubeSlices
+		m.addTriangle(startRing[j], startRing[next], endRing[next])
+		m.addTriangle(startRing[j], endRing[next], endRing[j])
+	}
+
+	if !withCaps {
+		return
+	}
+
+	startCenter := m.addVertex(start)
+	endCenter := m.addVertex(end)
+	for j := 0; j < tubeSlices; j++ {
+		next := (j + 1) % tubeSlices
+		m.addTriangle(startCenter, startRing[next], startRing[j])
+		m.addTriangle(endCenter, endRing[j], endRing[next])
+	}
+}
+
+// cross ベクトルの外積
+func cross(a, b spatial.Vector3) spatial.Vector3 {
+	return spatial.Vector3{
+		X: a.Y*b.Z - a.Z*b.Y,
+		Y: a.Z*b.X - a.X*b.Z,
+		Z: a.X*b.Y - a.Y*b.X,
+	}
+}
+
+// dot ベクトルの内積
+func dot(a, b spatial.Vector3) float64 {
+	return a.X*b.X + a.Y*b.Y + a.Z*b.Z
+}
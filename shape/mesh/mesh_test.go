@@ -0,0 +1,299 @@
+package mesh
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+)
+
+// TestNewLocalFrame01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 原点：(139.753098, 35.685371, 11.0)
+//
+// + 確認内容
+//   - 原点の局所直交座標が(0, 0, 0)であること
+func TestNewLocalFrame01(t *testing.T) {
+	//入力パラメータ
+	origin, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+
+	// テスト対象呼び出し
+	frame := NewLocalFrame(origin)
+	resultVal := frame.ToLocal(origin.Lon(), origin.Lat(), origin.Alt())
+
+	// 原点が(0, 0, 0)に変換されること
+	if !resultVal.IsClose(spatial.Point3{}, 1e-6) {
+		t.Errorf("局所直交座標 - 期待値：%v, 取得値：%v", spatial.Point3{}, resultVal)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestToLocal01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 原点：(139.753098, 35.685371, 0.0)
+//   - 変換対象：原点の100m上空、原点から緯度方向に0.001度北
+//
+// + 確認内容
+//   - 上空の点がZ軸方向に100m離れていること
+//   - 北の点がY軸方向に約111m離れていること
+func TestToLocal01(t *testing.T) {
+	//入力パラメータ
+	origin, _ := object.NewPoint(139.753098, 35.685371, 0.0)
+	frame := NewLocalFrame(origin)
+
+	// テスト対象呼び出し
+	upper := frame.ToLocal(origin.Lon(), origin.Lat(), 100.0)
+	north := frame.ToLocal(origin.Lon(), origin.Lat()+0.001, 0.0)
+
+	// 上空の点の比較
+	if !upper.IsClose(spatial.Point3{Z: 100.0}, 1e-6) {
+		t.Errorf("上空の点 - 期待値：%v, 取得値：%v", spatial.Point3{Z: 100.0}, upper)
+	}
+
+	// 北の点の比較(緯度1度あたり約110.9km)
+	if math.Abs(north.X) > 1e-6 || north.Y < 110.0 || north.Y > 112.0 || math.Abs(north.Z) > 0.01 {
+		t.Errorf("北の点 - 期待値：(0, 約111, 0), 取得値：%v", north)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestNewVoxelMesh01 正常系動作確認(ボクセル1個)
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：25/29803148/13212522/25/0
+//
+// + 確認内容
+//   - 頂点数が8、三角形数が12であること
+//   - 全ての三角形の法線がボクセルの外側を向いていること
+//   - 戻り値としてエラー内容が返却されないこと
+func TestNewVoxelMesh01(t *testing.T) {
+	//入力パラメータ
+	spatialIDs := []string{"25/29803148/13212522/25/0"}
+	origin, _ := object.NewPoint(139.753098, 35.685371, 0.0)
+
+	// テスト対象呼び出し
+	resultVal, err := NewVoxelMesh(spatialIDs, NewLocalFrame(origin))
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	// 頂点数、三角形数の比較
+	if len(resultVal.Vertices) != 8 {
+		t.Errorf("頂点数 - 期待値：8, 取得値：%v", len(resultVal.Vertices))
+	}
+	if len(resultVal.Triangles) != 12 {
+		t.Errorf("三角形数 - 期待値：12, 取得値：%v", len(resultVal.Triangles))
+	}
+
+	// ボクセルの中心
+	center := spatial.Point3{}
+	for _, vertex := range resultVal.Vertices {
+		center.X += vertex.X / 8
+		center.Y += vertex.Y / 8
+		center.Z += vertex.Z / 8
+	}
+
+	// 三角形の法線が外向きであること
+	for _, triangle := range resultVal.Triangles {
+		a := resultVal.Vertices[triangle[0]]
+		b := resultVal.Vertices[triangle[1]]
+		c := resultVal.Vertices[triangle[2]]
+		normal := cross(spatial.NewVectorFromPoints(a, b), spatial.NewVectorFromPoints(a, c))
+		centroid := spatial.Point3{X: (a.X + b.X + c.X) / 3, Y: (a.Y + b.Y + c.Y) / 3, Z: (a.Z + b.Z + c.Z) / 3}
+		if dot(normal, spatial.NewVectorFromPoints(center, centroid)) <= 0 {
+			t.Errorf("三角形の法線が内向き - 三角形：%v", triangle)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestNewVoxelMesh02 正常系動作確認(隣接するボクセル2個)
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：25/29803148/13212522/25/0, 25/29803149/13212522/25/0
+//
+// + 確認内容
+//   - 共有面が除かれ、頂点数が12、三角形数が20であること
+func TestNewVoxelMesh02(t *testing.T) {
+	//入力パラメータ
+	spatialIDs := []string{"25/29803148/13212522/25/0", "25/29803149/13212522/25/0"}
+	origin, _ := object.NewPoint(139.753098, 35.685371, 0.0)
+
+	// テスト対象呼び出し
+	resultVal, err := NewVoxelMesh(spatialIDs, NewLocalFrame(origin))
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	// 頂点数、三角形数の比較
+	if len(resultVal.Vertices) != 12 {
+		t.Errorf("頂点数 - 期待値：12, 取得値：%v", len(resultVal.Vertices))
+	}
+	if len(resultVal.Triangles) != 20 {
+		t.Errorf("三角形数 - 期待値：20, 取得値：%v", len(resultVal.Triangles))
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestNewVoxelMesh03 異常系動作確認(拡張空間IDのフォーマット不正)
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：25/29803148/13212522/25(不正値)
+//
+// + 確認内容
+//   - 戻り値としてエラー内容が返却されること
+func TestNewVoxelMesh03(t *testing.T) {
+	// 期待値
+	expectErr := "InputValueError,入力チェックエラー"
+
+	//入力パラメータ
+	spatialIDs := []string{"25/29803148/13212522/25"}
+	origin, _ := object.NewPoint(139.753098, 35.685371, 0.0)
+
+	// テスト対象呼び出し
+	_, resultErr := NewVoxelMesh(spatialIDs, NewLocalFrame(origin))
+
+	if resultErr == nil || resultErr.Error() != expectErr {
+		// 戻り値のエラーインスタンスが期待値と異なる場合Errorをログに出力
+		t.Errorf("error - 期待値：%s, 取得値：%v\n", expectErr, resultErr)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestNewCylindersMesh01 正常系動作確認(接続点数が1)
+//
+// 試験詳細：
+// + 試験データ
+//   - 円柱の中心の接続点：(139.753098, 35.685371, 11.0)
+//   - 円柱の半径：2.0
+//
+// + 確認内容
+//   - 球のメッシュが返却されること
+//   - 全ての頂点が中心から半径の距離にあること
+func TestNewCylindersMesh01(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	radius := 2.0
+
+	// テスト対象呼び出し
+	resultVal, err := NewCylindersMesh([]*object.Point{p1}, radius, false, NewLocalFrame(p1))
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	// 頂点数、三角形数の比較
+	expectVertexNum := 2 + (sphereStacks-1)*tubeSlices
+	expectTriangleNum := 2 * (sphereStacks - 1) * tubeSlices
+	if len(resultVal.Vertices) != expectVertexNum {
+		t.Errorf("頂点数 - 期待値：%v, 取得値：%v", expectVertexNum, len(resultVal.Vertices))
+	}
+	if len(resultVal.Triangles) != expectTriangleNum {
+		t.Errorf("三角形数 - 期待値：%v, 取得値：%v", expectTriangleNum, len(resultVal.Triangles))
+	}
+
+	// 頂点と中心の距離の比較
+	for _, vertex := range resultVal.Vertices {
+		distance := spatial.NewVectorFromPoints(spatial.Point3{}, vertex).Norm()
+		if math.Abs(distance-radius) > 1e-6 {
+			t.Errorf("中心からの距離 - 期待値：%v, 取得値：%v", radius, distance)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestNewCylindersMesh02 正常系動作確認(円柱とカプセル)
+//
+// 試験詳細：
+// + 試験データ
+//   - 円柱の中心の接続点：3点
+//   - 円柱の半径：2.0
+//   - 始点、終点の球状判定：false, true
+//
+// + 確認内容
+//   - 円柱の場合、円筒2本(蓋あり)と接続点の球1個のメッシュが返却されること
+//   - カプセルの場合、円筒2本(蓋なし)と始点・終点の球4個のメッシュが返却されること
+func TestNewCylindersMesh02(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+	p3, _ := object.NewPoint(139.753198, 35.685471, 21.0)
+	center := []*object.Point{p1, p2, p3}
+	radius := 2.0
+	frame := NewLocalFrame(p1)
+
+	// 期待値
+	sphereVertexNum := 2 + (sphereStacks-1)*tubeSlices
+	cylinderExpectVal := 2*(2*tubeSlices+2) + sphereVertexNum
+	capsuleExpectVal := 2*(2*tubeSlices) + 4*sphereVertexNum
+
+	// テスト対象呼び出し
+	cylinder, cylinderErr := NewCylindersMesh(center, radius, false, frame)
+	capsule, capsuleErr := NewCylindersMesh(center, radius, true, frame)
+
+	// エラーが返された場合はErrorをログに出力
+	if cylinderErr != nil || capsuleErr != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v, %v", cylinderErr, capsuleErr)
+	}
+
+	// 頂点数の比較
+	if len(cylinder.Vertices) != cylinderExpectVal {
+		t.Errorf("円柱の頂点数 - 期待値：%v, 取得値：%v", cylinderExpectVal, len(cylinder.Vertices))
+	}
+	if len(capsule.Vertices) != capsuleExpectVal {
+		t.Errorf("カプセルの頂点数 - 期待値：%v, 取得値：%v", capsuleExpectVal, len(capsule.Vertices))
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestNewCylindersMesh03 異常系動作確認(入力値不正)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：円柱の半径が0
+//   - パターン2：接続点にnilを含む
+//
+// + 確認内容
+//   - 戻り値としてエラー内容が返却されること
+func TestNewCylindersMesh03(t *testing.T) {
+	// 期待値
+	expectErr := "InputValueError,入力チェックエラー"
+
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	frame := NewLocalFrame(p1)
+
+	// テスト対象呼び出し
+	_, resultErr1 := NewCylindersMesh([]*object.Point{p1}, 0.0, false, frame)
+	_, resultErr2 := NewCylindersMesh([]*object.Point{p1, nil}, 2.0, false, frame)
+
+	for _, resultErr := range []error{resultErr1, resultErr2} {
+		if resultErr == nil || !reflect.DeepEqual(resultErr.Error(), expectErr) {
+			// 戻り値のエラーインスタンスが期待値と異なる場合Errorをログに出力
+			t.Errorf("error - 期待値：%s, 取得値：%v\n", expectErr, resultErr)
+		}
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,334 @@
+package mesh
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+)
+
+// toYUp 局所直交座標(Z軸上向き)からY軸上向きの座標への変換
+//
+// OBJ形式、glTF形式はY軸上向きの右手系を前提とするため、
+// 東をX軸、上をY軸、南をZ軸とする座標に変換する。
+//
+// 引数：
+//
+//	point： 局所直交座標
+//
+// 戻り値：
+//
+//	Y軸上向きの座標
+func toYUp(point spatial.Point3) spatial.Point3 {
+	return spatial.Point3{X: point.X, Y: point.Z, Z: -point.Y}
+}
+
+// WriteOBJ OBJ形式出力
+//
+// メッシュをWavefront OBJ形式で出力する。メッシュごとにオブジェクトを分ける。
+// 座標はY軸上向き(X:東, Y:上, Z:南)で出力する。
+//
+// 引数：
+//
+//	w： 出力先
+//	meshes： 出力するメッシュ
+//
+// 戻り値(エラー)：
+//
+//	出力先への書き込みに失敗した場合、エラーインスタンスが返却される。
+func WriteOBJ(w io.Writer, meshes ...*Mesh) error {
+	writer := bufio.NewWriter(w)
+
+	fmt.Fprintln(writer, "# spatial_id_plus_go mesh")
+
+	// OBJ形式の頂点インデックスは1始まりかつファイル全体の通し番号
+	offset := 1
+	for _, mesh := range meshes {
+		if len(mesh.Vertices) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(writer, "o %s\n", mesh.Name)
+		for _, vertex := range mesh.Vertices {
+			v := toYUp(vertex)
+			fmt.Fprintf(writer, "v %f %f %f\n", v.X, v.Y, v.Z)
+		}
+		for _, triangle := range mesh.Triangles {
+			fmt.Fprintf(writer, "f %d %d %d\n",
+				triangle[0]+offset, triangle[1]+offset, triangle[2]+offset)
+		}
+		offset += len(mesh.Vertices)
+	}
+
+	return writer.Flush()
+}
+
+// WritePLY PLY形式出力
+//
+// メッシュを1つにまとめ、頂点色付きのASCII PLY形式で出力する。
+// 座標は局所直交座標(X:東, Y:北, Z:上)のまま出力する。
+//
+// 引数：
+//
+//	w： 出力先
+//	meshes： 出力するメッシュ
+//
+// 戻り値(エラー)：
+//
+//	出力先への書き込みに失敗した場合、エラーインスタンスが返却される。
+func WritePLY(w io.Writer, meshes ...*Mesh) error {
+	writer := bufio.NewWriter(w)
+
+	vertexNum := 0
+	triangleNum := 0
+	for _, mesh := range meshes {
+		vertexNum += len(mesh.Vertices)
+		triangleNum += len(mesh.Triangles)
+	}
+
+	fmt.Fprintln(writer, "ply")
+	fmt.Fprintln(writer, "format ascii 1.0")
+	fmt.Fprintln(writer, "comment spatial_id_plus_go mesh")
+	fmt.Fprintf(writer, "element vertex %d\n", vertexNum)
+	fmt.Fprintln(writer, "property float x")
+	fmt.Fprintln(writer, "property float y")
+	fmt.Fprintln(writer, "property float z")
+	fmt.Fprintln(writer, "property uchar red")
+	fmt.Fprintln(writer, "property uchar green")
+	fmt.Fprintln(writer, "property uchar blue")
+	fmt.Fprintln(writer, "property uchar alpha")
+	fmt.Fprintf(writer, "element face %d\n", triangleNum)
+	fmt.Fprintln(writer, "property list uchar int vertex_indices")
+	fmt.Fprintln(writer, "end_header")
+
+	for _, mesh := range meshes {
+		r, g, b, a := toColorBytes(mesh.Color)
+		for _, vertex := range mesh.Vertices {
+			fmt.Fprintf(writer, "%f %f %f %d %d %d %d\n", vertex.X, vertex.Y, vertex.Z, r, g, b, a)
+		}
+	}
+
+	offset := 0
+	for _, mesh := range meshes {
+		for _, triangle := range mesh.Triangles {
+			fmt.Fprintf(writer, "3 %d %d %d\n",
+				triangle[0]+offset, triangle[1]+offset, triangle[2]+offset)
+		}
+		offset += len(mesh.Vertices)
+	}
+
+	return writer.Flush()
+}
+
+// toColorBytes 表示色を0～255の値に変換
+//
+// 引数：
+//
+//	color： 表示色(RGBA, 0～1)
+//
+// 戻り値：
+//
+//	表示色(RGBA, 0～255)
+func toColorBytes(color [4]float64) (uint8, uint8, uint8, uint8) {
+	values := [4]uint8{}
+	for i, c := range color {
+		values[i] = uint8(math.Round(math.Max(0, math.Min(1, c)) * 255))
+	}
+	return values[0], values[1], values[2], values[3]
+}
+
+// glTF形式の定数
+const (
+	gltfFloat        = 5126  // コンポーネント型(float)
+	gltfUnsignedInt  = 5125  // コンポーネント型(unsigned int)
+	gltfArrayBuffer  = 34962 // 頂点バッファ
+	gltfElementArray = 34963 // インデックスバッファ
+)
+
+// gltfDocument glTF形式のJSON構造
+type gltfDocument struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes,omitempty"`
+	Meshes      []gltfMesh       `json:"meshes,omitempty"`
+	Materials   []gltfMaterial   `json:"materials,omitempty"`
+	Accessors   []gltfAccessor   `json:"accessors,omitempty"`
+	BufferViews []gltfBufferView `json:"bufferViews,omitempty"`
+	Buffers     []gltfBuffer     `json:"buffers,omitempty"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes,omitempty"`
+}
+
+type gltfNode struct {
+	Name string `json:"name"`
+	Mesh int    `json:"mesh"`
+}
+
+type gltfMesh struct {
+	Name       string          `json:"name"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Material   int            `json:"material"`
+}
+
+type gltfMaterial struct {
+	Name                 string                   `json:"name"`
+	PbrMetallicRoughness gltfPbrMetallicRoughness `json:"pbrMetallicRoughness"`
+	AlphaMode            string                   `json:"alphaMode"`
+	DoubleSided          bool                     `json:"doubleSided"`
+}
+
+type gltfPbrMetallicRoughness struct {
+	BaseColorFactor [4]float64 `json:"baseColorFactor"`
+	MetallicFactor  float64    `json:"metallicFactor"`
+	RoughnessFactor float64    `json:"roughnessFactor"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float64 `json:"min,omitempty"`
+	Max           []float64 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target"`
+}
+
+type gltfBuffer struct {
+	ByteLength int    `json:"byteLength"`
+	URI        string `json:"uri"`
+}
+
+// WriteGLTF glTF形式出力
+//
+// メッシュをglTF 2.0形式(バッファをData URIで埋め込んだ.gltf)で出力する。
+// メッシュごとにノードとマテリアルを分け、透過色のメッシュは半透明で表示する。
+// 座標はY軸上向き(X:東, Y:上, Z:南)で出力する。
+//
+// 引数：
+//
+//	w： 出力先
+//	meshes： 出力するメッシュ
+//
+// 戻り値(エラー)：
+//
+//	出力先への書き込みに失敗した場合、エラーインスタンスが返却される。
+func WriteGLTF(w io.Writer, meshes ...*Mesh) error {
+	doc := gltfDocument{
+		Asset:  gltfAsset{Version: "2.0", Generator: "spatial_id_plus_go"},
+		Scenes: []gltfScene{{}},
+	}
+	buffer := new(bytes.Buffer)
+
+	for _, mesh := range meshes {
+		if len(mesh.Vertices) == 0 || len(mesh.Triangles) == 0 {
+			continue
+		}
+
+		// 頂点座標
+		minValues := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+		maxValues := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+		positionOffset := buffer.Len()
+		for _, vertex := range mesh.Vertices {
+			v := toYUp(vertex)
+			for i, value := range []float64{v.X, v.Y, v.Z} {
+				// アクセサの最小値・最大値は書き込むfloat32の値と一致させる
+				value = float64(float32(value))
+				minValues[i] = math.Min(minValues[i], value)
+				maxValues[i] = math.Max(maxValues[i], value)
+				binary.Write(buffer, binary.LittleEndian, float32(value))
+			}
+		}
+		positionLength := buffer.Len() - positionOffset
+
+		// 頂点インデックス
+		indexOffset := buffer.Len()
+		for _, triangle := range mesh.Triangles {
+			for _, index := range triangle {
+				binary.Write(buffer, binary.LittleEndian, uint32(index))
+			}
+		}
+		indexLength := buffer.Len() - indexOffset
+
+		doc.BufferViews = append(doc.BufferViews,
+			gltfBufferView{ByteOffset: positionOffset, ByteLength: positionLength, Target: gltfArrayBuffer},
+			gltfBufferView{ByteOffset: indexOffset, ByteLength: indexLength, Target: gltfElementArray},
+		)
+		doc.Accessors = append(doc.Accessors,
+			gltfAccessor{
+				BufferView:    len(doc.BufferViews) - 2,
+				ComponentType: gltfFloat,
+				Count:         len(mesh.Vertices),
+				Type:          "VEC3",
+				Min:           minValues,
+				Max:           maxValues,
+			},
+			gltfAccessor{
+				BufferView:    len(doc.BufferViews) - 1,
+				ComponentType: gltfUnsignedInt,
+				Count:         len(mesh.Triangles) * 3,
+				Type:          "SCALAR",
+			},
+		)
+
+		alphaMode := "OPAQUE"
+		if mesh.Color[3] < 1 {
+			alphaMode = "BLEND"
+		}
+		doc.Materials = append(doc.Materials, gltfMaterial{
+			Name: mesh.Name,
+			PbrMetallicRoughness: gltfPbrMetallicRoughness{
+				BaseColorFactor: mesh.Color,
+				RoughnessFactor: 1,
+			},
+			AlphaMode:   alphaMode,
+			DoubleSided: true,
+		})
+		doc.Meshes = append(doc.Meshes, gltfMesh{
+			Name: mesh.Name,
+			Primitives: []gltfPrimitive{{
+				Attributes: map[string]int{"POSITION": len(doc.Accessors) - 2},
+				Indices:    len(doc.Accessors) - 1,
+				Material:   len(doc.Materials) - 1,
+			}},
+		})
+		doc.Nodes = append(doc.Nodes, gltfNode{Name: mesh.Name, Mesh: len(doc.Meshes) - 1})
+		doc.Scenes[0].Nodes = append(doc.Scenes[0].Nodes, len(doc.Nodes)-1)
+	}
+
+	// 出力するメッシュが無い場合はバッファを省略
+	if buffer.Len() > 0 {
+		doc.Buffers = []gltfBuffer{{
+			ByteLength: buffer.Len(),
+			URI:        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(buffer.Bytes()),
+		}}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
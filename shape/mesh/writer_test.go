@@ -0,0 +1,187 @@
+package mesh
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+)
+
+// newTestMesh 試験用の三角形1枚のメッシュを作成
+func newTestMesh(name string, alpha float64) *Mesh {
+	return &Mesh{
+		Name: name,
+		Vertices: []spatial.Point3{
+			{X: 0, Y: 0, Z: 0},
+			{X: 1, Y: 0, Z: 0},
+			{X: 0, Y: 1, Z: 2},
+		},
+		Triangles: [][3]int{{0, 1, 2}},
+		Color:     [4]float64{1, 0, 0, alpha},
+	}
+}
+
+// TestWriteOBJ01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 三角形1枚のメッシュ2個
+//
+// + 確認内容
+//   - メッシュごとにオブジェクトが出力されること
+//   - 頂点インデックスがファイル全体の通し番号(1始まり)であること
+//   - 頂点座標がY軸上向きに変換されていること
+func TestWriteOBJ01(t *testing.T) {
+	//入力パラメータ
+	buffer := new(bytes.Buffer)
+
+	// テスト対象呼び出し
+	err := WriteOBJ(buffer, newTestMesh("voxels", 1), newTestMesh("cylinders", 0.5))
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	resultVal := buffer.String()
+	for _, expectLine := range []string{
+		"o voxels\n",
+		"o cylinders\n",
+		"v 0.000000 2.000000 -1.000000\n",
+		"f 1 2 3\n",
+		"f 4 5 6\n",
+	} {
+		if !strings.Contains(resultVal, expectLine) {
+			t.Errorf("出力内容 - 期待値：%q を含む, 取得値：%v", expectLine, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestWritePLY01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 三角形1枚のメッシュ2個
+//
+// + 確認内容
+//   - ヘッダの頂点数が6、面数が2であること
+//   - 2個目のメッシュの頂点インデックスがずれて出力されること
+//   - 頂点色が出力されること
+func TestWritePLY01(t *testing.T) {
+	//入力パラメータ
+	buffer := new(bytes.Buffer)
+
+	// テスト対象呼び出し
+	err := WritePLY(buffer, newTestMesh("voxels", 1), newTestMesh("cylinders", 0.5))
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	resultVal := buffer.String()
+	for _, expectLine := range []string{
+		"element vertex 6\n",
+		"element face 2\n",
+		"0.000000 1.000000 2.000000 255 0 0 255\n",
+		"0.000000 1.000000 2.000000 255 0 0 128\n",
+		"3 3 4 5\n",
+	} {
+		if !strings.Contains(resultVal, expectLine) {
+			t.Errorf("出力内容 - 期待値：%q を含む, 取得値：%v", expectLine, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestWriteGLTF01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 三角形1枚のメッシュ2個(不透明、半透明)
+//
+// + 確認内容
+//   - glTF 2.0のJSONとして読み込めること
+//   - メッシュ、ノード、マテリアルが2個ずつ出力されること
+//   - バッファの長さが埋め込まれたデータの長さと一致すること
+//   - 半透明のマテリアルがBLENDで出力されること
+func TestWriteGLTF01(t *testing.T) {
+	//入力パラメータ
+	buffer := new(bytes.Buffer)
+
+	// テスト対象呼び出し
+	err := WriteGLTF(buffer, newTestMesh("voxels", 1), newTestMesh("cylinders", 0.5))
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	resultVal := gltfDocument{}
+	if err := json.Unmarshal(buffer.Bytes(), &resultVal); err != nil {
+		t.Fatalf("JSON読込 - 期待値：nil, 取得値：%v", err)
+	}
+
+	if resultVal.Asset.Version != "2.0" {
+		t.Errorf("バージョン - 期待値：2.0, 取得値：%v", resultVal.Asset.Version)
+	}
+	if len(resultVal.Meshes) != 2 || len(resultVal.Nodes) != 2 || len(resultVal.Materials) != 2 {
+		t.Errorf("メッシュ、ノード、マテリアル数 - 期待値：2, 取得値：%v, %v, %v",
+			len(resultVal.Meshes), len(resultVal.Nodes), len(resultVal.Materials))
+	}
+	if len(resultVal.Accessors) != 4 || resultVal.Accessors[0].Count != 3 || resultVal.Accessors[1].Count != 3 {
+		t.Errorf("アクセサ - 期待値：4個(要素数3), 取得値：%v", resultVal.Accessors)
+	}
+
+	// バッファの長さの比較(頂点座標 3×3×4byte + インデックス 3×4byte)×2
+	data, _ := base64.StdEncoding.DecodeString(
+		strings.TrimPrefix(resultVal.Buffers[0].URI, "data:application/octet-stream;base64,"),
+	)
+	if resultVal.Buffers[0].ByteLength != 96 || len(data) != 96 {
+		t.Errorf("バッファの長さ - 期待値：96, 取得値：%v, %v", resultVal.Buffers[0].ByteLength, len(data))
+	}
+
+	// アルファモードの比較
+	if resultVal.Materials[0].AlphaMode != "OPAQUE" || resultVal.Materials[1].AlphaMode != "BLEND" {
+		t.Errorf("アルファモード - 期待値：OPAQUE, BLEND, 取得値：%v, %v",
+			resultVal.Materials[0].AlphaMode, resultVal.Materials[1].AlphaMode)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestWriteGLTF02 正常系動作確認(空のメッシュ)
+//
+// 試験詳細：
+// + 試験データ
+//   - 頂点の無いメッシュ
+//
+// + 確認内容
+//   - メッシュ、バッファが出力されないこと
+func TestWriteGLTF02(t *testing.T) {
+	//入力パラメータ
+	buffer := new(bytes.Buffer)
+
+	// テスト対象呼び出し
+	err := WriteGLTF(buffer, &Mesh{Name: "voxels"})
+
+	// エラーが返された場合はErrorをログに出力
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	resultVal := gltfDocument{}
+	json.Unmarshal(buffer.Bytes(), &resultVal)
+	if len(resultVal.Meshes) != 0 || len(resultVal.Buffers) != 0 {
+		t.Errorf("メッシュ、バッファ数 - 期待値：0, 取得値：%v, %v",
+			len(resultVal.Meshes), len(resultVal.Buffers))
+	}
+
+	t.Log("テスト終了")
+}
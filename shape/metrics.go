@@ -0,0 +1,444 @@
+package shape
+
+import (
+	"sort"
+
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// voxelSizes 同一緯度、同一精度のボクセルの大きさ(単位:m)のキャッシュ
+type voxelSizes map[voxelIndex]spatial.Vector3
+
+// get ボクセルの大きさ(単位:m)を取得
+//
+// 引数：
+//
+//	index： ボクセルの成分インデックス
+//
+// 戻り値：
+//
+//	ボクセルの東西、南北、高さ方向の大きさ
+func (s voxelSizes) get(index voxelIndex) spatial.Vector3 {
+	key := voxelIndex{hZoom: index.hZoom, y: index.y, vZoom: index.vZoom}
+	if size, ok := s[key]; ok {
+		return size
+	}
+	s[key] = voxelSizeMeters(key)
+	return s[key]
+}
+
+// covers 集合のボクセルに含まれるかの判定
+//
+// 引数：
+//
+//	index： 判定するボクセルの成分インデックス
+//	self： 判定するボクセル自身を含める場合true
+//
+// 戻り値：
+//
+//	水平方向精度、垂直方向精度がともに同じか粗い集合のボクセルに含まれる場合true
+func (s *voxelSet) covers(index voxelIndex, self bool) bool {
+	for _, zoom := range s.zooms {
+		if zoom[0] > index.hZoom || zoom[1] > index.vZoom {
+			continue
+		}
+		if !self && zoom == [2]int64{index.hZoom, index.vZoom} {
+			continue
+		}
+		h, v := index.hZoom-zoom[0], index.vZoom-zoom[1]
+		ancestor := voxelIndex{hZoom: zoom[0], x: index.x >> h, y: index.y >> h, vZoom: zoom[1], f: index.f >> v}
+		if s.voxels[ancestor] {
+			return true
+		}
+	}
+	return false
+}
+
+// outermost 他のボクセルに含まれないボクセルの取得
+//
+// 戻り値：
+//
+//	他のボクセルに含まれないボクセルの成分インデックスのリスト
+func (s *voxelSet) outermost() []voxelIndex {
+	results := make([]voxelIndex, 0, len(s.voxels))
+	for index := range s.voxels {
+		if !s.covers(index, false) {
+			results = append(results, index)
+		}
+	}
+	return results
+}
+
+// voxelColumn 水平方向のボクセルの柱構造体
+//
+// 水平方向の位置が同じ拡張空間IDの高さ方向の範囲に、水平方向に含む粗い精度の柱の範囲を加えたもの。
+// 水平方向精度、垂直方向精度の一方のみが粗い拡張空間IDどうしの重なりも、高さ方向の範囲の和集合で除く。
+type voxelColumn struct {
+	index     voxelIndex   // 水平方向のボクセル(水平方向精度、X、Y成分のみ)
+	intervals [][2]int64   // 自身と水平方向に含む柱の高さ成分インデックスの範囲の和集合(昇順、重複なし)
+	children  []voxelIndex // 水平方向に含まれる最も近い細かい精度の柱
+}
+
+// newVoxelColumns 水平方向のボクセルの柱の取得
+//
+// 引数：
+//
+//	vZoom： 高さ成分インデックスを換算する垂直方向精度(集合の最も細かい垂直方向精度以上)
+//
+// 戻り値：
+//
+//	水平方向のボクセルごとの柱
+func (s *voxelSet) newVoxelColumns(vZoom int64) map[voxelIndex]*voxelColumn {
+	columns := map[voxelIndex]*voxelColumn{}
+	for index := range s.voxels {
+		key := voxelIndex{hZoom: index.hZoom, x: index.x, y: index.y}
+		column, ok := columns[key]
+		if !ok {
+			column = &voxelColumn{index: key}
+			columns[key] = column
+		}
+		d := vZoom - index.vZoom
+		column.intervals = append(column.intervals, [2]int64{index.f << d, (index.f+1)<<d - 1})
+	}
+
+	// 粗い精度の柱から順に、水平方向に含む最も近い柱の範囲を加える
+	keys := make([]voxelIndex, 0, len(columns))
+	for key := range columns {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].hZoom < keys[j].hZoom })
+	for _, key := range keys {
+		column := columns[key]
+		for h := key.hZoom - 1; h >= 0; h-- {
+			d := key.hZoom - h
+			if parent, ok := columns[voxelIndex{hZoom: h, x: key.x >> d, y: key.y >> d}]; ok {
+				column.intervals = append(column.intervals, parent.intervals...)
+				parent.children = append(parent.children, key)
+				break
+			}
+		}
+		column.intervals = mergeIntervals(column.intervals)
+	}
+	return columns
+}
+
+// mergeIntervals 成分インデックスの範囲の和集合
+//
+// 引数：
+//
+//	intervals： 成分インデックスの範囲(最小値、最大値)のリスト
+//
+// 戻り値：
+//
+//	重なる、または接する範囲を統合した範囲のリスト(昇順)
+func mergeIntervals(intervals [][2]int64) [][2]int64 {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i][0] < intervals[j][0] })
+	merged := make([][2]int64, 0, len(intervals))
+	for _, interval := range intervals {
+		if last := len(merged) - 1; last >= 0 && interval[0] <= merged[last][1]+1 {
+			merged[last][1] = max(merged[last][1], interval[1])
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+// maxVZoom 集合の最も細かい垂直方向精度の取得
+//
+// 戻り値：
+//
+//	集合に含まれる垂直方向精度の最大値
+func (s *voxelSet) maxVZoom() int64 {
+	vZoom := int64(0)
+	for _, zoom := range s.zooms {
+		vZoom = max(vZoom, zoom[1])
+	}
+	return vZoom
+}
+
+// CalcExtendedSpatialIdsVolume 拡張空間IDの体積の算出
+//
+// 拡張空間IDの体積(単位:m^3)の合計を算出する。
+// ボクセルの水平方向の大きさは、各拡張空間IDの緯度のボクセルの大きさ(単位:m)とする。
+// 重複する拡張空間ID、他の粗い精度の拡張空間IDに含まれる部分は除いて算出する。
+// 水平方向精度、垂直方向精度の一方のみが粗い拡張空間IDどうしの重なりも除く。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//
+// 戻り値：
+//
+//	体積(単位:m^3)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDのフォーマットが不正な場合、もしくは精度が 0 ～ 35 の整数値以外の場合
+func CalcExtendedSpatialIdsVolume(spatialIDs []string) (float64, error) {
+	set, err := newVoxelSet(spatialIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	// 柱ごとに、水平方向に含まれる細かい精度の柱を除いた面積と高さ方向の範囲の積を合計する
+	vZoom := set.maxVZoom()
+	sizes := voxelSizes{}
+	volume := 0.0
+	for _, column := range set.newVoxelColumns(vZoom) {
+		area, _, _ := columnArea(column, vZoom, sizes, false)
+		height := sizes.get(voxelIndex{hZoom: column.index.hZoom, y: column.index.y, vZoom: vZoom}).Z
+		for _, interval := range column.intervals {
+			volume += area * float64(interval[1]-interval[0]+1) * height
+		}
+	}
+	return volume, nil
+}
+
+// columnArea 柱の水平方向に含まれる細かい精度の柱を除いた面積の算出
+//
+// 引数：
+//
+//	column： 水平方向のボクセルの柱
+//	vZoom： 大きさを取得する垂直方向精度
+//	sizes： ボクセルの大きさのキャッシュ
+//	crossing： 経度180度をまたがる範囲で算出する場合true(西半球の経度を360度ずらす)
+//
+// 戻り値：
+//
+//	面積(単位:m^2)、面積で重み付けした経度、緯度の合計
+func columnArea(column *voxelColumn, vZoom int64, sizes voxelSizes, crossing bool) (float64, float64, float64) {
+	var area, lon, lat float64
+	for i, index := range append([]voxelIndex{column.index}, column.children...) {
+		index.vZoom = vZoom
+		size := sizes.get(index)
+		centers, _ := shape.GetPointOnExtendedSpatialId(index.String(), enum.Center)
+		centerLon := centers[0].Lon()
+		if crossing && centerLon < 0 {
+			centerLon += 360
+		}
+		sign := 1.0
+		if i > 0 {
+			sign = -1
+		}
+		area += sign * size.X * size.Y
+		lon += sign * size.X * size.Y * centerLon
+		lat += sign * size.X * size.Y * centers[0].Lat()
+	}
+	return area, lon, lat
+}
+
+// CalcExtendedSpatialIdsSurfaceArea 拡張空間IDの表面積の算出
+//
+// 拡張空間IDの集合の外側に接する面の面積(単位:m^2)の合計を算出する。
+// 面の反対側が集合のボクセルに含まれる場合は面積に含めない。
+// 精度の異なる拡張空間IDは、面を最も細かい精度のボクセルの面に分割して判定する。
+// 重なる拡張空間IDの同じ位置の面は1回のみ面積に含める。
+// 分割する面の数は精度の差に対して指数的に増えるため、水平方向精度、垂直方向精度それぞれの
+// 最大値と最小値の差はmaxSurfaceZoomDifference以下とする。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//
+// 戻り値：
+//
+//	表面積(単位:m^2)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDのフォーマットが不正な場合、精度が 0 ～ 35 の整数値以外の場合、
+//	               もしくは精度の差がmaxSurfaceZoomDifferenceを超える場合
+func CalcExtendedSpatialIdsSurfaceArea(spatialIDs []string) (float64, error) {
+	set, err := newVoxelSet(spatialIDs)
+	if err != nil {
+		return 0, err
+	}
+	hZoom, vZoom := int64(0), int64(0)
+	minHZoom, minVZoom := int64(35), int64(35)
+	for _, zoom := range set.zooms {
+		hZoom, vZoom = max(hZoom, zoom[0]), max(vZoom, zoom[1])
+		minHZoom, minVZoom = min(minHZoom, zoom[0]), min(minVZoom, zoom[1])
+	}
+	if hZoom-minHZoom > maxSurfaceZoomDifference || vZoom-minVZoom > maxSurfaceZoomDifference {
+		return 0, errors.NewSpatialIdError(errors.InputValueErrorCode, "精度の差が大きすぎます")
+	}
+
+	// 面の外側に接する最も細かい精度のボクセル、面に垂直な軸、面の向きごとの面積
+	type faceKey struct {
+		cell [3]int64
+		axis int
+		side int64
+	}
+	faceAreas := map[faceKey]float64{}
+	sizes := voxelSizes{}
+	xSize := int64(1) << hZoom
+	for _, index := range set.outermost() {
+		size := sizes.get(index)
+		// 最も細かい精度に換算した成分インデックスの範囲と、分割した面の数
+		voxel := newVoxelRange(index, hZoom, vZoom)
+		h, v := int64(1)<<(hZoom-index.hZoom), int64(1)<<(vZoom-index.vZoom)
+		faces := []struct {
+			axis  int
+			area  float64
+			count int64
+		}{
+			{0, size.Y * size.Z, h * v},
+			{1, size.X * size.Z, h * v},
+			{2, size.X * size.Y, h * h},
+		}
+		for _, face := range faces {
+			for _, side := range []int64{-1, 1} {
+				forEachFaceCell(voxel, face.axis, side, func(cell [3]int64) {
+					// 経度180度をまたがる場合は反対側のボクセルで判定する
+					x := (cell[0]%xSize + xSize) % xSize
+					neighbour := voxelIndex{hZoom: hZoom, x: x, y: cell[1], vZoom: vZoom, f: cell[2]}
+					if !set.covers(neighbour, true) {
+						key := faceKey{cell: [3]int64{x, cell[1], cell[2]}, axis: face.axis, side: side}
+						faceAreas[key] = face.area / float64(face.count)
+					}
+				})
+			}
+		}
+	}
+
+	area := 0.0
+	for _, faceArea := range faceAreas {
+		area += faceArea
+	}
+	return area, nil
+}
+
+// maxSurfaceZoomDifference 表面積の算出で許容する精度の差の最大値
+const maxSurfaceZoomDifference = 10
+
+// forEachFaceCell 面の外側に接する最も細かい精度のボクセルの列挙
+//
+// 引数：
+//
+//	voxel： 最も細かい精度に換算した成分インデックスの範囲
+//	axis： 面に垂直な軸(0：X、1：Y、2：高さ)
+//	side： 負の側の面の場合-1、正の側の面の場合1
+//	f： 面の外側に接するボクセルの成分インデックスを受け取る関数
+func forEachFaceCell(voxel voxelRange, axis int, side int64, f func([3]int64)) {
+	low, high := voxel.min, voxel.max
+	if side < 0 {
+		low[axis], high[axis] = voxel.min[axis]-1, voxel.min[axis]-1
+	} else {
+		low[axis], high[axis] = voxel.max[axis]+1, voxel.max[axis]+1
+	}
+	for x := low[0]; x <= high[0]; x++ {
+		for y := low[1]; y <= high[1]; y++ {
+			for z := low[2]; z <= high[2]; z++ {
+				f([3]int64{x, y, z})
+			}
+		}
+	}
+}
+
+// CalcExtendedSpatialIdsBoundingBox 拡張空間IDに外接する範囲の算出
+//
+// 経度180度をまたがる範囲の方が狭い場合は、経度180度をまたがる範囲(WestがEastより大きい)を返却する。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//
+// 戻り値：
+//
+//	外接する範囲
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDが空の場合、拡張空間IDのフォーマットが不正な場合、
+//	               もしくは精度が 0 ～ 35 の整数値以外の場合
+func CalcExtendedSpatialIdsBoundingBox(spatialIDs []string) (BoundingBox, error) {
+	if len(spatialIDs) == 0 {
+		return BoundingBox{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	if _, err := parseVoxelSet(spatialIDs); err != nil {
+		return BoundingBox{}, err
+	}
+	box, _ := calcNarrowBoundingBox(spatialIDs)
+	return box, nil
+}
+
+// calcNarrowBoundingBox 経度方向が狭い方の外接する範囲の算出
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//
+// 戻り値：
+//
+//	外接する範囲、経度180度をまたがる範囲の場合true
+func calcNarrowBoundingBox(spatialIDs []string) (BoundingBox, bool) {
+	box := calcBoundingBox(spatialIDs, false)
+	crossingBox := calcBoundingBox(spatialIDs, true)
+	width := crossingBox.East - crossingBox.West
+	if width < 0 {
+		width += 360
+	}
+	if width < box.East-box.West {
+		return crossingBox, true
+	}
+	return box, false
+}
+
+// CalcExtendedSpatialIdsCentroid 拡張空間IDの重心の算出
+//
+// 拡張空間IDの中心を体積で重み付けした平均を重心とする。
+// 経度180度をまたがる範囲の方が狭い場合は、経度180度をまたがる範囲で平均する。
+// 重複する部分はCalcExtendedSpatialIdsVolumeと同様に除いて算出する。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//
+// 戻り値：
+//
+//	重心
+//
+// 戻り値(エラー)：
+//
+//	CalcExtendedSpatialIdsBoundingBoxと同じ
+func CalcExtendedSpatialIdsCentroid(spatialIDs []string) (*object.Point, error) {
+	if len(spatialIDs) == 0 {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	set, err := newVoxelSet(spatialIDs)
+	if err != nil {
+		return nil, err
+	}
+	_, crossing := calcNarrowBoundingBox(spatialIDs)
+
+	// 柱ごとに水平方向と高さ方向の重み付けの合計を掛け合わせる
+	vZoom := set.maxVZoom()
+	sizes := voxelSizes{}
+	var lon, lat, alt, volume float64
+	for _, column := range set.newVoxelColumns(vZoom) {
+		area, areaLon, areaLat := columnArea(column, vZoom, sizes, crossing)
+		height := sizes.get(voxelIndex{hZoom: column.index.hZoom, y: column.index.y, vZoom: vZoom}).Z
+		for _, interval := range column.intervals {
+			length := float64(interval[1]-interval[0]+1) * height
+			lon += areaLon * length
+			lat += areaLat * length
+			// 範囲の中心の高さ
+			alt += area * length * float64(interval[0]+interval[1]+1) / 2 * height
+			volume += area * length
+		}
+	}
+
+	lon = lon / volume
+	if lon > 180 {
+		lon -= 360
+	}
+	return object.NewPoint(lon, lat/volume, alt/volume)
+}
@@ -0,0 +1,313 @@
+package shape
+
+import (
+	"math"
+	"testing"
+)
+
+// TestCalcExtendedSpatialIdsVolume01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：20/931339/412910/20/3(緯度約35.68度、東西、南北約31.0m、高さ32.0mのボクセル)
+//   - パターン1：1個
+//   - パターン2：精度21の8個の子ボクセル
+//   - パターン3：1個と精度21の8個の子ボクセル、重複する1個
+//   - パターン4：緯度0度付近、60度付近の同じ精度の拡張空間ID
+//
+// + 確認内容
+//   - パターン1：体積が約31.0×31.0×32.0m^3であること
+//   - パターン2、3：体積がパターン1と一致すること
+//   - パターン4：緯度60度付近の体積が緯度0度付近の約1/4であること
+func TestCalcExtendedSpatialIdsVolume01(t *testing.T) {
+	//入力パラメータ
+	spatialID := "20/931339/412910/20/3"
+	index, _ := parseVoxelIndex(spatialID)
+	children := []string{}
+	for _, child := range index.children() {
+		children = append(children, child.String())
+	}
+
+	// テスト対象呼び出し
+	volume, err := CalcExtendedSpatialIdsVolume([]string{spatialID})
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	if math.Abs(volume-31.0*31.0*32.0)/volume > 0.01 {
+		t.Errorf("パターン1 - 期待値：%v, 取得値：%v", 31.0*31.0*32.0, volume)
+	}
+
+	for i, spatialIDs := range [][]string{children, append([]string{spatialID, spatialID}, children...)} {
+		resultVal, err := CalcExtendedSpatialIdsVolume(spatialIDs)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+2, err)
+		}
+		if math.Abs(resultVal-volume)/volume > 0.001 {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+2, volume, resultVal)
+		}
+	}
+
+	equator, _ := CalcExtendedSpatialIdsVolume([]string{"20/0/524288/20/0"})
+	north, _ := CalcExtendedSpatialIdsVolume([]string{"20/0/304500/20/0"})
+	if math.Abs(north/equator-0.25) > 0.01 {
+		t.Errorf("パターン4 - 期待値：0.25, 取得値：%v", north/equator)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestCalcExtendedSpatialIdsSurfaceArea01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：20/931339/412910/20/3
+//   - パターン1：1個
+//   - パターン2：東西に面で接する2個
+//   - パターン3：精度21の8個の子ボクセル
+//   - パターン4：1個と、東に面で接するボクセルの精度21の子ボクセルのうち西側の4個
+//
+// + 確認内容
+//   - パターン1：表面積が6面の面積の合計であること
+//   - パターン2：表面積がパターン1の2倍から接する2面の面積を除いた値であること
+//   - パターン3：表面積がパターン1と一致すること
+//   - パターン4：表面積がパターン1と子ボクセル4個の直方体の表面積の合計から接する面を除いた値であること
+func TestCalcExtendedSpatialIdsSurfaceArea01(t *testing.T) {
+	//入力パラメータ
+	spatialID := "20/931339/412910/20/3"
+	index, _ := parseVoxelIndex(spatialID)
+	size := voxelSizeMeters(index)
+	single := 2 * (size.X*size.Y + size.X*size.Z + size.Y*size.Z)
+	children := []string{}
+	for _, child := range index.children() {
+		children = append(children, child.String())
+	}
+	eastIndex, _ := parseVoxelIndex("20/931340/412910/20/3")
+	halves := []string{spatialID}
+	for _, child := range eastIndex.children() {
+		if child.x%2 == 0 {
+			halves = append(halves, child.String())
+		}
+	}
+	// 東西の幅が半分の直方体の表面積から、接する面の2倍を除く
+	half := 2*(size.X/2*size.Y+size.X/2*size.Z+size.Y*size.Z) - 2*size.Y*size.Z
+	inputs := []struct {
+		spatialIDs []string
+		expect     float64
+	}{
+		{[]string{spatialID}, single},
+		{[]string{spatialID, "20/931340/412910/20/3"}, 2*single - 2*size.Y*size.Z},
+		{children, single},
+		{halves, single + half},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := CalcExtendedSpatialIdsSurfaceArea(input.spatialIDs)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		if math.Abs(resultVal-input.expect)/input.expect > 0.001 {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+1, input.expect, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestCalcExtendedSpatialIdsBoundingBox01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：3/1/2/3/0、3/2/3/3/1
+//   - パターン2：3/7/2/3/0、3/0/2/3/0(経度180度をまたがる)
+//
+// + 確認内容
+//   - パターン1：経度-135度～-45度、高さ0m～8388608mの範囲であること
+//   - パターン2：西端が135度、東端が-135度であること
+func TestCalcExtendedSpatialIdsBoundingBox01(t *testing.T) {
+	//入力パラメータ
+	inputs := []struct {
+		spatialIDs []string
+		west       float64
+		east       float64
+	}{
+		{[]string{"3/1/2/3/0", "3/2/3/3/1"}, -135, -45},
+		{[]string{"3/7/2/3/0", "3/0/2/3/0"}, 135, -135},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := CalcExtendedSpatialIdsBoundingBox(input.spatialIDs)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		if math.Abs(resultVal.West-input.west) > 1e-9 || math.Abs(resultVal.East-input.east) > 1e-9 {
+			t.Errorf("パターン%d - 期待値：%v～%v, 取得値：%v", i+1, input.west, input.east, resultVal)
+		}
+		if i == 0 && (resultVal.Bottom != 0 || math.Abs(resultVal.Top-8388608) > 1e-6) {
+			t.Errorf("パターン%d 高さ - 期待値：0～8388608, 取得値：%v", i+1, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestCalcExtendedSpatialIdsCentroid01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：20/931339/412910/20/3、20/931341/412910/20/3
+//   - パターン2：上記に加え、精度21の子ボクセルのうち1個
+//   - パターン3：3/7/2/3/0、3/0/2/3/0(経度180度をまたがる)
+//
+// + 確認内容
+//   - パターン1、2：重心が20/931340/412910/20/3の中心と一致すること
+//   - パターン3：重心の経度が±180度であること
+func TestCalcExtendedSpatialIdsCentroid01(t *testing.T) {
+	//入力パラメータ
+	first, _ := parseVoxelIndex("20/931339/412910/20/3")
+	middle, _ := CalcExtendedSpatialIdsCentroid([]string{"20/931340/412910/20/3"})
+	inputs := [][]string{
+		{"20/931339/412910/20/3", "20/931341/412910/20/3"},
+		{"20/931339/412910/20/3", "20/931341/412910/20/3", first.children()[0].String()},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := CalcExtendedSpatialIdsCentroid(input)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		if math.Abs(resultVal.Lon()-middle.Lon()) > 1e-9 || math.Abs(resultVal.Lat()-middle.Lat()) > 1e-6 ||
+			math.Abs(resultVal.Alt()-middle.Alt()) > 1e-6 {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+1, middle, resultVal)
+		}
+	}
+
+	resultVal, err := CalcExtendedSpatialIdsCentroid([]string{"3/7/2/3/0", "3/0/2/3/0"})
+	if err != nil {
+		t.Fatalf("パターン3 error - 期待値：nil, 取得値：%v", err)
+	}
+	if math.Abs(math.Abs(resultVal.Lon())-180) > 1e-9 {
+		t.Errorf("パターン3 - 期待値：±180, 取得値：%v", resultVal.Lon())
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestCalcExtendedSpatialIdsVolume02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：拡張空間IDのフォーマットが不正
+//   - パターン2：精度が36
+//   - パターン3：拡張空間IDが空
+//
+// + 確認内容
+//   - パターン1、2：全ての関数でエラーが返却されること
+//   - パターン3：外接する範囲、重心でエラーが返却され、体積、表面積が0であること
+func TestCalcExtendedSpatialIdsVolume02(t *testing.T) {
+	//入力パラメータ
+	inputs := [][]string{
+		{"20/931339/412910/20"},
+		{"36/0/0/20/0"},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, volumeErr := CalcExtendedSpatialIdsVolume(input)
+		_, areaErr := CalcExtendedSpatialIdsSurfaceArea(input)
+		_, boxErr := CalcExtendedSpatialIdsBoundingBox(input)
+		_, centroidErr := CalcExtendedSpatialIdsCentroid(input)
+		if volumeErr == nil || areaErr == nil || boxErr == nil || centroidErr == nil {
+			t.Errorf("パターン%d error - 期待値：エラー, 取得値：%v, %v, %v, %v", i+1, volumeErr, areaErr, boxErr, centroidErr)
+		}
+	}
+
+	volume, volumeErr := CalcExtendedSpatialIdsVolume([]string{})
+	area, areaErr := CalcExtendedSpatialIdsSurfaceArea([]string{})
+	_, boxErr := CalcExtendedSpatialIdsBoundingBox([]string{})
+	_, centroidErr := CalcExtendedSpatialIdsCentroid([]string{})
+	if volume != 0 || area != 0 || volumeErr != nil || areaErr != nil || boxErr == nil || centroidErr == nil {
+		t.Errorf("パターン3 - 取得値：%v, %v, %v, %v, %v, %v", volume, area, volumeErr, areaErr, boxErr, centroidErr)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestCalcExtendedSpatialIdsVolume03 正常系動作確認(精度の異なる拡張空間IDの重なり)
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：20/931339/412910/21/6(水平方向精度20、上下半分のボクセル)と
+//     21/1862678/825820/20/3(水平方向精度21、南北、東西半分のボクセル)
+//   - 比較対象：上記の和集合を精度21の重複しない5個の拡張空間IDに分割したもの
+//
+// + 確認内容
+//   - 体積、表面積、重心が比較対象と一致すること(重なる部分を重複して数えないこと)
+func TestCalcExtendedSpatialIdsVolume03(t *testing.T) {
+	//入力パラメータ
+	overlapped := []string{"20/931339/412910/21/6", "21/1862678/825820/20/3"}
+	distinct := []string{
+		"21/1862678/825820/21/6",
+		"21/1862679/825820/21/6",
+		"21/1862678/825821/21/6",
+		"21/1862679/825821/21/6",
+		"21/1862678/825820/21/7",
+	}
+
+	// テスト対象呼び出し
+	volume, volumeErr := CalcExtendedSpatialIdsVolume(overlapped)
+	area, areaErr := CalcExtendedSpatialIdsSurfaceArea(overlapped)
+	centroid, centroidErr := CalcExtendedSpatialIdsCentroid(overlapped)
+	if volumeErr != nil || areaErr != nil || centroidErr != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v, %v, %v", volumeErr, areaErr, centroidErr)
+	}
+
+	expectVolume, _ := CalcExtendedSpatialIdsVolume(distinct)
+	expectArea, _ := CalcExtendedSpatialIdsSurfaceArea(distinct)
+	expectCentroid, _ := CalcExtendedSpatialIdsCentroid(distinct)
+	if math.Abs(volume-expectVolume)/expectVolume > 1e-9 {
+		t.Errorf("体積 - 期待値：%v, 取得値：%v", expectVolume, volume)
+	}
+	if math.Abs(area-expectArea)/expectArea > 1e-6 {
+		t.Errorf("表面積 - 期待値：%v, 取得値：%v", expectArea, area)
+	}
+	if math.Abs(centroid.Lon()-expectCentroid.Lon()) > 1e-9 || math.Abs(centroid.Lat()-expectCentroid.Lat()) > 1e-9 ||
+		math.Abs(centroid.Alt()-expectCentroid.Alt()) > 1e-6 {
+		t.Errorf("重心 - 期待値：%v, 取得値：%v", expectCentroid, centroid)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestCalcExtendedSpatialIdsSurfaceArea02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：水平方向精度10と21の拡張空間ID
+//   - パターン2：垂直方向精度10と21の拡張空間ID
+//
+// + 確認内容
+//   - 精度の差がmaxSurfaceZoomDifferenceを超えるため、エラーが返却されること
+func TestCalcExtendedSpatialIdsSurfaceArea02(t *testing.T) {
+	//入力パラメータ
+	inputs := [][]string{
+		{"10/0/0/20/0", "21/0/0/20/0"},
+		{"20/0/0/10/0", "20/0/0/21/0"},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := CalcExtendedSpatialIdsSurfaceArea(input)
+
+		if err == nil {
+			t.Errorf("パターン%d - 期待値：エラー, 取得値：%v", i+1, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
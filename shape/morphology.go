@@ -0,0 +1,457 @@
+package shape
+
+import (
+	"math"
+	"sort"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// Connectivity ボクセルの隣接の種類
+type Connectivity int
+
+const (
+	Connectivity6  Connectivity = 6  // 面で隣接する6個のボクセル
+	Connectivity18 Connectivity = 18 // 面、辺で隣接する18個のボクセル
+	Connectivity26 Connectivity = 26 // 面、辺、頂点で隣接する26個のボクセル
+)
+
+// neighbourOffsets 隣接するボクセルの成分インデックスの差分を取得
+//
+// 引数：
+//
+//	connectivity： ボクセルの隣接の種類
+//
+// 戻り値：
+//
+//	隣接するボクセルの(X, Y, 高さ)成分インデックスの差分のリスト
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 隣接の種類が6、18、26以外の場合
+func neighbourOffsets(connectivity Connectivity) ([][3]int64, error) {
+	limit, err := connectivityLimit(connectivity)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make([][3]int64, 0, connectivity)
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for df := int64(-1); df <= 1; df++ {
+				num := 0
+				for _, d := range []int64{dx, dy, df} {
+					if d != 0 {
+						num++
+					}
+				}
+				if num == 0 || num > limit {
+					continue
+				}
+				offsets = append(offsets, [3]int64{dx, dy, df})
+			}
+		}
+	}
+	return offsets, nil
+}
+
+// connectivityLimit 隣接するボクセルの差分が0でない成分の数の上限を取得
+//
+// 引数：
+//
+//	connectivity： ボクセルの隣接の種類
+//
+// 戻り値：
+//
+//	6近傍の場合1、18近傍の場合2、26近傍の場合3
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 隣接の種類が6、18、26以外の場合
+func connectivityLimit(connectivity Connectivity) (int, error) {
+	switch connectivity {
+	case Connectivity6:
+		return 1, nil
+	case Connectivity18:
+		return 2, nil
+	case Connectivity26:
+		return 3, nil
+	default:
+		return 0, errors.NewSpatialIdError(errors.InputValueErrorCode, "隣接の種類が不正です")
+	}
+}
+
+// shift 成分インデックスをずらしたボクセルの取得
+//
+// X成分は経度180度をまたがる場合に反対側に折り返す。
+//
+// 引数：
+//
+//	offset： (X, Y, 高さ)成分インデックスの差分
+//
+// 戻り値：
+//
+//	ずらしたボクセルの成分インデックス、Y成分、もしくは高さ成分が範囲外となる場合はfalse
+func (v voxelIndex) shift(offset [3]int64) (voxelIndex, bool) {
+	size, vSize := int64(1)<<v.hZoom, int64(1)<<v.vZoom
+	y, f := v.y+offset[1], v.f+offset[2]
+	if y < 0 || y >= size || f < -vSize || f >= vSize {
+		return voxelIndex{}, false
+	}
+	return voxelIndex{
+		hZoom: v.hZoom,
+		x:     ((v.x+offset[0])%size + size) % size,
+		y:     y,
+		vZoom: v.vZoom,
+		f:     f,
+	}, true
+}
+
+// parseVoxelSet 拡張空間IDのリストから成分インデックスの集合を取得
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//
+// 戻り値：
+//
+//	成分インデックスの集合
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDのフォーマットが不正な場合、精度が 0 ～ 35 の整数値以外の場合、
+//	               もしくは成分インデックスが精度に対して範囲外の場合
+func parseVoxelSet(spatialIDs []string) (map[voxelIndex]bool, error) {
+	voxels := make(map[voxelIndex]bool, len(spatialIDs))
+	for _, spatialID := range spatialIDs {
+		index, err := parseVoxelIndex(spatialID)
+		if err != nil {
+			return nil, err
+		}
+		if !shape.CheckZoom(index.hZoom) || !shape.CheckZoom(index.vZoom) || !index.isValid() {
+			return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+		}
+		voxels[index] = true
+	}
+	return voxels, nil
+}
+
+// voxelSetToSpatialIDs 成分インデックスの集合から拡張空間IDのリストを取得
+//
+// 引数：
+//
+//	voxels： 成分インデックスの集合
+//
+// 戻り値：
+//
+//	拡張空間IDのリスト(昇順)
+func voxelSetToSpatialIDs(voxels map[voxelIndex]bool) []string {
+	results := make([]string, 0, len(voxels))
+	for index := range voxels {
+		results = append(results, index.String())
+	}
+	sort.Strings(results)
+	return results
+}
+
+// dilateVoxelSet ボクセルの集合の膨張
+//
+// 引数：
+//
+//	voxels： 成分インデックスの集合
+//	offsets： ボクセルごとの膨張させる成分インデックスの差分を返却する関数
+//
+// 戻り値：
+//
+//	膨張後の成分インデックスの集合
+func dilateVoxelSet(voxels map[voxelIndex]bool, offsets func(voxelIndex) [][3]int64) map[voxelIndex]bool {
+	dilated := make(map[voxelIndex]bool, len(voxels))
+	for index := range voxels {
+		dilated[index] = true
+		for _, offset := range offsets(index) {
+			if neighbour, ok := index.shift(offset); ok {
+				dilated[neighbour] = true
+			}
+		}
+	}
+	return dilated
+}
+
+// erodeVoxelSet ボクセルの集合の収縮
+//
+// 引数：
+//
+//	voxels： 成分インデックスの集合
+//	offsets： ボクセルごとの確認する成分インデックスの差分を返却する関数
+//
+// 戻り値：
+//
+//	確認する全てのボクセルが集合に含まれるボクセルの集合
+func erodeVoxelSet(voxels map[voxelIndex]bool, offsets func(voxelIndex) [][3]int64) map[voxelIndex]bool {
+	eroded := make(map[voxelIndex]bool, len(voxels))
+	for index := range voxels {
+		keep := true
+		for _, offset := range offsets(index) {
+			neighbour, ok := index.shift(offset)
+			if !ok || !voxels[neighbour] {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			eroded[index] = true
+		}
+	}
+	return eroded
+}
+
+// DilateExtendedSpatialIds 拡張空間IDの膨張
+//
+// 拡張空間IDの集合に、隣接するボクセルを追加する処理を指定回数繰り返す。
+// 建物等の拡張空間IDの周囲にボクセル数で緩衝領域を設ける際に使用する。
+// 隣接するボクセルは各拡張空間IDと同じ精度で取得し、経度180度をまたがる場合は反対側に折り返す。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//	connectivity： ボクセルの隣接の種類(6、18、26)
+//	iterations： 繰り返し回数
+//
+// 戻り値：
+//
+//	膨張後の拡張空間IDのリスト(昇順)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDのフォーマットが不正な場合、精度が 0 ～ 35 の整数値以外の場合、
+//	               隣接の種類が不正な場合、もしくは繰り返し回数が負の場合
+func DilateExtendedSpatialIds(spatialIDs []string, connectivity Connectivity, iterations int) ([]string, error) {
+	return morphologyByConnectivity(spatialIDs, connectivity, iterations, dilateVoxelSet)
+}
+
+// ErodeExtendedSpatialIds 拡張空間IDの収縮
+//
+// 隣接するボクセルが全て集合に含まれる拡張空間IDのみを残す処理を指定回数繰り返す。
+// 隣接するボクセルは各拡張空間IDと同じ精度で確認するため、精度の異なる拡張空間IDは隣接として扱わない。
+// 経度180度をまたがる場合は反対側に折り返し、緯度方向の範囲外は集合に含まれないものとする。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//	connectivity： ボクセルの隣接の種類(6、18、26)
+//	iterations： 繰り返し回数
+//
+// 戻り値：
+//
+//	収縮後の拡張空間IDのリスト(昇順)
+//
+// 戻り値(エラー)：
+//
+//	DilateExtendedSpatialIdsと同じ
+func ErodeExtendedSpatialIds(spatialIDs []string, connectivity Connectivity, iterations int) ([]string, error) {
+	return morphologyByConnectivity(spatialIDs, connectivity, iterations, erodeVoxelSet)
+}
+
+// morphologyByConnectivity 隣接の種類による膨張、収縮の共通処理
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//	connectivity： ボクセルの隣接の種類
+//	iterations： 繰り返し回数
+//	operation： 膨張、または収縮の処理
+//
+// 戻り値：
+//
+//	処理後の拡張空間IDのリスト(昇順)
+//
+// 戻り値(エラー)：
+//
+//	DilateExtendedSpatialIdsと同じ
+func morphologyByConnectivity(
+	spatialIDs []string,
+	connectivity Connectivity,
+	iterations int,
+	operation func(map[voxelIndex]bool, func(voxelIndex) [][3]int64) map[voxelIndex]bool,
+) ([]string, error) {
+	offsets, err := neighbourOffsets(connectivity)
+	if err != nil {
+		return []string{}, err
+	}
+	if iterations < 0 {
+		return []string{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	voxels, err := parseVoxelSet(spatialIDs)
+	if err != nil {
+		return []string{}, err
+	}
+
+	for i := 0; i < iterations; i++ {
+		voxels = operation(voxels, func(voxelIndex) [][3]int64 { return offsets })
+	}
+	return voxelSetToSpatialIDs(voxels), nil
+}
+
+// DilateExtendedSpatialIdsByDistance 拡張空間IDの距離による膨張
+//
+// 各拡張空間IDに、中心間の距離が指定距離以下となるボクセルを追加する。
+// ボクセルの水平方向の大きさは緯度により異なるため、各拡張空間IDの緯度のボクセルの大きさ(単位:m)で距離を求める。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//	distance： 距離(単位:m)
+//
+// 戻り値：
+//
+//	膨張後の拡張空間IDのリスト(昇順)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDのフォーマットが不正な場合、精度が 0 ～ 35 の整数値以外の場合、
+//	               距離が負の場合、もしくはボクセルあたりの確認する差分の数がmaxDistanceOffsetsを超える場合
+func DilateExtendedSpatialIdsByDistance(spatialIDs []string, distance float64) ([]string, error) {
+	return morphologyByDistance(spatialIDs, distance, dilateVoxelSet)
+}
+
+// ErodeExtendedSpatialIdsByDistance 拡張空間IDの距離による収縮
+//
+// 中心間の距離が指定距離以下となるボクセルが全て集合に含まれる拡張空間IDのみを残す。
+// 距離はDilateExtendedSpatialIdsByDistanceと同じく各拡張空間IDの緯度のボクセルの大きさで求める。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//	distance： 距離(単位:m)
+//
+// 戻り値：
+//
+//	収縮後の拡張空間IDのリスト(昇順)
+//
+// 戻り値(エラー)：
+//
+//	DilateExtendedSpatialIdsByDistanceと同じ
+func ErodeExtendedSpatialIdsByDistance(spatialIDs []string, distance float64) ([]string, error) {
+	return morphologyByDistance(spatialIDs, distance, erodeVoxelSet)
+}
+
+// morphologyByDistance 距離による膨張、収縮の共通処理
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//	distance： 距離(単位:m)
+//	operation： 膨張、または収縮の処理
+//
+// 戻り値：
+//
+//	処理後の拡張空間IDのリスト(昇順)
+//
+// 戻り値(エラー)：
+//
+//	DilateExtendedSpatialIdsByDistanceと同じ
+func morphologyByDistance(
+	spatialIDs []string,
+	distance float64,
+	operation func(map[voxelIndex]bool, func(voxelIndex) [][3]int64) map[voxelIndex]bool,
+) ([]string, error) {
+	if distance < 0 || math.IsNaN(distance) || math.IsInf(distance, 0) {
+		return []string{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	voxels, err := parseVoxelSet(spatialIDs)
+	if err != nil {
+		return []string{}, err
+	}
+
+	// 同一緯度、同一精度のボクセルは差分が同じため、処理の前にまとめて取得する
+	cache := map[voxelIndex][][3]int64{}
+	for index := range voxels {
+		key := voxelIndex{hZoom: index.hZoom, y: index.y, vZoom: index.vZoom}
+		if _, ok := cache[key]; ok {
+			continue
+		}
+		if cache[key], err = distanceOffsets(voxelSizeMeters(key), distance); err != nil {
+			return []string{}, err
+		}
+	}
+	offsets := func(index voxelIndex) [][3]int64 {
+		return cache[voxelIndex{hZoom: index.hZoom, y: index.y, vZoom: index.vZoom}]
+	}
+
+	return voxelSetToSpatialIDs(operation(voxels, offsets)), nil
+}
+
+// voxelSizeMeters ボクセルの大きさ(単位:m)を算出
+//
+// 引数：
+//
+//	index： ボクセルの成分インデックス
+//
+// 戻り値：
+//
+//	ボクセルの東西、南北、高さ方向の大きさ
+func voxelSizeMeters(index voxelIndex) spatial.Vector3 {
+	spatialID := index.String()
+	rect := Rectangular{hZoom: index.hZoom, vZoom: index.vZoom, factor: 1}
+	unitVoxel, _ := rect.calcUnitVoxelVector(spatialID)
+
+	// 水平方向はボクセル中心の緯度のWebメルカトル換算係数で補正する
+	centers, _ := shape.GetPointOnExtendedSpatialId(spatialID, enum.Center)
+	scale := math.Cos(common.DegreeToRadian(centers[0].Lat()))
+	return spatial.Vector3{X: unitVoxel.X * scale, Y: unitVoxel.Y * scale, Z: unitVoxel.Z}
+}
+
+// maxDistanceOffsets 距離による膨張、収縮で許容する、ボクセルあたりの確認する差分の数の最大値
+const maxDistanceOffsets = 1 << 20
+
+// distanceOffsets 中心間の距離が指定距離以下となるボクセルの成分インデックスの差分を取得
+//
+// 引数：
+//
+//	size： ボクセルの大きさ(単位:m)
+//	distance： 距離(単位:m)
+//
+// 戻り値：
+//
+//	(X, Y, 高さ)成分インデックスの差分のリスト(差分0を除く)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 確認する差分の数がmaxDistanceOffsetsを超える場合
+func distanceOffsets(size spatial.Vector3, distance float64) ([][3]int64, error) {
+	xAppr := math.Floor(distance / size.X)
+	yAppr := math.Floor(distance / size.Y)
+	zAppr := math.Floor(distance / size.Z)
+	if (2*xAppr+1)*(2*yAppr+1)*(2*zAppr+1) > maxDistanceOffsets {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "距離がボクセルの大きさに対して大きすぎます")
+	}
+	xApprNum, yApprNum, zApprNum := int64(xAppr), int64(yAppr), int64(zAppr)
+
+	offsets := [][3]int64{}
+	for x := -xApprNum; x <= xApprNum; x++ {
+		for y := -yApprNum; y <= yApprNum; y++ {
+			for z := -zApprNum; z <= zApprNum; z++ {
+				if x == 0 && y == 0 && z == 0 {
+					continue
+				}
+				d := math.Sqrt(
+					math.Pow(float64(x)*size.X, 2) + math.Pow(float64(y)*size.Y, 2) + math.Pow(float64(z)*size.Z, 2),
+				)
+				if d <= distance {
+					offsets = append(offsets, [3]int64{x, y, z})
+				}
+			}
+		}
+	}
+	return offsets, nil
+}
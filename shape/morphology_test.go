@@ -0,0 +1,244 @@
+package shape
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestDilateExtendedSpatialIds01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：20/931339/412910/20/3
+//   - パターン1～3：隣接の種類6、18、26、繰り返し回数1
+//   - パターン4：隣接の種類6、繰り返し回数2
+//   - パターン5：隣接の種類26、繰り返し回数0
+//
+// + 確認内容
+//   - 拡張空間IDの数が7、19、27、25、1個であること
+//   - 元の拡張空間IDが含まれ、結果が昇順であること
+func TestDilateExtendedSpatialIds01(t *testing.T) {
+	//入力パラメータ
+	spatialID := "20/931339/412910/20/3"
+	inputs := []struct {
+		connectivity Connectivity
+		iterations   int
+		expect       int
+	}{
+		{Connectivity6, 1, 7},
+		{Connectivity18, 1, 19},
+		{Connectivity26, 1, 27},
+		{Connectivity6, 2, 25},
+		{Connectivity26, 0, 1},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := DilateExtendedSpatialIds([]string{spatialID}, input.connectivity, input.iterations)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		if len(resultVal) != input.expect {
+			t.Errorf("パターン%d 個数 - 期待値：%v, 取得値：%v", i+1, input.expect, len(resultVal))
+		}
+		if !sort.StringsAreSorted(resultVal) || sort.SearchStrings(resultVal, spatialID) == len(resultVal) {
+			t.Errorf("パターン%d - 取得値：%v", i+1, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestDilateExtendedSpatialIds02 正常系動作確認(経度180度、緯度方向、高さ方向の範囲外)
+//
+// 試験詳細：
+// + 試験データ
+//   - 隣接の種類6、繰り返し回数1
+//   - パターン1：拡張空間ID：3/7/0/3/0
+//   - パターン2：拡張空間ID：3/0/3/3/7(高さ成分が最大値)
+//
+// + 確認内容
+//   - パターン1：X成分が0に折り返した拡張空間IDが含まれ、緯度方向の範囲外の拡張空間IDが含まれないこと
+//   - パターン2：高さ方向の範囲外の拡張空間IDが含まれないこと
+func TestDilateExtendedSpatialIds02(t *testing.T) {
+	//入力パラメータ
+	inputs := []struct {
+		spatialID string
+		expect    []string
+	}{
+		{"3/7/0/3/0", []string{"3/0/0/3/0", "3/6/0/3/0", "3/7/0/3/-1", "3/7/0/3/0", "3/7/0/3/1", "3/7/1/3/0"}},
+		{"3/0/3/3/7", []string{"3/0/2/3/7", "3/0/3/3/6", "3/0/3/3/7", "3/0/4/3/7", "3/1/3/3/7", "3/7/3/3/7"}},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := DilateExtendedSpatialIds([]string{input.spatialID}, Connectivity6, 1)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		if !reflect.DeepEqual(input.expect, resultVal) {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+1, input.expect, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestErodeExtendedSpatialIds01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：3×3×3の拡張空間ID、隣接の種類26、繰り返し回数1
+//   - パターン2：3×3×3の拡張空間ID、隣接の種類6、繰り返し回数2
+//   - パターン3：1個の拡張空間IDを隣接の種類6で2回膨張した拡張空間ID、隣接の種類6、繰り返し回数2
+//   - パターン4：3×3×3の拡張空間IDの中心の精度を上げた8個に置き換えたもの、隣接の種類6、繰り返し回数1
+//
+// + 確認内容
+//   - パターン1、3：中心の拡張空間IDのみ残ること
+//   - パターン2：拡張空間IDが残らないこと
+//   - パターン4：精度の異なる拡張空間IDを隣接として扱わず、拡張空間IDが残らないこと
+func TestErodeExtendedSpatialIds01(t *testing.T) {
+	//入力パラメータ
+	center := "20/931339/412910/20/3"
+	cube, _ := DilateExtendedSpatialIds([]string{center}, Connectivity26, 1)
+	diamond, _ := DilateExtendedSpatialIds([]string{center}, Connectivity6, 2)
+	index, _ := parseVoxelIndex(center)
+	mixed := []string{}
+	for _, spatialID := range cube {
+		if spatialID != center {
+			mixed = append(mixed, spatialID)
+		}
+	}
+	for _, child := range index.children() {
+		mixed = append(mixed, child.String())
+	}
+	inputs := []struct {
+		spatialIDs   []string
+		connectivity Connectivity
+		iterations   int
+		expect       []string
+	}{
+		{cube, Connectivity26, 1, []string{center}},
+		{cube, Connectivity6, 2, []string{}},
+		{diamond, Connectivity6, 2, []string{center}},
+		{mixed, Connectivity6, 1, []string{}},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := ErodeExtendedSpatialIds(input.spatialIDs, input.connectivity, input.iterations)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		if !reflect.DeepEqual(input.expect, resultVal) {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+1, input.expect, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestDilateExtendedSpatialIdsByDistance01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：20/931339/412910/20/3(東西、南北約31.0m、高さ32.0mのボクセル)
+//   - 距離：0.0、35.0、45.0、70.0
+//
+// + 確認内容
+//   - 拡張空間IDの数が1、7、19、49個であること
+//   - 距離による膨張の結果を距離による収縮で元の拡張空間IDに戻せること
+func TestDilateExtendedSpatialIdsByDistance01(t *testing.T) {
+	//入力パラメータ
+	spatialID := "20/931339/412910/20/3"
+	inputs := []struct {
+		distance float64
+		expect   int
+	}{
+		{0.0, 1},
+		{35.0, 7},
+		{45.0, 19},
+		{70.0, 49},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := DilateExtendedSpatialIdsByDistance([]string{spatialID}, input.distance)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+		if len(resultVal) != input.expect {
+			t.Errorf("パターン%d 個数 - 期待値：%v, 取得値：%v", i+1, input.expect, len(resultVal))
+		}
+
+		eroded, err := ErodeExtendedSpatialIdsByDistance(resultVal, input.distance)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+		if !reflect.DeepEqual([]string{spatialID}, eroded) {
+			t.Errorf("パターン%d 収縮 - 期待値：%v, 取得値：%v", i+1, spatialID, eroded)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestDilateExtendedSpatialIds03 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：隣接の種類が8
+//   - パターン2：繰り返し回数が-1
+//   - パターン3：拡張空間IDのフォーマットが不正
+//   - パターン4：精度が36
+//   - パターン5：距離が-1.0
+//   - パターン6：距離が1000000.0(ボクセルあたりの確認する差分の数がmaxDistanceOffsetsを超える)
+//   - パターン7：X成分が負
+//
+// + 確認内容
+//   - 膨張、収縮ともにエラーが返却されること
+func TestDilateExtendedSpatialIds03(t *testing.T) {
+	//入力パラメータ
+	valid := []string{"20/931339/412910/20/3"}
+	inputs := []struct {
+		spatialIDs   []string
+		connectivity Connectivity
+		iterations   int
+		distance     float64
+	}{
+		{valid, Connectivity(8), 1, 0},
+		{valid, Connectivity6, -1, 0},
+		{[]string{"20/931339/412910"}, Connectivity6, 1, 0},
+		{[]string{"36/0/0/20/0"}, Connectivity6, 1, 0},
+		{valid, Connectivity6, 1, -1.0},
+		{valid, Connectivity6, 1, 1000000.0},
+		{[]string{"20/-5/0/20/0"}, Connectivity6, 1, 0},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, dilateErr := DilateExtendedSpatialIds(input.spatialIDs, input.connectivity, input.iterations)
+		_, erodeErr := ErodeExtendedSpatialIds(input.spatialIDs, input.connectivity, input.iterations)
+		_, dilateDistanceErr := DilateExtendedSpatialIdsByDistance(input.spatialIDs, input.distance)
+		_, erodeDistanceErr := ErodeExtendedSpatialIdsByDistance(input.spatialIDs, input.distance)
+
+		// 隣接の種類、繰り返し回数は距離による処理では使用しない
+		if i < 2 {
+			dilateDistanceErr, erodeDistanceErr = dilateErr, erodeErr
+		}
+		// 距離は隣接の種類による処理では使用しない
+		if i == 4 || i == 5 {
+			dilateErr, erodeErr = dilateDistanceErr, erodeDistanceErr
+		}
+		if dilateErr == nil || erodeErr == nil || dilateDistanceErr == nil || erodeDistanceErr == nil {
+			t.Errorf("パターン%d error - 期待値：エラー, 取得値：%v, %v, %v, %v",
+				i+1, dilateErr, erodeErr, dilateDistanceErr, erodeDistanceErr)
+		}
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,101 @@
+package shape
+
+// Stage 空間ID取得の処理段階
+type Stage string
+
+const (
+	StageRoute     Stage = "route"     // 経路全体(GetExtendedSpatialIdsOnCylinders)
+	StageSegment   Stage = "segment"   // 接続点間の円柱、または接続点の球(CalcValidSpatialIDs)
+	StageLine      Stage = "line"      // 始点・終点間の軸の空間ID取得
+	StageCandidate Stage = "candidate" // 軸の空間IDをシフトした全空間ID取得
+	StageInclude   Stage = "include"   // 内部空間ID取得
+	StageCollision Stage = "collision" // 衝突判定
+)
+
+// RouteSegment 経路全体の処理段階に通知する区間番号
+const RouteSegment = -1
+
+// StageResult 処理段階の結果
+//
+// Countの意味は処理段階によって異なる。
+//   - StageRoute, StageSegment: 取得した空間IDの数
+//   - StageLine: 始点・終点間の軸の空間IDの数
+//   - StageCandidate: 全空間ID(衝突判定の候補)の数
+//   - StageInclude: 内部空間IDの数
+//   - StageCollision: 衝突判定後の空間IDの数
+type StageResult struct {
+	Count int // 処理段階で得られた空間IDの数
+	Tests int // 衝突判定の実施回数(StageCollisionのみ)
+}
+
+// StageObserver 処理段階の観測インターフェース
+//
+// 処理段階ごとの処理時間や空間IDの数を計測する際に実装する。
+// 1回の空間ID取得の中では処理段階は入れ子で順に呼び出される。
+type StageObserver interface {
+	// StartStage 処理段階の開始
+	//
+	// 引数：
+	//
+	//	stage： 処理段階
+	//	segment： 区間番号(0始まり)。経路全体の場合はRouteSegment
+	//
+	// 戻り値：
+	//
+	//	処理段階の終了時に結果を渡して呼び出す関数
+	StartStage(stage Stage, segment int) func(result StageResult)
+}
+
+// Observer 処理段階の観測設定関数
+//
+// 以下の関数の処理段階ごとにStageObserverを呼び出す。
+// 未指定の場合は観測のための処理を行わない。
+//   - GetSpatialIdsOnCylinders
+//   - GetExtendedSpatialIdsOnCylinders
+//
+// 引数：
+//
+//	o: 処理段階の観測インターフェース
+//
+// 戻り値：
+//
+//	衝突判定実施オプショナル型の関数
+func Observer(o StageObserver) option {
+	return func(p *IsPrecisionOpts) {
+		p.Observer = o
+	}
+}
+
+// SetObserver 処理段階の観測設定
+//
+// CalcValidSpatialIDsの処理段階ごとにStageObserverを呼び出す。
+//
+// 引数：
+//
+//	o: 処理段階の観測インターフェース。nilの場合は観測しない
+//	segment: 通知する区間番号
+func (c *Capsule) SetObserver(o StageObserver, segment int) {
+	c.observer = o
+	c.segment = segment
+}
+
+// endNothing 観測しない場合の処理段階の終了関数
+func endNothing(StageResult) {}
+
+// startStage 処理段階の開始通知
+//
+// 引数：
+//
+//	o： 処理段階の観測インターフェース
+//	stage： 処理段階
+//	segment： 区間番号
+//
+// 戻り値：
+//
+//	処理段階の終了時に呼び出す関数。観測しない場合は何もしない関数
+func startStage(o StageObserver, stage Stage, segment int) func(StageResult) {
+	if o == nil {
+		return endNothing
+	}
+	return o.StartStage(stage, segment)
+}
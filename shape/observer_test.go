@@ -0,0 +1,82 @@
+package shape
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// stageEvent テスト用処理段階の記録
+type stageEvent struct {
+	stage   Stage
+	segment int
+	isStart bool
+	result  StageResult
+}
+
+// stageRecorder テスト用処理段階の観測
+type stageRecorder struct {
+	events []stageEvent
+}
+
+func (r *stageRecorder) StartStage(stage Stage, segment int) func(StageResult) {
+	r.events = append(r.events, stageEvent{stage: stage, segment: segment, isStart: true})
+	return func(result StageResult) {
+		r.events = append(r.events, stageEvent{stage: stage, segment: segment, result: result})
+	}
+}
+
+// TestObserver01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.753098, 35.685371, 11.0), (139.753198, 35.685371, 11.0)
+//   - 半径：2.0、水平精度：25、垂直精度：25
+//
+// + 確認内容
+//   - 経路全体、区間、各処理段階の順に開始、終了が通知されること
+//   - 経路全体と区間の空間IDの数が取得結果と一致すること
+//   - 衝突判定の実施回数が通知されること
+func TestObserver01(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+	recorder := new(stageRecorder)
+
+	// テスト対象呼び出し
+	resultVal, _ := GetExtendedSpatialIdsOnCylinders(
+		[]*object.Point{p1, p2}, 2.0, 25, 25, false, Observer(recorder),
+	)
+
+	// 期待値
+	expectOrder := []Stage{
+		StageRoute, StageSegment,
+		StageLine, StageLine,
+		StageCandidate, StageCandidate,
+		StageInclude, StageInclude,
+		StageCollision, StageCollision,
+		StageSegment, StageRoute,
+	}
+
+	resultOrder := []Stage{}
+	for _, event := range recorder.events {
+		resultOrder = append(resultOrder, event.stage)
+	}
+	if !reflect.DeepEqual(expectOrder, resultOrder) {
+		t.Fatalf("処理段階 - 期待値：%v, 取得値：%v", expectOrder, resultOrder)
+	}
+
+	events := recorder.events
+	if events[0].segment != RouteSegment || events[1].segment != 0 {
+		t.Errorf("区間番号 - 期待値：%v, 0, 取得値：%v, %v", RouteSegment, events[0].segment, events[1].segment)
+	}
+	if events[11].result.Count != len(resultVal) || events[10].result.Count != len(resultVal) {
+		t.Errorf("空間IDの数 - 期待値：%v, 取得値：%v, %v", len(resultVal), events[11].result.Count, events[10].result.Count)
+	}
+	if events[9].result.Tests <= 0 || events[5].result.Count < len(resultVal) {
+		t.Errorf("衝突判定 - 取得値：%+v, 全空間ID：%+v", events[9].result, events[5].result)
+	}
+
+	t.Log("テスト終了")
+}
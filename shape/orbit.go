@@ -0,0 +1,397 @@
+package shape
+
+import (
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// loopPieces 周回経路の区間
+type loopPieces struct {
+	arcs []*turnArc          // 【直交座標空間】円弧の区間
+	legs [][2]spatial.Point3 // 【直交座標空間】直線の区間(始点、終点)
+}
+
+// GetSpatialIdsOnOrbit 空間ID(周回円)取得
+//
+// 水平な円を周回する経路が通る空間IDを取得する。
+// 旋回飛行するドローンの経路を空間IDで表現する際に使用する。
+//
+// 引数：
+//
+//	center     : 周回円の中心。高さは高度帯の下端とする
+//	orbitRadius: 周回円の半径(単位:m)
+//	radius     : 経路の管の半径(単位:m)
+//	band       : 高度帯の高さ(単位:m)。周回円の高さが中心の高さ～中心の高さ+bandの範囲となる
+//	zoom       : 精度レベル
+//	isPrecision: GetSpatialIdsOnCylindersと同じオプション。IsPrecision、LogHandler、Observer、
+//	             TerrainClip、AboveGroundLevel、Altitude、Geoidを使用する
+//
+// 戻り値：
+//
+//	周回円の経路が通る空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	GetExtendedSpatialIdsOnOrbitと同じ
+func GetSpatialIdsOnOrbit(
+	center *object.Point,
+	orbitRadius float64,
+	radius float64,
+	band float64,
+	zoom int64,
+	isPrecision ...option,
+) ([]string, error) {
+	ids, err := GetExtendedSpatialIdsOnOrbit(center, orbitRadius, radius, band, zoom, zoom, isPrecision...)
+	if err != nil {
+		return ids, err
+	}
+	return shape.ConvertExtendedSpatialIdsToSpatialIds(ids)
+}
+
+// GetExtendedSpatialIdsOnOrbit 拡張空間ID(周回円)取得
+//
+// 水平な円を周回する経路が通る拡張空間IDを取得する。
+// 経路は円環の一部(円弧に沿った管)を4つつなげた円環とし、高度帯の範囲の円環が通過する空間IDを取得する。
+//
+// 引数：
+//
+//	center     : 周回円の中心。高さは高度帯の下端とする
+//	orbitRadius: 周回円の半径(単位:m)
+//	radius     : 経路の管の半径(単位:m)
+//	band       : 高度帯の高さ(単位:m)。周回円の高さが中心の高さ～中心の高さ+bandの範囲となる
+//	hZoom      : 水平方向の精度レベル
+//	vZoom      : 垂直方向の精度レベル
+//	isPrecision: GetExtendedSpatialIdsOnCylindersと同じオプション。IsPrecision、LogHandler、Observer、
+//	             TerrainClip、AboveGroundLevel、Altitude、Geoidを使用する
+//
+// 戻り値：
+//
+//	周回円の経路が通る拡張空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 中心がnilの場合、周回円、管の半径が0以下の場合、もしくは高度帯の高さが負の場合
+//	 高さ変換不可： 高さの変換に必要なモデルが未指定の場合、
+//	               もしくは中心の位置のジオイド高、標高を取得できない場合。
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func GetExtendedSpatialIdsOnOrbit(
+	center *object.Point,
+	orbitRadius float64,
+	radius float64,
+	band float64,
+	hZoom int64,
+	vZoom int64,
+	isPrecision ...option,
+) ([]string, error) {
+	if orbitRadius <= consts.Minima {
+		return []string{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+
+	build := func(orth []spatial.Point3, factor float64) loopPieces {
+		r := orbitRadius * factor
+		arcs := make([]*turnArc, 0, 4)
+		for k := 0; k < 4; k++ {
+			arcs = append(arcs, &turnArc{
+				center: orth[0],
+				start:  orbitPoint(orth[0], r, float64(k)*math.Pi/2),
+				end:    orbitPoint(orth[0], r, float64(k+1)*math.Pi/2),
+			})
+		}
+		return loopPieces{arcs: arcs}
+	}
+
+	return getExtendedSpatialIdsOnLoop([]*object.Point{center}, build, radius, band, hZoom, vZoom, isPrecision)
+}
+
+// GetSpatialIdsOnRacetrack 空間ID(レーストラック)取得
+//
+// 2本の平行な直線と半円をつなげた水平なレーストラック(待機経路)が通る空間IDを取得する。
+//
+// 引数：
+//
+//	start      : 直線の区間の始点。高さは高度帯の下端とする
+//	end        : 直線の区間の終点。高さは使用せず、始点の高さとする
+//	turnRadius : 半円の半径(単位:m)。もう1本の直線は進行方向の右側に半円の直径だけ離れた位置とする
+//	radius     : 経路の管の半径(単位:m)
+//	band       : 高度帯の高さ(単位:m)
+//	zoom       : 精度レベル
+//	isPrecision: GetSpatialIdsOnOrbitと同じオプション
+//
+// 戻り値：
+//
+//	レーストラックの経路が通る空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	GetExtendedSpatialIdsOnRacetrackと同じ
+func GetSpatialIdsOnRacetrack(
+	start *object.Point,
+	end *object.Point,
+	turnRadius float64,
+	radius float64,
+	band float64,
+	zoom int64,
+	isPrecision ...option,
+) ([]string, error) {
+	ids, err := GetExtendedSpatialIdsOnRacetrack(start, end, turnRadius, radius, band, zoom, zoom, isPrecision...)
+	if err != nil {
+		return ids, err
+	}
+	return shape.ConvertExtendedSpatialIdsToSpatialIds(ids)
+}
+
+// GetExtendedSpatialIdsOnRacetrack 拡張空間ID(レーストラック)取得
+//
+// 2本の平行な直線と半円をつなげた水平なレーストラック(待機経路)が通る拡張空間IDを取得する。
+// 直線の区間は円柱、半円の区間は円環の一部(円弧に沿った管)とし、高度帯の範囲の経路が通過する空間IDを取得する。
+//
+// 引数：
+//
+//	start      : 直線の区間の始点。高さは高度帯の下端とする
+//	end        : 直線の区間の終点。高さは使用せず、始点の高さとする
+//	turnRadius : 半円の半径(単位:m)。もう1本の直線は進行方向の右側に半円の直径だけ離れた位置とする
+//	radius     : 経路の管の半径(単位:m)
+//	band       : 高度帯の高さ(単位:m)
+//	hZoom      : 水平方向の精度レベル
+//	vZoom      : 垂直方向の精度レベル
+//	isPrecision: GetExtendedSpatialIdsOnOrbitと同じオプション
+//
+// 戻り値：
+//
+//	レーストラックの経路が通る拡張空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 始点、終点がnilの場合、始点と終点が同じ位置の場合、半円、管の半径が0以下の場合、
+//	               もしくは高度帯の高さが負の場合
+//	 高さ変換不可： 高さの変換に必要なモデルが未指定の場合、
+//	               もしくは始点、終点の位置のジオイド高、標高を取得できない場合。
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func GetExtendedSpatialIdsOnRacetrack(
+	start *object.Point,
+	end *object.Point,
+	turnRadius float64,
+	radius float64,
+	band float64,
+	hZoom int64,
+	vZoom int64,
+	isPrecision ...option,
+) ([]string, error) {
+	if turnRadius <= consts.Minima {
+		return []string{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+
+	build := func(orth []spatial.Point3, factor float64) loopPieces {
+		r := turnRadius * factor
+		a := orth[0]
+		b := spatial.Point3{X: orth[1].X, Y: orth[1].Y, Z: a.Z}
+
+		// 進行方向と右側への単位ベクトル
+		d := spatial.NewVectorFromPoints(a, b).Unit()
+		right := spatial.Vector3{X: d.Y, Y: -d.X, Z: 0}
+		a2 := a.Translate(right.Scale(2 * r))
+		b2 := b.Translate(right.Scale(2 * r))
+		bCenter := b.Translate(right.Scale(r))
+		aCenter := a.Translate(right.Scale(r))
+		bTop := bCenter.Translate(d.Scale(r))
+		aTop := aCenter.Translate(d.Scale(-r))
+
+		return loopPieces{
+			arcs: []*turnArc{
+				{center: bCenter, start: b, end: bTop},
+				{center: bCenter, start: bTop, end: b2},
+				{center: aCenter, start: a2, end: aTop},
+				{center: aCenter, start: aTop, end: a},
+			},
+			legs: [][2]spatial.Point3{{a, b}, {b2, a2}},
+		}
+	}
+
+	points := []*object.Point{start, end}
+	if !common.Include(points, nil) && start.Lon() == end.Lon() && start.Lat() == end.Lat() {
+		return []string{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+
+	return getExtendedSpatialIdsOnLoop(points, build, radius, band, hZoom, vZoom, isPrecision)
+}
+
+// orbitPoint 【直交座標空間】水平な円周上の点
+func orbitPoint(center spatial.Point3, r, angle float64) spatial.Point3 {
+	sin, cos := math.Sincos(angle)
+	return spatial.Point3{X: center.X + r*cos, Y: center.Y + r*sin, Z: center.Z}
+}
+
+// getExtendedSpatialIdsOnLoop 周回経路の拡張空間ID取得
+//
+// 基準点から周回経路の区間を作成し、高度帯の下端、上端の経路の空間IDと、その間の高さの空間IDを取得する。
+//
+// 引数：
+//
+//	points： 周回経路の基準点
+//	build： 【直交座標空間】基準点の座標とWebメルカトル換算係数から区間を作成する関数
+//	radius： 経路の管の半径(単位:m)
+//	band： 高度帯の高さ(単位:m)
+//	hZoom： 水平方向の精度レベル
+//	vZoom： 垂直方向の精度レベル
+//	opts： 衝突判定実施オプション
+//
+// 戻り値：
+//
+//	周回経路が通る拡張空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	GetExtendedSpatialIdsOnOrbitと同じ
+func getExtendedSpatialIdsOnLoop(
+	points []*object.Point,
+	build func(orth []spatial.Point3, factor float64) loopPieces,
+	radius float64,
+	band float64,
+	hZoom int64,
+	vZoom int64,
+	opts []option,
+) ([]string, error) {
+	p := &IsPrecisionOpts{
+		IsPrecision: true,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	var log *slog.Logger
+	var begin time.Time
+	if p.LogHandler != nil {
+		log = slog.New(p.LogHandler)
+		begin = time.Now()
+	}
+
+	spatialIDs := []string{}
+	endRoute := startStage(p.Observer, StageRoute, RouteSegment)
+	defer func() { endRoute(StageResult{Count: len(spatialIDs)}) }()
+
+	// 入力値チェック
+	if common.Include(points, nil) || !shape.CheckZoom(hZoom) || !shape.CheckZoom(vZoom) ||
+		radius <= consts.Minima || band < 0 {
+		return spatialIDs, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	if err := checkMercatorLatitude(points); err != nil {
+		return spatialIDs, err
+	}
+
+	// 基準点の高さを楕円体高に変換
+	if p.Altitude != AltitudeEllipsoidal {
+		terrain := p.AboveGround
+		if terrain == nil {
+			terrain = p.Terrain
+		}
+		converted, err := ConvertAltitudePoints(points, p.Altitude, p.Geoid, terrain)
+		if err != nil {
+			debugLog(log, "基準点の高さを変換できません", slog.Int("altitude", int(p.Altitude)))
+			return spatialIDs, err
+		}
+		points = converted
+	}
+
+	// 経度180度に近い場合は経度を180度ずらした座標系で空間IDを取得する
+	shifted := isAntimeridianFrame(points...)
+	if shifted {
+		shiftedPoints, err := shiftLongitudes(points...)
+		if err != nil {
+			return spatialIDs, err
+		}
+		points = shiftedPoints
+	}
+
+	// メルカトル距離補正
+	factor := 1 / math.Cos(common.DegreeToRadian(points[0].Lat()))
+	debugLog(log, "メルカトル係数", slog.Float64("factor", factor), slog.Bool("shifted", shifted))
+
+	// 【直交座標空間】基準点の座標
+	crsPoints, _ := shape.ConvertPointListToProjectedPointList(points, consts.OrthCrs)
+	orth := make([]spatial.Point3, 0, len(crsPoints))
+	for _, crsPoint := range crsPoints {
+		orth = append(orth, spatial.Point3{X: crsPoint.X, Y: crsPoint.Y, Z: crsPoint.Alt * factor})
+	}
+
+	// 高度帯の下端、上端の経路の空間ID
+	offsets := []float64{0}
+	if band > 0 {
+		offsets = append(offsets, band)
+	}
+	segment := 0
+	for _, offset := range offsets {
+		bandOrth := make([]spatial.Point3, 0, len(orth))
+		for _, o := range orth {
+			bandOrth = append(bandOrth, spatial.Point3{X: o.X, Y: o.Y, Z: o.Z + offset*factor})
+		}
+		pieces := build(bandOrth, factor)
+
+		for _, leg := range pieces.legs {
+			if leg[0].IsClose(leg[1], consts.Minima) {
+				continue
+			}
+			capsule := NewCapsule(leg[0], leg[1], radius, hZoom, vZoom, false, p.IsPrecision, factor)
+			capsule.logger = log
+			capsule.SetObserver(p.Observer, segment)
+			segment++
+			legSpatialIDs, err := capsule.CalcValidSpatialIDs()
+			if err != nil {
+				return []string{}, err
+			}
+			spatialIDs = common.Union(wrapExtendedSpatialIDs(legSpatialIDs, hZoom, vZoom, shifted), spatialIDs)
+		}
+		for _, turn := range pieces.arcs {
+			arc := NewArc(turn.center, turn.start, turn.end, radius, hZoom, vZoom, p.IsPrecision, factor)
+			arc.logger = log
+			arc.SetObserver(p.Observer, segment)
+			segment++
+			arcSpatialIDs, err := arc.CalcValidSpatialIDs()
+			if err != nil {
+				return []string{}, err
+			}
+			spatialIDs = common.Union(wrapExtendedSpatialIDs(arcSpatialIDs, hZoom, vZoom, shifted), spatialIDs)
+		}
+		debugLog(log, "高度帯の経路の空間ID", slog.Float64("offset", offset), slog.Int("totalIDs", len(spatialIDs)))
+	}
+
+	// 高度帯の下端、上端の間の高さの空間IDを補完
+	if band > 0 {
+		spatialIDs = fillVerticalSpatialIDs(spatialIDs, hZoom, vZoom)
+	}
+	spatialIDs = common.Unique(spatialIDs)
+
+	// 地中の空間IDを除外
+	if p.Terrain != nil {
+		aboveIDs, undergroundIDs, err := ClassifyExtendedSpatialIdsByTerrain(
+			spatialIDs, ellipsoidalTerrainModel(p.Terrain, p.Geoid),
+		)
+		if err != nil {
+			debugLog(log, "地形による空間IDの分類ができません")
+			return []string{}, err
+		}
+		debugLog(log, "地中の空間IDを除外", slog.Int("undergroundIDs", len(undergroundIDs)))
+		spatialIDs = aboveIDs
+	}
+
+	if log != nil {
+		debugLog(
+			log,
+			"拡張空間ID(周回経路)取得",
+			slog.Int("segments", segment),
+			slog.Int("acceptedIDs", len(spatialIDs)),
+			slog.Duration("elapsed", time.Since(begin)),
+		)
+	}
+
+	return spatialIDs, nil
+}
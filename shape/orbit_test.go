@@ -0,0 +1,162 @@
+package shape
+
+import (
+	"math"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// idAtDistance テスト用基準点から方位角、距離を指定した地点の空間ID
+func idAtDistance(lon, lat, alt, azimuth, distance float64, zoom int64) string {
+	return idAtDistanceZoom(lon, lat, alt, azimuth, distance, zoom, zoom)
+}
+
+// idAtDistanceZoom テスト用基準点から方位角、距離を指定した地点の水平精度、垂直精度の拡張空間ID
+func idAtDistanceZoom(lon, lat, alt, azimuth, distance float64, hZoom, vZoom int64) string {
+	lon, lat = geodesicDirect(lon, lat, common.DegreeToRadian(azimuth), distance)
+	p, _ := object.NewPoint(lon, lat, alt)
+	ids, _ := shape.GetExtendedSpatialIdsOnPoints([]*object.Point{p}, hZoom, vZoom)
+	return ids[0]
+}
+
+// TestOrbit01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 中心：(139.75, 35.68, 100.0)、周回円の半径：200.0、管の半径：10.0、水平精度：20、垂直精度：20
+//   - パターン1：高度帯なし
+//   - パターン2：高度帯50.0m
+//
+// + 確認内容
+//   - 中心から8方位に200mの地点の空間IDが含まれること
+//   - 中心の空間IDが含まれないこと
+//   - 全ての空間IDの中心が周回円から離れていないこと(中心からの距離が140m～260m)
+//   - パターン2：高度帯の中央(高さ125.0m)の空間IDが含まれ、高さ200.0mの空間IDが含まれないこと
+func TestOrbit01(t *testing.T) {
+	//入力パラメータ
+	center, _ := object.NewPoint(139.75, 35.68, 100.0)
+
+	for i, band := range []float64{0, 50} {
+		// テスト対象呼び出し
+		resultVal, err := GetExtendedSpatialIdsOnOrbit(center, 200.0, 10.0, band, 20, 20)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		for azimuth := 0.0; azimuth < 360; azimuth += 45 {
+			if id := idAtDistance(center.Lon(), center.Lat(), 100.0, azimuth, 200, 20); !common.Include(resultVal, id) {
+				t.Errorf("パターン%d 方位%v度 - 期待値：%v を含む, 取得値：%v", i+1, azimuth, id, resultVal)
+			}
+		}
+		if id := idAtDistance(center.Lon(), center.Lat(), 100.0, 0, 0, 20); common.Include(resultVal, id) {
+			t.Errorf("パターン%d 中心 - 期待値：%v を含まない, 取得値：%v", i+1, id, resultVal)
+		}
+		for _, spatialID := range resultVal {
+			points, _ := shape.GetPointOnExtendedSpatialId(spatialID, enum.Center)
+			distance, _, _ := geodesicInverse(center.Lon(), center.Lat(), points[0].Lon(), points[0].Lat())
+			if distance < 140 || distance > 260 {
+				t.Errorf("パターン%d 空間ID%v - 期待値：中心から140m～260m, 取得値：%v", i+1, spatialID, distance)
+			}
+		}
+
+		middleID := idAtDistance(center.Lon(), center.Lat(), 125.0, 90, 200, 20)
+		highID := idAtDistance(center.Lon(), center.Lat(), 200.0, 90, 200, 20)
+		if band > 0 && (!common.Include(resultVal, middleID) || common.Include(resultVal, highID)) {
+			t.Errorf("パターン%d 高度帯 - 期待値：%v を含み、%v を含まない, 取得値：%v", i+1, middleID, highID, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestRacetrack01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点：(139.75, 35.68, 100.0)、終点：(139.76, 35.68, 100.0)(東向き約900m)
+//   - 半円の半径：100.0、管の半径：10.0、水平精度：20、垂直精度：20、衝突判定あり、なし
+//
+// + 確認内容
+//   - 直線の区間の中点と、その南200mの地点(右側の直線の区間)の空間IDが含まれること
+//   - 終点の南100m、東100mの地点(半円の頂点)、始点の南100m、西100mの地点の空間IDが含まれること
+//   - レーストラックの中央(直線の区間の中点の南100m)の空間IDが含まれないこと
+func TestRacetrack01(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 100.0)
+	end, _ := object.NewPoint(139.76, 35.68, 100.0)
+
+	// 期待値
+	midLon := (start.Lon() + end.Lon()) / 2
+	endLon, endLat := geodesicDirect(end.Lon(), end.Lat(), math.Pi, 100)
+	startLon, startLat := geodesicDirect(start.Lon(), start.Lat(), math.Pi, 100)
+	includeIDs := []string{
+		idAtDistance(midLon, 35.68, 100.0, 0, 0, 20),
+		idAtDistance(midLon, 35.68, 100.0, 180, 200, 20),
+		idAtDistance(endLon, endLat, 100.0, 90, 100, 20),
+		idAtDistance(startLon, startLat, 100.0, 270, 100, 20),
+	}
+	excludeID := idAtDistance(midLon, 35.68, 100.0, 180, 100, 20)
+
+	for _, isPrecision := range []bool{true, false} {
+		// テスト対象呼び出し
+		resultVal, err := GetExtendedSpatialIdsOnRacetrack(start, end, 100.0, 10.0, 0, 20, 20, IsPrecision(isPrecision))
+		if err != nil {
+			t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+		}
+
+		for i, id := range includeIDs {
+			if !common.Include(resultVal, id) {
+				t.Errorf("衝突判定%v 地点%d - 期待値：%v を含む, 取得値：%v", isPrecision, i+1, id, resultVal)
+			}
+		}
+		if common.Include(resultVal, excludeID) {
+			t.Errorf("衝突判定%v 中央 - 期待値：%v を含まない, 取得値：%v", isPrecision, excludeID, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestOrbit02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：周回円の半径が0
+//   - パターン2：管の半径が0
+//   - パターン3：高度帯の高さが負
+//   - パターン4：中心がnil
+//   - パターン5：レーストラックの始点と終点が同じ位置
+//   - パターン6：レーストラックの半円の半径が0
+//   - パターン7：精度が範囲外
+//
+// + 確認内容
+//   - 入力チェックエラーとなること
+func TestOrbit02(t *testing.T) {
+	//入力パラメータ
+	p, _ := object.NewPoint(139.75, 35.68, 100.0)
+	q, _ := object.NewPoint(139.75, 35.68, 200.0)
+	calls := []func() ([]string, error){
+		func() ([]string, error) { return GetExtendedSpatialIdsOnOrbit(p, 0, 10, 0, 20, 20) },
+		func() ([]string, error) { return GetExtendedSpatialIdsOnOrbit(p, 200, 0, 0, 20, 20) },
+		func() ([]string, error) { return GetExtendedSpatialIdsOnOrbit(p, 200, 10, -1, 20, 20) },
+		func() ([]string, error) { return GetExtendedSpatialIdsOnOrbit(nil, 200, 10, 0, 20, 20) },
+		func() ([]string, error) { return GetExtendedSpatialIdsOnRacetrack(p, q, 100, 10, 0, 20, 20) },
+		func() ([]string, error) { return GetExtendedSpatialIdsOnRacetrack(p, q, 0, 10, 0, 20, 20) },
+		func() ([]string, error) { return GetSpatialIdsOnOrbit(p, 200, 10, 0, 36) },
+	}
+
+	for i, call := range calls {
+		// テスト対象呼び出し
+		resultVal, err := call()
+
+		if err == nil || len(resultVal) != 0 {
+			t.Errorf("パターン%d - 期待値：入力チェックエラー, 取得値：%v, %v", i+1, resultVal, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
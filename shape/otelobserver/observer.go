@@ -0,0 +1,177 @@
+// Package otelobserver 空間ID取得処理のOpenTelemetry計測パッケージ
+//
+// shape.StageObserverをOpenTelemetryのトレース、メトリクスに変換する。
+// 処理段階ごとにスパンを作成し、処理時間と空間IDの数をメトリクスとして記録する。
+//
+// 使用例：
+//
+//	observer, err := otelobserver.New(tracerProvider.Tracer("spatialid"), meterProvider.Meter("spatialid"))
+//	...
+//	ids, err := shape.GetExtendedSpatialIdsOnCylinders(
+//		center, radius, hZoom, vZoom, false, shape.Observer(observer.WithContext(ctx)),
+//	)
+package otelobserver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// メトリクス名、スパン名、属性名
+const (
+	DurationMetric = "spatialid.stage.duration"         // 処理段階の処理時間(単位:s)
+	CountMetric    = "spatialid.stage.count"            // 処理段階で得られた空間IDの数
+	TestsMetric    = "spatialid.collision.tests"        // 衝突判定の実施回数
+	spanPrefix     = "spatialid."                       // スパン名の接頭辞
+	stageKey       = attribute.Key("spatialid.stage")   // 処理段階の属性名
+	segmentKey     = attribute.Key("spatialid.segment") // 区間番号の属性名
+	countKey       = attribute.Key("spatialid.count")   // 空間IDの数の属性名
+	testsKey       = attribute.Key("spatialid.tests")   // 衝突判定の実施回数の属性名
+)
+
+// Observerがshape.StageObserverを実装していることの確認
+var _ shape.StageObserver = (*Observer)(nil)
+
+// instruments メトリクスの計測器
+type instruments struct {
+	duration metric.Float64Histogram // 処理段階の処理時間
+	count    metric.Int64Histogram   // 処理段階で得られた空間IDの数
+	tests    metric.Int64Counter     // 衝突判定の実施回数
+}
+
+// Observer OpenTelemetryによる処理段階の観測構造体
+//
+// shape.StageObserverを実装する。
+// 入れ子の処理段階のスパンを親子関係とするため、処理中のスパンをスタックで保持する。
+// 複数の空間ID取得を並行して計測する場合は、WithContextで取得ごとに観測を分ける。
+type Observer struct {
+	tracer      trace.Tracer      // スパンの作成元
+	instruments *instruments      // メトリクスの計測器
+	ctx         context.Context   // 最上位のスパンの親コンテキスト
+	mutex       sync.Mutex        // スタックの排他制御
+	stack       []context.Context // 処理中のスパンのコンテキスト
+}
+
+// New 観測構造体初期化関数
+//
+// 引数：
+//
+//	tracer： スパンの作成元
+//	meter： メトリクスの計測器の作成元
+//
+// 戻り値：
+//
+//	初期化したObserverオブジェクト
+//
+// 戻り値(エラー)：
+//
+//	メトリクスの計測器の作成に失敗した場合、エラーインスタンスが返却される。
+func New(tracer trace.Tracer, meter metric.Meter) (*Observer, error) {
+	duration, err := meter.Float64Histogram(
+		DurationMetric,
+		metric.WithDescription("空間ID取得の処理段階ごとの処理時間"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	count, err := meter.Int64Histogram(
+		CountMetric,
+		metric.WithDescription("空間ID取得の処理段階ごとに得られた空間IDの数"),
+		metric.WithUnit("{id}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	tests, err := meter.Int64Counter(
+		TestsMetric,
+		metric.WithDescription("衝突判定の実施回数"),
+		metric.WithUnit("{test}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Observer{
+		tracer:      tracer,
+		instruments: &instruments{duration: duration, count: count, tests: tests},
+		ctx:         context.Background(),
+	}, nil
+}
+
+// WithContext 親コンテキスト指定の観測取得
+//
+// メトリクスの計測器を共有し、ctxのスパンを最上位のスパンの親とする観測を返却する。
+//
+// 引数：
+//
+//	ctx： 親コンテキスト
+//
+// 戻り値：
+//
+//	ctxを親とするObserverオブジェクト
+func (o *Observer) WithContext(ctx context.Context) *Observer {
+	return &Observer{
+		tracer:      o.tracer,
+		instruments: o.instruments,
+		ctx:         ctx,
+	}
+}
+
+// StartStage 処理段階の開始
+//
+// 処理段階のスパンを開始し、終了時にスパンの終了とメトリクスの記録を行う関数を返却する。
+//
+// 引数：
+//
+//	stage： 処理段階
+//	segment： 区間番号
+//
+// 戻り値：
+//
+//	処理段階の終了時に結果を渡して呼び出す関数
+func (o *Observer) StartStage(stage shape.Stage, segment int) func(shape.StageResult) {
+	begin := time.Now()
+
+	o.mutex.Lock()
+	parent := o.ctx
+	if len(o.stack) > 0 {
+		parent = o.stack[len(o.stack)-1]
+	}
+	ctx, span := o.tracer.Start(
+		parent,
+		spanPrefix+string(stage),
+		trace.WithAttributes(stageKey.String(string(stage)), segmentKey.Int(segment)),
+	)
+	o.stack = append(o.stack, ctx)
+	depth := len(o.stack)
+	o.mutex.Unlock()
+
+	return func(result shape.StageResult) {
+		o.mutex.Lock()
+		// 自身より内側の処理段階が終了していない場合も含めてスタックから取り除く
+		if len(o.stack) >= depth {
+			o.stack = o.stack[:depth-1]
+		}
+		o.mutex.Unlock()
+
+		span.SetAttributes(countKey.Int(result.Count))
+		if stage == shape.StageCollision {
+			span.SetAttributes(testsKey.Int(result.Tests))
+		}
+		span.End()
+
+		attrs := metric.WithAttributes(stageKey.String(string(stage)))
+		o.instruments.duration.Record(ctx, time.Since(begin).Seconds(), attrs)
+		o.instruments.count.Record(ctx, int64(result.Count), attrs)
+		if stage == shape.StageCollision {
+			o.instruments.tests.Add(ctx, int64(result.Tests))
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package otelobserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestObserver01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.753098, 35.685371, 11.0), (139.753198, 35.685371, 11.0)
+//   - 半径：2.0、水平精度：25、垂直精度：25
+//   - 観測：インメモリのエクスポーター、リーダーを設定したObserver
+//
+// + 確認内容
+//   - 経路全体、区間、各処理段階のスパンが親子関係で出力されること
+//   - 経路全体のスパンの空間IDの数が取得結果と一致すること
+//   - 処理時間、空間IDの数、衝突判定の実施回数のメトリクスが記録されること
+func TestObserver01(t *testing.T) {
+	//入力パラメータ
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	observer, err := New(tracerProvider.Tracer("test"), meterProvider.Meter("test"))
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+
+	// テスト対象呼び出し
+	resultVal, err := shape.GetExtendedSpatialIdsOnCylinders(
+		[]*object.Point{p1, p2}, 2.0, 25, 25, false,
+		shape.Observer(observer.WithContext(context.Background())),
+	)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	// スパンの確認
+	spans := exporter.GetSpans()
+	byName := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	route, ok := byName["spatialid.route"]
+	if !ok {
+		t.Fatalf("スパン - 期待値：spatialid.route, 取得値：%v", len(spans))
+	}
+	for _, attr := range route.Attributes {
+		if attr.Key == countKey && attr.Value.AsInt64() != int64(len(resultVal)) {
+			t.Errorf("空間IDの数 - 期待値：%v, 取得値：%v", len(resultVal), attr.Value.AsInt64())
+		}
+	}
+	segment := byName["spatialid.segment"]
+	if segment.Parent.SpanID() != route.SpanContext.SpanID() {
+		t.Errorf("親スパン - 期待値：spatialid.route, 取得値：%v", segment.Parent.SpanID())
+	}
+	for _, name := range []string{"spatialid.line", "spatialid.candidate", "spatialid.include", "spatialid.collision"} {
+		stage, ok := byName[name]
+		if !ok {
+			t.Errorf("スパン - 期待値：%v, 取得値：なし", name)
+			continue
+		}
+		if stage.Parent.SpanID() != segment.SpanContext.SpanID() {
+			t.Errorf("親スパン - 期待値：spatialid.segment, 取得値：%v(%v)", stage.Parent.SpanID(), name)
+		}
+	}
+
+	// メトリクスの確認
+	metrics := metricdata.ResourceMetrics{}
+	if err := reader.Collect(context.Background(), &metrics); err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	recorded := map[string]bool{}
+	for _, scope := range metrics.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			recorded[m.Name] = true
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == TestsMetric {
+				if len(sum.DataPoints) != 1 || sum.DataPoints[0].Value <= 0 {
+					t.Errorf("衝突判定の実施回数 - 期待値：1以上, 取得値：%v", sum.DataPoints)
+				}
+			}
+		}
+	}
+	for _, name := range []string{DurationMetric, CountMetric, TestsMetric} {
+		if !recorded[name] {
+			t.Errorf("メトリクス - 期待値：%v, 取得値：なし", name)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestObserver02 正常系動作確認(衝突判定なし)
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.753098, 35.685371, 11.0)、半径：1.0、精度：25、衝突判定：未実施
+//
+// + 確認内容
+//   - 内部空間ID取得、衝突判定のスパンが出力されないこと
+func TestObserver02(t *testing.T) {
+	//入力パラメータ
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	observer, _ := New(tracerProvider.Tracer("test"), sdkmetric.NewMeterProvider().Meter("test"))
+	p, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+
+	// テスト対象呼び出し
+	shape.GetExtendedSpatialIdsOnCylinders(
+		[]*object.Point{p}, 1.0, 25, 25, false, shape.IsPrecision(false), shape.Observer(observer),
+	)
+
+	names := map[string]bool{}
+	for _, span := range exporter.GetSpans() {
+		names[span.Name] = true
+	}
+	if !names["spatialid.route"] || !names["spatialid.candidate"] || names["spatialid.include"] || names["spatialid.collision"] {
+		t.Errorf("スパン - 期待値：route, candidateあり、include, collisionなし, 取得値：%v", names)
+	}
+
+	t.Log("テスト終了")
+}
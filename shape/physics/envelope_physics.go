@@ -0,0 +1,112 @@
+// Package physics 物理オブジェクト操作パッケージ
+package physics
+
+import (
+	"math"
+
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+)
+
+// envelopeRefineIterate 線分上の最近点の探索の反復回数
+const envelopeRefineIterate = 40
+
+// EnvelopePhysics 半径が変化する管用の物理オブジェクト構造体
+//
+// 始点から終点まで水平方向、垂直方向の半径が線形に変化する回転楕円体を掃引した形状とする。
+// ODEに該当するジオメトリが無いため、衝突判定は線分とボクセルの距離により行う。
+type EnvelopePhysics struct {
+	BasePhysics                     // 基底物理オブジェクト構造体の埋め込み
+	start           spatial.Point3  // 始点
+	axis            spatial.Vector3 // 始点から終点へのベクトル
+	startHorizontal float64         // 始点の水平方向の半径
+	startVertical   float64         // 始点の垂直方向の半径
+	endHorizontal   float64         // 終点の水平方向の半径
+	endVertical     float64         // 終点の垂直方向の半径
+}
+
+// NewEnvelopePhysics 半径が変化する管用の物理オブジェクト構造体コンストラクタ
+//
+// 引数：
+//
+//	start：始点
+//	end：終点
+//	startHorizontal：始点の水平方向の半径
+//	startVertical：始点の垂直方向の半径
+//	endHorizontal：終点の水平方向の半径
+//	endVertical：終点の垂直方向の半径
+//
+// 戻り値：
+//
+//	半径が変化する管用の物理オブジェクト構造体
+func NewEnvelopePhysics(
+	start spatial.Point3,
+	end spatial.Point3,
+	startHorizontal float64,
+	startVertical float64,
+	endHorizontal float64,
+	endVertical float64,
+) *EnvelopePhysics {
+	return &EnvelopePhysics{
+		BasePhysics:     *NewBasePhysics(),
+		start:           start,
+		axis:            spatial.NewVectorFromPoints(start, end),
+		startHorizontal: startHorizontal,
+		startVertical:   startVertical,
+		endHorizontal:   endHorizontal,
+		endVertical:     endVertical,
+	}
+}
+
+// IsCollideVoxel ボクセルオブジェクト衝突判定処理
+//
+// 線分上のいずれかの点の回転楕円体とボクセルが重なる場合に衝突と判定する。
+//
+// 引数：
+//
+//	center: ボクセル中心
+//	lens: ボクセルの対角線ベクトル
+//
+// 戻り値：
+//
+//	衝突判定結果
+func (e EnvelopePhysics) IsCollideVoxel(center spatial.Point3, lens spatial.Vector3) bool {
+	// 回転楕円体を単位球とした場合のボクセルまでの距離の2乗(1以下で重なる)
+	distance := func(t float64) float64 {
+		point := e.start.Translate(e.axis.Scale(t))
+		horizontal := e.startHorizontal + (e.endHorizontal-e.startHorizontal)*t
+		vertical := e.startVertical + (e.endVertical-e.startVertical)*t
+		dx := math.Max(math.Abs(point.X-center.X)-lens.X/2, 0)
+		dy := math.Max(math.Abs(point.Y-center.Y)-lens.Y/2, 0)
+		dz := math.Max(math.Abs(point.Z-center.Z)-lens.Z/2, 0)
+		return (dx*dx+dy*dy)/(horizontal*horizontal) + dz*dz/(vertical*vertical)
+	}
+
+	// ボクセルの大きさの1/4以下の間隔で線分上の点を確認する
+	minLen := math.Min(lens.X, math.Min(lens.Y, lens.Z))
+	n := 16
+	if minLen > 0 {
+		n = int(math.Max(float64(n), math.Ceil(e.axis.Norm()/(minLen/4))))
+	}
+	step := 1 / float64(n)
+
+	best, bestDistance := 0.0, distance(0)
+	for i := 1; i <= n; i++ {
+		if d := distance(step * float64(i)); d < bestDistance {
+			best, bestDistance = step*float64(i), d
+		}
+	}
+
+	// 最も近い点の前後で三分探索
+	low, high := math.Max(best-step, 0), math.Min(best+step, 1)
+	for i := 0; i < envelopeRefineIterate; i++ {
+		m1, m2 := low+(high-low)/3, high-(high-low)/3
+		if distance(m1) < distance(m2) {
+			high = m2
+		} else {
+			low = m1
+		}
+	}
+	bestDistance = math.Min(bestDistance, distance((low+high)/2))
+
+	return bestDistance <= 1
+}
@@ -0,0 +1,55 @@
+package physics
+
+import (
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+)
+
+// TestEnvelopePhysicsIsCollideVoxel01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点: (0,0,0)、水平方向の半径: 1、垂直方向の半径: 0.5
+//   - 終点: (10,0,0)、水平方向の半径: 3、垂直方向の半径: 1
+//   - ボクセルの対角線ベクトル: (0.2,0.2,0.2)
+//   - パターン1：ボクセル中心(0,1.05,0)(始点の水平方向の半径以内)
+//   - パターン2：ボクセル中心(0,0,0.8)(始点の垂直方向の半径より遠い)
+//   - パターン3：ボクセル中心(10,2.8,0)(終点の水平方向の半径以内)
+//   - パターン4：ボクセル中心(10,0,1.2)(終点の垂直方向の半径より遠い)
+//   - パターン5：ボクセル中心(5,1.9,0)(中点の水平方向の半径以内)
+//   - パターン6：ボクセル中心(5,2.3,0)(中点の水平方向の半径より遠い)
+//
+// + 確認内容
+//   - パターン1、3、5は衝突、パターン2、4、6は衝突しないと判定されること
+func TestEnvelopePhysicsIsCollideVoxel01(t *testing.T) {
+	//入力値
+	envelope := NewEnvelopePhysics(
+		spatial.Point3{X: 0, Y: 0, Z: 0},
+		spatial.Point3{X: 10, Y: 0, Z: 0},
+		1, 0.5, 3, 1,
+	)
+	lens := spatial.Vector3{X: 0.2, Y: 0.2, Z: 0.2}
+	inputs := []struct {
+		center spatial.Point3
+		expect bool
+	}{
+		{spatial.Point3{X: 0, Y: 1.05, Z: 0}, true},
+		{spatial.Point3{X: 0, Y: 0, Z: 0.8}, false},
+		{spatial.Point3{X: 10, Y: 2.8, Z: 0}, true},
+		{spatial.Point3{X: 10, Y: 0, Z: 1.2}, false},
+		{spatial.Point3{X: 5, Y: 1.9, Z: 0}, true},
+		{spatial.Point3{X: 5, Y: 2.3, Z: 0}, false},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		result := envelope.IsCollideVoxel(input.center, lens)
+
+		if result != input.expect {
+			t.Errorf("パターン%d 衝突判定 - 期待値：%v, 取得値：%v", i+1, input.expect, result)
+		}
+	}
+
+	t.Log("テスト終了")
+}
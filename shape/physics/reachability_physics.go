@@ -0,0 +1,123 @@
+// Package physics 物理オブジェクト操作パッケージ
+package physics
+
+import (
+	"math"
+
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+)
+
+// reachabilityRefineIterate 到達範囲の最近時刻の探索の反復回数
+const reachabilityRefineIterate = 60
+
+// ReachabilityPhysics 到達可能範囲用の物理オブジェクト構造体
+//
+// 時刻tの到達可能範囲を、始点から風で流された位置を中心とし、
+// 水平方向の半径、上昇量、下降量が時刻に比例して大きくなる円柱とする。
+// 時刻は0～1に正規化し、到達可能範囲は全ての時刻の円柱の和集合とする。
+// ODEに該当するジオメトリが無いため、衝突判定はボクセルと円柱の距離により行う。
+type ReachabilityPhysics struct {
+	BasePhysics                 // 基底物理オブジェクト構造体の埋め込み
+	start       spatial.Point3  // 始点
+	drift       spatial.Vector3 // 時刻1までに風で流される移動量
+	radius      float64         // 時刻1の水平方向の半径
+	climb       float64         // 時刻1の上昇量
+	descent     float64         // 時刻1の下降量
+}
+
+// NewReachabilityPhysics 到達可能範囲用の物理オブジェクト構造体コンストラクタ
+//
+// 引数：
+//
+//	start  ：始点
+//	drift  ：時刻1までに風で流される移動量
+//	radius ：時刻1の水平方向の半径
+//	climb  ：時刻1の上昇量
+//	descent：時刻1の下降量
+//
+// 戻り値：
+//
+//	到達可能範囲用の物理オブジェクト構造体
+func NewReachabilityPhysics(
+	start spatial.Point3,
+	drift spatial.Vector3,
+	radius float64,
+	climb float64,
+	descent float64,
+) *ReachabilityPhysics {
+	return &ReachabilityPhysics{
+		BasePhysics: *NewBasePhysics(),
+		start:       start,
+		drift:       drift,
+		radius:      radius,
+		climb:       climb,
+		descent:     descent,
+	}
+}
+
+// IsCollideVoxel ボクセルオブジェクト衝突判定処理
+//
+// ボクセルの高さの範囲と円柱の高さの範囲が重なる時刻のうち、
+// ボクセルと円柱の中心軸の水平距離が円柱の半径以下となる時刻がある場合に衝突と判定する。
+//
+// 引数：
+//
+//	center: ボクセル中心
+//	lens: ボクセルの対角線ベクトル
+//
+// 戻り値：
+//
+//	衝突判定結果
+func (r ReachabilityPhysics) IsCollideVoxel(center spatial.Point3, lens spatial.Vector3) bool {
+	// 高さの範囲が重なる時刻(円柱の下端がボクセルの上端以下、円柱の上端がボクセルの下端以上)
+	low, high := 0.0, 1.0
+	low, high = limitLinear(low, high, r.start.Z, r.drift.Z-r.descent, center.Z+lens.Z/2)
+	low, high = limitLinear(low, high, -r.start.Z, -(r.drift.Z + r.climb), -(center.Z - lens.Z/2))
+	if low > high {
+		return false
+	}
+
+	// 水平距離と円柱の半径の差(時刻に対して凸関数)
+	excess := func(t float64) float64 {
+		dx := math.Max(math.Abs(r.start.X+r.drift.X*t-center.X)-lens.X/2, 0)
+		dy := math.Max(math.Abs(r.start.Y+r.drift.Y*t-center.Y)-lens.Y/2, 0)
+		return math.Hypot(dx, dy) - r.radius*t
+	}
+
+	// 凸関数のため三分探索で最小値を求める
+	for i := 0; i < reachabilityRefineIterate; i++ {
+		m1, m2 := low+(high-low)/3, high-(high-low)/3
+		if excess(m1) < excess(m2) {
+			high = m2
+		} else {
+			low = m1
+		}
+	}
+
+	return excess((low+high)/2) <= 0
+}
+
+// limitLinear 一次式の不等式を満たす時刻の範囲
+//
+// 引数：
+//
+//	low ：時刻の範囲の下端
+//	high：時刻の範囲の上端
+//	a   ：一次式の定数項
+//	b   ：一次式の係数
+//	limit：一次式の上限
+//
+// 戻り値：
+//
+//	low～highのうち a + b*t ≦ limit を満たす時刻の範囲(満たす時刻が無い場合は下端が上端より大きい)
+func limitLinear(low, high, a, b, limit float64) (float64, float64) {
+	switch {
+	case b > 0:
+		high = math.Min(high, (limit-a)/b)
+	case b < 0:
+		low = math.Max(low, (limit-a)/b)
+	case a > limit:
+		return 1, 0
+	}
+	return low, high
+}
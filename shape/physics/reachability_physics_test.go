@@ -0,0 +1,57 @@
+package physics
+
+import (
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+)
+
+// TestReachabilityPhysicsIsCollideVoxel01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点: (0,0,0)、風で流される移動量: (10,0,0)
+//   - 水平方向の半径: 5、上昇量: 2、下降量: 1
+//   - ボクセルの対角線ベクトル: (0.2,0.2,0.2)
+//   - パターン1：ボクセル中心(15,0,0)(時刻1の円柱の内部)
+//   - パターン2：ボクセル中心(-3,0,0)(風上で到達できない)
+//   - パターン3：ボクセル中心(10,0,1.8)(上昇量以内)
+//   - パターン4：ボクセル中心(10,0,2.3)(上昇量より高い)
+//   - パターン5：ボクセル中心(5,0,-0.4)(時刻0.5以降の円柱の内部)
+//   - パターン6：ボクセル中心(2,0,-0.8)(下降できる時刻には流されて到達できない)
+//   - パターン7：ボクセル中心(0,4,0)(風に直交する方向で到達できない)
+//
+// + 確認内容
+//   - パターン1、3、5は衝突、パターン2、4、6、7は衝突しないと判定されること
+func TestReachabilityPhysicsIsCollideVoxel01(t *testing.T) {
+	//入力値
+	reachability := NewReachabilityPhysics(
+		spatial.Point3{X: 0, Y: 0, Z: 0},
+		spatial.Vector3{X: 10, Y: 0, Z: 0},
+		5, 2, 1,
+	)
+	lens := spatial.Vector3{X: 0.2, Y: 0.2, Z: 0.2}
+	inputs := []struct {
+		center spatial.Point3
+		expect bool
+	}{
+		{spatial.Point3{X: 15, Y: 0, Z: 0}, true},
+		{spatial.Point3{X: -3, Y: 0, Z: 0}, false},
+		{spatial.Point3{X: 10, Y: 0, Z: 1.8}, true},
+		{spatial.Point3{X: 10, Y: 0, Z: 2.3}, false},
+		{spatial.Point3{X: 5, Y: 0, Z: -0.4}, true},
+		{spatial.Point3{X: 2, Y: 0, Z: -0.8}, false},
+		{spatial.Point3{X: 0, Y: 4, Z: 0}, false},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		result := reachability.IsCollideVoxel(input.center, lens)
+
+		if result != input.expect {
+			t.Errorf("パターン%d 衝突判定 - 期待値：%v, 取得値：%v", i+1, input.expect, result)
+		}
+	}
+
+	t.Log("テスト終了")
+}
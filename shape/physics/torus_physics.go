@@ -0,0 +1,157 @@
+// Package physics 物理オブジェクト操作パッケージ
+package physics
+
+import (
+	"math"
+
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+)
+
+// torusRefineIterate 円弧上の最近点の探索の反復回数
+const torusRefineIterate = 40
+
+// TorusPhysics 円環の一部(円弧に沿った管)用の物理オブジェクト構造体
+//
+// ODEに円環のジオメトリが無いため、衝突判定は円弧とボクセルの距離により行う。
+type TorusPhysics struct {
+	BasePhysics                 // 基底物理オブジェクト構造体の埋め込み
+	radius      float64         // 管の半径
+	center      spatial.Point3  // 円弧の中心
+	majorRadius float64         // 円弧の半径
+	e1          spatial.Vector3 // 円弧の中心から始点への単位ベクトル
+	e2          spatial.Vector3 // 円弧の面内でe1に直交し終点側を向く単位ベクトル
+	angle       float64         // 円弧の中心角(単位:rad)
+}
+
+// NewTorusPhysics 円環の一部用の物理オブジェクト構造体コンストラクタ
+//
+// 円弧の中心から始点、終点への中心角が180度未満の円弧に沿った管の物理オブジェクトを作成する。
+//
+// 引数：
+//
+//	radius：管の半径
+//	center：円弧の中心
+//	start ：円弧の始点
+//	end   ：円弧の終点(円弧の中心からの距離は始点と同じとする)
+//
+// 戻り値：
+//
+//	円環の一部用の物理オブジェクト構造体
+func NewTorusPhysics(radius float64, center spatial.Point3, start spatial.Point3, end spatial.Point3) *TorusPhysics {
+	toStart := spatial.NewVectorFromPoints(center, start)
+	toEnd := spatial.NewVectorFromPoints(center, end)
+	majorRadius := toStart.Norm()
+	e1 := toStart.Unit()
+
+	// 円弧の面内で始点方向に直交する単位ベクトル
+	cosAngle := dotVector(toEnd, e1)
+	perpendicular := spatial.Vector3{
+		X: toEnd.X - e1.X*cosAngle,
+		Y: toEnd.Y - e1.Y*cosAngle,
+		Z: toEnd.Z - e1.Z*cosAngle,
+	}
+	e2 := spatial.Vector3{}
+	angle := 0.0
+	if norm := perpendicular.Norm(); norm > 0 {
+		e2 = perpendicular.Scale(1 / norm)
+		angle = math.Atan2(norm, cosAngle)
+	}
+
+	return &TorusPhysics{
+		BasePhysics: *NewBasePhysics(),
+		radius:      radius,
+		center:      center,
+		majorRadius: majorRadius,
+		e1:          e1,
+		e2:          e2,
+		angle:       angle,
+	}
+}
+
+// IsCollideVoxel ボクセルオブジェクト衝突判定処理
+//
+// 円弧上の点とボクセルの最短距離が管の半径以下の場合に衝突と判定する。
+//
+// 引数：
+//
+//	center: ボクセル中心
+//	lens: ボクセルの対角線ベクトル
+//
+// 戻り値：
+//
+//	衝突判定結果
+func (t TorusPhysics) IsCollideVoxel(center spatial.Point3, lens spatial.Vector3) bool {
+	distance := func(phi float64) float64 {
+		return boxDistance(t.pointAt(phi), center, lens)
+	}
+
+	// ボクセルの大きさの1/4以下の間隔で円弧上の点を確認する
+	minLen := math.Min(lens.X, math.Min(lens.Y, lens.Z))
+	n := 16
+	if minLen > 0 {
+		n = int(math.Max(float64(n), math.Ceil(t.angle*t.majorRadius/(minLen/4))))
+	}
+	step := t.angle / float64(n)
+
+	best, bestDistance := 0.0, distance(0)
+	for i := 1; i <= n; i++ {
+		if d := distance(step * float64(i)); d < bestDistance {
+			best, bestDistance = step*float64(i), d
+		}
+	}
+
+	// 最も近い点の前後で三分探索
+	low, high := math.Max(best-step, 0), math.Min(best+step, t.angle)
+	for i := 0; i < torusRefineIterate; i++ {
+		m1, m2 := low+(high-low)/3, high-(high-low)/3
+		if distance(m1) < distance(m2) {
+			high = m2
+		} else {
+			low = m1
+		}
+	}
+	bestDistance = math.Min(bestDistance, distance((low+high)/2))
+
+	return bestDistance <= t.radius
+}
+
+// pointAt 円弧上の点
+//
+// 引数：
+//
+//	phi：始点からの中心角(単位:rad)
+//
+// 戻り値：
+//
+//	円弧上の点
+func (t TorusPhysics) pointAt(phi float64) spatial.Point3 {
+	sin, cos := math.Sincos(phi)
+	return spatial.Point3{
+		X: t.center.X + t.majorRadius*(t.e1.X*cos+t.e2.X*sin),
+		Y: t.center.Y + t.majorRadius*(t.e1.Y*cos+t.e2.Y*sin),
+		Z: t.center.Z + t.majorRadius*(t.e1.Z*cos+t.e2.Z*sin),
+	}
+}
+
+// boxDistance 点とボクセルの最短距離
+//
+// 引数：
+//
+//	point：点
+//	center：ボクセル中心
+//	lens：ボクセルの対角線ベクトル
+//
+// 戻り値：
+//
+//	最短距離(点がボクセル内部の場合は0)
+func boxDistance(point spatial.Point3, center spatial.Point3, lens spatial.Vector3) float64 {
+	dx := math.Max(math.Abs(point.X-center.X)-lens.X/2, 0)
+	dy := math.Max(math.Abs(point.Y-center.Y)-lens.Y/2, 0)
+	dz := math.Max(math.Abs(point.Z-center.Z)-lens.Z/2, 0)
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// dotVector ベクトルの内積
+func dotVector(a, b spatial.Vector3) float64 {
+	return a.X*b.X + a.Y*b.Y + a.Z*b.Z
+}
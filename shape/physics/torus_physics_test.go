@@ -0,0 +1,100 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+)
+
+// TestNewTorusPhysics01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 管の半径: 1
+//   - 円弧の中心: (1,2,3)
+//   - 円弧の始点: (11,2,3)
+//   - 円弧の終点: (1,12,3)
+//
+// + 確認内容
+//   - 円弧の半径が10、中心角が90度であること
+//   - 円弧の始点、終点、中点が円弧上の点として取得されること
+func TestNewTorusPhysics01(t *testing.T) {
+	//入力値
+	center := spatial.Point3{X: 1, Y: 2, Z: 3}
+	start := spatial.Point3{X: 11, Y: 2, Z: 3}
+	end := spatial.Point3{X: 1, Y: 12, Z: 3}
+
+	// テスト対象呼び出し
+	resultP := NewTorusPhysics(1, center, start, end)
+
+	if resultP.radius != 1 || resultP.majorRadius != 10 || math.Abs(resultP.angle-math.Pi/2) > 1e-12 {
+		t.Errorf("半径、中心角 - 期待値：1, 10, %v, 取得値：%v, %v, %v",
+			math.Pi/2, resultP.radius, resultP.majorRadius, resultP.angle)
+	}
+
+	mid := 10 / math.Sqrt2
+	for _, c := range []struct {
+		phi    float64
+		expect spatial.Point3
+	}{
+		{0, start},
+		{math.Pi / 2, end},
+		{math.Pi / 4, spatial.Point3{X: 1 + mid, Y: 2 + mid, Z: 3}},
+	} {
+		result := resultP.pointAt(c.phi)
+		if !result.IsClose(c.expect, 1e-9) {
+			t.Errorf("円弧上の点(%v) - 期待値：%v, 取得値：%v", c.phi, c.expect, result)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestTorusPhysicsIsCollideVoxel01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 管の半径: 1、円弧の中心: (0,0,0)、始点: (10,0,0)、終点: (0,10,0)
+//   - ボクセルの対角線ベクトル: (0.5,0.5,0.5)
+//   - パターン1：ボクセル中心(7.07,7.07,0)(円弧上)
+//   - パターン2：ボクセル中心(11.2,0,0)(始点から管の半径以内)
+//   - パターン3：ボクセル中心(11.3,0,0)(始点から管の半径より遠い)
+//   - パターン4：ボクセル中心(0,0,0)(円弧の中心)
+//   - パターン5：ボクセル中心(-10,0,0)(円弧の範囲外の円周上)
+//   - パターン6：ボクセル中心(0,10,1.2)(終点の上方、管の半径以内)
+//
+// + 確認内容
+//   - パターン1、2、6は衝突、パターン3、4、5は衝突しないと判定されること
+func TestTorusPhysicsIsCollideVoxel01(t *testing.T) {
+	//入力値
+	torus := NewTorusPhysics(
+		1,
+		spatial.Point3{X: 0, Y: 0, Z: 0},
+		spatial.Point3{X: 10, Y: 0, Z: 0},
+		spatial.Point3{X: 0, Y: 10, Z: 0},
+	)
+	lens := spatial.Vector3{X: 0.5, Y: 0.5, Z: 0.5}
+	inputs := []struct {
+		center spatial.Point3
+		expect bool
+	}{
+		{spatial.Point3{X: 7.07, Y: 7.07, Z: 0}, true},
+		{spatial.Point3{X: 11.2, Y: 0, Z: 0}, true},
+		{spatial.Point3{X: 11.3, Y: 0, Z: 0}, false},
+		{spatial.Point3{X: 0, Y: 0, Z: 0}, false},
+		{spatial.Point3{X: -10, Y: 0, Z: 0}, false},
+		{spatial.Point3{X: 0, Y: 10, Z: 1.2}, true},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		result := torus.IsCollideVoxel(input.center, lens)
+
+		if result != input.expect {
+			t.Errorf("パターン%d 衝突判定 - 期待値：%v, 取得値：%v", i+1, input.expect, result)
+		}
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,330 @@
+package shape
+
+import (
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// GetSpatialIdProbabilitiesOnCylinders 空間ID(存在確率)取得
+//
+// 経路からの横方向、高さ方向の位置の誤差を正規分布とした場合に、
+// 経路を飛行する機体が各空間IDのボクセル内に存在する確率を取得する。
+//
+// 引数：
+//
+//	center     : 経路の接続点。Pointを複数指定するリスト
+//	sigma      : 水平方向、垂直方向の位置の誤差の標準偏差(単位:m)
+//	cutoff     : 存在確率の下限。存在確率がcutoff未満の空間IDは返却しない
+//	zoom       : 精度レベル
+//	isPrecision: GetSpatialIdsOnCylindersと同じオプション。LogHandler、
+//	             TerrainClip、AboveGroundLevel、Altitude、Geoid、Geodesicを使用する
+//
+// 戻り値：
+//
+//	空間IDをキー、存在確率を値とするマップ
+//
+// 戻り値(エラー)：
+//
+//	GetExtendedSpatialIdProbabilitiesOnCylindersと同じ
+func GetSpatialIdProbabilitiesOnCylinders(
+	center []*object.Point,
+	sigma Uncertainty,
+	cutoff float64,
+	zoom int64,
+	isPrecision ...option,
+) (map[string]float64, error) {
+	probabilities, err := GetExtendedSpatialIdProbabilitiesOnCylinders(center, sigma, cutoff, zoom, zoom, isPrecision...)
+	if err != nil {
+		return probabilities, err
+	}
+
+	// 拡張空間IDを空間IDのフォーマットに変換
+	result := make(map[string]float64, len(probabilities))
+	for extendedID, probability := range probabilities {
+		ids, err := shape.ConvertExtendedSpatialIdsToSpatialIds([]string{extendedID})
+		if err != nil {
+			return map[string]float64{}, err
+		}
+		for _, id := range ids {
+			result[id] = math.Max(result[id], probability)
+		}
+	}
+	return result, nil
+}
+
+// GetExtendedSpatialIdProbabilitiesOnCylinders 拡張空間ID(存在確率)取得
+//
+// 経路からの横方向、高さ方向の位置の誤差を正規分布とした場合に、
+// 経路を飛行する機体が各拡張空間IDのボクセル内に存在する確率を取得する。
+// 存在確率は経路の区間ごとにボクセルの範囲で誤差の分布を積分し、最も大きい値とする。
+// 経路の始点、終点より外側のボクセルは、始点、終点を中心とした分布を積分する。
+//
+// 引数：
+//
+//	center     : 経路の接続点。Pointを複数指定するリスト
+//	sigma      : 水平方向、垂直方向の位置の誤差の標準偏差(単位:m)
+//	cutoff     : 存在確率の下限。存在確率がcutoff未満の拡張空間IDは返却しない
+//	hZoom      : 水平方向の精度レベル
+//	vZoom      : 垂直方向の精度レベル
+//	isPrecision: GetExtendedSpatialIdsOnCylindersと同じオプション。LogHandler、
+//	             TerrainClip、AboveGroundLevel、Altitude、Geoid、Geodesicを使用する
+//
+// 戻り値：
+//
+//	拡張空間IDをキー、存在確率を値とするマップ
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 座標の値が不正の場合、標準偏差が0以下の場合、もしくは存在確率の下限が0～1の範囲外の場合
+//	               緯度がWebメルカトル投影の範囲外(±85.0511287798度を超える)の接続点がある場合もエラー
+//	 高さ変換不可： 高さの変換に必要なモデルが未指定の場合、
+//	               もしくは接続点の位置のジオイド高、標高を取得できない場合。
+//	 測地線分割不可： Geodesicに負の間隔を指定した場合、もしくは対蹠点に近い区間がある場合。
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func GetExtendedSpatialIdProbabilitiesOnCylinders(
+	center []*object.Point,
+	sigma Uncertainty,
+	cutoff float64,
+	hZoom int64,
+	vZoom int64,
+	isPrecision ...option,
+) (map[string]float64, error) {
+	p := &IsPrecisionOpts{IsPrecision: true}
+	for _, opt := range isPrecision {
+		opt(p)
+	}
+
+	var log *slog.Logger
+	var begin time.Time
+	if p.LogHandler != nil {
+		log = slog.New(p.LogHandler)
+		begin = time.Now()
+	}
+
+	probabilities := map[string]float64{}
+
+	// 入力値チェック
+	if common.Include(center, nil) || !shape.CheckZoom(hZoom) || !shape.CheckZoom(vZoom) {
+		return probabilities, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	} else if sigma.Horizontal <= consts.Minima || sigma.Vertical <= consts.Minima {
+		debugLog(log, "標準偏差が0以下", slog.Float64("horizontal", sigma.Horizontal), slog.Float64("vertical", sigma.Vertical))
+		return probabilities, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	} else if !(cutoff > 0 && cutoff < 1) {
+		debugLog(log, "存在確率の下限が範囲外", slog.Float64("cutoff", cutoff))
+		return probabilities, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	} else if len(center) == 0 {
+		debugLog(log, "接続点数が0個")
+		return probabilities, nil
+	}
+
+	// 接続点の高さを楕円体高に変換
+	if p.Altitude != AltitudeEllipsoidal {
+		terrain := p.AboveGround
+		if terrain == nil {
+			terrain = p.Terrain
+		}
+		converted, err := ConvertAltitudePoints(center, p.Altitude, p.Geoid, terrain)
+		if err != nil {
+			debugLog(log, "接続点の高さを変換できません", slog.Int("altitude", int(p.Altitude)))
+			return probabilities, err
+		}
+		center = converted
+	}
+
+	// 接続点間を測地線に沿って分割
+	if p.GeodesicInterval != 0 {
+		densified, err := DensifyGeodesic(center, p.GeodesicInterval)
+		if err != nil {
+			debugLog(log, "接続点間を測地線に沿って分割できません", slog.Float64("interval", p.GeodesicInterval))
+			return probabilities, err
+		}
+		center = densified
+	}
+
+	if err := checkMercatorLatitude(center); err != nil {
+		debugLog(log, "緯度がWebメルカトル投影の範囲外")
+		return probabilities, err
+	}
+
+	// 存在確率がcutoff以上となりうる範囲(標準偏差の倍数)
+	// ボクセルの存在確率は最も近い面より外側となる確率以下のため、片側の裾の確率がcutoffとなる距離とする
+	k := math.Max(math.Sqrt2*math.Erfinv(1-2*cutoff), 1)
+	candidateRadius := math.Max(sigma.Horizontal, sigma.Vertical) * k
+
+	// 候補の拡張空間IDは外接する直方体の範囲とする
+	candidateOpts := []option{IsPrecision(false)}
+	if p.Terrain != nil {
+		candidateOpts = append(candidateOpts, TerrainClip(p.Terrain), Geoid(p.Geoid))
+	}
+	candidates, err := GetExtendedSpatialIdsOnCylinders(center, candidateRadius, hZoom, vZoom, true, candidateOpts...)
+	if err != nil {
+		return probabilities, err
+	}
+	debugLog(log, "存在確率の候補", slog.Float64("radius", candidateRadius), slog.Int("candidateIDs", len(candidates)))
+
+	// 経度180度に近い経路は経度を180度ずらした座標系で計算する
+	points := center
+	shifted := isAntimeridianFrame(center...)
+	if shifted {
+		points, err = shiftLongitudes(center...)
+		if err != nil {
+			return probabilities, err
+		}
+	}
+
+	// メルカトル距離補正
+	factor := 1 / math.Cos(common.DegreeToRadian(center[0].Lat()))
+
+	// 【直交座標空間】接続点
+	crsPoints, _ := shape.ConvertPointListToProjectedPointList(points, consts.OrthCrs)
+	orthPoints := make([]spatial.Point3, 0, len(crsPoints))
+	for _, crsPoint := range crsPoints {
+		orthPoints = append(orthPoints, spatial.Point3{X: crsPoint.X, Y: crsPoint.Y, Z: crsPoint.Alt * factor})
+	}
+
+	rect := NewRectangular(orthPoints[0], orthPoints[0], 0, hZoom, vZoom, factor)
+	sigmaH, sigmaV := sigma.Horizontal*factor, sigma.Vertical*factor
+	size := int64(1) << hZoom
+	latDict := make(map[int64]spatial.Vector3)
+	for _, candidate := range candidates {
+		ids := GetVoxelIDToSpatialID(candidate)
+		frameID := candidate
+		if shifted {
+			frameID = GetSpatialIDOnAxisIDs((ids[0]+size/2)%size, ids[1], ids[2], hZoom, vZoom)
+		}
+
+		// 【直交座標空間】ボクセルの対角線のベクトル
+		lens, ok := latDict[ids[1]]
+		if !ok {
+			lens, _ = rect.calcUnitVoxelVector(frameID)
+			latDict[ids[1]] = lens
+		}
+		// 【直交座標空間】ボクセルの中心座標
+		centers, _ := shape.GetPointOnExtendedSpatialId(frameID, enum.Center)
+		orthCenters, _ := shape.ConvertPointListToProjectedPointList(centers, consts.OrthCrs)
+		voxel := spatial.Point3{X: orthCenters[0].X, Y: orthCenters[0].Y, Z: orthCenters[0].Alt * factor}
+
+		probability := 0.0
+		if len(orthPoints) == 1 {
+			probability = pointProbability(orthPoints[0], voxel, lens, sigmaH, sigmaV)
+		}
+		for i := range orthPoints[:len(orthPoints)-1] {
+			probability = math.Max(
+				probability,
+				segmentProbability(orthPoints[i], orthPoints[i+1], voxel, lens, sigmaH, sigmaV),
+			)
+		}
+
+		if probability >= cutoff {
+			probabilities[candidate] = probability
+		}
+	}
+
+	if log != nil {
+		debugLog(
+			log,
+			"拡張空間ID(存在確率)取得",
+			slog.Int("acceptedIDs", len(probabilities)),
+			slog.Duration("elapsed", time.Since(begin)),
+		)
+	}
+
+	return probabilities, nil
+}
+
+// normalInterval 正規分布の区間の確率
+//
+// 引数：
+//
+//	low： 区間の下端(分布の中心からの距離)
+//	high： 区間の上端(分布の中心からの距離)
+//	sigma： 標準偏差
+//
+// 戻り値：
+//
+//	平均0、標準偏差sigmaの正規分布に従う値がlow～highとなる確率
+func normalInterval(low, high, sigma float64) float64 {
+	return (math.Erf(high/(sigma*math.Sqrt2)) - math.Erf(low/(sigma*math.Sqrt2))) / 2
+}
+
+// pointProbability 点を中心とした分布のボクセルの存在確率
+//
+// 水平方向は等方的な2次元正規分布とし、X、Y、Z成分ごとに積分する。
+//
+// 引数：
+//
+//	point： 【直交座標空間】分布の中心
+//	voxel： 【直交座標空間】ボクセルの中心
+//	lens： 【直交座標空間】ボクセルの対角線ベクトル
+//	sigmaH： 【直交座標空間】水平方向の標準偏差
+//	sigmaV： 【直交座標空間】垂直方向の標準偏差
+//
+// 戻り値：
+//
+//	ボクセルの存在確率
+func pointProbability(point, voxel spatial.Point3, lens spatial.Vector3, sigmaH, sigmaV float64) float64 {
+	dx, dy, dz := voxel.X-point.X, voxel.Y-point.Y, voxel.Z-point.Z
+	return normalInterval(dx-lens.X/2, dx+lens.X/2, sigmaH) *
+		normalInterval(dy-lens.Y/2, dy+lens.Y/2, sigmaH) *
+		normalInterval(dz-lens.Z/2, dz+lens.Z/2, sigmaV)
+}
+
+// segmentProbability 区間を飛行する場合のボクセルの存在確率
+//
+// ボクセルが区間の進行方向の範囲内にある場合は、区間に直交する水平方向と高さ方向の誤差を積分する。
+// 範囲外の場合は近い方の端点を中心とした分布を積分する。
+//
+// 引数：
+//
+//	start： 【直交座標空間】区間の始点
+//	end： 【直交座標空間】区間の終点
+//	voxel： 【直交座標空間】ボクセルの中心
+//	lens： 【直交座標空間】ボクセルの対角線ベクトル
+//	sigmaH： 【直交座標空間】水平方向の標準偏差
+//	sigmaV： 【直交座標空間】垂直方向の標準偏差
+//
+// 戻り値：
+//
+//	ボクセルの存在確率
+func segmentProbability(start, end, voxel spatial.Point3, lens spatial.Vector3, sigmaH, sigmaV float64) float64 {
+	// 水平方向の進行方向の単位ベクトル
+	length := math.Hypot(end.X-start.X, end.Y-start.Y)
+	if length <= consts.Minima {
+		// 垂直な区間は高さ方向の範囲内で端点と同じ水平方向の分布とする
+		low, high := math.Min(start.Z, end.Z), math.Max(start.Z, end.Z)
+		z := math.Min(math.Max(voxel.Z, low), high)
+		return pointProbability(spatial.Point3{X: start.X, Y: start.Y, Z: z}, voxel, lens, sigmaH, sigmaV)
+	}
+	ux, uy := (end.X-start.X)/length, (end.Y-start.Y)/length
+
+	// 進行方向のボクセルの範囲
+	along := (voxel.X-start.X)*ux + (voxel.Y-start.Y)*uy
+	alongHalf := (math.Abs(ux)*lens.X + math.Abs(uy)*lens.Y) / 2
+	if along+alongHalf < 0 {
+		return pointProbability(start, voxel, lens, sigmaH, sigmaV)
+	} else if along-alongHalf > length {
+		return pointProbability(end, voxel, lens, sigmaH, sigmaV)
+	}
+
+	// 区間上の最も近い点の高さ
+	t := math.Min(math.Max(along/length, 0), 1)
+	z := start.Z + (end.Z-start.Z)*t
+
+	// 横方向のボクセルの範囲(進行方向に直交する水平方向)
+	cross := -(voxel.X-start.X)*uy + (voxel.Y-start.Y)*ux
+	crossHalf := (math.Abs(uy)*lens.X + math.Abs(ux)*lens.Y) / 2
+	dz := voxel.Z - z
+
+	return normalInterval(cross-crossHalf, cross+crossHalf, sigmaH) *
+		normalInterval(dz-lens.Z/2, dz+lens.Z/2, sigmaV)
+}
@@ -0,0 +1,154 @@
+package shape
+
+import (
+	"math"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// TestGetExtendedSpatialIdProbabilitiesOnCylinders01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0), (139.76, 35.68, 100.0)(東向き約900m)
+//   - 標準偏差：水平方向10m、垂直方向5m、存在確率の下限：1e-6、水平精度：21、垂直精度：23
+//
+// + 確認内容
+//   - 全ての存在確率が下限以上、1以下であること
+//   - 区間の中点の空間IDの存在確率が、中点の北40mの空間IDの存在確率より大きいこと
+//   - 区間の中点を通る経度方向の断面の存在確率の合計が約1であること
+//   - 区間の中点の北100mの空間IDが含まれないこと
+func TestGetExtendedSpatialIdProbabilitiesOnCylinders01(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 100.0)
+	end, _ := object.NewPoint(139.76, 35.68, 100.0)
+	sigma := Uncertainty{Horizontal: 10, Vertical: 5}
+
+	// 期待値
+	midLon := (start.Lon() + end.Lon()) / 2
+	midID := idAtDistanceZoom(midLon, 35.68, 100.0, 0, 0, 21, 23)
+	northID := idAtDistanceZoom(midLon, 35.68, 100.0, 0, 40, 21, 23)
+	farID := idAtDistanceZoom(midLon, 35.68, 100.0, 0, 100, 21, 23)
+
+	// テスト対象呼び出し
+	resultVal, err := GetExtendedSpatialIdProbabilitiesOnCylinders(
+		[]*object.Point{start, end}, sigma, 1e-6, 21, 23,
+	)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	for id, probability := range resultVal {
+		if probability < 1e-6 || probability > 1 {
+			t.Errorf("空間ID%v - 期待値：1e-6～1, 取得値：%v", id, probability)
+		}
+	}
+	if resultVal[midID] <= resultVal[northID] {
+		t.Errorf("中点 - 期待値：%v(北40m)より大きい, 取得値：%v", resultVal[northID], resultVal[midID])
+	}
+	midX := GetVoxelIDToSpatialID(midID)[0]
+	total := 0.0
+	for id, probability := range resultVal {
+		if GetVoxelIDToSpatialID(id)[0] == midX {
+			total += probability
+		}
+	}
+	if math.Abs(total-1) > 1e-3 {
+		t.Errorf("断面の存在確率の合計 - 期待値：約1, 取得値：%v", total)
+	}
+	if _, ok := resultVal[farID]; ok {
+		t.Errorf("北100m - 期待値：%v を含まない, 取得値：%v", farID, resultVal)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestGetExtendedSpatialIdProbabilitiesOnCylinders02 正常系動作確認(接続点1点)
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0)
+//   - 標準偏差：水平方向10m、垂直方向5m、存在確率の下限：1e-7、水平精度：21、垂直精度：23
+//
+// + 確認内容
+//   - 存在確率の合計が約1であること
+//   - 接続点の空間IDの存在確率が最も大きいこと(誤差1e-12以内)
+func TestGetExtendedSpatialIdProbabilitiesOnCylinders02(t *testing.T) {
+	//入力パラメータ
+	point, _ := object.NewPoint(139.75, 35.68, 100.0)
+
+	// 期待値
+	pointID := idAtDistanceZoom(point.Lon(), point.Lat(), point.Alt(), 0, 0, 21, 23)
+
+	// テスト対象呼び出し
+	resultVal, err := GetExtendedSpatialIdProbabilitiesOnCylinders(
+		[]*object.Point{point}, Uncertainty{Horizontal: 10, Vertical: 5}, 1e-7, 21, 23,
+	)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	total := 0.0
+	for id, probability := range resultVal {
+		total += probability
+		if probability > resultVal[pointID]+1e-12 {
+			t.Errorf("空間ID%v - 期待値：%v以下, 取得値：%v", id, resultVal[pointID], probability)
+		}
+	}
+	if math.Abs(total-1) > 1e-3 {
+		t.Errorf("存在確率の合計 - 期待値：約1, 取得値：%v", total)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestGetExtendedSpatialIdProbabilitiesOnCylinders03 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：水平方向の標準偏差が0
+//   - パターン2：垂直方向の標準偏差が負
+//   - パターン3：存在確率の下限が0
+//   - パターン4：存在確率の下限が1
+//   - パターン5：接続点にnilを含む
+//   - パターン6：精度が範囲外
+//
+// + 確認内容
+//   - 入力チェックエラーとなること
+func TestGetExtendedSpatialIdProbabilitiesOnCylinders03(t *testing.T) {
+	//入力パラメータ
+	point, _ := object.NewPoint(139.75, 35.68, 100.0)
+	center := []*object.Point{point}
+	calls := []func() (map[string]float64, error){
+		func() (map[string]float64, error) {
+			return GetExtendedSpatialIdProbabilitiesOnCylinders(center, Uncertainty{0, 5}, 0.01, 21, 23)
+		},
+		func() (map[string]float64, error) {
+			return GetExtendedSpatialIdProbabilitiesOnCylinders(center, Uncertainty{10, -1}, 0.01, 21, 23)
+		},
+		func() (map[string]float64, error) {
+			return GetExtendedSpatialIdProbabilitiesOnCylinders(center, Uncertainty{10, 5}, 0, 21, 23)
+		},
+		func() (map[string]float64, error) {
+			return GetExtendedSpatialIdProbabilitiesOnCylinders(center, Uncertainty{10, 5}, 1, 21, 23)
+		},
+		func() (map[string]float64, error) {
+			return GetExtendedSpatialIdProbabilitiesOnCylinders([]*object.Point{nil}, Uncertainty{10, 5}, 0.01, 21, 23)
+		},
+		func() (map[string]float64, error) {
+			return GetSpatialIdProbabilitiesOnCylinders(center, Uncertainty{10, 5}, 0.01, 36)
+		},
+	}
+
+	for i, call := range calls {
+		// テスト対象呼び出し
+		resultVal, err := call()
+
+		if err == nil || len(resultVal) != 0 {
+			t.Errorf("パターン%d - 期待値：入力チェックエラー, 取得値：%v, %v", i+1, resultVal, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
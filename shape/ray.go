@@ -0,0 +1,304 @@
+package shape
+
+import (
+	"math"
+	"sort"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// RayVoxel 線分が通過する拡張空間ID構造体
+type RayVoxel struct {
+	SpatialID string  // 拡張空間ID
+	Entry     float64 // 始点から線分がボクセルに入る位置までの距離(単位:m)
+	Exit      float64 // 始点から線分がボクセルを出る位置までの距離(単位:m)
+}
+
+// rayCell 格子の座標系で線分が通過するセル
+type rayCell struct {
+	index [3]int64 // セルの軸ID
+	entry float64  // 線分がセルに入る位置(始点を0、終点を1とする)
+	exit  float64  // 線分がセルを出る位置(始点を0、終点を1とする)
+}
+
+// maxRayVoxels 線分が通過する拡張空間IDの取得で許容する、通過するボクセルの数の最大値
+const maxRayVoxels = 1 << 20
+
+// TraverseExtendedSpatialIds 線分が通過する拡張空間IDの取得
+//
+// 始点から終点までの線分が通過する拡張空間IDを、3次元DDA(Amanatides-Woo法)により
+// 始点から近い順に取得する。線分はGetExtendedSpatialIdsOnLineと同じく
+// Webメルカトル投影の平面上の直線とし、ボクセルの辺、頂点のみを通過する場合は含めない。
+// 経度180度をまたがる線分は経度180度を越える短い方の線分とする。
+// 緯度方向、高さ方向の範囲外のボクセルは含めない。
+//
+// 引数：
+//
+//	start： 始点
+//	end： 終点
+//	hZoom： 水平方向の精度レベル
+//	vZoom： 垂直方向の精度レベル
+//
+// 戻り値：
+//
+//	線分が通過する拡張空間IDと、始点からボクセルに入る位置、出る位置までの距離のリスト
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 始点、終点がnilの場合、緯度がWebメルカトル投影の範囲外の場合、
+//	               もしくは通過するボクセルの数がmaxRayVoxelsを超える場合
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func TraverseExtendedSpatialIds(start, end *object.Point, hZoom, vZoom int64) ([]RayVoxel, error) {
+	voxels := []RayVoxel{}
+	err := traverseExtendedSpatialIds(start, end, hZoom, vZoom, func(voxel RayVoxel) bool {
+		voxels = append(voxels, voxel)
+		return true
+	})
+	if err != nil {
+		return []RayVoxel{}, err
+	}
+	return voxels, nil
+}
+
+// traverseExtendedSpatialIds 線分が通過する拡張空間IDの走査
+//
+// 線分が通過する拡張空間IDを始点から近い順に取得し、取得するごとに関数を呼び出す。
+//
+// 引数：
+//
+//	start： 始点
+//	end： 終点
+//	hZoom： 水平方向の精度レベル
+//	vZoom： 垂直方向の精度レベル
+//	visit： 拡張空間IDごとに呼び出す関数。falseを返却した場合は走査を終了する
+//
+// 戻り値(エラー)：
+//
+//	TraverseExtendedSpatialIdsと同じ
+func traverseExtendedSpatialIds(start, end *object.Point, hZoom, vZoom int64, visit func(RayVoxel) bool) error {
+	if start == nil || end == nil || !shape.CheckZoom(hZoom) || !shape.CheckZoom(vZoom) {
+		return errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	points := []*object.Point{start, end}
+	if err := checkMercatorLatitude(points); err != nil {
+		return err
+	}
+
+	// 経度180度をまたがる場合は経度を180度ずらした座標系で取得する
+	shifted := isAntimeridianFrame(points...)
+	if shifted {
+		shiftedPoints, err := shiftLongitudes(points...)
+		if err != nil {
+			return err
+		}
+		points = shiftedPoints
+	}
+
+	// 始点の拡張空間IDの頂点から格子の原点、ボクセルの大きさを算出
+	ids, err := shape.GetExtendedSpatialIdsOnPoints(points[:1], hZoom, vZoom)
+	if err != nil {
+		return err
+	}
+	origin := GetVoxelIDToSpatialID(ids[0])
+	vertexes, _ := shape.GetPointOnExtendedSpatialId(ids[0], enum.Vertex)
+	orthVertexes, _ := shape.ConvertPointListToProjectedPointList(vertexes, consts.OrthCrs)
+	minX, minZ := math.Inf(1), math.Inf(1)
+	maxX, maxY, maxZ := math.Inf(-1), math.Inf(-1), math.Inf(-1)
+	minY := math.Inf(1)
+	for _, v := range orthVertexes {
+		minX, maxX = math.Min(minX, v.X), math.Max(maxX, v.X)
+		minY, maxY = math.Min(minY, v.Y), math.Max(maxY, v.Y)
+		minZ, maxZ = math.Min(minZ, v.Alt), math.Max(maxZ, v.Alt)
+	}
+
+	// 【格子の座標系】始点、終点(Y成分は南向きを正とする)
+	orthPoints, _ := shape.ConvertPointListToProjectedPointList(points, consts.OrthCrs)
+	var a, b [3]float64
+	for i, p := range orthPoints {
+		c := [3]float64{
+			float64(origin[0]) + (p.X-minX)/(maxX-minX),
+			float64(origin[1]) + (maxY-p.Y)/(maxY-minY),
+			float64(origin[2]) + (p.Alt-minZ)/(maxZ-minZ),
+		}
+		if i == 0 {
+			a = c
+		} else {
+			b = c
+		}
+	}
+
+	// 線分の長さ(水平方向はWebメルカトル換算係数で補正する)
+	factor := 1 / math.Cos(common.DegreeToRadian(points[0].Lat()))
+	horizontal := math.Hypot(orthPoints[1].X-orthPoints[0].X, orthPoints[1].Y-orthPoints[0].Y) / factor
+	length := math.Hypot(horizontal, orthPoints[1].Alt-orthPoints[0].Alt)
+
+	// 通過するセルの数は各軸で越える境界の数の和に1を加えた値以下となる
+	count := 1.0
+	for i := range a {
+		count += math.Abs(math.Floor(b[i]) - math.Floor(a[i]))
+	}
+	if count > maxRayVoxels {
+		return errors.NewSpatialIdError(errors.InputValueErrorCode, "線分が通過するボクセルの数が多すぎます")
+	}
+
+	size, vSize := int64(1)<<hZoom, int64(1)<<vZoom
+	offset := int64(0)
+	if shifted {
+		offset = size / 2
+	}
+	traverseGrid(a, b, func(cell rayCell) bool {
+		if cell.index[1] < 0 || cell.index[1] >= size || cell.index[2] < -vSize || cell.index[2] >= vSize {
+			return true
+		}
+		x := ((cell.index[0]+offset)%size + size) % size
+		return visit(RayVoxel{
+			SpatialID: GetSpatialIDOnAxisIDs(x, cell.index[1], cell.index[2], hZoom, vZoom),
+			Entry:     cell.entry * length,
+			Exit:      cell.exit * length,
+		})
+	})
+
+	return nil
+}
+
+// traverseGrid 3次元DDAによる線分が通過するセルの取得
+//
+// 大きさ1のセルの格子で、始点から終点までの線分が通過するセルを始点から近い順に取得する。
+// 線分がセルの辺、頂点のみを通過する場合(通過する長さが0の場合)は含めない。
+//
+// 引数：
+//
+//	a： 【格子の座標系】始点
+//	b： 【格子の座標系】終点
+//	visit： 線分が通過するセルごとに呼び出す関数。falseを返却した場合は走査を終了する
+func traverseGrid(a, b [3]float64, visit func(rayCell) bool) {
+	var cell [3]int64
+	var step [3]int64
+	var tMax, tDelta [3]float64
+	for i := range a {
+		cell[i] = int64(math.Floor(a[i]))
+		d := b[i] - a[i]
+		switch {
+		case d > 0:
+			step[i] = 1
+			tMax[i] = (float64(cell[i]) + 1 - a[i]) / d
+			tDelta[i] = 1 / d
+		case d < 0:
+			step[i] = -1
+			tMax[i] = (a[i] - float64(cell[i])) / -d
+			tDelta[i] = -1 / d
+		default:
+			tMax[i] = math.Inf(1)
+			tDelta[i] = math.Inf(1)
+		}
+	}
+
+	visited := false
+	t := 0.0
+	for {
+		// 次にセルの境界を越える軸
+		axis := 0
+		for i := 1; i < 3; i++ {
+			if tMax[i] < tMax[axis] {
+				axis = i
+			}
+		}
+		next := tMax[axis]
+		exit := math.Min(next, 1)
+		if exit > t || !visited && next >= 1 {
+			visited = true
+			if !visit(rayCell{index: cell, entry: t, exit: exit}) {
+				return
+			}
+		}
+		if next >= 1 {
+			return
+		}
+		cell[axis] += step[axis]
+		t = next
+		tMax[axis] += tDelta[axis]
+	}
+}
+
+// IsLineOfSight 見通しの判定
+//
+// 始点から終点までの線分が障害物の拡張空間IDを通過しない場合に見通しがあると判定する。
+// 地上局とドローン間の通信の見通し等の確認に使用する。
+// 障害物の拡張空間IDの精度レベルは混在してもよい。
+//
+// 引数：
+//
+//	start： 始点
+//	end： 終点
+//	blocked： 障害物の拡張空間ID
+//
+// 戻り値：
+//
+//	見通しがある場合true、
+//	見通しが無い場合は始点から最も近い障害物の拡張空間IDと、ボクセルに入る位置、出る位置までの距離
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 始点、終点がnilの場合、緯度がWebメルカトル投影の範囲外の場合、
+//	               障害物の拡張空間IDが不正な場合、
+//	               もしくは障害物の精度で通過するボクセルの数がmaxRayVoxelsを超える場合
+func IsLineOfSight(start, end *object.Point, blocked []string) (bool, *RayVoxel, error) {
+	if start == nil || end == nil {
+		return false, nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+
+	// 精度レベルごとに障害物の拡張空間IDを分類
+	blockedSet := make(map[string]bool, len(blocked))
+	zooms := [][2]int64{}
+	for _, spatialID := range blocked {
+		id, err := ParseExtendedSpatialID(spatialID)
+		if err != nil {
+			return false, nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "障害物の空間IDが不正です")
+		}
+		if !containsZooms(zooms, id.HZoom(), id.VZoom()) {
+			zooms = append(zooms, [2]int64{id.HZoom(), id.VZoom()})
+		}
+		blockedSet[id.String()] = true
+	}
+	sort.Slice(zooms, func(i, j int) bool {
+		return zooms[i][0] < zooms[j][0] || zooms[i][0] == zooms[j][0] && zooms[i][1] < zooms[j][1]
+	})
+
+	// 精度レベルごとに線分が通過する拡張空間IDを確認し、始点から最も近い障害物を返却
+	// (見つかった障害物より遠いボクセル、最初の障害物より後のボクセルは走査しない)
+	var first *RayVoxel
+	for _, zoom := range zooms {
+		err := traverseExtendedSpatialIds(start, end, zoom[0], zoom[1], func(voxel RayVoxel) bool {
+			if first != nil && voxel.Entry >= first.Entry {
+				return false
+			}
+			if blockedSet[voxel.SpatialID] {
+				first = &voxel
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return false, nil, err
+		}
+	}
+
+	return first == nil, first, nil
+}
+
+// containsZooms 精度レベルの組の確認
+func containsZooms(zooms [][2]int64, hZoom, vZoom int64) bool {
+	for _, zoom := range zooms {
+		if zoom[0] == hZoom && zoom[1] == vZoom {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,291 @@
+package shape
+
+import (
+	"math"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// TestTraverseExtendedSpatialIds01 正常系動作確認(東西方向の線分)
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点：(139.75, 35.68, 100.0)、終点：(139.751, 35.68, 100.0)、精度：20
+//
+// + 確認内容
+//   - 始点、終点の空間IDが先頭、末尾であること
+//   - 空間IDのXが1ずつ増加し、Y、Zが変化しないこと
+//   - 先頭の入る位置が0、末尾の出る位置が線分の長さ(約90.4m)であり、入る位置と前の出る位置が一致すること
+func TestTraverseExtendedSpatialIds01(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 100.0)
+	end, _ := object.NewPoint(139.751, 35.68, 100.0)
+
+	// テスト対象呼び出し
+	resultVal, err := TraverseExtendedSpatialIds(start, end, 20, 20)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	ends, _ := shape.GetExtendedSpatialIdsOnPoints([]*object.Point{start, end}, 20, 20)
+	if len(resultVal) < 2 || resultVal[0].SpatialID != ends[0] || resultVal[len(resultVal)-1].SpatialID != ends[1] {
+		t.Fatalf("始点、終点の空間ID - 期待値：%v, 取得値：%v", ends, resultVal)
+	}
+	for i := 1; i < len(resultVal); i++ {
+		prev := GetVoxelIDToSpatialID(resultVal[i-1].SpatialID)
+		cur := GetVoxelIDToSpatialID(resultVal[i].SpatialID)
+		if cur[0] != prev[0]+1 || cur[1] != prev[1] || cur[2] != prev[2] {
+			t.Errorf("隣接しない空間ID - 前：%v, 取得値：%v", resultVal[i-1].SpatialID, resultVal[i].SpatialID)
+		}
+		if math.Abs(resultVal[i].Entry-resultVal[i-1].Exit) > 1e-9 {
+			t.Errorf("入る位置 - 期待値：%v, 取得値：%v", resultVal[i-1].Exit, resultVal[i].Entry)
+		}
+	}
+
+	length, _, _ := geodesicInverse(139.75, 35.68, 139.751, 35.68)
+	if resultVal[0].Entry != 0 || math.Abs(resultVal[len(resultVal)-1].Exit-length) > 0.5 {
+		t.Errorf("線分の長さ - 期待値：%v, 取得値：%v～%v", length, resultVal[0].Entry, resultVal[len(resultVal)-1].Exit)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestTraverseExtendedSpatialIds02 正常系動作確認(3次元の斜めの線分)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：始点：(139.75, 35.68, 100.0)、終点：(139.7507, 35.6805, 150.0)、精度：20
+//   - パターン2：始点、終点を入れ替えたもの
+//
+// + 確認内容
+//   - 始点、終点の空間IDが先頭、末尾であること
+//   - 各空間IDが前の空間IDと面で隣接すること(1軸のみ1変化すること)
+//   - 出る位置が入る位置より大きく、入る位置が増加すること
+//   - パターン1、2で空間IDの数が一致すること
+func TestTraverseExtendedSpatialIds02(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.75, 35.68, 100.0)
+	p2, _ := object.NewPoint(139.7507, 35.6805, 150.0)
+	counts := []int{}
+
+	for i, points := range [][2]*object.Point{{p1, p2}, {p2, p1}} {
+		// テスト対象呼び出し
+		resultVal, err := TraverseExtendedSpatialIds(points[0], points[1], 20, 20)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		ends, _ := shape.GetExtendedSpatialIdsOnPoints(points[:], 20, 20)
+		if len(resultVal) < 2 || resultVal[0].SpatialID != ends[0] || resultVal[len(resultVal)-1].SpatialID != ends[1] {
+			t.Fatalf("パターン%d 始点、終点の空間ID - 期待値：%v, 取得値：%v", i+1, ends, resultVal)
+		}
+		for j := 1; j < len(resultVal); j++ {
+			prev := GetVoxelIDToSpatialID(resultVal[j-1].SpatialID)
+			cur := GetVoxelIDToSpatialID(resultVal[j].SpatialID)
+			diff := int64(0)
+			for k := range cur {
+				d := cur[k] - prev[k]
+				if d < 0 {
+					d = -d
+				}
+				diff += d
+			}
+			if diff != 1 {
+				t.Errorf("パターン%d 隣接しない空間ID - 前：%v, 取得値：%v", i+1, resultVal[j-1].SpatialID, resultVal[j].SpatialID)
+			}
+			if resultVal[j].Exit <= resultVal[j].Entry || resultVal[j].Entry < resultVal[j-1].Entry {
+				t.Errorf("パターン%d 入る位置、出る位置 - 取得値：%v", i+1, resultVal[j])
+			}
+		}
+		counts = append(counts, len(resultVal))
+	}
+
+	if counts[0] != counts[1] {
+		t.Errorf("空間IDの数 - パターン1：%v, パターン2：%v", counts[0], counts[1])
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestTraverseExtendedSpatialIds03 正常系動作確認(経度180度をまたがる線分)
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点：(179.9999, 0.0, 10.0)、終点：(-179.9999, 0.0, 10.0)、精度：16
+//
+// + 確認内容
+//   - 始点、終点の空間IDが先頭、末尾であり、空間IDの数が5個以下であること
+func TestTraverseExtendedSpatialIds03(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(179.9999, 0.0, 10.0)
+	end, _ := object.NewPoint(-179.9999, 0.0, 10.0)
+
+	// テスト対象呼び出し
+	resultVal, err := TraverseExtendedSpatialIds(start, end, 16, 16)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	ends, _ := shape.GetExtendedSpatialIdsOnPoints([]*object.Point{start, end}, 16, 16)
+	if len(resultVal) < 2 || len(resultVal) > 5 ||
+		resultVal[0].SpatialID != ends[0] || resultVal[len(resultVal)-1].SpatialID != ends[1] {
+		t.Errorf("期待値：%v～%v, 取得値：%v", ends[0], ends[1], resultVal)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestTraverseExtendedSpatialIds04 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：始点がnil
+//   - パターン2：水平精度が36
+//   - パターン3：終点の緯度が86度
+//   - パターン4：水平精度35で経度1度の線分(通過するボクセルの数がmaxRayVoxelsを超える)
+//
+// + 確認内容
+//   - エラーが返却されること
+func TestTraverseExtendedSpatialIds04(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 100.0)
+	end, _ := object.NewPoint(139.751, 35.68, 100.0)
+	polar, _ := object.NewPoint(139.751, 86.0, 100.0)
+	far, _ := object.NewPoint(140.75, 35.68, 100.0)
+	inputs := []struct {
+		start *object.Point
+		end   *object.Point
+		hZoom int64
+	}{
+		{nil, end, 20},
+		{start, end, 36},
+		{start, polar, 20},
+		{start, far, 35},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, err := TraverseExtendedSpatialIds(input.start, input.end, input.hZoom, 20)
+		if err == nil {
+			t.Errorf("パターン%d error - 期待値：エラー, 取得値：nil", i+1)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestTraverseExtendedSpatialIds05 正常系動作確認(高さ方向の範囲外)
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点：(139.75, 35.68, 100.0)、終点：(139.75, 35.68, 40000000.0)、水平精度：10、垂直精度：0
+//
+// + 確認内容
+//   - 高さ成分が範囲外(1)の拡張空間IDが含まれず、始点の拡張空間IDのみが返却されること
+func TestTraverseExtendedSpatialIds05(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 100.0)
+	end, _ := object.NewPoint(139.75, 35.68, 40000000.0)
+
+	// テスト対象呼び出し
+	resultVal, err := TraverseExtendedSpatialIds(start, end, 10, 0)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	ids, _ := shape.GetExtendedSpatialIdsOnPoints([]*object.Point{start}, 10, 0)
+	if len(resultVal) != 1 || resultVal[0].SpatialID != ids[0] {
+		t.Errorf("期待値：%v, 取得値：%v", ids, resultVal)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestIsLineOfSight01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点：(139.75, 35.68, 100.0)、終点：(139.751, 35.68, 100.0)
+//   - パターン1：線分から50m離れた精度20の障害物
+//   - パターン2：始点から60m、30mの地点の精度20の障害物
+//   - パターン3：始点から60mの地点の精度20の障害物と、始点から45mの地点を含む精度18の障害物
+//
+// + 確認内容
+//   - パターン1：見通しがあること
+//   - パターン2：見通しが無く、始点から30mの地点の障害物が返却されること
+//   - パターン3：見通しが無く、精度18の障害物が返却されること
+func TestIsLineOfSight01(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 100.0)
+	end, _ := object.NewPoint(139.751, 35.68, 100.0)
+	farID := idAtDistance(139.7505, 35.68, 100.0, 0, 50, 20)
+	nearID := idAtDistance(139.75, 35.68, 100.0, 90, 30, 20)
+	middleID := idAtDistance(139.75, 35.68, 100.0, 90, 60, 20)
+	coarseID := idAtDistanceZoom(139.75, 35.68, 100.0, 90, 45, 18, 18)
+	inputs := []struct {
+		blocked []string
+		expect  string
+	}{
+		{[]string{farID}, ""},
+		{[]string{middleID, nearID}, nearID},
+		{[]string{middleID, coarseID}, coarseID},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		visible, obstruction, err := IsLineOfSight(start, end, input.blocked)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		if input.expect == "" {
+			if !visible || obstruction != nil {
+				t.Errorf("パターン%d - 期待値：見通しあり, 取得値：%v", i+1, obstruction)
+			}
+			continue
+		}
+		if visible || obstruction == nil || obstruction.SpatialID != input.expect {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+1, input.expect, obstruction)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestIsLineOfSight02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：始点がnil
+//   - パターン2：障害物の空間IDの形式が不正
+//   - パターン3：障害物の空間IDのX成分が範囲外
+//   - パターン4：障害物の空間IDの精度が64
+//
+// + 確認内容
+//   - エラーが返却されること
+func TestIsLineOfSight02(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 100.0)
+	end, _ := object.NewPoint(139.751, 35.68, 100.0)
+	inputs := []struct {
+		start   *object.Point
+		blocked []string
+	}{
+		{nil, []string{}},
+		{start, []string{"20/1/2"}},
+		{start, []string{"20/-5/0/20/0"}},
+		{start, []string{"64/0/0/20/0"}},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, _, err := IsLineOfSight(input.start, end, input.blocked)
+		if err == nil {
+			t.Errorf("パターン%d error - 期待値：エラー, 取得値：nil", i+1)
+		}
+	}
+
+	t.Log("テスト終了")
+}
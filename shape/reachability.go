@@ -0,0 +1,303 @@
+package shape
+
+import (
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape/physics"
+)
+
+// Kinematics 機体の運動性能構造体
+type Kinematics struct {
+	MaxSpeed    float64 // 水平方向の対気速度の最大値(単位:m/s)
+	ClimbRate   float64 // 上昇率の最大値(単位:m/s)
+	DescentRate float64 // 下降率の最大値(単位:m/s)
+}
+
+// Wind 風速構造体
+type Wind struct {
+	East  float64 // 東向きの風速(単位:m/s)
+	North float64 // 北向きの風速(単位:m/s)
+	Up    float64 // 上向きの風速(単位:m/s)
+}
+
+// Reachability 到達可能範囲の構造体
+//
+// 始点から運動性能の範囲で飛行し、風で流される機体が時間内に到達できる範囲の空間IDを取得する。
+type Reachability struct {
+	*Capsule // カプセル構造体の埋め込み(衝突判定の処理を共用する)
+}
+
+// NewReachability 到達可能範囲の構造体コンストラクタ
+//
+// 引数：
+//
+//	start： 【直交座標空間】始点
+//	kinematics： 機体の運動性能
+//	wind： 風速
+//	horizon： 時間(単位:s)
+//	hZoom： 水平精度
+//	vZoom： 垂直精度
+//	isPrecision： 衝突判定実施オプション
+//	factor： Webメルカトル換算係数
+//
+// 戻り値：
+//
+//	到達可能範囲の構造体ポインタ
+func NewReachability(
+	start spatial.Point3,
+	kinematics Kinematics,
+	wind Wind,
+	horizon float64,
+	hZoom int64,
+	vZoom int64,
+	isPrecision bool,
+	factor float64,
+) *Reachability {
+	// 【直交座標空間】時間内に風で流される移動量
+	drift := spatial.Vector3{
+		X: wind.East * horizon * factor,
+		Y: wind.North * horizon * factor,
+		Z: wind.Up * horizon * factor,
+	}
+	end := start.Translate(drift)
+
+	// 風で流される経路から最も離れる距離を外接する直方体の半径とする
+	radius := math.Max(kinematics.MaxSpeed, math.Max(kinematics.ClimbRate, kinematics.DescentRate)) * horizon
+
+	capsule := new(Capsule)
+	capsule.Rectangular = NewRectangular(start, end, radius, hZoom, vZoom, factor)
+	capsule.isCapsule = true
+	capsule.isSphere = start.IsClose(end, consts.Minima)
+	capsule.isPrecision = isPrecision
+	capsule.object = physics.NewReachabilityPhysics(
+		start,
+		drift,
+		kinematics.MaxSpeed*horizon*factor,
+		kinematics.ClimbRate*horizon*factor,
+		kinematics.DescentRate*horizon*factor,
+	)
+
+	return &Reachability{Capsule: capsule}
+}
+
+// CalcValidSpatialIDs 有効な空間ID取得
+//
+// 風で流される経路の空間IDから到達可能範囲の有効な空間IDを取得
+//
+// 戻り値：
+//
+//	最終的にオブジェクトと衝突すると判定した空間ID
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func (r *Reachability) CalcValidSpatialIDs() ([]string, error) {
+	endSegment := startStage(r.observer, StageSegment, r.segment)
+
+	// 風で流される経路の空間IDを取得
+	endStage := startStage(r.observer, StageLine, r.segment)
+	lineSpatialIDs, _, err := r.calcLineSpatialIDs()
+	endStage(StageResult{Count: len(lineSpatialIDs)})
+	if err != nil {
+		endSegment(StageResult{})
+		return []string{}, err
+	}
+
+	// 外接する直方体の空間IDを全空間IDとして取得
+	endStage = startStage(r.observer, StageCandidate, r.segment)
+	r.calcAllSpatialIDs(lineSpatialIDs, r.calcBaseUnitVoxel())
+	endStage(StageResult{Count: len(r.allSpatialIDs)})
+
+	// 衝突判定実施オプションがfalseの場合は衝突判定をスキップ
+	if !r.isPrecision {
+		endSegment(StageResult{Count: len(r.allSpatialIDs)})
+		return r.allSpatialIDs, nil
+	}
+
+	// 到達可能範囲は始点付近が細いため内部空間IDは取得せず、全空間IDとオブジェクトで衝突判定
+	endStage = startStage(r.observer, StageCollision, r.segment)
+	tests := r.calcCollideSpatialIDs()
+	endStage(StageResult{Count: len(r.includeSpatialIDs), Tests: tests})
+
+	endSegment(StageResult{Count: len(r.includeSpatialIDs)})
+	return r.includeSpatialIDs, nil
+}
+
+// GetSpatialIdsOnReachability 空間ID(到達可能範囲)取得
+//
+// 始点から時間内に到達できる範囲の空間IDを取得する。
+// 不測の事態に備えた緊急時の飛行範囲(コンティンジェンシー空域)を空間IDで表現する際に使用する。
+//
+// 引数：
+//
+//	start      : 始点
+//	kinematics : 機体の運動性能
+//	wind       : 風速
+//	horizon    : 時間(単位:s)
+//	zoom       : 精度レベル
+//	isPrecision: GetExtendedSpatialIdsOnReachabilityと同じオプション
+//
+// 戻り値：
+//
+//	到達可能範囲の空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	GetExtendedSpatialIdsOnReachabilityと同じ
+func GetSpatialIdsOnReachability(
+	start *object.Point,
+	kinematics Kinematics,
+	wind Wind,
+	horizon float64,
+	zoom int64,
+	isPrecision ...option,
+) ([]string, error) {
+	ids, err := GetExtendedSpatialIdsOnReachability(start, kinematics, wind, horizon, zoom, zoom, isPrecision...)
+	if err != nil {
+		return ids, err
+	}
+	return shape.ConvertExtendedSpatialIdsToSpatialIds(ids)
+}
+
+// GetExtendedSpatialIdsOnReachability 拡張空間ID(到達可能範囲)取得
+//
+// 始点から時間内に到達できる範囲の拡張空間IDを取得する。
+// 時刻tに到達できる範囲は、始点から風でt秒間流された位置を中心とし、
+// 水平方向の半径を最大速度×t、上昇量を上昇率×t、下降量を下降率×tとした円柱とし、
+// 時間内の全ての時刻の範囲を合わせた拡張空間IDを取得する。
+//
+// 引数：
+//
+//	start      : 始点
+//	kinematics : 機体の運動性能
+//	wind       : 風速
+//	horizon    : 時間(単位:s)
+//	hZoom      : 水平方向の精度レベル
+//	vZoom      : 垂直方向の精度レベル
+//	isPrecision: GetExtendedSpatialIdsOnCylindersと同じオプション。IsPrecision、LogHandler、Observer、
+//	             TerrainClip、AboveGroundLevel、Altitude、Geoidを使用する
+//
+// 戻り値：
+//
+//	到達可能範囲の拡張空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 始点がnilの場合、最大速度、時間が0以下の場合、もしくは上昇率、下降率が負の場合
+//	               緯度がWebメルカトル投影の範囲外(±85.0511287798度を超える)の場合もエラー
+//	 高さ変換不可： 高さの変換に必要なモデルが未指定の場合、
+//	               もしくは始点の位置のジオイド高、標高を取得できない場合。
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func GetExtendedSpatialIdsOnReachability(
+	start *object.Point,
+	kinematics Kinematics,
+	wind Wind,
+	horizon float64,
+	hZoom int64,
+	vZoom int64,
+	isPrecision ...option,
+) ([]string, error) {
+	p := &IsPrecisionOpts{
+		IsPrecision: true,
+	}
+	for _, opt := range isPrecision {
+		opt(p)
+	}
+
+	var log *slog.Logger
+	var begin time.Time
+	if p.LogHandler != nil {
+		log = slog.New(p.LogHandler)
+		begin = time.Now()
+	}
+
+	spatialIDs := []string{}
+	endRoute := startStage(p.Observer, StageRoute, RouteSegment)
+	defer func() { endRoute(StageResult{Count: len(spatialIDs)}) }()
+
+	// 入力値チェック
+	if start == nil || !shape.CheckZoom(hZoom) || !shape.CheckZoom(vZoom) ||
+		kinematics.MaxSpeed <= consts.Minima || horizon <= consts.Minima ||
+		kinematics.ClimbRate < 0 || kinematics.DescentRate < 0 {
+		return spatialIDs, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	points := []*object.Point{start}
+	if err := checkMercatorLatitude(points); err != nil {
+		return spatialIDs, err
+	}
+
+	// 始点の高さを楕円体高に変換
+	if p.Altitude != AltitudeEllipsoidal {
+		terrain := p.AboveGround
+		if terrain == nil {
+			terrain = p.Terrain
+		}
+		converted, err := ConvertAltitudePoints(points, p.Altitude, p.Geoid, terrain)
+		if err != nil {
+			debugLog(log, "始点の高さを変換できません", slog.Int("altitude", int(p.Altitude)))
+			return spatialIDs, err
+		}
+		points = converted
+	}
+
+	// 経度180度に近い場合は経度を180度ずらした座標系で空間IDを取得する
+	shifted := isAntimeridianFrame(points...)
+	if shifted {
+		shiftedPoints, err := shiftLongitudes(points...)
+		if err != nil {
+			return spatialIDs, err
+		}
+		points = shiftedPoints
+	}
+
+	// メルカトル距離補正
+	factor := 1 / math.Cos(common.DegreeToRadian(points[0].Lat()))
+	debugLog(log, "メルカトル係数", slog.Float64("factor", factor), slog.Bool("shifted", shifted))
+
+	// 【直交座標空間】始点の座標
+	crsPoints, _ := shape.ConvertPointListToProjectedPointList(points, consts.OrthCrs)
+	orthStart := spatial.Point3{X: crsPoints[0].X, Y: crsPoints[0].Y, Z: crsPoints[0].Alt * factor}
+
+	reachability := NewReachability(orthStart, kinematics, wind, horizon, hZoom, vZoom, p.IsPrecision, factor)
+	reachability.logger = log
+	reachability.SetObserver(p.Observer, 0)
+	reachableSpatialIDs, err := reachability.CalcValidSpatialIDs()
+	if err != nil {
+		return spatialIDs, err
+	}
+	spatialIDs = common.Unique(wrapExtendedSpatialIDs(reachableSpatialIDs, hZoom, vZoom, shifted))
+
+	// 地中の空間IDを除外
+	if p.Terrain != nil {
+		aboveIDs, undergroundIDs, err := ClassifyExtendedSpatialIdsByTerrain(
+			spatialIDs, ellipsoidalTerrainModel(p.Terrain, p.Geoid),
+		)
+		if err != nil {
+			debugLog(log, "地形による空間IDの分類ができません")
+			return []string{}, err
+		}
+		debugLog(log, "地中の空間IDを除外", slog.Int("undergroundIDs", len(undergroundIDs)))
+		spatialIDs = aboveIDs
+	}
+
+	if log != nil {
+		debugLog(
+			log,
+			"拡張空間ID(到達可能範囲)取得",
+			slog.Int("acceptedIDs", len(spatialIDs)),
+			slog.Duration("elapsed", time.Since(begin)),
+		)
+	}
+
+	return spatialIDs, nil
+}
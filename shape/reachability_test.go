@@ -0,0 +1,109 @@
+package shape
+
+import (
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// TestReachability01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 始点：(139.75, 35.68, 100.0)、最大速度：10m/s、上昇率：2m/s、下降率：1m/s
+//   - 風速：東向き5m/s、時間：60s、水平精度：18、垂直精度：21、衝突判定あり、なし
+//
+// + 確認内容
+//   - 始点の東700m、西150m、始点の上100m、下50mの地点の空間IDが含まれること
+//   - 衝突判定ありの場合、始点の西600m、始点の上150m、下80mの地点の空間IDが含まれないこと
+func TestReachability01(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 100.0)
+	kinematics := Kinematics{MaxSpeed: 10, ClimbRate: 2, DescentRate: 1}
+	wind := Wind{East: 5}
+
+	// 期待値
+	includeIDs := []string{
+		idAtDistanceZoom(start.Lon(), start.Lat(), 100.0, 90, 700, 18, 21),
+		idAtDistanceZoom(start.Lon(), start.Lat(), 100.0, 270, 150, 18, 21),
+		idAtDistanceZoom(start.Lon(), start.Lat(), 200.0, 0, 0, 18, 21),
+		idAtDistanceZoom(start.Lon(), start.Lat(), 50.0, 0, 0, 18, 21),
+	}
+	excludeIDs := []string{
+		idAtDistanceZoom(start.Lon(), start.Lat(), 100.0, 270, 600, 18, 21),
+		idAtDistanceZoom(start.Lon(), start.Lat(), 250.0, 0, 0, 18, 21),
+		idAtDistanceZoom(start.Lon(), start.Lat(), 20.0, 0, 0, 18, 21),
+	}
+
+	for _, isPrecision := range []bool{true, false} {
+		// テスト対象呼び出し
+		resultVal, err := GetExtendedSpatialIdsOnReachability(start, kinematics, wind, 60, 18, 21, IsPrecision(isPrecision))
+		if err != nil {
+			t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+		}
+
+		for i, id := range includeIDs {
+			if !common.Include(resultVal, id) {
+				t.Errorf("衝突判定%v 地点%d - 期待値：%v を含む, 取得値：%v", isPrecision, i+1, id, resultVal)
+			}
+		}
+		for i, id := range excludeIDs {
+			if isPrecision && common.Include(resultVal, id) {
+				t.Errorf("衝突判定%v 地点%d - 期待値：%v を含まない, 取得値：%v", isPrecision, i+1, id, resultVal)
+			}
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestReachability02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：始点がnil
+//   - パターン2：最大速度が0
+//   - パターン3：時間が0
+//   - パターン4：上昇率が負
+//   - パターン5：下降率が負
+//   - パターン6：精度が範囲外
+//
+// + 確認内容
+//   - 入力チェックエラーとなること
+func TestReachability02(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 100.0)
+	wind := Wind{East: 5}
+	calls := []func() ([]string, error){
+		func() ([]string, error) {
+			return GetExtendedSpatialIdsOnReachability(nil, Kinematics{10, 2, 1}, wind, 60, 18, 21)
+		},
+		func() ([]string, error) {
+			return GetExtendedSpatialIdsOnReachability(start, Kinematics{0, 2, 1}, wind, 60, 18, 21)
+		},
+		func() ([]string, error) {
+			return GetExtendedSpatialIdsOnReachability(start, Kinematics{10, 2, 1}, wind, 0, 18, 21)
+		},
+		func() ([]string, error) {
+			return GetExtendedSpatialIdsOnReachability(start, Kinematics{10, -2, 1}, wind, 60, 18, 21)
+		},
+		func() ([]string, error) {
+			return GetExtendedSpatialIdsOnReachability(start, Kinematics{10, 2, -1}, wind, 60, 18, 21)
+		},
+		func() ([]string, error) {
+			return GetSpatialIdsOnReachability(start, Kinematics{10, 2, 1}, wind, 60, 36)
+		},
+	}
+
+	for i, call := range calls {
+		// テスト対象呼び出し
+		resultVal, err := call()
+
+		if err == nil || len(resultVal) != 0 {
+			t.Errorf("パターン%d - 期待値：入力チェックエラー, 取得値：%v, %v", i+1, resultVal, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
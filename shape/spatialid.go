@@ -0,0 +1,136 @@
+package shape
+
+import (
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// ExtendedSpatialID 拡張空間ID構造体
+//
+// 水平方向精度、垂直方向精度と、X、Y、高さ成分インデックスを保持する。
+// 成分インデックスは精度に対して有効な範囲であることを検証済みの値とする。
+type ExtendedSpatialID struct {
+	index voxelIndex // 成分インデックス
+}
+
+// NewExtendedSpatialID 拡張空間ID構造体コンストラクタ
+//
+// 引数：
+//
+//	hZoom： 水平方向精度
+//	x： X成分インデックス
+//	y： Y成分インデックス
+//	vZoom： 垂直方向精度
+//	f： 高さ成分インデックス
+//
+// 戻り値：
+//
+//	拡張空間ID構造体
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+//	 入力数値不正： X、Y成分インデックスが 0 ～ 2^水平方向精度-1 の範囲外の場合、
+//	               もしくは高さ成分インデックスが -2^垂直方向精度 ～ 2^垂直方向精度-1 の範囲外の場合
+func NewExtendedSpatialID(hZoom, x, y, vZoom, f int64) (*ExtendedSpatialID, error) {
+	if !shape.CheckZoom(hZoom) || !shape.CheckZoom(vZoom) {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	index := voxelIndex{hZoom: hZoom, x: x, y: y, vZoom: vZoom, f: f}
+	if !index.isValid() {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	return &ExtendedSpatialID{index: index}, nil
+}
+
+// isValid 成分インデックスの範囲の判定
+//
+// 戻り値：
+//
+//	X、Y成分インデックスが 0 ～ 2^水平方向精度-1、
+//	高さ成分インデックスが -2^垂直方向精度 ～ 2^垂直方向精度-1 の範囲内の場合true
+func (v voxelIndex) isValid() bool {
+	hSize, vSize := int64(1)<<v.hZoom, int64(1)<<v.vZoom
+	return v.x >= 0 && v.x < hSize && v.y >= 0 && v.y < hSize && v.f >= -vSize && v.f < vSize
+}
+
+// ParseExtendedSpatialID 拡張空間IDの文字列の解析
+//
+// "水平方向精度/X成分/Y成分/垂直方向精度/高さ成分"の形式の文字列を拡張空間ID構造体に変換する。
+// GetVoxelIDToSpatialIDと異なり、不正な文字列はエラーとして返却する。
+//
+// 引数：
+//
+//	spatialID： 拡張空間ID
+//
+// 戻り値：
+//
+//	拡張空間ID構造体
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 空間IDフォーマット不正：空間IDのフォーマットに違反する値が"拡張空間ID"に入力されていた場合。
+//	 その他：NewExtendedSpatialIDと同じ
+func ParseExtendedSpatialID(spatialID string) (*ExtendedSpatialID, error) {
+	index, err := parseVoxelIndex(spatialID)
+	if err != nil {
+		return nil, err
+	}
+	return NewExtendedSpatialID(index.hZoom, index.x, index.y, index.vZoom, index.f)
+}
+
+// String 拡張空間IDの文字列を取得
+//
+// 戻り値：
+//
+//	拡張空間ID
+func (e ExtendedSpatialID) String() string {
+	return e.index.String()
+}
+
+// HZoom 水平方向精度を取得
+//
+// 戻り値：
+//
+//	水平方向精度
+func (e ExtendedSpatialID) HZoom() int64 {
+	return e.index.hZoom
+}
+
+// X X成分インデックスを取得
+//
+// 戻り値：
+//
+//	X成分インデックス
+func (e ExtendedSpatialID) X() int64 {
+	return e.index.x
+}
+
+// Y Y成分インデックスを取得
+//
+// 戻り値：
+//
+//	Y成分インデックス
+func (e ExtendedSpatialID) Y() int64 {
+	return e.index.y
+}
+
+// VZoom 垂直方向精度を取得
+//
+// 戻り値：
+//
+//	垂直方向精度
+func (e ExtendedSpatialID) VZoom() int64 {
+	return e.index.vZoom
+}
+
+// F 高さ成分インデックスを取得
+//
+// 戻り値：
+//
+//	高さ成分インデックス
+func (e ExtendedSpatialID) F() int64 {
+	return e.index.f
+}
@@ -0,0 +1,149 @@
+package shape
+
+import (
+	"testing"
+)
+
+// TestParseExtendedSpatialID01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：20/931339/412910/20/3
+//   - パターン2：0/0/0/0/-1(精度0、高さ成分が負の最小値)
+//   - パターン3：35/34359738367/34359738367/35/34359738367(精度35、成分が最大値)
+//
+// + 確認内容
+//   - 各成分が取得できること
+//   - 文字列が入力値と一致すること
+func TestParseExtendedSpatialID01(t *testing.T) {
+	//入力パラメータ
+	inputs := []struct {
+		spatialID string
+		expect    [5]int64
+	}{
+		{"20/931339/412910/20/3", [5]int64{20, 931339, 412910, 20, 3}},
+		{"0/0/0/0/-1", [5]int64{0, 0, 0, 0, -1}},
+		{"35/34359738367/34359738367/35/34359738367", [5]int64{35, 34359738367, 34359738367, 35, 34359738367}},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := ParseExtendedSpatialID(input.spatialID)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		components := [5]int64{resultVal.HZoom(), resultVal.X(), resultVal.Y(), resultVal.VZoom(), resultVal.F()}
+		if components != input.expect {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+1, input.expect, components)
+		}
+		if resultVal.String() != input.spatialID {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v", i+1, input.spatialID, resultVal.String())
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestParseExtendedSpatialID02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1～4：区切り文字の数が不正、空文字列、数値以外を含む
+//   - パターン5、6：水平方向精度、垂直方向精度が範囲外
+//   - パターン7～10：X、Y成分インデックスが範囲外
+//   - パターン11、12：高さ成分インデックスが範囲外
+//
+// + 確認内容
+//   - エラーが返却されること
+func TestParseExtendedSpatialID02(t *testing.T) {
+	//入力パラメータ
+	inputs := []string{
+		"20/931339/412910/20",
+		"20/931339/412910/20/3/1",
+		"",
+		"20/931339/a/20/3",
+		"36/0/0/20/0",
+		"20/0/0/-1/0",
+		"20/1048576/0/20/0",
+		"20/-1/0/20/0",
+		"20/0/1048576/20/0",
+		"20/0/-1/20/0",
+		"20/0/0/20/1048576",
+		"20/0/0/20/-1048577",
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, err := ParseExtendedSpatialID(input)
+		if err == nil {
+			t.Errorf("パターン%d error - 期待値：エラー, 取得値：%v", i+1, resultVal)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestNewExtendedSpatialID01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：(20, 931339, 412910, 20, 3)
+//   - パターン2：(20, 931339, 412910, 20, 1048576)(高さ成分が範囲外)
+//
+// + 確認内容
+//   - パターン1：文字列が20/931339/412910/20/3となること
+//   - パターン2：エラーが返却されること
+func TestNewExtendedSpatialID01(t *testing.T) {
+	// テスト対象呼び出し
+	resultVal, err := NewExtendedSpatialID(20, 931339, 412910, 20, 3)
+	if err != nil {
+		t.Fatalf("パターン1 error - 期待値：nil, 取得値：%v", err)
+	}
+	if resultVal.String() != "20/931339/412910/20/3" {
+		t.Errorf("パターン1 - 期待値：20/931339/412910/20/3, 取得値：%v", resultVal.String())
+	}
+
+	if _, err := NewExtendedSpatialID(20, 931339, 412910, 20, 1048576); err == nil {
+		t.Errorf("パターン2 error - 期待値：エラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
+
+// FuzzParseExtendedSpatialID 任意の文字列の解析
+//
+// 試験詳細：
+// + 試験データ
+//   - 正常な拡張空間ID、不正な拡張空間IDを初期値とする任意の文字列
+//
+// + 確認内容
+//   - パニックが発生しないこと
+//   - 解析できた場合、文字列を再度解析した結果が一致し、成分が精度の範囲内であること
+func FuzzParseExtendedSpatialID(f *testing.F) {
+	for _, seed := range []string{
+		"20/931339/412910/20/3", "0/0/0/0/-1", "35/34359738367/34359738367/35/34359738367",
+		"", "/", "////", "20/931339/412910/20", "36/0/0/20/0", "20/-1/0/20/0",
+		"99999999999999999999/0/0/0/0", "63/0/0/63/0", "+1/0/1/-0/0",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, spatialID string) {
+		// テスト対象呼び出し
+		resultVal, err := ParseExtendedSpatialID(spatialID)
+		if err != nil {
+			return
+		}
+
+		reparsed, err := ParseExtendedSpatialID(resultVal.String())
+		if err != nil || *reparsed != *resultVal {
+			t.Fatalf("再解析 - 期待値：%v, 取得値：%v, %v", resultVal, reparsed, err)
+		}
+		hSize, vSize := int64(1)<<resultVal.HZoom(), int64(1)<<resultVal.VZoom()
+		if resultVal.X() < 0 || resultVal.X() >= hSize || resultVal.Y() < 0 || resultVal.Y() >= hSize ||
+			resultVal.F() < -vSize || resultVal.F() >= vSize {
+			t.Fatalf("成分の範囲 - 取得値：%v", resultVal)
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package shape
+
+import (
+	"math"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/enum"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// ElevationModel 地表面の標高モデルインターフェース
+//
+// terrain.DEMが実装する。
+type ElevationModel interface {
+	// Elevation 標高取得
+	//
+	// 引数：
+	//
+	//	lon： 経度
+	//	lat： 緯度
+	//
+	// 戻り値：
+	//
+	//	標高(単位:m)。空間IDの高さと同じ基準の値とする
+	//	標高を取得できたか。範囲外、欠損値の場合はfalse
+	Elevation(lon, lat float64) (float64, bool)
+}
+
+// footprintSamples 空間IDの水平方向の範囲内で標高を取得する位置(範囲に対する割合)
+var footprintSamples = [...]float64{0, 0.5, 1}
+
+// TerrainClip 地形による空間IDの除外設定関数
+//
+// 以下の関数で取得した空間IDのうち、上端が地表面より低い空間IDを除外する。
+// 地表面の標高は空間IDの水平方向の範囲内の最小値とし、
+// 範囲内に標高を取得できない位置がある場合は除外しない。
+//   - GetSpatialIdsOnCylinders
+//   - GetExtendedSpatialIdsOnCylinders
+//
+// 引数：
+//
+//	m: 地表面の標高モデル。nilの場合は除外しない
+//
+// 戻り値：
+//
+//	衝突判定実施オプショナル型の関数
+func TerrainClip(m ElevationModel) option {
+	return func(p *IsPrecisionOpts) {
+		p.Terrain = m
+	}
+}
+
+// AboveGroundLevel 接続点の高さの地上高指定設定関数
+//
+// 以下の関数の接続点の高さを地上高として扱い、
+// 接続点の位置の地表面の標高を加えた高さに変換してから空間IDを取得する。
+// Altitude(AltitudeAboveGround)の指定を兼ねる。
+//   - GetSpatialIdsOnCylinders
+//   - GetExtendedSpatialIdsOnCylinders
+//
+// 引数：
+//
+//	m: 地表面の標高モデル。nilの場合は変換しない
+//
+// 戻り値：
+//
+//	衝突判定実施オプショナル型の関数
+func AboveGroundLevel(m ElevationModel) option {
+	return func(p *IsPrecisionOpts) {
+		p.AboveGround = m
+		if m != nil {
+			p.Altitude = AltitudeAboveGround
+		}
+	}
+}
+
+// ConvertAboveGroundLevelPoints 地上高の接続点の変換
+//
+// 高さを地上高とする接続点を、地表面の標高を加えた高さの接続点に変換する。
+// 同じPointを複数回指定した場合は、変換後も同じPointとなる。
+//
+// 引数：
+//
+//	points： 高さを地上高とする接続点
+//	m： 地表面の標高モデル
+//
+// 戻り値：
+//
+//	地表面の標高を加えた高さの接続点
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力値不正： 接続点にnilがある場合、もしくは接続点の位置の標高を取得できない場合
+func ConvertAboveGroundLevelPoints(points []*object.Point, m ElevationModel) ([]*object.Point, error) {
+	return offsetPoints(points, m.Elevation, "接続点の位置の標高を取得できません")
+}
+
+// ClassifyExtendedSpatialIdsByTerrain 地形による拡張空間IDの分類
+//
+// 拡張空間IDを、上端が地表面より低い空間ID(地中)とそれ以外に分類する。
+// 地表面の標高は空間IDの水平方向の範囲内の最小値とし、
+// 範囲内に標高を取得できない位置がある場合は地中としない。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//	m： 地表面の標高モデル
+//
+// 戻り値：
+//
+//	地中でない拡張空間IDのリスト
+//	地中の拡張空間IDのリスト
+//
+// 戻り値(エラー)：
+//
+//	拡張空間IDのフォーマットが不正な場合、エラーインスタンスが返却される。
+func ClassifyExtendedSpatialIdsByTerrain(
+	spatialIDs []string,
+	m ElevationModel,
+) (aboveIDs []string, undergroundIDs []string, err error) {
+	aboveIDs = []string{}
+	undergroundIDs = []string{}
+
+	// 水平方向の位置が同じ空間IDは地表面の標高を共有する
+	grounds := map[string]float64{}
+	for _, spatialID := range spatialIDs {
+		vertexes, err := shape.GetPointOnExtendedSpatialId(spatialID, enum.Vertex)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		west, south, east, north, ceiling := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1), math.Inf(-1)
+		for _, v := range vertexes {
+			west, east = math.Min(west, v.Lon()), math.Max(east, v.Lon())
+			south, north = math.Min(south, v.Lat()), math.Max(north, v.Lat())
+			ceiling = math.Max(ceiling, v.Alt())
+		}
+
+		key := horizontalKey(spatialID)
+		ground, ok := grounds[key]
+		if !ok {
+			ground = minElevation(m, west, south, east, north)
+			grounds[key] = ground
+		}
+
+		if ceiling < ground {
+			undergroundIDs = append(undergroundIDs, spatialID)
+		} else {
+			aboveIDs = append(aboveIDs, spatialID)
+		}
+	}
+
+	return aboveIDs, undergroundIDs, nil
+}
+
+// horizontalKey 拡張空間IDの水平方向の位置
+//
+// 引数：
+//
+//	spatialID： 拡張空間ID
+//
+// 戻り値：
+//
+//	水平方向の精度、X、Yの部分の文字列
+func horizontalKey(spatialID string) string {
+	parts := strings.SplitN(spatialID, consts.SpatialIDDelimiter, 4)
+	return strings.Join(parts[:min(3, len(parts))], consts.SpatialIDDelimiter)
+}
+
+// minElevation 範囲内の地表面の最小標高取得
+//
+// 範囲の頂点、辺の中点、中心の標高の最小値を返却する。
+//
+// 引数：
+//
+//	m： 地表面の標高モデル
+//	west, south, east, north： 範囲の西端、南端、東端、北端の経緯度
+//
+// 戻り値：
+//
+//	最小標高(単位:m)。標高を取得できない位置がある場合は負の無限大
+func minElevation(m ElevationModel, west, south, east, north float64) float64 {
+	ground := math.Inf(1)
+	for _, rx := range footprintSamples {
+		for _, ry := range footprintSamples {
+			e, ok := m.Elevation(west+(east-west)*rx, south+(north-south)*ry)
+			if !ok {
+				return math.Inf(-1)
+			}
+			ground = math.Min(ground, e)
+		}
+	}
+	return ground
+}
@@ -0,0 +1,197 @@
+package shape
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// flatTerrain テスト用の平坦な地表面
+//
+// 経度lonMax以下の範囲で一定の標高を返却する。
+type flatTerrain struct {
+	elevation float64
+	lonMax    float64
+}
+
+func (f flatTerrain) Elevation(lon, lat float64) (float64, bool) {
+	if lon > f.lonMax {
+		return 0, false
+	}
+	return f.elevation, true
+}
+
+// TestClassifyExtendedSpatialIdsByTerrain01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 地表面：標高10m(経度180度以下)
+//   - 拡張空間ID：上端が6m、10m、13mの空間ID、標高を取得できない範囲の上端が1mの空間ID
+//
+// + 確認内容
+//   - 上端が地表面より低い空間IDのみ地中に分類されること
+//   - 標高を取得できない範囲の空間IDは地中に分類されないこと
+func TestClassifyExtendedSpatialIdsByTerrain01(t *testing.T) {
+	//入力パラメータ
+	spatialIDs := []string{
+		"25/29803304/13212108/25/5",
+		"25/29803304/13212108/25/9",
+		"25/29803304/13212108/25/12",
+		"25/29803304/13212108/25/0",
+	}
+	terrain := flatTerrain{elevation: 10, lonMax: 180}
+
+	// 期待値
+	expectAbove := []string{"25/29803304/13212108/25/9", "25/29803304/13212108/25/12"}
+	expectUnderground := []string{"25/29803304/13212108/25/5", "25/29803304/13212108/25/0"}
+
+	// テスト対象呼び出し
+	aboveIDs, undergroundIDs, err := ClassifyExtendedSpatialIdsByTerrain(spatialIDs, terrain)
+	if err != nil || !reflect.DeepEqual(aboveIDs, expectAbove) || !reflect.DeepEqual(undergroundIDs, expectUnderground) {
+		t.Errorf("分類 - 期待値：%v, %v, 取得値：%v, %v, %v", expectAbove, expectUnderground, aboveIDs, undergroundIDs, err)
+	}
+
+	// 標高を取得できない範囲
+	aboveIDs, undergroundIDs, _ = ClassifyExtendedSpatialIdsByTerrain(spatialIDs[3:], flatTerrain{elevation: 10, lonMax: 0})
+	if !reflect.DeepEqual(aboveIDs, spatialIDs[3:]) || len(undergroundIDs) != 0 {
+		t.Errorf("範囲外 - 期待値：%v, [], 取得値：%v, %v", spatialIDs[3:], aboveIDs, undergroundIDs)
+	}
+
+	// 不正な拡張空間ID
+	if _, _, err := ClassifyExtendedSpatialIdsByTerrain([]string{"25/1/2"}, terrain); err == nil {
+		t.Error("error - 期待値：エラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestTerrainClip01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.753098, 35.685371, 11.0), (139.753198, 35.685371, 11.0)
+//   - 半径：2.0、水平精度：25、垂直精度：25
+//   - 地表面：標高11m
+//
+// + 確認内容
+//   - 地形を考慮しない結果のうち、地中でない空間IDのみ取得されること
+//   - 地中の空間IDが1個以上除外されること
+func TestTerrainClip01(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+	center := []*object.Point{p1, p2}
+	terrain := flatTerrain{elevation: 11, lonMax: 180}
+
+	// 期待値
+	allIDs, _ := GetExtendedSpatialIdsOnCylinders(center, 2.0, 25, 25, false)
+	expectVal, undergroundIDs, _ := ClassifyExtendedSpatialIdsByTerrain(allIDs, terrain)
+
+	// テスト対象呼び出し
+	resultVal, err := GetExtendedSpatialIdsOnCylinders(center, 2.0, 25, 25, false, TerrainClip(terrain))
+
+	if err != nil || !reflect.DeepEqual(resultVal, expectVal) {
+		t.Errorf("空間ID - 期待値：%v, 取得値：%v, %v", expectVal, resultVal, err)
+	}
+	if len(undergroundIDs) == 0 {
+		t.Errorf("地中の空間ID - 期待値：1個以上, 取得値：%v", undergroundIDs)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestAboveGroundLevel01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点(地上高)：(139.753098, 35.685371, 1.0), (139.753198, 35.685371, 1.0)
+//   - 半径：2.0、水平精度：25、垂直精度：25
+//   - 地表面：標高10m
+//
+// + 確認内容
+//   - 接続点の高さを11.0mとした場合と同じ空間IDが取得されること
+func TestAboveGroundLevel01(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.753098, 35.685371, 1.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 1.0)
+	terrain := flatTerrain{elevation: 10, lonMax: 180}
+
+	// 期待値
+	e1, _ := object.NewPoint(139.753098, 35.685371, 11.0)
+	e2, _ := object.NewPoint(139.753198, 35.685371, 11.0)
+	expectVal, _ := GetExtendedSpatialIdsOnCylinders([]*object.Point{e1, e2}, 2.0, 25, 25, false)
+
+	// テスト対象呼び出し
+	resultVal, err := GetExtendedSpatialIdsOnCylinders(
+		[]*object.Point{p1, p2}, 2.0, 25, 25, false, AboveGroundLevel(terrain),
+	)
+
+	if err != nil || !reflect.DeepEqual(resultVal, expectVal) {
+		t.Errorf("空間ID - 期待値：%v, 取得値：%v, %v", expectVal, resultVal, err)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestAboveGroundLevel02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点(地上高)：(139.753098, 35.685371, 1.0)
+//   - 地表面：経度0度以下のみ標高を取得可能
+//
+// + 確認内容
+//   - 入力チェックエラーとなること
+func TestAboveGroundLevel02(t *testing.T) {
+	//入力パラメータ
+	p, _ := object.NewPoint(139.753098, 35.685371, 1.0)
+
+	// テスト対象呼び出し
+	_, err := GetExtendedSpatialIdsOnCylinders(
+		[]*object.Point{p}, 2.0, 25, 25, false, AboveGroundLevel(flatTerrain{elevation: 10, lonMax: 0}),
+	)
+
+	if err == nil {
+		t.Error("error - 期待値：入力チェックエラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestConvertAboveGroundLevelPoints01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：p1, p2, p1(同じPointを2回指定)
+//   - 地表面：標高10m
+//
+// + 確認内容
+//   - 高さに地表面の標高が加算されること
+//   - 同じPointを指定した位置は変換後も同じPointとなること
+//   - nilを含む場合は入力チェックエラーとなること
+func TestConvertAboveGroundLevelPoints01(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.753098, 35.685371, 1.0)
+	p2, _ := object.NewPoint(139.753198, 35.685371, 2.5)
+	terrain := flatTerrain{elevation: 10, lonMax: 180}
+
+	// テスト対象呼び出し
+	resultVal, err := ConvertAboveGroundLevelPoints([]*object.Point{p1, p2, p1}, terrain)
+
+	if err != nil || len(resultVal) != 3 {
+		t.Fatalf("接続点 - 期待値：3個, 取得値：%v, %v", resultVal, err)
+	}
+	if resultVal[0].Alt() != 11.0 || resultVal[1].Alt() != 12.5 || resultVal[0].Lon() != p1.Lon() {
+		t.Errorf("高さ - 期待値：11.0, 12.5, 取得値：%v, %v", resultVal[0].Alt(), resultVal[1].Alt())
+	}
+	if resultVal[0] != resultVal[2] {
+		t.Error("同一のPoint - 期待値：同じPoint, 取得値：異なるPoint")
+	}
+
+	if _, err := ConvertAboveGroundLevelPoints([]*object.Point{p1, nil}, terrain); err == nil {
+		t.Error("error - 期待値：入力チェックエラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
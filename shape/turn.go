@@ -0,0 +1,384 @@
+package shape
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape/physics"
+)
+
+// turnMinAngle 旋回の円弧を配置する最小の旋回角(単位:rad)
+const turnMinAngle = 1e-6
+
+// TurnRadius 旋回半径設定関数
+//
+// 以下の関数の経由する接続点の角を、指定した旋回半径の円弧に置き換えてから空間IDを取得する。
+// 円弧の区間は円弧に沿った管(円環の一部)の空間IDとし、接続点の球は使用しない。
+// 接続点間が短く旋回半径の円弧を配置できない場合は、配置できる最大の半径の円弧とする。
+//   - GetSpatialIdsOnCylinders
+//   - GetExtendedSpatialIdsOnCylinders
+//
+// 引数：
+//
+//	r: 旋回半径(単位:m)。0の場合は円弧に置き換えない
+//
+// 戻り値：
+//
+//	衝突判定実施オプショナル型の関数
+func TurnRadius(r float64) option {
+	return func(p *IsPrecisionOpts) {
+		p.TurnRadius = r
+	}
+}
+
+// turnArc 旋回の円弧構造体
+type turnArc struct {
+	center  spatial.Point3 // 【直交座標空間】円弧の中心
+	start   spatial.Point3 // 【直交座標空間】円弧の始点
+	end     spatial.Point3 // 【直交座標空間】円弧の終点
+	shifted bool           // 経度を180度ずらした座標系であるか
+}
+
+// smoothCorners 接続点の角の円弧への置き換え
+//
+// 経由する接続点を円弧の始点、終点に置き換え、円弧を返却する。
+// 円弧の始点、終点は接続点の前後の区間上に配置する。
+//
+// 引数：
+//
+//	center： 接続点
+//	turnRadius： 旋回半径(単位:m)
+//	factor： Webメルカトル換算係数
+//
+// 戻り値：
+//
+//	円弧の始点、終点に置き換えた接続点
+//	円弧の始点を始点とする区間の番号をキーとした円弧
+//
+// 戻り値(エラー)：
+//
+//	円弧の始点、終点の座標が不正な場合、エラーインスタンスが返却される。
+func smoothCorners(center []*object.Point, turnRadius, factor float64) ([]*object.Point, map[int]*turnArc, error) {
+	arcs := map[int]*turnArc{}
+	if len(center) < 3 {
+		return center, arcs, nil
+	}
+
+	smoothed := []*object.Point{center[0]}
+	for k := 1; k < len(center)-1; k++ {
+		arc, arcStart, arcEnd, err := filletCorner(
+			center[k-1], center[k], center[k+1],
+			k-1 == 0, k+1 == len(center)-1,
+			turnRadius*factor, factor,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		if arc == nil {
+			smoothed = append(smoothed, center[k])
+			continue
+		}
+
+		if arcStart != smoothed[len(smoothed)-1] {
+			smoothed = append(smoothed, arcStart)
+		}
+		arcs[len(smoothed)-1] = arc
+		smoothed = append(smoothed, arcEnd)
+	}
+	if last := center[len(center)-1]; last != smoothed[len(smoothed)-1] {
+		smoothed = append(smoothed, last)
+	}
+
+	return smoothed, arcs, nil
+}
+
+// filletCorner 接続点の角の円弧
+//
+// 接続点の前後の区間に接する円弧を求める。
+// 円弧の始点、終点は、経路の端の区間では区間の長さ、それ以外の区間では区間の長さの半分まで配置する。
+//
+// 引数：
+//
+//	previous： 前の接続点
+//	corner： 角の接続点
+//	next： 次の接続点
+//	isFirst： 前の接続点が経路の始点であるか
+//	isLast： 次の接続点が経路の終点であるか
+//	radius： 【直交座標空間】旋回半径
+//	factor： Webメルカトル換算係数
+//
+// 戻り値：
+//
+//	円弧。旋回しない場合、もしくは折り返す場合はnil
+//	円弧の始点(前の接続点と一致する場合は前の接続点)
+//	円弧の終点(次の接続点と一致する場合は次の接続点)
+//
+// 戻り値(エラー)：
+//
+//	円弧の始点、終点の座標が不正な場合、エラーインスタンスが返却される。
+func filletCorner(
+	previous, corner, next *object.Point,
+	isFirst, isLast bool,
+	radius, factor float64,
+) (*turnArc, *object.Point, *object.Point, error) {
+	points := []*object.Point{previous, corner, next}
+	shifted := isAntimeridianFrame(points...)
+	if shifted {
+		shiftedPoints, err := shiftLongitudes(points...)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		points = shiftedPoints
+	}
+
+	// 【直交座標空間】接続点の座標
+	crsPoints, _ := shape.ConvertPointListToProjectedPointList(points, consts.OrthCrs)
+	orth := make([]spatial.Point3, 0, len(crsPoints))
+	for _, crsPoint := range crsPoints {
+		orth = append(orth, spatial.Point3{X: crsPoint.X, Y: crsPoint.Y, Z: crsPoint.Alt * factor})
+	}
+
+	in := spatial.NewVectorFromPoints(orth[0], orth[1])
+	out := spatial.NewVectorFromPoints(orth[1], orth[2])
+	inLength, outLength := in.Norm(), out.Norm()
+	if inLength < consts.Minima || outLength < consts.Minima {
+		return nil, nil, nil, nil
+	}
+	u, v := in.Unit(), out.Unit()
+	cosAngle := math.Max(-1, math.Min(1, u.X*v.X+u.Y*v.Y+u.Z*v.Z))
+	angle := math.Acos(cosAngle)
+	if angle < turnMinAngle || angle > math.Pi-turnMinAngle {
+		return nil, nil, nil, nil
+	}
+
+	// 接続点から円弧の始点、終点までの距離
+	tangent := radius * math.Tan(angle/2)
+	inLimit, outLimit := inLength/2, outLength/2
+	if isFirst {
+		inLimit = inLength
+	}
+	if isLast {
+		outLimit = outLength
+	}
+	if limit := math.Min(inLimit, outLimit); tangent > limit {
+		tangent = limit
+		radius = tangent / math.Tan(angle/2)
+	}
+
+	// 円弧の中心は始点から旋回する側に旋回半径だけ離れた点
+	normal := spatial.Vector3{X: v.X - u.X*cosAngle, Y: v.Y - u.Y*cosAngle, Z: v.Z - u.Z*cosAngle}.Unit()
+	arcStartOrth := orth[1].Translate(u.Scale(-tangent))
+	arcEndOrth := orth[1].Translate(v.Scale(tangent))
+	arc := &turnArc{
+		center:  arcStartOrth.Translate(normal.Scale(radius)),
+		start:   arcStartOrth,
+		end:     arcEndOrth,
+		shifted: shifted,
+	}
+
+	arcStart, arcEnd := previous, next
+	if tangent < inLength-consts.Minima {
+		p, err := orthToPoint(arcStartOrth, factor, shifted)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		arcStart = p
+	}
+	if tangent < outLength-consts.Minima {
+		p, err := orthToPoint(arcEndOrth, factor, shifted)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		arcEnd = p
+	}
+
+	return arc, arcStart, arcEnd, nil
+}
+
+// orthToPoint 【直交座標空間⇒緯度経度空間】座標変換
+//
+// 引数：
+//
+//	point： 【直交座標空間】座標
+//	factor： Webメルカトル換算係数
+//	shifted： 経度を180度ずらした座標系であるか
+//
+// 戻り値：
+//
+//	緯度経度空間の座標
+//
+// 戻り値(エラー)：
+//
+//	座標が不正な場合、エラーインスタンスが返却される。
+func orthToPoint(point spatial.Point3, factor float64, shifted bool) (*object.Point, error) {
+	projected := convertSpatialProjectionPoint(spatial.Point3{X: point.X, Y: point.Y, Z: point.Z / factor})
+	points, err := shape.ConvertProjectedPointListToPointList([]*object.ProjectedPoint{&projected}, consts.OrthCrs)
+	if err != nil {
+		return nil, err
+	}
+	if shifted {
+		points, err = shiftLongitudes(points...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return points[0], nil
+}
+
+// Arc 円弧に沿った管の構造体
+//
+// 旋回の円弧に沿った管(円環の一部)の空間IDを取得する。
+type Arc struct {
+	*Capsule                // カプセル構造体の埋め込み(衝突判定の処理を共用する)
+	center   spatial.Point3 // 円弧の中心
+	tube     float64        // 管の半径
+}
+
+// NewArc 円弧に沿った管の構造体コンストラクタ
+//
+// 引数：
+//
+//	center： 円弧の中心
+//	startPoint： 円弧の始点
+//	endPoint： 円弧の終点
+//	radius： 管の半径
+//	hZoom： 水平精度
+//	vZoom： 垂直精度
+//	isPrecision： 衝突判定実施オプション
+//	factor： Webメルカトル換算係数
+//
+// 戻り値：
+//
+//	円弧に沿った管の構造体ポインタ
+func NewArc(
+	center spatial.Point3,
+	startPoint spatial.Point3,
+	endPoint spatial.Point3,
+	radius float64,
+	hZoom int64,
+	vZoom int64,
+	isPrecision bool,
+	factor float64,
+) *Arc {
+	capsule := new(Capsule)
+	capsule.Rectangular = NewRectangular(startPoint, endPoint, radius, hZoom, vZoom, factor)
+	// 円柱の内部を埋める処理は行わない
+	capsule.isCapsule = true
+	capsule.isPrecision = isPrecision
+	capsule.object = physics.NewTorusPhysics(radius*factor, center, startPoint, endPoint)
+
+	return &Arc{Capsule: capsule, center: center, tube: radius}
+}
+
+// calcArcLineSpatialIDs 円弧の軸の空間IDを取得
+//
+// 円弧を弦で分割し、弦の空間IDを取得する。
+//
+// 引数：
+//
+//	unitVoxel： 単位ボクセル
+//
+// 戻り値：
+//
+//	円弧の弦の空間ID
+//	弦と円弧の最大の距離(単位:m)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func (a *Arc) calcArcLineSpatialIDs(unitVoxel spatial.Vector3) ([]string, float64, error) {
+	toStart := spatial.NewVectorFromPoints(a.center, a.start)
+	toEnd := spatial.NewVectorFromPoints(a.center, a.end)
+	majorRadius := toStart.Norm()
+	angle := math.Acos(math.Max(-1, math.Min(1,
+		(toStart.X*toEnd.X+toStart.Y*toEnd.Y+toStart.Z*toEnd.Z)/(majorRadius*toEnd.Norm()),
+	)))
+
+	// 弦と円弧の距離が単位ボクセルの半分以下となるように分割する
+	sag := math.Min(unitVoxel.X, unitVoxel.Y) / 2
+	n := 1
+	if sag < majorRadius {
+		n = int(math.Max(1, math.Ceil(angle/(2*math.Acos(1-sag/majorRadius)))))
+	}
+
+	chord := make([]*object.ProjectedPoint, 0, n+1)
+	for i := 0; i <= n; i++ {
+		// 始点、終点への方向ベクトルの球面線形補間
+		s := float64(i) / float64(n)
+		w1, w2 := 1-s, s
+		if sinAngle := math.Sin(angle); sinAngle > 0 {
+			w1, w2 = math.Sin((1-s)*angle)/sinAngle, math.Sin(s*angle)/sinAngle
+		}
+		point := a.center.Translate(toStart.Scale(w1)).Translate(toEnd.Scale(w2))
+		projected := convertSpatialProjectionPoint(spatial.Point3{X: point.X, Y: point.Y, Z: point.Z / a.factor})
+		chord = append(chord, &projected)
+	}
+	wgs84Points, _ := shape.ConvertProjectedPointListToPointList(chord, consts.OrthCrs)
+
+	lineSpatialIDs := []string{}
+	for i := range wgs84Points[:len(wgs84Points)-1] {
+		ids, err := shape.GetExtendedSpatialIdsOnLine(wgs84Points[i], wgs84Points[i+1], a.hZoom, a.vZoom)
+		if err != nil {
+			return []string{}, 0, err
+		}
+		lineSpatialIDs = common.Union(ids, lineSpatialIDs)
+	}
+
+	return lineSpatialIDs, majorRadius * (1 - math.Cos(angle/float64(2*n))) / a.factor, nil
+}
+
+// CalcValidSpatialIDs 有効な空間ID取得
+//
+// 円弧の弦の空間IDから円弧に沿った管の有効な空間IDを取得
+//
+// 戻り値：
+//
+//	最終的にオブジェクトと衝突すると判定した空間ID
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func (a *Arc) CalcValidSpatialIDs() ([]string, error) {
+	endSegment := startStage(a.observer, StageSegment, a.segment)
+
+	// 【直交座標空間】単位ボクセル
+	unitVoxel := a.calcBaseUnitVoxel()
+
+	// 円弧の弦の空間IDを取得
+	endStage := startStage(a.observer, StageLine, a.segment)
+	lineSpatialIDs, sag, err := a.calcArcLineSpatialIDs(unitVoxel)
+	endStage(StageResult{Count: len(lineSpatialIDs)})
+	if err != nil {
+		endSegment(StageResult{})
+		return []string{}, err
+	}
+	debugLog(a.logger, "円弧の弦の空間ID取得", slog.Int("lineIDs", len(lineSpatialIDs)), slog.Float64("sag", sag))
+
+	// 弦と円弧の距離を加えた半径で外接する空間IDを全空間IDとして取得
+	endStage = startStage(a.observer, StageCandidate, a.segment)
+	a.radius = a.tube + sag
+	a.calcAllSpatialIDs(lineSpatialIDs, unitVoxel)
+	a.radius = a.tube
+	endStage(StageResult{Count: len(a.allSpatialIDs)})
+
+	// 衝突判定実施オプションがfalseの場合は衝突判定をスキップ
+	if !a.isPrecision {
+		endSegment(StageResult{Count: len(a.allSpatialIDs)})
+		return a.allSpatialIDs, nil
+	}
+
+	// 内部空間IDは取得せず、全空間IDとオブジェクトで衝突判定
+	endStage = startStage(a.observer, StageCollision, a.segment)
+	tests := a.calcCollideSpatialIDs()
+	endStage(StageResult{Count: len(a.includeSpatialIDs), Tests: tests})
+
+	endSegment(StageResult{Count: len(a.includeSpatialIDs)})
+	return a.includeSpatialIDs, nil
+}
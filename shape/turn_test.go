@@ -0,0 +1,158 @@
+package shape
+
+import (
+	"math"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// turnRoute テスト用の直角に曲がる経路(約900m東進した後、約1100m北進)
+func turnRoute() []*object.Point {
+	p1, _ := object.NewPoint(139.75, 35.68, 100.0)
+	p2, _ := object.NewPoint(139.76, 35.68, 100.0)
+	p3, _ := object.NewPoint(139.76, 35.69, 100.0)
+	return []*object.Point{p1, p2, p3}
+}
+
+// TestSmoothCorners01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0), (139.76, 35.68, 100.0), (139.76, 35.69, 100.0)
+//   - パターン1：旋回半径200m
+//   - パターン2：旋回半径5000m(区間の長さを超える)
+//
+// + 確認内容
+//   - パターン1：角の接続点が円弧の始点、終点に置き換わり、角の接続点から約200mの位置であること
+//     円弧の中心から円弧の始点、終点までの距離が旋回半径と一致すること
+//   - パターン2：円弧の始点が経路の始点と同じPointとなり、半径を縮めた円弧となること
+func TestSmoothCorners01(t *testing.T) {
+	//入力パラメータ
+	center := turnRoute()
+	factor := 1 / math.Cos(common.DegreeToRadian(center[0].Lat()))
+
+	// テスト対象呼び出し
+	resultVal, arcs, err := smoothCorners(center, 200, factor)
+	if err != nil || len(resultVal) != 4 || len(arcs) != 1 || arcs[1] == nil {
+		t.Fatalf("パターン1 - 期待値：4点、区間1の円弧, 取得値：%v, %v, %v", resultVal, arcs, err)
+	}
+	if resultVal[0] != center[0] || resultVal[3] != center[2] {
+		t.Errorf("パターン1 始点、終点 - 期待値：元のPoint, 取得値：%v, %v", resultVal[0], resultVal[3])
+	}
+	for i, point := range resultVal[1:3] {
+		distance, _, _ := geodesicInverse(center[1].Lon(), center[1].Lat(), point.Lon(), point.Lat())
+		if math.Abs(distance-200) > 2 {
+			t.Errorf("パターン1 円弧の端点%dまでの距離 - 期待値：約200, 取得値：%v", i+1, distance)
+		}
+	}
+	arc := arcs[1]
+	for _, point := range []spatial.Point3{arc.start, arc.end} {
+		if distance := spatial.NewVectorFromPoints(arc.center, point).Norm(); math.Abs(distance-200*factor) > 1e-6 {
+			t.Errorf("パターン1 円弧の半径 - 期待値：%v, 取得値：%v", 200*factor, distance)
+		}
+	}
+
+	// テスト対象呼び出し
+	resultVal, arcs, err = smoothCorners(center, 5000, factor)
+	if err != nil || len(resultVal) != 3 || len(arcs) != 1 || arcs[0] == nil || resultVal[0] != center[0] {
+		t.Fatalf("パターン2 - 期待値：3点(始点は元のPoint)、区間0の円弧, 取得値：%v, %v, %v", resultVal, arcs, err)
+	}
+	if distance := spatial.NewVectorFromPoints(arcs[0].center, arcs[0].start).Norm(); distance >= 5000*factor {
+		t.Errorf("パターン2 円弧の半径 - 期待値：%v未満, 取得値：%v", 5000*factor, distance)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestSmoothCorners02 正常系動作確認(円弧に置き換えない角)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：直進する接続点
+//   - パターン2：折り返す接続点
+//   - パターン3：接続点が2点
+//
+// + 確認内容
+//   - 接続点がそのまま返却され、円弧が無いこと
+func TestSmoothCorners02(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.75, 35.68, 100.0)
+	p2, _ := object.NewPoint(139.76, 35.68, 100.0)
+	p3, _ := object.NewPoint(139.77, 35.68, 100.0)
+	inputs := [][]*object.Point{
+		{p1, p2, p3},
+		{p1, p2, p1},
+		{p1, p2},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		resultVal, arcs, err := smoothCorners(input, 200, 1.2)
+
+		if err != nil || len(arcs) != 0 || len(resultVal) != len(input) {
+			t.Errorf("パターン%d - 期待値：%v, 取得値：%v, %v, %v", i+1, input, resultVal, arcs, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestTurnRadius01 正常系・異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0), (139.76, 35.68, 100.0), (139.76, 35.69, 100.0)
+//   - 半径：10.0、水平精度：20、垂直精度：20、旋回半径：200m
+//   - 衝突判定あり、なし
+//
+// + 確認内容
+//   - 円弧の中点の空間IDが含まれること
+//   - 角の接続点の空間IDが含まれないこと(TurnRadius未指定の場合は含まれること)
+//   - TurnRadiusに負の値を指定した場合は入力チェックエラーとなること
+func TestTurnRadius01(t *testing.T) {
+	//入力パラメータ
+	center := turnRoute()
+
+	// 期待値
+	factor := 1 / math.Cos(common.DegreeToRadian(center[0].Lat()))
+	_, arcs, _ := smoothCorners(center, 200, factor)
+	arc := arcs[1]
+	toCorner := spatial.NewVectorFromPoints(
+		arc.center, spatial.Point3{X: (arc.start.X + arc.end.X) / 2, Y: (arc.start.Y + arc.end.Y) / 2, Z: arc.start.Z},
+	)
+	mid, _ := orthToPoint(arc.center.Translate(toCorner.Unit().Scale(200*factor)), factor, arc.shifted)
+	midIDs, _ := shape.GetExtendedSpatialIdsOnPoints([]*object.Point{mid}, 20, 20)
+	cornerIDs, _ := shape.GetExtendedSpatialIdsOnPoints([]*object.Point{center[1]}, 20, 20)
+
+	for _, isPrecision := range []bool{true, false} {
+		// テスト対象呼び出し
+		resultVal, err := GetExtendedSpatialIdsOnCylinders(
+			center, 10.0, 20, 20, false, IsPrecision(isPrecision), TurnRadius(200),
+		)
+		if err != nil {
+			t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+		}
+
+		if !common.Include(resultVal, midIDs[0]) {
+			t.Errorf("衝突判定%v 円弧の中点 - 期待値：%v を含む, 取得値：%v", isPrecision, midIDs[0], resultVal)
+		}
+		if common.Include(resultVal, cornerIDs[0]) {
+			t.Errorf("衝突判定%v 角の接続点 - 期待値：%v を含まない, 取得値：%v", isPrecision, cornerIDs[0], resultVal)
+		}
+	}
+
+	sharpVal, _ := GetExtendedSpatialIdsOnCylinders(center, 10.0, 20, 20, false)
+	if !common.Include(sharpVal, cornerIDs[0]) {
+		t.Errorf("TurnRadius未指定 - 期待値：%v を含む, 取得値：%v", cornerIDs[0], sharpVal)
+	}
+
+	if _, err := GetExtendedSpatialIdsOnCylinders(center, 10.0, 20, 20, false, TurnRadius(-1)); err == nil {
+		t.Error("error - 期待値：入力チェックエラー, 取得値：nil")
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,300 @@
+package shape
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/trajectoryjp/spatial_id_go/common/consts"
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+	"github.com/trajectoryjp/spatial_id_go/common/spatial"
+	"github.com/trajectoryjp/spatial_id_plus_go/shape/physics"
+)
+
+// Uncertainty 位置の不確かさ構造体
+//
+// 航法誤差の2σ等、経路の中心から外れうる距離を表す。
+type Uncertainty struct {
+	Horizontal float64 // 水平方向の不確かさ(単位:m)
+	Vertical   float64 // 垂直方向の不確かさ(単位:m)
+}
+
+// PositionUncertainty 接続点ごとの位置の不確かさ設定関数
+//
+// 以下の関数の円柱の半径に接続点ごとの位置の不確かさを加え、
+// 接続点間で水平方向、垂直方向の半径が線形に変化する経路の空間IDを取得する。
+// 接続点は円柱の半径に不確かさを加えた回転楕円体状とする。
+//   - GetSpatialIdsOnCylinders
+//   - GetExtendedSpatialIdsOnCylinders
+//
+// 引数：
+//
+//	u: 接続点ごとの位置の不確かさ。接続点と同じ数を指定する
+//
+// 戻り値：
+//
+//	衝突判定実施オプショナル型の関数
+func PositionUncertainty(u []Uncertainty) option {
+	return func(p *IsPrecisionOpts) {
+		p.Uncertainties = u
+	}
+}
+
+// UncertaintyGrowth 位置の不確かさの増加率設定関数
+//
+// 以下の関数の位置の不確かさを、経路の始点からの経路に沿った距離に比例して増加させる。
+// PositionUncertaintyと併用した場合は接続点ごとの不確かさに加算する。
+// 時間あたりの増加率は速度で割って距離あたりの増加率として指定する。
+//   - GetSpatialIdsOnCylinders
+//   - GetExtendedSpatialIdsOnCylinders
+//
+// 引数：
+//
+//	g: 経路に沿った1mあたりの水平方向、垂直方向の不確かさの増加量(単位:m/m)
+//
+// 戻り値：
+//
+//	衝突判定実施オプショナル型の関数
+func UncertaintyGrowth(g Uncertainty) option {
+	return func(p *IsPrecisionOpts) {
+		p.UncertaintyGrowth = g
+	}
+}
+
+// calcUncertainties 接続点ごとの位置の不確かさの算出
+//
+// 接続点ごとの不確かさに、始点からの経路に沿った距離と増加率の積を加算する。
+//
+// 引数：
+//
+//	center： 接続点
+//	uncertainties： 接続点ごとの位置の不確かさ(nilの場合は0)
+//	growth： 位置の不確かさの増加率
+//
+// 戻り値：
+//
+//	接続点ごとの位置の不確かさ
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力値不正： 不確かさの数が接続点の数と一致しない場合、もしくは不確かさ、増加率が負の場合
+//	 値変換エラー： 対蹠点に近い区間があり、経路に沿った距離を求められない場合
+func calcUncertainties(center []*object.Point, uncertainties []Uncertainty, growth Uncertainty) ([]Uncertainty, error) {
+	if uncertainties != nil && len(uncertainties) != len(center) {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "位置の不確かさの数が接続点の数と一致しません")
+	}
+	if !(growth.Horizontal >= 0 && growth.Vertical >= 0) {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "位置の不確かさの増加率が不正です")
+	}
+
+	result := make([]Uncertainty, len(center))
+	distance := 0.0
+	for i, point := range center {
+		if i > 0 {
+			d, _, err := geodesicInverse(center[i-1].Lon(), center[i-1].Lat(), point.Lon(), point.Lat())
+			if err != nil {
+				return nil, err
+			}
+			distance += math.Hypot(d, point.Alt()-center[i-1].Alt())
+		}
+		if uncertainties != nil {
+			result[i] = uncertainties[i]
+		}
+		if !(result[i].Horizontal >= 0 && result[i].Vertical >= 0) {
+			return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "位置の不確かさが不正です")
+		}
+		result[i].Horizontal += growth.Horizontal * distance
+		result[i].Vertical += growth.Vertical * distance
+	}
+
+	return result, nil
+}
+
+// interpolateUncertainties 分割した接続点の位置の不確かさの補間
+//
+// 分割前の接続点は同じPointのまま分割後の接続点に含まれるものとし、
+// 分割した接続点の不確かさを分割前の接続点の不確かさから接続点の順番により線形補間する。
+//
+// 引数：
+//
+//	original： 分割前の接続点
+//	uncertainties： 分割前の接続点ごとの位置の不確かさ
+//	densified： 分割後の接続点
+//
+// 戻り値：
+//
+//	分割後の接続点ごとの位置の不確かさ
+func interpolateUncertainties(original []*object.Point, uncertainties []Uncertainty, densified []*object.Point) []Uncertainty {
+	// 分割後の接続点における分割前の接続点の位置
+	positions := make([]int, 0, len(original))
+	for i, point := range densified {
+		if len(positions) < len(original) && point == original[len(positions)] {
+			positions = append(positions, i)
+		}
+	}
+
+	result := make([]Uncertainty, len(densified))
+	if len(positions) == 0 {
+		return result
+	}
+	for j := range positions[:len(positions)-1] {
+		from, to := positions[j], positions[j+1]
+		for i := from; i < to; i++ {
+			ratio := float64(i-from) / float64(to-from)
+			result[i] = Uncertainty{
+				Horizontal: uncertainties[j].Horizontal + (uncertainties[j+1].Horizontal-uncertainties[j].Horizontal)*ratio,
+				Vertical:   uncertainties[j].Vertical + (uncertainties[j+1].Vertical-uncertainties[j].Vertical)*ratio,
+			}
+		}
+	}
+	for i := positions[len(positions)-1]; i < len(densified); i++ {
+		result[i] = uncertainties[len(positions)-1]
+	}
+
+	return result
+}
+
+// inflateRadius 位置の不確かさを加えた半径
+//
+// 引数：
+//
+//	radius： 円柱の半径(単位:m)
+//	u： 位置の不確かさ
+//
+// 戻り値：
+//
+//	水平方向、垂直方向の半径(単位:m)
+func inflateRadius(radius float64, u Uncertainty) Uncertainty {
+	return Uncertainty{Horizontal: radius + u.Horizontal, Vertical: radius + u.Vertical}
+}
+
+// Envelope 半径が変化する管の構造体
+//
+// 始点、終点の水平方向、垂直方向の半径から、半径が線形に変化する管の空間IDを取得する。
+type Envelope struct {
+	*Capsule                // カプセル構造体の埋め込み(衝突判定の処理を共用する)
+	maxRadius   float64     // 外接する直方体の半径
+	minRadius   float64     // 内接する直方体の半径
+	startRadius Uncertainty // 始点の水平方向、垂直方向の半径
+	endRadius   Uncertainty // 終点の水平方向、垂直方向の半径
+}
+
+// NewEnvelope 半径が変化する管の構造体コンストラクタ
+//
+// 引数：
+//
+//	startPoint： 始点
+//	endPoint： 終点
+//	startRadius： 始点の水平方向、垂直方向の半径(単位:m)
+//	endRadius： 終点の水平方向、垂直方向の半径(単位:m)
+//	hZoom： 水平精度
+//	vZoom： 垂直精度
+//	isPrecision： 衝突判定実施オプション
+//	factor： Webメルカトル換算係数
+//
+// 戻り値：
+//
+//	半径が変化する管の構造体ポインタ
+func NewEnvelope(
+	startPoint spatial.Point3,
+	endPoint spatial.Point3,
+	startRadius Uncertainty,
+	endRadius Uncertainty,
+	hZoom int64,
+	vZoom int64,
+	isPrecision bool,
+	factor float64,
+) *Envelope {
+	maxRadius := math.Max(
+		math.Max(startRadius.Horizontal, startRadius.Vertical),
+		math.Max(endRadius.Horizontal, endRadius.Vertical),
+	)
+	minRadius := math.Min(
+		math.Min(startRadius.Horizontal, startRadius.Vertical),
+		math.Min(endRadius.Horizontal, endRadius.Vertical),
+	)
+
+	capsule := new(Capsule)
+	capsule.Rectangular = NewRectangular(startPoint, endPoint, maxRadius, hZoom, vZoom, factor)
+	// 始点、終点は回転楕円体状とし、円柱の内部を埋める処理は行わない
+	capsule.isCapsule = true
+	capsule.isSphere = startPoint.IsClose(endPoint, consts.Minima)
+	capsule.isPrecision = isPrecision
+	capsule.object = physics.NewEnvelopePhysics(
+		startPoint,
+		endPoint,
+		startRadius.Horizontal*factor,
+		startRadius.Vertical*factor,
+		endRadius.Horizontal*factor,
+		endRadius.Vertical*factor,
+	)
+
+	return &Envelope{
+		Capsule:     capsule,
+		maxRadius:   maxRadius,
+		minRadius:   minRadius,
+		startRadius: startRadius,
+		endRadius:   endRadius,
+	}
+}
+
+// CalcValidSpatialIDs 有効な空間ID取得
+//
+// 始点・終点間の軸の空間IDから半径が変化する管の有効な空間IDを取得
+//
+// 戻り値：
+//
+//	最終的にオブジェクトと衝突すると判定した空間ID
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 精度閾値超過： 水平方向精度、または垂直方向精度に 0 ～ 35 の整数値以外が入力されていた場合。
+func (e *Envelope) CalcValidSpatialIDs() ([]string, error) {
+	endSegment := startStage(e.observer, StageSegment, e.segment)
+
+	// 始点・終点間の軸の空間IDを取得
+	endStage := startStage(e.observer, StageLine, e.segment)
+	lineSpatialIDs, insideLineSpatialIDs, err := e.calcLineSpatialIDs()
+	endStage(StageResult{Count: len(lineSpatialIDs)})
+	if err != nil {
+		endSegment(StageResult{})
+		return []string{}, err
+	}
+
+	// 【直交座標空間】単位ボクセル
+	unitVoxel := e.calcBaseUnitVoxel()
+	debugLog(
+		e.logger,
+		"半径が変化する管",
+		slog.Float64("maxRadius", e.maxRadius),
+		slog.Float64("minRadius", e.minRadius),
+	)
+
+	// 最大の半径で外接する直方体の空間IDを全空間IDとして取得
+	endStage = startStage(e.observer, StageCandidate, e.segment)
+	e.calcAllSpatialIDs(lineSpatialIDs, unitVoxel)
+	endStage(StageResult{Count: len(e.allSpatialIDs)})
+
+	// 衝突判定実施オプションがfalseの場合は衝突判定をスキップ
+	if !e.isPrecision {
+		endSegment(StageResult{Count: len(e.allSpatialIDs)})
+		return e.allSpatialIDs, nil
+	}
+
+	// 最小の半径で内接する直方体の空間IDを内部空間IDとして取得
+	endStage = startStage(e.observer, StageInclude, e.segment)
+	e.radius = e.minRadius
+	e.calcIncludeSpatialIDs(insideLineSpatialIDs, unitVoxel)
+	e.radius = e.maxRadius
+	endStage(StageResult{Count: len(e.includeSpatialIDs)})
+
+	// 全空間IDから内部空間IDを除いた空間IDとオブジェクトで衝突判定
+	endStage = startStage(e.observer, StageCollision, e.segment)
+	tests := e.calcCollideSpatialIDs()
+	endStage(StageResult{Count: len(e.includeSpatialIDs), Tests: tests})
+
+	endSegment(StageResult{Count: len(e.includeSpatialIDs)})
+	return e.includeSpatialIDs, nil
+}
@@ -0,0 +1,216 @@
+package shape
+
+import (
+	"math"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+	"github.com/trajectoryjp/spatial_id_go/common/object"
+)
+
+// TestCalcUncertainties01 正常系・異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0), (139.76, 35.68, 100.0), (139.76, 35.68, 200.0)
+//   - 不確かさ：{1, 2}, {0, 0}, {3, 1}、増加率：{0.01, 0.001}
+//   - 異常系パターン1：不確かさの数が接続点の数と一致しない
+//   - 異常系パターン2：増加率が負
+//   - 異常系パターン3：不確かさが負
+//
+// + 確認内容
+//   - 接続点の不確かさに始点からの距離と増加率の積が加算されること
+//   - 異常系は入力チェックエラーとなること
+func TestCalcUncertainties01(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.75, 35.68, 100.0)
+	p2, _ := object.NewPoint(139.76, 35.68, 100.0)
+	p3, _ := object.NewPoint(139.76, 35.68, 200.0)
+	center := []*object.Point{p1, p2, p3}
+	growth := Uncertainty{Horizontal: 0.01, Vertical: 0.001}
+
+	// 期待値
+	d, _, _ := geodesicInverse(p1.Lon(), p1.Lat(), p2.Lon(), p2.Lat())
+	expectVal := []Uncertainty{
+		{Horizontal: 1, Vertical: 2},
+		{Horizontal: 0.01 * d, Vertical: 0.001 * d},
+		{Horizontal: 3 + 0.01*(d+100), Vertical: 1 + 0.001*(d+100)},
+	}
+
+	// テスト対象呼び出し
+	resultVal, err := calcUncertainties(center, []Uncertainty{{1, 2}, {0, 0}, {3, 1}}, growth)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	for i, result := range resultVal {
+		if math.Abs(result.Horizontal-expectVal[i].Horizontal) > 1e-9 ||
+			math.Abs(result.Vertical-expectVal[i].Vertical) > 1e-9 {
+			t.Errorf("接続点%d - 期待値：%v, 取得値：%v", i, expectVal[i], result)
+		}
+	}
+
+	errorInputs := []struct {
+		uncertainties []Uncertainty
+		growth        Uncertainty
+	}{
+		{[]Uncertainty{{1, 1}}, Uncertainty{}},
+		{nil, Uncertainty{Horizontal: -0.01}},
+		{[]Uncertainty{{1, 1}, {-1, 1}, {1, 1}}, Uncertainty{}},
+	}
+	for i, input := range errorInputs {
+		// テスト対象呼び出し
+		if _, err := calcUncertainties(center, input.uncertainties, input.growth); err == nil {
+			t.Errorf("異常系パターン%d - 期待値：入力チェックエラー, 取得値：nil", i+1)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestInterpolateUncertainties01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 分割前の接続点：a, b, c、不確かさ：{0, 0}, {3, 6}, {1, 1}
+//   - 分割後の接続点：a, x, y, b, c
+//
+// + 確認内容
+//   - 分割した接続点の不確かさが接続点の順番により線形補間されること
+func TestInterpolateUncertainties01(t *testing.T) {
+	//入力パラメータ
+	a, _ := object.NewPoint(139.75, 35.68, 100.0)
+	x, _ := object.NewPoint(139.751, 35.68, 100.0)
+	y, _ := object.NewPoint(139.752, 35.68, 100.0)
+	b, _ := object.NewPoint(139.753, 35.68, 100.0)
+	c, _ := object.NewPoint(139.753, 35.69, 100.0)
+
+	// 期待値
+	expectVal := []Uncertainty{{0, 0}, {1, 2}, {2, 4}, {3, 6}, {1, 1}}
+
+	// テスト対象呼び出し
+	resultVal := interpolateUncertainties(
+		[]*object.Point{a, b, c},
+		[]Uncertainty{{0, 0}, {3, 6}, {1, 1}},
+		[]*object.Point{a, x, y, b, c},
+	)
+
+	if len(resultVal) != len(expectVal) {
+		t.Fatalf("期待値：%v, 取得値：%v", expectVal, resultVal)
+	}
+	for i, result := range resultVal {
+		if math.Abs(result.Horizontal-expectVal[i].Horizontal) > 1e-9 ||
+			math.Abs(result.Vertical-expectVal[i].Vertical) > 1e-9 {
+			t.Errorf("接続点%d - 期待値：%v, 取得値：%v", i, expectVal[i], result)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestPositionUncertainty01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0), (139.76, 35.68, 100.0)(東向き約900m)
+//   - 半径：5.0、水平精度：22、垂直精度：22
+//   - パターン1：不確かさ 始点{0, 0}、終点{60, 20}
+//   - パターン2：増加率{0.05, 0.02}
+//   - 衝突判定あり、なし
+//
+// + 確認内容
+//   - 終点の北40mの地点、終点の上15mの地点の空間IDが含まれること
+//   - 衝突判定ありの場合、始点の北40mの地点、始点の上15mの地点の空間IDが含まれないこと
+//   - 不確かさを指定しない場合は終点の北40mの地点の空間IDが含まれないこと
+func TestPositionUncertainty01(t *testing.T) {
+	//入力パラメータ
+	start, _ := object.NewPoint(139.75, 35.68, 100.0)
+	end, _ := object.NewPoint(139.76, 35.68, 100.0)
+	center := []*object.Point{start, end}
+	opts := []option{
+		PositionUncertainty([]Uncertainty{{0, 0}, {60, 20}}),
+		UncertaintyGrowth(Uncertainty{Horizontal: 0.05, Vertical: 0.02}),
+	}
+
+	// 期待値
+	includeIDs := []string{
+		idAtDistance(end.Lon(), end.Lat(), 100.0, 0, 40, 22),
+		idAtDistance(end.Lon(), end.Lat(), 115.0, 0, 0, 22),
+	}
+	excludeIDs := []string{
+		idAtDistance(start.Lon(), start.Lat(), 100.0, 0, 40, 22),
+		idAtDistance(start.Lon(), start.Lat(), 115.0, 0, 0, 22),
+	}
+
+	for i, opt := range opts {
+		for _, isPrecision := range []bool{true, false} {
+			// テスト対象呼び出し
+			resultVal, err := GetExtendedSpatialIdsOnCylinders(center, 5.0, 22, 22, false, opt, IsPrecision(isPrecision))
+			if err != nil {
+				t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+			}
+
+			for _, id := range includeIDs {
+				if !common.Include(resultVal, id) {
+					t.Errorf("パターン%d 衝突判定%v - 期待値：%v を含む, 取得値：%v", i+1, isPrecision, id, resultVal)
+				}
+			}
+			for _, id := range excludeIDs {
+				if isPrecision && common.Include(resultVal, id) {
+					t.Errorf("パターン%d 衝突判定%v - 期待値：%v を含まない, 取得値：%v", i+1, isPrecision, id, resultVal)
+				}
+			}
+		}
+	}
+
+	plainVal, _ := GetExtendedSpatialIdsOnCylinders(center, 5.0, 22, 22, false)
+	if common.Include(plainVal, includeIDs[0]) {
+		t.Errorf("不確かさ未指定 - 期待値：%v を含まない, 取得値：%v", includeIDs[0], plainVal)
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestPositionUncertainty02 正常系・異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0)の1点、半径：5.0、不確かさ{30, 5}、水平精度：22、垂直精度：22
+//   - 異常系パターン1：接続点2点に対して不確かさ1点
+//   - 異常系パターン2：TurnRadiusと併用
+//
+// + 確認内容
+//   - 1点の場合、北20mの地点の空間IDが含まれ、北50mの地点の空間IDが含まれないこと
+//   - 異常系は入力チェックエラーとなること
+func TestPositionUncertainty02(t *testing.T) {
+	//入力パラメータ
+	p1, _ := object.NewPoint(139.75, 35.68, 100.0)
+	p2, _ := object.NewPoint(139.76, 35.68, 100.0)
+
+	// テスト対象呼び出し
+	resultVal, err := GetExtendedSpatialIdsOnCylinders(
+		[]*object.Point{p1}, 5.0, 22, 22, false, PositionUncertainty([]Uncertainty{{30, 5}}),
+	)
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	if id := idAtDistance(p1.Lon(), p1.Lat(), 100.0, 0, 20, 22); !common.Include(resultVal, id) {
+		t.Errorf("北20m - 期待値：%v を含む, 取得値：%v", id, resultVal)
+	}
+	if id := idAtDistance(p1.Lon(), p1.Lat(), 100.0, 0, 50, 22); common.Include(resultVal, id) {
+		t.Errorf("北50m - 期待値：%v を含まない, 取得値：%v", id, resultVal)
+	}
+
+	errorOpts := [][]option{
+		{PositionUncertainty([]Uncertainty{{30, 5}})},
+		{UncertaintyGrowth(Uncertainty{Horizontal: 0.01}), TurnRadius(100)},
+	}
+	for i, opts := range errorOpts {
+		// テスト対象呼び出し
+		resultVal, err := GetExtendedSpatialIdsOnCylinders([]*object.Point{p1, p2}, 5.0, 22, 22, false, opts...)
+		if err == nil || len(resultVal) != 0 {
+			t.Errorf("異常系パターン%d - 期待値：入力チェックエラー, 取得値：%v, %v", i+1, resultVal, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,162 @@
+package shape
+
+import (
+	"math"
+
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+	"github.com/trajectoryjp/spatial_id_go/shape"
+)
+
+// DownsampleMode 粗い精度に変換する際の拡張空間IDの選択方法
+type DownsampleMode int
+
+const (
+	DownsampleAnyTouch     DownsampleMode = iota // 変換前の拡張空間IDを一部でも含むボクセルを選択する(安全側)
+	DownsampleFullyCovered                       // 変換前の拡張空間IDで全体が覆われるボクセルのみを選択する
+)
+
+// maxConvertedSpatialIDs 精度変換で許容する変換後の拡張空間IDの数の最大値
+const maxConvertedSpatialIDs = 1 << 22
+
+// zoomRange 精度変換後の成分インデックスの範囲を取得
+//
+// 引数：
+//
+//	index： 変換前の成分インデックス
+//	from： 変換前の精度
+//	to： 変換後の精度
+//
+// 戻り値：
+//
+//	変換後の成分インデックスの最小値、最大値と、変換後のボクセルに占める変換前のボクセルの割合
+func zoomRange(index, from, to int64) (int64, int64, float64) {
+	if to >= from {
+		d := to - from
+		return index << d, (index+1)<<d - 1, 1
+	}
+	d := from - to
+	return index >> d, index >> d, math.Ldexp(1, -int(d))
+}
+
+// ConvertExtendedSpatialIdsZoom 拡張空間IDの精度変換
+//
+// 拡張空間IDの集合を指定の水平方向精度、垂直方向精度の拡張空間IDに変換する。
+// 細かい精度への変換は子ボクセルに分割し、粗い精度への変換は選択方法に応じて親ボクセルを選択する。
+// 水平方向、垂直方向で変換の向きが異なってもよい。
+// 細かい精度で一度取得した円柱等の拡張空間IDを、複数の精度で提供する際に使用する。
+// 重複する拡張空間ID、他の粗い精度の拡張空間IDに含まれる拡張空間IDは除いて変換する。
+// 全体が覆われるかの判定では、水平方向精度、垂直方向精度の一方のみが粗い拡張空間IDどうしの重なりも
+// 重複して数えない。
+// 細かい精度への変換で変換後の拡張空間IDの数がmaxConvertedSpatialIDsを超える場合はエラーとする。
+//
+// 引数：
+//
+//	spatialIDs： 拡張空間IDのリスト
+//	hZoom： 変換後の水平方向精度
+//	vZoom： 変換後の垂直方向精度
+//	mode： 粗い精度に変換する際の選択方法
+//
+// 戻り値：
+//
+//	変換後の拡張空間IDのリスト(昇順)
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力数値不正： 拡張空間IDのフォーマットが不正な場合、精度が 0 ～ 35 の整数値以外の場合、
+//	               選択方法が不正な場合、もしくは変換後の拡張空間IDの数がmaxConvertedSpatialIDsを超える場合
+func ConvertExtendedSpatialIdsZoom(
+	spatialIDs []string,
+	hZoom int64,
+	vZoom int64,
+	mode DownsampleMode,
+) ([]string, error) {
+	if !shape.CheckZoom(hZoom) || !shape.CheckZoom(vZoom) ||
+		(mode != DownsampleAnyTouch && mode != DownsampleFullyCovered) {
+		return []string{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "")
+	}
+	set, err := newVoxelSet(spatialIDs)
+	if err != nil {
+		return []string{}, err
+	}
+
+	// 変換後のボクセルを列挙する前に、変換後の拡張空間IDの数を見積もる
+	outermost := set.outermost()
+	count := 0.0
+	for _, index := range outermost {
+		minX, maxX, _ := zoomRange(index.x, index.hZoom, hZoom)
+		minY, maxY, _ := zoomRange(index.y, index.hZoom, hZoom)
+		minF, maxF, _ := zoomRange(index.f, index.vZoom, vZoom)
+		count += float64(maxX-minX+1) * float64(maxY-minY+1) * float64(maxF-minF+1)
+	}
+	if count > maxConvertedSpatialIDs {
+		return []string{}, errors.NewSpatialIdError(errors.InputValueErrorCode, "変換後の拡張空間IDの数が多すぎます")
+	}
+
+	// 変換前のボクセルを一部でも含む変換後のボクセルを選択
+	voxels := map[voxelIndex]bool{}
+	for _, index := range outermost {
+		minX, maxX, _ := zoomRange(index.x, index.hZoom, hZoom)
+		minY, maxY, _ := zoomRange(index.y, index.hZoom, hZoom)
+		minF, maxF, _ := zoomRange(index.f, index.vZoom, vZoom)
+		for x := minX; x <= maxX; x++ {
+			for y := minY; y <= maxY; y++ {
+				for f := minF; f <= maxF; f++ {
+					voxels[voxelIndex{hZoom: hZoom, x: x, y: y, vZoom: vZoom, f: f}] = true
+				}
+			}
+		}
+	}
+
+	if mode == DownsampleFullyCovered {
+		coverage := set.coverage(hZoom, vZoom)
+		for index := range voxels {
+			if coverage[index] < 1-1e-9 {
+				delete(voxels, index)
+			}
+		}
+	}
+	return voxelSetToSpatialIDs(voxels), nil
+}
+
+// coverage 変換後のボクセルごとの変換前のボクセルで覆われる割合の集計
+//
+// 水平方向のボクセルの柱ごとに、水平方向に含まれる細かい精度の柱を除いた割合と
+// 高さ方向の範囲の和集合の割合を掛け合わせて集計する。
+//
+// 引数：
+//
+//	hZoom： 変換後の水平方向精度
+//	vZoom： 変換後の垂直方向精度
+//
+// 戻り値：
+//
+//	変換後のボクセルごとの覆われる割合
+func (s *voxelSet) coverage(hZoom, vZoom int64) map[voxelIndex]float64 {
+	columnVZoom := max(s.maxVZoom(), vZoom)
+	shift := columnVZoom - vZoom
+	coverage := map[voxelIndex]float64{}
+	for _, column := range s.newVoxelColumns(columnVZoom) {
+		for i, index := range append([]voxelIndex{column.index}, column.children...) {
+			sign := 1.0
+			if i > 0 {
+				sign = -1
+			}
+			minX, maxX, xRatio := zoomRange(index.x, index.hZoom, hZoom)
+			minY, maxY, yRatio := zoomRange(index.y, index.hZoom, hZoom)
+			for _, interval := range column.intervals {
+				for f := interval[0] >> shift; f <= interval[1]>>shift; f++ {
+					// 変換後の高さ成分の範囲のうち、柱の高さ方向の範囲と重なる割合
+					length := min(interval[1], (f+1)<<shift-1) - max(interval[0], f<<shift) + 1
+					ratio := sign * xRatio * yRatio * math.Ldexp(float64(length), -int(shift))
+					for x := minX; x <= maxX; x++ {
+						for y := minY; y <= maxY; y++ {
+							coverage[voxelIndex{hZoom: hZoom, x: x, y: y, vZoom: vZoom, f: f}] += ratio
+						}
+					}
+				}
+			}
+		}
+	}
+	return coverage
+}
@@ -0,0 +1,215 @@
+package shape
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/trajectoryjp/spatial_id_go/common"
+)
+
+// TestConvertExtendedSpatialIdsZoom01 正常系動作確認(細かい精度への変換)
+//
+// 試験詳細：
+// + 試験データ
+//   - 拡張空間ID：20/931339/412910/20/3
+//   - パターン1：水平精度21、垂直精度21
+//   - パターン2：水平精度21、垂直精度20
+//   - パターン3：水平精度21、垂直精度19
+//
+// + 確認内容
+//   - パターン1：8個の子ボクセルに変換されること
+//   - パターン2：水平方向の4個の子ボクセルに変換されること
+//   - パターン3：水平方向の4個の子ボクセルで、高さ成分が親ボクセルの成分となること
+func TestConvertExtendedSpatialIdsZoom01(t *testing.T) {
+	//入力パラメータ
+	spatialID := "20/931339/412910/20/3"
+	index, _ := parseVoxelIndex(spatialID)
+	children := []string{}
+	for _, child := range index.children() {
+		children = append(children, child.String())
+	}
+	sort.Strings(children)
+	inputs := []struct {
+		hZoom  int64
+		vZoom  int64
+		expect []string
+	}{
+		{21, 21, children},
+		{21, 20, []string{
+			"21/1862678/825820/20/3", "21/1862678/825821/20/3", "21/1862679/825820/20/3", "21/1862679/825821/20/3",
+		}},
+		{21, 19, []string{
+			"21/1862678/825820/19/1", "21/1862678/825821/19/1", "21/1862679/825820/19/1", "21/1862679/825821/19/1",
+		}},
+	}
+
+	for i, input := range inputs {
+		for _, mode := range []DownsampleMode{DownsampleAnyTouch, DownsampleFullyCovered} {
+			// テスト対象呼び出し
+			resultVal, err := ConvertExtendedSpatialIdsZoom([]string{spatialID}, input.hZoom, input.vZoom, mode)
+			if err != nil {
+				t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+			}
+
+			// パターン3の垂直方向は半分のみ覆うため、全体が覆われるボクセルは無い
+			expectVal := input.expect
+			if i == 2 && mode == DownsampleFullyCovered {
+				expectVal = []string{}
+			}
+			if !reflect.DeepEqual(expectVal, resultVal) {
+				t.Errorf("パターン%d(選択方法%d) - 期待値：%v, 取得値：%v", i+1, mode, expectVal, resultVal)
+			}
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestConvertExtendedSpatialIdsZoom02 正常系動作確認(粗い精度への変換)
+//
+// 試験詳細：
+// + 試験データ
+//   - 親ボクセル：20/931339/412910/20/3
+//   - パターン1：8個の子ボクセル
+//   - パターン2：7個の子ボクセル
+//   - パターン3：7個の子ボクセルと、残り1個の子ボクセルの精度22の8個の子ボクセル
+//   - パターン4：8個の子ボクセルと、東に面で接するボクセルの子ボクセル1個
+//   - パターン5：垂直方向精度21の上半分のボクセルと、水平方向精度21の西側の2個の柱(上半分で重なる)
+//   - パターン6：垂直方向精度21の上半分のボクセルと、水平方向精度21の4個の柱(上半分で重なる)
+//
+// + 確認内容
+//   - 一部でも含むボクセルを選択する場合：パターン1～3、5、6は親ボクセル、パターン4は親ボクセルと東のボクセルとなること
+//   - 全体が覆われるボクセルを選択する場合：パターン1、3、4、6は親ボクセル、パターン2、5は無しとなること
+func TestConvertExtendedSpatialIdsZoom02(t *testing.T) {
+	//入力パラメータ
+	parent := "20/931339/412910/20/3"
+	east := "20/931340/412910/20/3"
+	index, _ := parseVoxelIndex(parent)
+	eastIndex, _ := parseVoxelIndex(east)
+	children := []string{}
+	for _, child := range index.children() {
+		children = append(children, child.String())
+	}
+	grandchildren := []string{}
+	for _, child := range index.children()[7].children() {
+		grandchildren = append(grandchildren, child.String())
+	}
+	upper := "20/931339/412910/21/7"
+	columns := []string{}
+	for _, child := range index.children() {
+		if child.f == 6 {
+			child.vZoom, child.f = 20, 3
+			columns = append(columns, child.String())
+		}
+	}
+	inputs := []struct {
+		spatialIDs   []string
+		anyTouch     []string
+		fullyCovered []string
+	}{
+		{children, []string{parent}, []string{parent}},
+		{children[:7], []string{parent}, []string{}},
+		{append(append([]string{}, children[:7]...), grandchildren...), []string{parent}, []string{parent}},
+		{append(append([]string{}, children...), eastIndex.children()[0].String()), []string{parent, east}, []string{parent}},
+		{append([]string{upper}, columns[:2]...), []string{parent}, []string{}},
+		{append([]string{upper}, columns...), []string{parent}, []string{parent}},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		anyTouch, err := ConvertExtendedSpatialIdsZoom(input.spatialIDs, 20, 20, DownsampleAnyTouch)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+		fullyCovered, err := ConvertExtendedSpatialIdsZoom(input.spatialIDs, 20, 20, DownsampleFullyCovered)
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		if !reflect.DeepEqual(input.anyTouch, anyTouch) {
+			t.Errorf("パターン%d 一部でも含む - 期待値：%v, 取得値：%v", i+1, input.anyTouch, anyTouch)
+		}
+		if !reflect.DeepEqual(input.fullyCovered, fullyCovered) {
+			t.Errorf("パターン%d 全体が覆われる - 期待値：%v, 取得値：%v", i+1, input.fullyCovered, fullyCovered)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestConvertExtendedSpatialIdsZoom03 正常系動作確認(円柱の拡張空間ID)
+//
+// 試験詳細：
+// + 試験データ
+//   - 接続点：(139.75, 35.68, 100.0), (139.76, 35.68, 100.0), (139.76, 35.69, 100.0)
+//   - 半径：10.0、精度：20の円柱の拡張空間ID
+//   - 精度18に変換し、精度20に戻す
+//
+// + 確認内容
+//   - 一部でも含むボクセルを選択した場合、元の拡張空間IDを全て含むこと
+//   - 全体が覆われるボクセルを選択した場合、元の拡張空間IDに全て含まれること
+func TestConvertExtendedSpatialIdsZoom03(t *testing.T) {
+	//入力パラメータ
+	spatialIDs, _ := GetExtendedSpatialIdsOnCylinders(turnRoute(), 10.0, 20, 20, false)
+
+	for _, mode := range []DownsampleMode{DownsampleAnyTouch, DownsampleFullyCovered} {
+		// テスト対象呼び出し
+		coarse, err := ConvertExtendedSpatialIdsZoom(spatialIDs, 18, 18, mode)
+		if err != nil {
+			t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+		}
+		fine, err := ConvertExtendedSpatialIdsZoom(coarse, 20, 20, mode)
+		if err != nil {
+			t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+		}
+
+		if mode == DownsampleAnyTouch {
+			if missing := common.Difference(spatialIDs, fine); len(missing) != 0 || len(coarse) == 0 {
+				t.Errorf("一部でも含む - 含まれない拡張空間ID：%v", missing)
+			}
+		} else {
+			if extra := common.Difference(fine, spatialIDs); len(extra) != 0 {
+				t.Errorf("全体が覆われる - 元に無い拡張空間ID：%v", extra)
+			}
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestConvertExtendedSpatialIdsZoom04 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：変換後の水平精度が36
+//   - パターン2：選択方法が不正
+//   - パターン3：拡張空間IDのフォーマットが不正
+//   - パターン4：0/0/0/0/0を水平精度35に変換(変換後の拡張空間IDの数がmaxConvertedSpatialIDsを超える)
+//
+// + 確認内容
+//   - エラーが返却されること
+func TestConvertExtendedSpatialIdsZoom04(t *testing.T) {
+	//入力パラメータ
+	valid := []string{"20/931339/412910/20/3"}
+	inputs := []struct {
+		spatialIDs []string
+		hZoom      int64
+		mode       DownsampleMode
+	}{
+		{valid, 36, DownsampleAnyTouch},
+		{valid, 20, DownsampleMode(2)},
+		{[]string{"20/931339/412910/20"}, 20, DownsampleAnyTouch},
+		{[]string{"0/0/0/0/0"}, 35, DownsampleFullyCovered},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, err := ConvertExtendedSpatialIdsZoom(input.spatialIDs, input.hZoom, 20, input.mode)
+		if err == nil {
+			t.Errorf("パターン%d error - 期待値：エラー, 取得値：nil", i+1)
+		}
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,107 @@
+package terrain
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+)
+
+// ReadASCIIGrid ESRI ASCIIグリッド形式の数値標高モデル読込
+//
+// ヘッダはncols, nrows, xllcorner(またはxllcenter), yllcorner(またはyllcenter),
+// cellsize(またはdx, dy), NODATA_value(省略可)とし、経緯度で記述されたものとする。
+// ヘッダに続けて北の行から順に標高を記述する。
+//
+// 引数：
+//
+//	r： 読込元
+//
+// 戻り値：
+//
+//	読み込んだDEMオブジェクト
+//
+// 戻り値(エラー)：
+//
+//	ヘッダ、もしくは標高の記述が不正な場合、エラーインスタンスが返却される。
+func ReadASCIIGrid(r io.Reader) (*DEM, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	scanner.Split(bufio.ScanWords)
+
+	header := map[string]float64{}
+	var values []float64
+	for scanner.Scan() {
+		word := scanner.Text()
+		v, err := strconv.ParseFloat(word, 64)
+		if err == nil {
+			values = append(values, v)
+			break
+		}
+
+		// ヘッダのキーと値
+		key := strings.ToLower(word)
+		if !scanner.Scan() {
+			return nil, asciiGridError("ヘッダの値がありません: " + word)
+		}
+		if header[key], err = strconv.ParseFloat(scanner.Text(), 64); err != nil {
+			return nil, asciiGridError("ヘッダの値が不正です: " + word)
+		}
+	}
+
+	cols, rows := int(header["ncols"]), int(header["nrows"])
+	if cols <= 0 || rows <= 0 {
+		return nil, asciiGridError("ncols, nrowsが不正です")
+	}
+	cellLon, cellLat := header["cellsize"], header["cellsize"]
+	if _, ok := header["dx"]; ok {
+		cellLon, cellLat = header["dx"], header["dy"]
+	}
+
+	// 北西端のセルの中心
+	west, okX := header["xllcorner"]
+	south, okY := header["yllcorner"]
+	west, south = west+cellLon/2, south+cellLat/2
+	if v, ok := header["xllcenter"]; ok {
+		west, okX = v, true
+	}
+	if v, ok := header["yllcenter"]; ok {
+		south, okY = v, true
+	}
+	if !okX || !okY {
+		return nil, asciiGridError("xllcorner, yllcornerがありません")
+	}
+	north := south + cellLat*float64(rows-1)
+
+	nodata, hasNodata := header["nodata_value"]
+	for len(values) < cols*rows && scanner.Scan() {
+		v, err := strconv.ParseFloat(scanner.Text(), 64)
+		if err != nil {
+			return nil, asciiGridError("標高の値が不正です: " + scanner.Text())
+		}
+		values = append(values, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(values) != cols*rows {
+		return nil, asciiGridError("標高の数がncols×nrowsと一致しません")
+	}
+	if hasNodata {
+		for i, v := range values {
+			if v == nodata {
+				values[i] = math.NaN()
+			}
+		}
+	}
+
+	return NewDEM(west, north, cellLon, cellLat, cols, rows, values)
+}
+
+// asciiGridError ASCIIグリッド形式の入力チェックエラー生成
+func asciiGridError(detail string) error {
+	return errors.NewSpatialIdError(errors.InputValueErrorCode, "ASCIIグリッド: "+detail)
+}
@@ -0,0 +1,128 @@
+package terrain
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReadASCIIGrid01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：xllcorner, yllcorner, cellsize指定、NODATA_valueあり
+//   - パターン2：xllcenter, yllcenter, dx, dy指定
+//
+// + 確認内容
+//   - 北西端のセルの中心が正しく求められること
+//   - 欠損値が除外されること
+func TestReadASCIIGrid01(t *testing.T) {
+	//入力パラメータ
+	inputs := []string{
+		"ncols 2\nnrows 2\nxllcorner 139.0\nyllcorner 35.0\ncellsize 0.5\nNODATA_value -9999\n10 20\n30 -9999\n",
+		"NCOLS 2\nNROWS 2\nXLLCENTER 139.25\nYLLCENTER 35.25\nDX 0.5\nDY 0.5\n10 20 30 -9999\n",
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		dem, err := ReadASCIIGrid(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("パターン%d error - 期待値：nil, 取得値：%v", i+1, err)
+		}
+
+		// 期待値
+		expectNoData := i == 0
+		for _, c := range []struct {
+			lon, lat, expect float64
+		}{
+			{139.25, 35.75, 10},
+			{139.75, 35.75, 20},
+			{139.25, 35.25, 30},
+			{139.5, 35.75, 15},
+		} {
+			result, ok := dem.Elevation(c.lon, c.lat)
+			if !ok || math.Abs(result-c.expect) > 1e-9 {
+				t.Errorf("パターン%d 標高(%v, %v) - 期待値：%v, 取得値：%v, %v", i+1, c.lon, c.lat, c.expect, result, ok)
+			}
+		}
+		result, ok := dem.Elevation(139.75, 35.25)
+		if ok == expectNoData || (!expectNoData && result != -9999) {
+			t.Errorf("パターン%d 欠損値 - 期待値：%v, 取得値：%v, %v", i+1, !expectNoData, result, ok)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestReadASCIIGrid02 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：ncolsなし
+//   - パターン2：標高の数が不足
+//   - パターン3：標高が数値でない
+//   - パターン4：yllcornerなし
+//
+// + 確認内容
+//   - 入力チェックエラーとなること
+func TestReadASCIIGrid02(t *testing.T) {
+	//入力パラメータ
+	inputs := []string{
+		"nrows 1\nxllcorner 0\nyllcorner 0\ncellsize 1\n1\n",
+		"ncols 2\nnrows 2\nxllcorner 0\nyllcorner 0\ncellsize 1\n1 2 3\n",
+		"ncols 1\nnrows 2\nxllcorner 0\nyllcorner 0\ncellsize 1\n1 x\n",
+		"ncols 1\nnrows 1\nxllcorner 0\ncellsize 1\n1\n",
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, err := ReadASCIIGrid(strings.NewReader(input))
+
+		if err == nil || !strings.HasPrefix(err.Error(), "InputValueError") {
+			t.Errorf("パターン%d error - 期待値：入力チェックエラー, 取得値：%v", i+1, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestOpen01 正常系・異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：拡張子.ascのファイル
+//   - パターン2：拡張子.csvのファイル
+//   - パターン3：存在しないファイル
+//
+// + 確認内容
+//   - パターン1：ASCIIグリッド形式として読み込まれること
+//   - パターン2、3：エラーとなること
+func TestOpen01(t *testing.T) {
+	//入力パラメータ
+	dir := t.TempDir()
+	content := "ncols 1\nnrows 1\nxllcorner 139.0\nyllcorner 35.0\ncellsize 1\n42\n"
+	for _, name := range []string{"dem.asc", "dem.csv"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// テスト対象呼び出し
+	dem, err := Open(filepath.Join(dir, "dem.asc"))
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+	if result, ok := dem.Elevation(139.5, 35.5); !ok || result != 42 {
+		t.Errorf("標高 - 期待値：42, 取得値：%v, %v", result, ok)
+	}
+
+	for _, name := range []string{"dem.csv", "missing.tif"} {
+		if _, err := Open(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s error - 期待値：エラー, 取得値：nil", name)
+		}
+	}
+
+	t.Log("テスト終了")
+}
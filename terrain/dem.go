@@ -0,0 +1,187 @@
+// Package terrain 数値標高モデル(DEM)パッケージ
+//
+// 経緯度の格子状の標高データをファイルから読み込み、任意の地点の標高を補間して返却する。
+// 読み込み可能な形式は以下の通り。
+//   - ESRI ASCIIグリッド形式(.asc)
+//   - GeoTIFF形式(.tif, .tiff)。地理座標系(経緯度)、1バンド、非圧縮またはDeflate圧縮のもの
+//
+// 使用例：
+//
+//	dem, err := terrain.Open("dem.tif")
+//	...
+//	ids, err := shape.GetExtendedSpatialIdsOnCylinders(
+//		center, radius, hZoom, vZoom, false, shape.TerrainClip(dem),
+//	)
+package terrain
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+)
+
+// DEM 数値標高モデル構造体
+//
+// 格子点はセルの中心とし、北西端のセルから東方向、南方向の順に標高を保持する。
+// 欠損値はNaNとして保持する。
+type DEM struct {
+	west    float64   // 北西端のセルの中心の経度
+	north   float64   // 北西端のセルの中心の緯度
+	cellLon float64   // 経度方向のセルの大きさ(単位:度)
+	cellLat float64   // 緯度方向のセルの大きさ(単位:度)
+	cols    int       // 経度方向のセル数
+	rows    int       // 緯度方向のセル数
+	values  []float64 // 標高(単位:m)
+}
+
+// NewDEM 数値標高モデル初期化関数
+//
+// 引数：
+//
+//	west： 北西端のセルの中心の経度
+//	north： 北西端のセルの中心の緯度
+//	cellLon： 経度方向のセルの大きさ(単位:度)
+//	cellLat： 緯度方向のセルの大きさ(単位:度)
+//	cols： 経度方向のセル数
+//	rows： 緯度方向のセル数
+//	values： 北西端のセルから東方向、南方向の順に並べた標高(単位:m)。欠損値はNaN
+//
+// 戻り値：
+//
+//	初期化したDEMオブジェクト
+//
+// 戻り値(エラー)：
+//
+//	以下の条件に当てはまる場合、エラーインスタンスが返却される。
+//	 入力値不正： セルの大きさが0以下、セル数が0以下、もしくは標高の数がセル数と一致しない場合
+func NewDEM(west, north, cellLon, cellLat float64, cols, rows int, values []float64) (*DEM, error) {
+	if !(cellLon > 0) || !(cellLat > 0) || cols <= 0 || rows <= 0 || len(values) != cols*rows {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "DEM: 格子の定義が不正です")
+	}
+	return &DEM{
+		west:    west,
+		north:   north,
+		cellLon: cellLon,
+		cellLat: cellLat,
+		cols:    cols,
+		rows:    rows,
+		values:  values,
+	}, nil
+}
+
+// Open 数値標高モデルファイル読込
+//
+// 拡張子で形式を判定する。
+//   - .asc: ESRI ASCIIグリッド形式
+//   - .tif, .tiff: GeoTIFF形式
+//
+// 引数：
+//
+//	path： ファイルのパス
+//
+// 戻り値：
+//
+//	読み込んだDEMオブジェクト
+//
+// 戻り値(エラー)：
+//
+//	ファイルが読み込めない場合、拡張子が未対応の場合、もしくは形式が不正な場合、エラーインスタンスが返却される。
+func Open(path string) (*DEM, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".asc" && ext != ".tif" && ext != ".tiff" {
+		return nil, errors.NewSpatialIdError(errors.InputValueErrorCode, "DEM: 未対応の拡張子です: "+ext)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if ext == ".asc" {
+		return ReadASCIIGrid(file)
+	}
+	return ReadGeoTIFF(file)
+}
+
+// Bounds 範囲取得
+//
+// 戻り値：
+//
+//	標高を取得可能な範囲の西端、南端、東端、北端の経緯度
+func (d *DEM) Bounds() (west, south, east, north float64) {
+	west = d.west - d.cellLon/2
+	north = d.north + d.cellLat/2
+	east = west + d.cellLon*float64(d.cols)
+	south = north - d.cellLat*float64(d.rows)
+	return west, south, east, north
+}
+
+// Elevation 標高取得
+//
+// 周囲4つのセルの標高を双線形補間して返却する。
+// 周囲のセルに欠損値を含む場合は、欠損値以外のセルで補間する。
+//
+// 引数：
+//
+//	lon： 経度
+//	lat： 緯度
+//
+// 戻り値：
+//
+//	標高(単位:m)
+//	標高を取得できたか。範囲外の場合、もしくは周囲のセルが全て欠損値の場合はfalse
+func (d *DEM) Elevation(lon, lat float64) (float64, bool) {
+	west, south, east, north := d.Bounds()
+	if !(lon >= west && lon <= east && lat >= south && lat <= north) {
+		return 0, false
+	}
+
+	// セルの中心を整数とした格子上の位置
+	fx := clamp(snap((lon-d.west)/d.cellLon), float64(d.cols-1))
+	fy := clamp(snap((d.north-lat)/d.cellLat), float64(d.rows-1))
+	x0, y0 := int(fx), int(fy)
+	x1, y1 := min(x0+1, d.cols-1), min(y0+1, d.rows-1)
+	tx, ty := fx-float64(x0), fy-float64(y0)
+
+	sum, weight := 0.0, 0.0
+	for _, c := range [4]struct {
+		x, y int
+		w    float64
+	}{
+		{x0, y0, (1 - tx) * (1 - ty)},
+		{x1, y0, tx * (1 - ty)},
+		{x0, y1, (1 - tx) * ty},
+		{x1, y1, tx * ty},
+	} {
+		v := d.values[c.y*d.cols+c.x]
+		if math.IsNaN(v) || c.w == 0 {
+			continue
+		}
+		sum += v * c.w
+		weight += c.w
+	}
+
+	if weight == 0 {
+		return 0, false
+	}
+	return sum / weight, true
+}
+
+// snap 丸め誤差の除去
+//
+// セルの中心を指定した場合に隣接するセルの重みが残らないよう、整数に十分近い値を整数とする。
+func snap(v float64) float64 {
+	if r := math.Round(v); math.Abs(v-r) < 1e-9 {
+		return r
+	}
+	return v
+}
+
+// clamp 0からupperの範囲への丸め
+func clamp(v, upper float64) float64 {
+	return math.Min(math.Max(v, 0), upper)
+}
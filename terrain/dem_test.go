@@ -0,0 +1,81 @@
+package terrain
+
+import (
+	"math"
+	"testing"
+)
+
+// TestNewDEM01 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：セルの大きさが0
+//   - パターン2：セル数が0
+//   - パターン3：標高の数がセル数と不一致
+//
+// + 確認内容
+//   - 入力チェックエラーとなること
+func TestNewDEM01(t *testing.T) {
+	//入力パラメータ
+	inputs := []struct {
+		cellLon, cellLat float64
+		cols, rows       int
+		values           []float64
+	}{
+		{0, 1, 1, 1, []float64{0}},
+		{1, 1, 0, 1, []float64{}},
+		{1, 1, 2, 2, []float64{0, 0, 0}},
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, err := NewDEM(139.0, 36.0, input.cellLon, input.cellLat, input.cols, input.rows, input.values)
+
+		if err == nil {
+			t.Errorf("パターン%d error - 期待値：入力チェックエラー, 取得値：nil", i+1)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestElevation01 正常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - 2×2セル、セルの大きさ1度、北西端のセルの中心(139.5, 36.5)、南東端のセルは欠損値
+//
+// + 確認内容
+//   - セルの中心で各セルの標高が取得されること
+//   - セルの間は欠損値以外のセルで補間されること
+//   - 外周のセルの外側半分は外周のセルの標高となること
+//   - 範囲外、欠損値のセルの中心は取得できないこと
+func TestElevation01(t *testing.T) {
+	//入力パラメータ
+	dem, _ := NewDEM(139.5, 36.5, 1, 1, 2, 2, []float64{10, 20, 30, math.NaN()})
+
+	for _, c := range []struct {
+		lon, lat, expect float64
+		ok               bool
+	}{
+		{139.5, 36.5, 10, true},
+		{140.5, 36.5, 20, true},
+		{139.5, 35.5, 30, true},
+		{140.0, 36.5, 15, true},
+		{140.0, 36.0, 20, true},
+		{139.0, 37.0, 10, true},
+		{141.0, 36.7, 20, true},
+		{140.5, 35.5, 0, false},
+		{138.9, 36.5, 0, false},
+		{139.5, 37.1, 0, false},
+	} {
+		// テスト対象呼び出し
+		result, ok := dem.Elevation(c.lon, c.lat)
+
+		if ok != c.ok || math.Abs(result-c.expect) > 1e-9 {
+			t.Errorf("標高(%v, %v) - 期待値：%v, %v, 取得値：%v, %v", c.lon, c.lat, c.expect, c.ok, result, ok)
+		}
+	}
+
+	t.Log("テスト終了")
+}
@@ -0,0 +1,691 @@
+package terrain
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/trajectoryjp/spatial_id_go/common/errors"
+)
+
+// GeoTIFFのタグ
+const (
+	tagImageWidth       = 256
+	tagImageLength      = 257
+	tagBitsPerSample    = 258
+	tagCompression      = 259
+	tagStripOffsets     = 273
+	tagSamplesPerPixel  = 277
+	tagRowsPerStrip     = 278
+	tagStripByteCounts  = 279
+	tagPredictor        = 317
+	tagTileWidth        = 322
+	tagTileLength       = 323
+	tagTileOffsets      = 324
+	tagTileByteCounts   = 325
+	tagSampleFormat     = 339
+	tagModelPixelScale  = 33550
+	tagModelTiepoint    = 33922
+	tagGeoKeyDirectory  = 34735
+	tagGDALNoData       = 42113
+	keyGTModelType      = 1024 // 座標系の種類のGeoKey
+	keyGTRasterType     = 1025 // 画素の座標の意味のGeoKey
+	modelTypeProjected  = 1    // 投影座標系
+	rasterPixelIsPoint  = 2    // 画素の座標が画素の中心を示す
+	compressionNone     = 1    // 非圧縮
+	compressionDeflate  = 8    // Deflate圧縮
+	compressionDeflate2 = 32946
+	predictorNone       = 1 // 予測なし
+	predictorHorizontal = 2 // 水平差分
+	sampleFormatUint    = 1 // 符号なし整数
+	sampleFormatInt     = 2 // 符号付き整数
+	sampleFormatFloat   = 3 // 浮動小数点数
+)
+
+// maxGeoTIFFPixels 読み込む画素数の上限
+const maxGeoTIFFPixels = 1 << 26
+
+// TIFFのフィールド型ごとの1要素のバイト数
+var tiffTypeSizes = map[uint16]uint32{
+	1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8,
+}
+
+// tiffEntry TIFFのIFDエントリ
+type tiffEntry struct {
+	typ   uint16  // フィールド型
+	count uint32  // 要素数
+	value [4]byte // 値、または値の位置
+}
+
+// tiffReader TIFFの読込構造体
+type tiffReader struct {
+	r       io.ReaderAt          // 読込元
+	size    uint64               // 読込元の大きさ(単位:byte)
+	order   binary.ByteOrder     // バイトオーダー
+	entries map[uint16]tiffEntry // 先頭のIFDのエントリ
+}
+
+// ReadGeoTIFF GeoTIFF形式の数値標高モデル読込
+//
+// 以下の条件を満たすGeoTIFFを読み込む。
+//   - 地理座標系(経緯度)で、ModelTiepoint、ModelPixelScaleで位置が定義されていること
+//   - 1バンドで、8/16/32ビット整数、もしくは32/64ビット浮動小数点数であること
+//   - 非圧縮、もしくはDeflate圧縮(水平差分予測を含む)であること
+//   - 画素数が2^26以下であること
+//
+// GDAL_NODATAタグに一致する値は欠損値とする。
+//
+// 引数：
+//
+//	r： 読込元
+//
+// 戻り値：
+//
+//	読み込んだDEMオブジェクト
+//
+// 戻り値(エラー)：
+//
+//	読込に失敗した場合、もしくは上記の条件を満たさない場合、エラーインスタンスが返却される。
+func ReadGeoTIFF(r io.ReaderAt) (*DEM, error) {
+	t, err := newTIFFReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	width, err := t.uint(tagImageWidth, 0)
+	if err != nil {
+		return nil, err
+	}
+	height, err := t.uint(tagImageLength, 0)
+	if err != nil {
+		return nil, err
+	}
+	if width == 0 || height == 0 {
+		return nil, geoTIFFError("画像の大きさがありません")
+	}
+	// 上限以下の幅、高さの積はオーバーフローしない
+	if width > maxGeoTIFFPixels || height > maxGeoTIFFPixels || width*height > maxGeoTIFFPixels {
+		return nil, geoTIFFError("画像の画素数が上限を超えています")
+	}
+
+	values, err := t.readValues(int(width), int(height))
+	if err != nil {
+		return nil, err
+	}
+
+	if nodata, ok := t.entries[tagGDALNoData]; ok {
+		raw, err := t.data(nodata)
+		if err != nil {
+			return nil, err
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimRight(string(raw), "\x00")), 64)
+		if err != nil {
+			return nil, geoTIFFError("GDAL_NODATAが不正です")
+		}
+		for i := range values {
+			if values[i] == v {
+				values[i] = math.NaN()
+			}
+		}
+	}
+
+	west, north, cellLon, cellLat, err := t.readGeoreference()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDEM(west, north, cellLon, cellLat, int(width), int(height), values)
+}
+
+// newTIFFReader TIFFの読込構造体初期化関数
+//
+// ヘッダと先頭のIFDを読み込む。
+//
+// 引数：
+//
+//	r： 読込元
+//
+// 戻り値：
+//
+//	初期化したtiffReaderオブジェクト
+//
+// 戻り値(エラー)：
+//
+//	TIFF形式でない場合、もしくはBigTIFFの場合、エラーインスタンスが返却される。
+func newTIFFReader(r io.ReaderAt) (*tiffReader, error) {
+	header := make([]byte, 8)
+	if err := readFull(r, header, 0); err != nil {
+		return nil, geoTIFFError("ヘッダが読み込めません")
+	}
+
+	size, err := readerSize(r)
+	if err != nil {
+		return nil, geoTIFFError("大きさが取得できません")
+	}
+	t := &tiffReader{r: r, size: size, entries: map[uint16]tiffEntry{}}
+	switch string(header[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, geoTIFFError("TIFF形式ではありません")
+	}
+	if t.order.Uint16(header[2:]) != 42 {
+		return nil, geoTIFFError("TIFF形式ではない、もしくはBigTIFFです")
+	}
+
+	offset := uint64(t.order.Uint32(header[4:]))
+	countBytes, err := t.readAt(offset, 2)
+	if err != nil {
+		return nil, geoTIFFError("IFDが読み込めません")
+	}
+	entryBytes, err := t.readAt(offset+2, 12*uint64(t.order.Uint16(countBytes)))
+	if err != nil {
+		return nil, geoTIFFError("IFDが読み込めません")
+	}
+	for i := 0; i < len(entryBytes); i += 12 {
+		e := tiffEntry{
+			typ:   t.order.Uint16(entryBytes[i+2:]),
+			count: t.order.Uint32(entryBytes[i+4:]),
+		}
+		copy(e.value[:], entryBytes[i+8:i+12])
+		t.entries[t.order.Uint16(entryBytes[i:])] = e
+	}
+
+	return t, nil
+}
+
+// data IFDエントリの値のバイト列取得
+//
+// 引数：
+//
+//	e： IFDエントリ
+//
+// 戻り値：
+//
+//	値のバイト列
+//
+// 戻り値(エラー)：
+//
+//	フィールド型が不明な場合、もしくは値が読み込めない場合、エラーインスタンスが返却される。
+func (t *tiffReader) data(e tiffEntry) ([]byte, error) {
+	size, ok := tiffTypeSizes[e.typ]
+	if !ok {
+		return nil, geoTIFFError("フィールド型が不明です")
+	}
+	length := uint64(size) * uint64(e.count)
+	if length <= 4 {
+		return e.value[:length], nil
+	}
+
+	raw, err := t.readAt(uint64(t.order.Uint32(e.value[:])), length)
+	if err != nil {
+		return nil, geoTIFFError("タグの値が読み込めません")
+	}
+	return raw, nil
+}
+
+// readAt 読込元の範囲内のバイト列読込
+//
+// 読込元の範囲外の位置、大きさは、バイト列を確保する前にエラーとする。
+//
+// 引数：
+//
+//	offset： 読込位置
+//	length： 読み込むバイト数
+//
+// 戻り値：
+//
+//	読み込んだバイト列
+//
+// 戻り値(エラー)：
+//
+//	読込元の範囲外の場合、もしくは読み込めない場合、エラーインスタンスが返却される。
+func (t *tiffReader) readAt(offset, length uint64) ([]byte, error) {
+	if offset > t.size || length > t.size-offset {
+		return nil, io.ErrUnexpectedEOF
+	}
+	buf := make([]byte, length)
+	if err := readFull(t.r, buf, int64(offset)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// floats タグの値の数値取得
+//
+// 引数：
+//
+//	tag： タグ
+//
+// 戻り値：
+//
+//	タグの値。タグがない場合はnil
+//
+// 戻り値(エラー)：
+//
+//	値が読み込めない場合、もしくは数値型でない場合、エラーインスタンスが返却される。
+func (t *tiffReader) floats(tag uint16) ([]float64, error) {
+	e, ok := t.entries[tag]
+	if !ok {
+		return nil, nil
+	}
+	raw, err := t.data(e)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]float64, e.count)
+	for i := range values {
+		switch e.typ {
+		case 1:
+			values[i] = float64(raw[i])
+		case 3:
+			values[i] = float64(t.order.Uint16(raw[2*i:]))
+		case 4:
+			values[i] = float64(t.order.Uint32(raw[4*i:]))
+		case 11:
+			values[i] = float64(math.Float32frombits(t.order.Uint32(raw[4*i:])))
+		case 12:
+			values[i] = math.Float64frombits(t.order.Uint64(raw[8*i:]))
+		default:
+			return nil, geoTIFFError("タグの値が数値ではありません")
+		}
+	}
+	return values, nil
+}
+
+// uints タグの値の整数取得
+//
+// 引数：
+//
+//	tag： タグ
+//
+// 戻り値：
+//
+//	タグの値。タグがない場合はnil
+//
+// 戻り値(エラー)：
+//
+//	値が読み込めない場合、エラーインスタンスが返却される。
+func (t *tiffReader) uints(tag uint16) ([]uint64, error) {
+	floats, err := t.floats(tag)
+	if err != nil || floats == nil {
+		return nil, err
+	}
+	values := make([]uint64, len(floats))
+	for i, v := range floats {
+		values[i] = uint64(v)
+	}
+	return values, nil
+}
+
+// uint タグの値の整数取得(1要素)
+//
+// 引数：
+//
+//	tag： タグ
+//	def： タグがない場合の値。0の場合はタグを必須とする
+//
+// 戻り値：
+//
+//	タグの先頭の値
+//
+// 戻り値(エラー)：
+//
+//	値が読み込めない場合、もしくは必須のタグがない場合、エラーインスタンスが返却される。
+func (t *tiffReader) uint(tag uint16, def uint64) (uint64, error) {
+	values, err := t.uints(tag)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		if def == 0 {
+			return 0, geoTIFFError("必須のタグがありません: " + strconv.Itoa(int(tag)))
+		}
+		return def, nil
+	}
+	return values[0], nil
+}
+
+// readValues 画素値の読込
+//
+// ストリップ、もしくはタイルごとに伸長して画素値を取得する。
+//
+// 引数：
+//
+//	width： 画像の幅
+//	height： 画像の高さ
+//
+// 戻り値：
+//
+//	北西端の画素から東方向、南方向の順に並べた画素値
+//
+// 戻り値(エラー)：
+//
+//	未対応の形式の場合、もしくは読込に失敗した場合、エラーインスタンスが返却される。
+func (t *tiffReader) readValues(width, height int) ([]float64, error) {
+	samples, err := t.uint(tagSamplesPerPixel, 1)
+	if err != nil {
+		return nil, err
+	}
+	bits, err := t.uint(tagBitsPerSample, 1)
+	if err != nil {
+		return nil, err
+	}
+	format, err := t.uint(tagSampleFormat, sampleFormatUint)
+	if err != nil {
+		return nil, err
+	}
+	compression, err := t.uint(tagCompression, compressionNone)
+	if err != nil {
+		return nil, err
+	}
+	predictor, err := t.uint(tagPredictor, predictorNone)
+	if err != nil {
+		return nil, err
+	}
+	decode, err := sampleDecoder(t.order, format, bits)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case samples != 1:
+		return nil, geoTIFFError("1バンドの画像のみ対応しています")
+	case compression != compressionNone && compression != compressionDeflate && compression != compressionDeflate2:
+		return nil, geoTIFFError("非圧縮、Deflate圧縮のみ対応しています")
+	case predictor != predictorNone && !(predictor == predictorHorizontal && format != sampleFormatFloat):
+		return nil, geoTIFFError("整数の水平差分予測のみ対応しています")
+	}
+
+	// ストリップはタイルの幅が画像の幅と等しいものとして扱う
+	blockWidth, blockHeight := uint64(width), uint64(height)
+	offsetTag, countTag := uint16(tagStripOffsets), uint16(tagStripByteCounts)
+	if _, ok := t.entries[tagTileOffsets]; ok {
+		if blockWidth, err = t.uint(tagTileWidth, 0); err != nil {
+			return nil, err
+		}
+		if blockHeight, err = t.uint(tagTileLength, 0); err != nil {
+			return nil, err
+		}
+		offsetTag, countTag = tagTileOffsets, tagTileByteCounts
+	} else if blockHeight, err = t.uint(tagRowsPerStrip, uint64(height)); err != nil {
+		return nil, err
+	}
+	blockHeight = min(blockHeight, uint64(height))
+	if blockWidth == 0 || blockHeight == 0 || blockWidth > maxGeoTIFFPixels || blockWidth*blockHeight > maxGeoTIFFPixels {
+		return nil, geoTIFFError("ストリップ、タイルの大きさが不正です")
+	}
+
+	offsets, err := t.uints(offsetTag)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := t.uints(countTag)
+	if err != nil {
+		return nil, err
+	}
+	across := (width + int(blockWidth) - 1) / int(blockWidth)
+	down := (height + int(blockHeight) - 1) / int(blockHeight)
+	if len(offsets) < across*down || len(counts) < len(offsets) {
+		return nil, geoTIFFError("ストリップ、タイルの位置が不正です")
+	}
+
+	// 画素値を確保する前に、ストリップ、タイルが読込元の範囲内であることを確認する
+	for b := 0; b < across*down; b++ {
+		if offsets[b] > t.size || counts[b] > t.size-offsets[b] {
+			return nil, geoTIFFError("画素値が読み込めません")
+		}
+	}
+
+	bytesPerSample := int(bits / 8)
+	rowBytes := int(blockWidth) * bytesPerSample
+	values := make([]float64, width*height)
+	for b := 0; b < across*down; b++ {
+		block, err := t.readAt(offsets[b], counts[b])
+		if err != nil {
+			return nil, geoTIFFError("画素値が読み込めません")
+		}
+		if compression != compressionNone {
+			if block, err = inflate(block, int64(rowBytes)*int64(blockHeight)); err != nil {
+				return nil, err
+			}
+		}
+
+		x0, y0 := (b%across)*int(blockWidth), (b/across)*int(blockHeight)
+		for y := 0; y < int(blockHeight) && y0+y < height; y++ {
+			if (y+1)*rowBytes > len(block) {
+				return nil, geoTIFFError("画素値の数が不足しています")
+			}
+			row := block[y*rowBytes : (y+1)*rowBytes]
+			if predictor == predictorHorizontal {
+				undoHorizontalPredictor(t.order, row, bytesPerSample)
+			}
+			for x := 0; x < int(blockWidth) && x0+x < width; x++ {
+				values[(y0+y)*width+x0+x] = decode(row[x*bytesPerSample:])
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// readGeoreference 位置情報の読込
+//
+// 戻り値：
+//
+//	北西端の画素の中心の経度、緯度
+//	経度方向、緯度方向の画素の大きさ(単位:度)
+//
+// 戻り値(エラー)：
+//
+//	位置情報がない場合、もしくは投影座標系の場合、エラーインスタンスが返却される。
+func (t *tiffReader) readGeoreference() (west, north, cellLon, cellLat float64, err error) {
+	scale, err := t.floats(tagModelPixelScale)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	tiepoint, err := t.floats(tagModelTiepoint)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if len(scale) < 2 || len(tiepoint) < 6 {
+		return 0, 0, 0, 0, geoTIFFError("ModelPixelScale、ModelTiepointがありません")
+	}
+
+	keys, err := t.uints(tagGeoKeyDirectory)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	pixelIsPoint := false
+	for i := 4; i+3 < len(keys); i += 4 {
+		switch {
+		case keys[i] == keyGTModelType && keys[i+3] == modelTypeProjected:
+			return 0, 0, 0, 0, geoTIFFError("地理座標系(経緯度)のみ対応しています")
+		case keys[i] == keyGTRasterType && keys[i+3] == rasterPixelIsPoint:
+			pixelIsPoint = true
+		}
+	}
+
+	// ModelTiepointの画像上の位置を北西端の画素の中心に換算
+	cellLon, cellLat = scale[0], scale[1]
+	offset := 0.5
+	if pixelIsPoint {
+		offset = 0
+	}
+	west = tiepoint[3] + (offset-tiepoint[0])*cellLon
+	north = tiepoint[4] - (offset-tiepoint[1])*cellLat
+	return west, north, cellLon, cellLat, nil
+}
+
+// sampleDecoder 画素値の変換関数取得
+//
+// 引数：
+//
+//	order： バイトオーダー
+//	format： SampleFormatの値
+//	bits： BitsPerSampleの値
+//
+// 戻り値：
+//
+//	画素値のバイト列を数値に変換する関数
+//
+// 戻り値(エラー)：
+//
+//	未対応の画素値の型の場合、エラーインスタンスが返却される。
+func sampleDecoder(order binary.ByteOrder, format, bits uint64) (func([]byte) float64, error) {
+	switch {
+	case format == sampleFormatUint && bits == 8:
+		return func(b []byte) float64 { return float64(b[0]) }, nil
+	case format == sampleFormatInt && bits == 8:
+		return func(b []byte) float64 { return float64(int8(b[0])) }, nil
+	case format == sampleFormatUint && bits == 16:
+		return func(b []byte) float64 { return float64(order.Uint16(b)) }, nil
+	case format == sampleFormatInt && bits == 16:
+		return func(b []byte) float64 { return float64(int16(order.Uint16(b))) }, nil
+	case format == sampleFormatUint && bits == 32:
+		return func(b []byte) float64 { return float64(order.Uint32(b)) }, nil
+	case format == sampleFormatInt && bits == 32:
+		return func(b []byte) float64 { return float64(int32(order.Uint32(b))) }, nil
+	case format == sampleFormatFloat && bits == 32:
+		return func(b []byte) float64 { return float64(math.Float32frombits(order.Uint32(b))) }, nil
+	case format == sampleFormatFloat && bits == 64:
+		return func(b []byte) float64 { return math.Float64frombits(order.Uint64(b)) }, nil
+	}
+	return nil, geoTIFFError("未対応の画素値の型です")
+}
+
+// undoHorizontalPredictor 水平差分予測の復元
+//
+// 1行分の画素値を、左隣の画素値との差分から画素値に戻す。
+//
+// 引数：
+//
+//	order： バイトオーダー
+//	row： 1行分の画素値のバイト列。復元した値で上書きする
+//	size： 1画素のバイト数
+func undoHorizontalPredictor(order binary.ByteOrder, row []byte, size int) {
+	for i := size; i+size <= len(row); i += size {
+		switch size {
+		case 1:
+			row[i] += row[i-1]
+		case 2:
+			order.PutUint16(row[i:], order.Uint16(row[i:])+order.Uint16(row[i-2:]))
+		case 4:
+			order.PutUint32(row[i:], order.Uint32(row[i:])+order.Uint32(row[i-4:]))
+		}
+	}
+}
+
+// inflate Deflate圧縮の伸長
+//
+// 引数：
+//
+//	block： 圧縮されたバイト列
+//
+// 戻り値：
+//
+//	伸長したバイト列。limitを超える部分は読み込まない
+//
+// 戻り値(エラー)：
+//
+//	伸長に失敗した場合、エラーインスタンスが返却される。
+func inflate(block []byte, limit int64) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(block))
+	if err != nil {
+		return nil, geoTIFFError("Deflate圧縮の伸長に失敗しました")
+	}
+	defer reader.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(reader, limit))
+	if err != nil {
+		return nil, geoTIFFError("Deflate圧縮の伸長に失敗しました")
+	}
+	return raw, nil
+}
+
+// readerSize 読込元の大きさ取得
+//
+// Size、Statを持たない読込元は、読み込める位置を探索して大きさを求める。
+//
+// 引数：
+//
+//	r： 読込元
+//
+// 戻り値：
+//
+//	読込元の大きさ(単位:byte)
+//
+// 戻り値(エラー)：
+//
+//	大きさが取得できない場合、エラーインスタンスが返却される。
+func readerSize(r io.ReaderAt) (uint64, error) {
+	switch v := r.(type) {
+	case interface{ Size() int64 }:
+		return uint64(max(v.Size(), 0)), nil
+	case interface{ Stat() (fs.FileInfo, error) }:
+		info, err := v.Stat()
+		if err != nil {
+			return 0, err
+		}
+		return uint64(max(info.Size(), 0)), nil
+	}
+
+	// n バイト目まで読み込めるかの判定
+	readable := func(n int64) bool {
+		return readFull(r, make([]byte, 1), n-1) == nil
+	}
+	low, high := int64(0), int64(1)
+	for readable(high) {
+		low = high
+		if high > math.MaxInt64/2 {
+			break
+		}
+		high *= 2
+	}
+	for low+1 < high {
+		middle := low + (high-low)/2
+		if readable(middle) {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return uint64(low), nil
+}
+
+// readFull 指定位置からのバイト列読込
+//
+// 末尾まで読み込んだ場合にio.EOFを返却する読込元があるため、読み込んだバイト数で判定する。
+//
+// 引数：
+//
+//	r： 読込元
+//	buf： 読込先。全体を読み込む
+//	offset： 読込位置
+//
+// 戻り値(エラー)：
+//
+//	bufの大きさ分読み込めなかった場合、エラーインスタンスが返却される。
+func readFull(r io.ReaderAt, buf []byte, offset int64) error {
+	n, err := r.ReadAt(buf, offset)
+	if n == len(buf) {
+		return nil
+	}
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// geoTIFFError GeoTIFF形式の入力チェックエラー生成
+func geoTIFFError(detail string) error {
+	return errors.NewSpatialIdError(errors.InputValueErrorCode, "GeoTIFF: "+detail)
+}
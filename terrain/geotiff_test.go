@@ -0,0 +1,377 @@
+package terrain
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// encodeTIFF テスト用TIFFの生成
+//
+// 先頭のIFDのみのTIFFを生成する。
+// タグの値は[]uint16(SHORT)、[]uint32(LONG)、[]float64(DOUBLE)、string(ASCII)で指定する。
+// ストリップ、タイルの位置、バイト数はoffsetTag、countTagに設定する。
+func encodeTIFF(order binary.ByteOrder, tags map[uint16]any, blocks [][]byte, offsetTag, countTag uint16) []byte {
+	buf := &bytes.Buffer{}
+	if order == binary.LittleEndian {
+		buf.WriteString("II")
+	} else {
+		buf.WriteString("MM")
+	}
+	buf.Write(make([]byte, 6))
+
+	offsets, counts := []uint32{}, []uint32{}
+	for _, block := range blocks {
+		offsets = append(offsets, uint32(buf.Len()))
+		counts = append(counts, uint32(len(block)))
+		buf.Write(block)
+	}
+	if buf.Len()%2 == 1 {
+		buf.WriteByte(0)
+	}
+	tags[offsetTag], tags[countTag] = offsets, counts
+
+	keys := []int{}
+	for tag := range tags {
+		keys = append(keys, int(tag))
+	}
+	sort.Ints(keys)
+
+	ifdOffset := buf.Len()
+	extOffset := ifdOffset + 2 + 12*len(keys) + 4
+	ifd, ext := &bytes.Buffer{}, &bytes.Buffer{}
+	binary.Write(ifd, order, uint16(len(keys)))
+	for _, key := range keys {
+		var typ uint16
+		var count int
+		data := &bytes.Buffer{}
+		switch v := tags[uint16(key)].(type) {
+		case []uint16:
+			typ, count = 3, len(v)
+			binary.Write(data, order, v)
+		case []uint32:
+			typ, count = 4, len(v)
+			binary.Write(data, order, v)
+		case []float64:
+			typ, count = 12, len(v)
+			binary.Write(data, order, v)
+		case string:
+			typ, count = 2, len(v)+1
+			data.WriteString(v + "\x00")
+		}
+
+		binary.Write(ifd, order, uint16(key))
+		binary.Write(ifd, order, typ)
+		binary.Write(ifd, order, uint32(count))
+		if data.Len() <= 4 {
+			ifd.Write(append(data.Bytes(), make([]byte, 4-data.Len())...))
+			continue
+		}
+		binary.Write(ifd, order, uint32(extOffset+ext.Len()))
+		ext.Write(data.Bytes())
+		if ext.Len()%2 == 1 {
+			ext.WriteByte(0)
+		}
+	}
+	binary.Write(ifd, order, uint32(0))
+
+	buf.Write(ifd.Bytes())
+	buf.Write(ext.Bytes())
+	raw := buf.Bytes()
+	order.PutUint16(raw[2:], 42)
+	order.PutUint32(raw[4:], uint32(ifdOffset))
+	return raw
+}
+
+// geoKeys テスト用GeoKeyDirectoryの生成
+func geoKeys(modelType, rasterType uint16) []uint16 {
+	return []uint16{1, 1, 0, 2, keyGTModelType, 0, 1, modelType, keyGTRasterType, 0, 1, rasterType}
+}
+
+// TestReadGeoTIFF01 正常系動作確認(非圧縮、ストリップ)
+//
+// 試験詳細：
+// + 試験データ
+//   - リトルエンディアン、32ビット浮動小数点数、3×2画素、1行ごとのストリップ
+//   - ModelTiepoint：(0, 0)→(139.0, 36.0)、ModelPixelScale：0.1度、PixelIsArea
+//   - GDAL_NODATA：-9999
+//
+// + 確認内容
+//   - 北西端の画素の中心が(139.05, 35.95)となること
+//   - 画素の中心の標高、補間した標高が取得されること
+//   - 欠損値が除外されること
+func TestReadGeoTIFF01(t *testing.T) {
+	//入力パラメータ
+	blocks := [][]byte{}
+	for _, row := range [][]float32{{100, 101, 102}, {110, -9999, 112}} {
+		block := &bytes.Buffer{}
+		binary.Write(block, binary.LittleEndian, row)
+		blocks = append(blocks, block.Bytes())
+	}
+	raw := encodeTIFF(binary.LittleEndian, map[uint16]any{
+		tagImageWidth:      []uint16{3},
+		tagImageLength:     []uint16{2},
+		tagBitsPerSample:   []uint16{32},
+		tagSampleFormat:    []uint16{sampleFormatFloat},
+		tagRowsPerStrip:    []uint16{1},
+		tagModelPixelScale: []float64{0.1, 0.1, 0},
+		tagModelTiepoint:   []float64{0, 0, 0, 139.0, 36.0, 0},
+		tagGeoKeyDirectory: geoKeys(2, 1),
+		tagGDALNoData:      "-9999",
+	}, blocks, tagStripOffsets, tagStripByteCounts)
+
+	// テスト対象呼び出し
+	dem, err := ReadGeoTIFF(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	west, south, east, north := dem.Bounds()
+	if math.Abs(west-139.0) > 1e-9 || math.Abs(north-36.0) > 1e-9 || math.Abs(east-139.3) > 1e-9 || math.Abs(south-35.8) > 1e-9 {
+		t.Errorf("範囲 - 期待値：139.0, 35.8, 139.3, 36.0, 取得値：%v, %v, %v, %v", west, south, east, north)
+	}
+
+	for _, c := range []struct {
+		lon, lat, expect float64
+		ok               bool
+	}{
+		{139.05, 35.95, 100, true},
+		{139.15, 35.95, 101, true},
+		{139.10, 35.95, 100.5, true},
+		{139.10, 35.90, (100 + 101 + 110) / 3.0, true},
+		{139.15, 35.85, 0, false},
+		{139.35, 35.85, 0, false},
+	} {
+		result, ok := dem.Elevation(c.lon, c.lat)
+		if ok != c.ok || math.Abs(result-c.expect) > 1e-9 {
+			t.Errorf("標高(%v, %v) - 期待値：%v, %v, 取得値：%v, %v", c.lon, c.lat, c.expect, c.ok, result, ok)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestReadGeoTIFF02 正常系動作確認(Deflate圧縮、タイル)
+//
+// 試験詳細：
+// + 試験データ
+//   - ビッグエンディアン、16ビット符号付き整数、3×3画素、2×2画素のタイル
+//   - Deflate圧縮、水平差分予測
+//   - ModelTiepoint：(1, 1)→(140.0, 35.0)、ModelPixelScale：0.5度、PixelIsPoint
+//
+// + 確認内容
+//   - タイルの端数部分を含めて全画素の標高が取得されること
+//   - 負の値が取得されること
+func TestReadGeoTIFF02(t *testing.T) {
+	//入力パラメータ
+	value := func(x, y int) int16 { return int16(10*y + x - 5) }
+	blocks := [][]byte{}
+	for ty := 0; ty < 2; ty++ {
+		for tx := 0; tx < 2; tx++ {
+			tile := &bytes.Buffer{}
+			for y := 2 * ty; y < 2*ty+2; y++ {
+				previous := int16(0)
+				for x := 2 * tx; x < 2*tx+2; x++ {
+					v := int16(0)
+					if x < 3 && y < 3 {
+						v = value(x, y)
+					}
+					binary.Write(tile, binary.BigEndian, v-previous)
+					previous = v
+				}
+			}
+			compressed := &bytes.Buffer{}
+			writer := zlib.NewWriter(compressed)
+			writer.Write(tile.Bytes())
+			writer.Close()
+			blocks = append(blocks, compressed.Bytes())
+		}
+	}
+	raw := encodeTIFF(binary.BigEndian, map[uint16]any{
+		tagImageWidth:      []uint16{3},
+		tagImageLength:     []uint16{3},
+		tagBitsPerSample:   []uint16{16},
+		tagSampleFormat:    []uint16{sampleFormatInt},
+		tagCompression:     []uint16{compressionDeflate},
+		tagPredictor:       []uint16{predictorHorizontal},
+		tagTileWidth:       []uint16{2},
+		tagTileLength:      []uint16{2},
+		tagModelPixelScale: []float64{0.5, 0.5, 0},
+		tagModelTiepoint:   []float64{1, 1, 0, 140.0, 35.0, 0},
+		tagGeoKeyDirectory: geoKeys(2, rasterPixelIsPoint),
+	}, blocks, tagTileOffsets, tagTileByteCounts)
+
+	// テスト対象呼び出し
+	dem, err := ReadGeoTIFF(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			lon, lat := 139.5+0.5*float64(x), 35.5-0.5*float64(y)
+			result, ok := dem.Elevation(lon, lat)
+			if !ok || result != float64(value(x, y)) {
+				t.Errorf("標高(%v, %v) - 期待値：%v, 取得値：%v, %v", lon, lat, value(x, y), result, ok)
+			}
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestReadGeoTIFF03 異常系動作確認
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：TIFF形式でないバイト列
+//   - パターン2：投影座標系
+//   - パターン3：ModelPixelScaleなし
+//   - パターン4：未対応の圧縮形式(LZW)
+//   - パターン5：3バンド
+//
+// + 確認内容
+//   - 入力チェックエラーとなること
+func TestReadGeoTIFF03(t *testing.T) {
+	//入力パラメータ
+	base := func(modify func(map[uint16]any)) []byte {
+		tags := map[uint16]any{
+			tagImageWidth:      []uint16{1},
+			tagImageLength:     []uint16{1},
+			tagBitsPerSample:   []uint16{8},
+			tagModelPixelScale: []float64{0.1, 0.1, 0},
+			tagModelTiepoint:   []float64{0, 0, 0, 139.0, 36.0, 0},
+			tagGeoKeyDirectory: geoKeys(2, 1),
+		}
+		modify(tags)
+		return encodeTIFF(binary.LittleEndian, tags, [][]byte{{1, 2, 3}}, tagStripOffsets, tagStripByteCounts)
+	}
+	inputs := [][]byte{
+		[]byte("P5 not a tiff"),
+		base(func(tags map[uint16]any) { tags[tagGeoKeyDirectory] = geoKeys(modelTypeProjected, 1) }),
+		base(func(tags map[uint16]any) { delete(tags, tagModelPixelScale) }),
+		base(func(tags map[uint16]any) { tags[tagCompression] = []uint16{5} }),
+		base(func(tags map[uint16]any) { tags[tagSamplesPerPixel] = []uint16{3} }),
+	}
+
+	// 正常な入力の確認
+	if _, err := ReadGeoTIFF(bytes.NewReader(base(func(map[uint16]any) {}))); err != nil {
+		t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, err := ReadGeoTIFF(bytes.NewReader(input))
+
+		if err == nil || !strings.HasPrefix(err.Error(), "InputValueError") {
+			t.Errorf("パターン%d error - 期待値：入力チェックエラー, 取得値：%v", i+1, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// TestReadGeoTIFF04 異常系動作確認(不正なヘッダ、切り詰められたファイル)
+//
+// 試験詳細：
+// + 試験データ
+//   - パターン1：画像の大きさが2^30×2^30画素
+//   - パターン2：ストリップのバイト数がファイルの大きさを超える
+//   - パターン3：ストリップの位置がファイルの大きさを超える
+//   - パターン4：IFDの位置がファイルの大きさを超える
+//   - パターン5：末尾を切り詰めたファイル(タグの値がファイルの範囲外)
+//   - パターン6：パターン5をSizeを持たない読込元から読み込む
+//
+// + 確認内容
+//   - 画素値を確保する前に入力チェックエラーとなること
+//   - Sizeを持たない読込元でも正常なファイルが読み込めること
+func TestReadGeoTIFF04(t *testing.T) {
+	//入力パラメータ
+	base := func(modify func(map[uint16]any)) []byte {
+		tags := map[uint16]any{
+			tagImageWidth:      []uint16{1},
+			tagImageLength:     []uint16{1},
+			tagBitsPerSample:   []uint16{8},
+			tagModelPixelScale: []float64{0.1, 0.1, 0},
+			tagModelTiepoint:   []float64{0, 0, 0, 139.0, 36.0, 0},
+			tagGeoKeyDirectory: geoKeys(2, 1),
+		}
+		modify(tags)
+		// ストリップの位置、バイト数は未使用のタグに出力し、modifyで指定する
+		return encodeTIFF(binary.LittleEndian, tags, [][]byte{{1}}, 65000, 65001)
+	}
+	strip := func(offset, count uint32) func(map[uint16]any) {
+		return func(tags map[uint16]any) {
+			tags[tagStripOffsets], tags[tagStripByteCounts] = []uint32{offset}, []uint32{count}
+		}
+	}
+	valid := base(strip(8, 1))
+	badIFD := append([]byte{}, valid...)
+	binary.LittleEndian.PutUint32(badIFD[4:], 0xFFFFFF00)
+	truncated := valid[:len(valid)-16]
+	inputs := []io.ReaderAt{
+		bytes.NewReader(base(func(tags map[uint16]any) {
+			strip(8, 1)(tags)
+			tags[tagImageWidth], tags[tagImageLength] = []uint32{1 << 30}, []uint32{1 << 30}
+		})),
+		bytes.NewReader(base(strip(8, 0xFFFFFFF0))),
+		bytes.NewReader(base(strip(0xFFFFFFF0, 1))),
+		bytes.NewReader(badIFD),
+		bytes.NewReader(truncated),
+		struct{ io.ReaderAt }{bytes.NewReader(truncated)},
+	}
+
+	// 正常な入力の確認
+	for _, r := range []io.ReaderAt{bytes.NewReader(valid), struct{ io.ReaderAt }{bytes.NewReader(valid)}} {
+		if _, err := ReadGeoTIFF(r); err != nil {
+			t.Fatalf("error - 期待値：nil, 取得値：%v", err)
+		}
+	}
+
+	for i, input := range inputs {
+		// テスト対象呼び出し
+		_, err := ReadGeoTIFF(input)
+
+		if err == nil || !strings.HasPrefix(err.Error(), "InputValueError") {
+			t.Errorf("パターン%d error - 期待値：入力チェックエラー, 取得値：%v", i+1, err)
+		}
+	}
+
+	t.Log("テスト終了")
+}
+
+// FuzzReadGeoTIFF 任意のバイト列の読込
+//
+// 試験詳細：
+// + 試験データ
+//   - 正常なGeoTIFF、TIFF形式でないバイト列を初期値とする任意のバイト列
+//
+// + 確認内容
+//   - パニックが発生しないこと
+//   - 読み込めない場合、入力チェックエラーとなること
+func FuzzReadGeoTIFF(f *testing.F) {
+	f.Add(encodeTIFF(binary.LittleEndian, map[uint16]any{
+		tagImageWidth:      []uint16{1},
+		tagImageLength:     []uint16{1},
+		tagBitsPerSample:   []uint16{8},
+		tagModelPixelScale: []float64{0.1, 0.1, 0},
+		tagModelTiepoint:   []float64{0, 0, 0, 139.0, 36.0, 0},
+		tagGeoKeyDirectory: geoKeys(2, 1),
+	}, [][]byte{{1}}, tagStripOffsets, tagStripByteCounts))
+	f.Add([]byte("P5 not a tiff"))
+	f.Add([]byte("II*\x00\x08\x00\x00\x00"))
+
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		// テスト対象呼び出し
+		_, err := ReadGeoTIFF(bytes.NewReader(raw))
+
+		if err != nil && !strings.HasPrefix(err.Error(), "InputValueError") {
+			t.Fatalf("error - 期待値：入力チェックエラー, 取得値：%v", err)
+		}
+	})
+}